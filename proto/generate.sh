@@ -0,0 +1,15 @@
+#!/bin/sh
+# Gera o código Go do contrato gRPC em cada serviço.
+# Requer protoc, protoc-gen-go (v1.36) e protoc-gen-go-grpc (v1.5) no PATH.
+set -e
+
+cd "$(dirname "$0")"
+
+for service in service-a service-b; do
+	out="../$service/internal/pb"
+	mkdir -p "$out"
+	protoc -I . \
+		--go_out="$out" --go_opt=paths=source_relative \
+		--go-grpc_out="$out" --go-grpc_opt=paths=source_relative \
+		weather/v1/weather.proto
+done
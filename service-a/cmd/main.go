@@ -1,15 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"service-a/internal/auth"
+	"service-a/internal/balancer"
 	"service-a/internal/config"
 	"service-a/internal/delivery"
+	"service-a/internal/graph"
+	"service-a/internal/i18n"
+	"service-a/internal/openapi"
+	weatherv1 "service-a/internal/pb/weather/v1"
+	"service-a/internal/serviceb"
+	"service-a/internal/stream"
 	"service-a/internal/tracing"
+	"shared/encoder"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -27,13 +42,226 @@ func main() {
 	httpClient := &http.Client{
 		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
-	handler := delivery.NewCEPHandler(cfg.ServiceBURL, httpClient)
+	// Com várias instâncias do serviço B, as chamadas HTTP são balanceadas entre elas; as URLs
+	// continuam montadas sobre SERVICE_B_URL, cujo host é trocado pelo da instância escolhida
+	var serviceBHTTP serviceb.HTTPClient = httpClient
+	resolver, err := serviceBResolver(cfg)
+	if err != nil {
+		log.Fatalf("Invalid service B endpoints: %v", err)
+	}
+	if resolver != nil {
+		lb := balancer.New(resolver, httpClient,
+			balancer.WithPolicy(cfg.ServiceBLBPolicy),
+			balancer.WithRefreshInterval(cfg.ServiceBDiscoveryInterval),
+			balancer.WithOutlierDetection(cfg.ServiceBEjectFailures, cfg.ServiceBEjectDuration),
+		)
+		if err := lb.Refresh(context.Background()); err != nil {
+			// A descoberta é tentada de novo a cada intervalo
+			log.Printf("Failed to resolve service B endpoints: %v", err)
+		}
+		if cfg.ServiceBDiscovery != "" {
+			go lb.Run(context.Background())
+		}
+		serviceBHTTP = lb
+		log.Printf("Service B load balancing: %s", cfg.ServiceBLBPolicy)
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		// Com SERVICE_B_GRPC_ADDR=dns:///host:porta, as conexões são distribuídas entre os endereços
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+	}
+	// Quem fez a requisição segue para o serviço B assinado com o segredo compartilhado
+	if cfg.InternalAuthSecret != "" {
+		signer := auth.NewSigner([]byte(cfg.InternalAuthSecret))
+		serviceBHTTP = signer.Client(serviceBHTTP)
+		dialOpts = append(dialOpts,
+			grpc.WithUnaryInterceptor(signer.UnaryClientInterceptor()),
+			grpc.WithStreamInterceptor(signer.StreamClientInterceptor()))
+		log.Println("Internal subject header enabled")
+	}
+
+	var handlerOpts []delivery.CEPHandlerOption
+	var clientOpts []serviceb.ClientOption
+	var graphOpts []graph.ClientOption
+	if cfg.ServiceBTransport == config.ServiceBTransportGRPC {
+		conn, err := grpc.NewClient(cfg.ServiceBGRPCAddr, dialOpts...)
+		if err != nil {
+			log.Fatalf("Failed to create gRPC client for %s: %v", cfg.ServiceBGRPCAddr, err)
+		}
+		defer conn.Close()
+		grpcClient := weatherv1.NewWeatherServiceClient(conn)
+		clientOpts = append(clientOpts, serviceb.WithGRPCClient(grpcClient))
+		graphOpts = append(graphOpts, graph.WithGRPCClient(grpcClient))
+		log.Printf("Service B transport: gRPC (%s)", cfg.ServiceBGRPCAddr)
+	}
+	if cfg.ServiceBHedge {
+		clientOpts = append(clientOpts, serviceb.WithHedging(serviceb.HedgePolicy{
+			Delay:      cfg.ServiceBHedgeDelay,
+			Percentile: cfg.ServiceBHedgePercentile,
+			Budget:     cfg.ServiceBHedgeBudget,
+		}))
+		log.Printf("Service B hedging enabled (p%g, min %s, budget %g)", cfg.ServiceBHedgePercentile*100, cfg.ServiceBHedgeDelay, cfg.ServiceBHedgeBudget)
+	}
+	if cfg.APIV1Sunset != "" {
+		// Data de desligamento da v1, anunciada no cabeçalho Sunset
+		sunset, err := time.Parse(time.DateOnly, cfg.APIV1Sunset)
+		if err != nil {
+			log.Fatalf("Invalid API v1 sunset date: %v", err)
+		}
+		handlerOpts = append(handlerOpts, delivery.WithV1Sunset(sunset))
+	}
+	// O mesmo cliente tipado atende as rotas, o stream e o GraphQL, com o limite de resposta, o
+	// balanceamento e o hedging configurados
+	serviceBClient := serviceb.NewClient(cfg.ServiceBURL, serviceBHTTP, clientOpts...)
+	handler := delivery.NewCEPHandler(serviceBClient, handlerOpts...)
+
+	// Um único poller por CEP atende todos os assinantes do stream
+	hub := stream.NewHub(stream.NewServiceBSource(serviceBClient), cfg.StreamPollInterval, cfg.StreamReplaySize)
+	streamHandler := delivery.NewStreamHandler(hub, cfg.StreamHeartbeatInterval)
+	wsHandler := delivery.NewWSHandler(hub, cfg.WSMaxSubscriptions, cfg.WSPingInterval, splitList(cfg.WSAllowedOrigins))
+
+	// Gateway GraphQL: os resolvers consultam o serviço B em lote, pelo transporte configurado
+	gateway, err := graph.NewGateway(graph.NewServiceBClient(serviceBClient, graphOpts...), cfg.GraphQLMaxCEPs, cfg.GraphQLMaxDepth)
+	if err != nil {
+		log.Fatalf("Failed to parse GraphQL schema: %v", err)
+	}
+	graphQLHandler := delivery.NewGraphQLHandler(gateway)
+
+	// O histórico e os alertas do serviço B são expostos pelo serviço A, com o escopo de cada um
+	serviceBProxy := delivery.NewProxyHandler(cfg.ServiceBURL, serviceBHTTP)
+
+	// Com chaves de API ou JWKS configurados, as rotas exigem X-API-Key ou um token com o escopo
+	// da operação; a autenticação roda dentro do span da rota, que recebe o client_id
+	protect := func(auth.Operation) func(http.Handler) http.Handler {
+		return func(h http.Handler) http.Handler { return h }
+	}
+	authOpts, err := authenticatorOptions(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+	if len(authOpts) > 0 {
+		protect = auth.NewAuthenticator(authOpts...).Middleware
+	} else {
+		log.Println("Authentication disabled: no API keys or JWKS configured")
+	}
+
+	// Contrato OpenAPI: documento em /openapi.json e validação das requisições
+	apiDoc, err := openapi.Load()
+	if err != nil {
+		log.Fatalf("Failed to load OpenAPI spec: %v", err)
+	}
+	specHandler, err := openapi.NewSpecHandler(apiDoc)
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI spec: %v", err)
+	}
+
+	var validatorOpts []openapi.ValidatorOption
+	if cfg.OpenAPIValidateResponses {
+		validatorOpts = append(validatorOpts, openapi.WithResponseValidation())
+		log.Println("OpenAPI response validation enabled")
+	}
+	validator, err := openapi.NewValidator(apiDoc, validatorOpts...)
+	if err != nil {
+		log.Fatalf("Failed to create OpenAPI validator: %v", err)
+	}
+
+	// A autenticação e a cota do cliente rodam antes da validação do contrato: sem credencial a
+	// resposta é 401 (ou 429 com a cota esgotada), sem revelar o contrato nem gastar a validação
+	route := func(op auth.Operation, h http.HandlerFunc, name string) http.Handler {
+		return otelhttp.NewHandler(protect(op)(validator.Middleware(h)), name)
+	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/cep", otelhttp.NewHandler(http.HandlerFunc(handler.Handle), "cep-handler"))
+	mux.Handle("/cep", route(auth.OperationLookup, handler.Handle, "cep-handler"))
+	mux.Handle("POST /v1/cep", route(auth.OperationLookup, handler.Handle, "cep-v1-handler"))
+	mux.Handle("POST /v2/cep", route(auth.OperationLookup, handler.Handle, "cep-v2-handler"))
+	mux.Handle("GET /cep/{cep}/stream", route(auth.OperationLookup, streamHandler.Handle, "cep-stream-handler"))
+	mux.Handle("GET /ws", route(auth.OperationLookup, wsHandler.Handle, "cep-ws-handler"))
+	mux.Handle("POST /graphql", route(auth.OperationBatch, graphQLHandler.Handle, "graphql-handler"))
+	mux.Handle("GET /history/", route(auth.OperationHistory, serviceBProxy.Handle, "history-handler"))
+	mux.Handle("/alerts/", route(auth.OperationAlerts, serviceBProxy.Handle, "alerts-handler"))
+	mux.Handle("GET /openapi.json", specHandler)
+
+	// Idioma das mensagens, negociado pelo Accept-Language e repassado ao serviço B
+	negotiator, err := i18n.NewNegotiator(cfg.DefaultLanguage)
+	if err != nil {
+		log.Fatalf("Failed to create language negotiator: %v", err)
+	}
+
+	encoders := encoder.NewRegistry()
 
 	log.Println("Starting server on :8080")
-	if err := http.ListenAndServe(":8080", mux); err != nil {
+	if err := http.ListenAndServe(":8080", negotiator.Middleware(encoders.Middleware(mux))); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// splitList separa uma lista de valores separados por vírgula, ignorando os vazios
+func splitList(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// serviceBResolver retorna o Resolver das instâncias do serviço B, ou nil quando só há SERVICE_B_URL
+func serviceBResolver(cfg *config.Config) (balancer.Resolver, error) {
+	switch {
+	case cfg.ServiceBDiscovery != "":
+		return balancer.NewDNSResolver(cfg.ServiceBDiscovery, nil)
+	case cfg.ServiceBEndpoints != "":
+		return balancer.NewStaticResolver(splitList(cfg.ServiceBEndpoints)), nil
+	default:
+		return nil, nil
+	}
+}
+
+// authenticatorOptions habilita as chaves de API_KEYS e de API_KEYS_FILE, com os limites padrão
+// da configuração, e os tokens verificados pelo JWKS de JWKS_FILE ou JWKS_URL
+func authenticatorOptions(cfg *config.Config) ([]auth.AuthenticatorOption, error) {
+	var opts []auth.AuthenticatorOption
+
+	keys := auth.NewKeyStore()
+	defaults := auth.Limits{Rate: cfg.APIKeyRate, Burst: cfg.APIKeyBurst, DailyQuota: cfg.APIKeyDailyQuota}
+	if err := keys.ParseList(cfg.APIKeys, defaults); err != nil {
+		return nil, err
+	}
+	if cfg.APIKeysFile != "" {
+		if err := keys.LoadFile(cfg.APIKeysFile, defaults); err != nil {
+			return nil, err
+		}
+	}
+	if keys.Len() > 0 {
+		opts = append(opts, auth.WithAPIKeys(keys, auth.NewLimiter()))
+		log.Printf("API key authentication enabled (%d keys)", keys.Len())
+	}
+
+	var keySet auth.KeySet
+	switch {
+	case cfg.JWKSFile != "":
+		var err error
+		if keySet, err = auth.LoadJWKSFile(cfg.JWKSFile); err != nil {
+			return nil, err
+		}
+	case cfg.JWKSURL != "":
+		jwksClient := &http.Client{Timeout: 10 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+		keySet = auth.NewRemoteKeySet(cfg.JWKSURL, jwksClient, cfg.JWKSCacheTTL)
+	default:
+		return opts, nil
+	}
+	verifier := auth.NewVerifier(keySet, cfg.JWTIssuer, cfg.JWTAudience, auth.WithLeeway(cfg.JWTLeeway))
+	scopes := auth.ScopeMap{
+		auth.OperationLookup:  cfg.JWTScopeLookup,
+		auth.OperationBatch:   cfg.JWTScopeBatch,
+		auth.OperationHistory: cfg.JWTScopeHistory,
+		auth.OperationAlerts:  cfg.JWTScopeAlerts,
+	}
+	opts = append(opts, auth.WithBearer(verifier, scopes))
+	log.Printf("Bearer token authentication enabled (issuer %s, audience %s)", cfg.JWTIssuer, cfg.JWTAudience)
+	return opts, nil
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// SubjectHeader leva ao serviço B quem fez a requisição, assinado com o segredo compartilhado
+// (mesmo cabeçalho lido pelo serviço B)
+const SubjectHeader = "X-Internal-Subject"
+
+// SubjectMetadataKey é o equivalente de SubjectHeader nos metadados gRPC
+const SubjectMetadataKey = "x-internal-subject"
+
+// subjectTTL é a validade da assinatura, curta para limitar a reutilização do cabeçalho
+const subjectTTL = time.Minute
+
+// subjectClaims é o conteúdo assinado do cabeçalho
+type subjectClaims struct {
+	Subject    string      `json:"sub"`
+	ClientID   string      `json:"client_id,omitempty"`
+	Operations []Operation `json:"ops"`
+	ExpiresAt  int64       `json:"exp"`
+}
+
+// Signer assina o Principal da requisição para o serviço B: o cabeçalho é o conteúdo em JSON e o
+// HMAC-SHA256 dele, ambos em base64url e separados por ponto
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSigner cria o Signer com o segredo compartilhado com o serviço B
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret, now: time.Now}
+}
+
+// Sign retorna o valor do cabeçalho para o Principal
+func (s *Signer) Sign(p Principal) string {
+	data, _ := json.Marshal(subjectClaims{
+		Subject:    p.Subject,
+		ClientID:   p.ClientID,
+		Operations: p.Operations,
+		ExpiresAt:  s.now().Add(subjectTTL).Unix(),
+	})
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(data)
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Client envia o cabeçalho assinado nas requisições feitas em nome de um Principal. Requisições
+// sem Principal no contexto (ex.: pollers compartilhados do stream) seguem sem o cabeçalho.
+func (s *Signer) Client(next HTTPClient) HTTPClient {
+	return &signingClient{next: next, signer: s}
+}
+
+type signingClient struct {
+	next   HTTPClient
+	signer *Signer
+}
+
+func (c *signingClient) Do(req *http.Request) (*http.Response, error) {
+	principal, ok := PrincipalFromContext(req.Context())
+	if !ok {
+		return c.next.Do(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(SubjectHeader, c.signer.Sign(principal))
+	return c.next.Do(req)
+}
+
+// UnaryClientInterceptor envia o cabeçalho assinado nos metadados das chamadas gRPC
+func (s *Signer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if principal, ok := PrincipalFromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, SubjectMetadataKey, s.Sign(principal))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor envia o cabeçalho assinado nos metadados dos streams gRPC (ex.: o
+// BatchGetByCEP do GraphQL)
+func (s *Signer) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if principal, ok := PrincipalFromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, SubjectMetadataKey, s.Sign(principal))
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
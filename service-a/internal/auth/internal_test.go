@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+var testPrincipal = Principal{Subject: "user-1", ClientID: "dashboard", Operations: []Operation{OperationLookup, OperationHistory}}
+
+func newSigner() *Signer {
+	s := NewSigner(testSecret)
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+// decodeSubject confere a assinatura do cabeçalho e retorna o conteúdo
+func decodeSubject(t *testing.T, value string) subjectClaims {
+	t.Helper()
+	payload, signature, ok := strings.Cut(value, ".")
+	require.True(t, ok)
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write(data)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), signature)
+
+	var claims subjectClaims
+	require.NoError(t, json.Unmarshal(data, &claims))
+	return claims
+}
+
+func TestSigner_Sign(t *testing.T) {
+	claims := decodeSubject(t, newSigner().Sign(testPrincipal))
+
+	assert.Equal(t, subjectClaims{
+		Subject:    "user-1",
+		ClientID:   "dashboard",
+		Operations: []Operation{OperationLookup, OperationHistory},
+		ExpiresAt:  testNow.Add(time.Minute).Unix(),
+	}, claims)
+}
+
+func TestSigner_Client(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(&http.Response{StatusCode: http.StatusOK}, nil)
+	client := newSigner().Client(httpClient)
+
+	signed, err := http.NewRequestWithContext(WithPrincipal(context.Background(), testPrincipal), http.MethodGet, "http://service-b/weather/Sao%20Paulo", nil)
+	require.NoError(t, err)
+	_, err = client.Do(signed)
+	require.NoError(t, err)
+	unsigned, err := http.NewRequest(http.MethodGet, "http://service-b/weather/Sao%20Paulo", nil)
+	require.NoError(t, err)
+	_, err = client.Do(unsigned)
+	require.NoError(t, err)
+
+	sent := httpClient.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, "user-1", decodeSubject(t, sent.Header.Get(SubjectHeader)).Subject)
+	// A requisição original não é alterada
+	assert.Empty(t, signed.Header.Get(SubjectHeader))
+	sent = httpClient.Calls[1].Arguments.Get(0).(*http.Request)
+	assert.Empty(t, sent.Header.Get(SubjectHeader))
+}
+
+func TestSigner_UnaryClientInterceptor(t *testing.T) {
+	interceptor := newSigner().UnaryClientInterceptor()
+	var sent []metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		sent = append(sent, md)
+		return nil
+	}
+
+	require.NoError(t, interceptor(WithPrincipal(context.Background(), testPrincipal), "/weather.v1.WeatherService/GetByCEP", nil, nil, nil, invoker))
+	require.NoError(t, interceptor(context.Background(), "/weather.v1.WeatherService/GetByCEP", nil, nil, nil, invoker))
+
+	require.Len(t, sent[0].Get(SubjectMetadataKey), 1)
+	assert.Equal(t, testPrincipal.Operations, decodeSubject(t, sent[0].Get(SubjectMetadataKey)[0]).Operations)
+	assert.Empty(t, sent[1].Get(SubjectMetadataKey))
+}
+
+func TestSigner_StreamClientInterceptor(t *testing.T) {
+	interceptor := newSigner().StreamClientInterceptor()
+	var sent metadata.MD
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	}
+
+	_, err := interceptor(WithPrincipal(context.Background(), testPrincipal), &grpc.StreamDesc{ServerStreams: true}, nil, "/weather.v1.WeatherService/BatchGetByCEP", streamer)
+
+	require.NoError(t, err)
+	require.Len(t, sent.Get(SubjectMetadataKey), 1)
+	assert.Equal(t, "dashboard", decodeSubject(t, sent.Get(SubjectMetadataKey)[0]).ClientID)
+}
@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// DefaultJWKSCacheTTL é por quanto tempo o JWKS buscado na URL é reutilizado
+	DefaultJWKSCacheTTL = 10 * time.Minute
+	// minJWKSRefreshInterval é o menor intervalo entre duas buscas do JWKS, mesmo com kid desconhecido
+	minJWKSRefreshInterval = time.Minute
+	// maxJWKSBytes limita o tamanho do JWKS buscado
+	maxJWKSBytes = 1 << 20
+	// jwksFetchTimeout limita cada busca do JWKS, que não depende do prazo das requisições
+	jwksFetchTimeout = 10 * time.Second
+	// minRSABits é o menor módulo RSA aceito
+	minRSABits = 2048
+)
+
+// ErrUnknownKey indica que o kid do token não está no JWKS
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// HTTPClient é uma interface para o cliente HTTP
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// KeySet fornece as chaves públicas que verificam as assinaturas dos tokens
+type KeySet interface {
+	// Key retorna a chave do kid; kid vazio só é aceito quando o conjunto tem uma única chave
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// jwk é uma chave pública do JWKS (RFC 7517); só RSA e EC são suportadas
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// curves são as curvas EC suportadas, pelo crv do JWK
+var curves = map[string]struct {
+	curve elliptic.Curve
+	ecdh  ecdh.Curve
+}{
+	"P-256": {elliptic.P256(), ecdh.P256()},
+	"P-384": {elliptic.P384(), ecdh.P384()},
+	"P-521": {elliptic.P521(), ecdh.P521()},
+}
+
+// ParseJWKS lê as chaves de assinatura de um JWKS, indexadas pelo kid. Chaves de cifragem e de
+// tipos não suportados são ignoradas.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsa()
+		case "EC":
+			key, err = k.ec()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JWK %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no supported signing keys")
+	}
+	return keys, nil
+}
+
+// rsa decodifica a chave RSA
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if key.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA key must have at least %d bits", minRSABits)
+	}
+	return key, nil
+}
+
+// ec decodifica a chave EC, conferindo se o ponto está na curva
+func (k jwk) ec() (*ecdsa.PublicKey, error) {
+	curve, ok := curves[k.Crv]
+	if !ok {
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	size := (curve.curve.Params().BitSize + 7) / 8
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+	if errX != nil || errY != nil || len(x) != size || len(y) != size {
+		return nil, errors.New("invalid coordinates")
+	}
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := curve.ecdh.NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid point: %w", err)
+	}
+	return &ecdsa.PublicKey{Curve: curve.curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
+
+// lookupKey busca a chave do kid no conjunto
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// staticKeySet é um JWKS fixo
+type staticKeySet map[string]crypto.PublicKey
+
+func (s staticKeySet) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	return lookupKey(s, kid)
+}
+
+// LoadJWKSFile lê o JWKS de um arquivo local, uma única vez, para validar os tokens sem acesso
+// ao provedor de identidade
+func LoadJWKSFile(path string) (KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS file: %w", err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return staticKeySet(keys), nil
+}
+
+// remoteKeySet busca o JWKS em uma URL e o guarda em cache
+type remoteKeySet struct {
+	url        string
+	httpClient HTTPClient
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+	// refreshing é fechado ao fim da busca em andamento; nil sem busca
+	refreshing chan struct{}
+}
+
+// NewRemoteKeySet cria o KeySet do JWKS em url, reutilizado por ttl. Um kid desconhecido (chave
+// nova do provedor) antecipa a busca, no máximo uma vez por minuto; se a busca falhar, as chaves
+// atuais continuam valendo. Uma única busca roda por vez, fora do lock e sem o contexto de quem a
+// disparou, e as chaves atuais seguem atendendo enquanto ela roda.
+func NewRemoteKeySet(url string, httpClient HTTPClient, ttl time.Duration) KeySet {
+	return &remoteKeySet{url: url, httpClient: httpClient, ttl: ttl, now: time.Now}
+}
+
+func (s *remoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	now := s.now()
+	key, err := lookupKey(s.keys, kid)
+	expired := now.Sub(s.fetchedAt) >= s.ttl
+	if (err != nil || expired) && s.refreshing == nil && now.Sub(s.attemptedAt) >= minJWKSRefreshInterval {
+		s.attemptedAt = now
+		s.refreshing = make(chan struct{})
+		go s.refresh(context.WithoutCancel(ctx), now, s.refreshing)
+	}
+	refreshing := s.refreshing
+	s.mu.Unlock()
+
+	// Com a chave em cache, não espera a busca, mesmo com o cache vencido
+	if err == nil || refreshing == nil {
+		return s.result(key, err)
+	}
+	select {
+	case <-refreshing:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	key, err = lookupKey(s.keys, kid)
+	s.mu.Unlock()
+	return s.result(key, err)
+}
+
+// result completa a busca da chave com o erro da última busca quando ainda não há chaves
+func (s *remoteKeySet) result(key crypto.PublicKey, err error) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		if s.lastErr != nil {
+			return nil, fmt.Errorf("JWKS unavailable: %w", s.lastErr)
+		}
+		return nil, errors.New("JWKS unavailable")
+	}
+	return key, err
+}
+
+// refresh busca o JWKS com um prazo próprio e avisa o fim fechando done
+func (s *remoteKeySet) refresh(ctx context.Context, startedAt time.Time, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+	keys, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		log.Printf("Auth: Error fetching JWKS from %s: %v", s.url, err)
+		s.lastErr = err
+	} else {
+		s.keys, s.fetchedAt, s.lastErr = keys, startedAt, nil
+	}
+	s.refreshing = nil
+	close(done)
+}
+
+// fetch busca e decodifica o JWKS
+func (s *remoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "jwks-fetch")
+	defer span.End()
+	span.SetAttributes(attribute.String("url", s.url))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "Error creating JWKS request")
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Error, "Error fetching JWKS")
+		return nil, err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("status", resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		span.SetStatus(codes.Error, "JWKS endpoint returned error")
+		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		span.SetStatus(codes.Error, "Error reading JWKS")
+		return nil, err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid JWKS")
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("keys", len(keys)))
+	return keys, nil
+}
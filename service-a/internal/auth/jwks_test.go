@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockHTTPClient struct {
+	mock.Mock
+}
+
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	args := m.Called(req)
+	resp, _ := args.Get(0).(*http.Response)
+	return resp, args.Error(1)
+}
+
+func jwksResponse(body []byte) *http.Response {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys, err := ParseJWKS(jwksJSON(t,
+		rsaJWK("rsa-1", &rsaKey.PublicKey),
+		ecJWK("ec-1", &ecKey.PublicKey),
+		map[string]string{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		map[string]string{"kty": "oct", "kid": "hmac-1", "k": "c2VjcmV0"},
+	))
+
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.True(t, rsaKey.PublicKey.Equal(keys["rsa-1"]))
+	assert.True(t, ecKey.PublicKey.Equal(keys["ec-1"]))
+}
+
+func TestParseJWKS_Invalid(t *testing.T) {
+	smallKey := &rsa.PublicKey{N: new(big.Int).Rsh(rsaKey.N, 1100), E: 65537}
+	offCurve := ecJWK("ec-1", &ecKey.PublicKey)
+	offCurve["y"] = offCurve["x"]
+
+	for name, data := range map[string][]byte{
+		"not json":        []byte("{"),
+		"no keys":         jwksJSON(t),
+		"small RSA key":   jwksJSON(t, rsaJWK("rsa-1", smallKey)),
+		"point off curve": jwksJSON(t, offCurve),
+	} {
+		_, err := ParseJWKS(data)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, rsaJWK("rsa-1", &rsaKey.PublicKey)), 0o600))
+
+	keySet, err := LoadJWKSFile(path)
+	require.NoError(t, err)
+
+	// Com uma única chave, o token sem kid também é aceito
+	for _, kid := range []string{"rsa-1", ""} {
+		key, err := keySet.Key(context.Background(), kid)
+		require.NoError(t, err)
+		assert.True(t, rsaKey.PublicKey.Equal(key))
+	}
+	_, err = keySet.Key(context.Background(), "rsa-2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+// waitRefresh espera a busca do JWKS em andamento, se houver
+func waitRefresh(s *remoteKeySet) {
+	s.mu.Lock()
+	refreshing := s.refreshing
+	s.mu.Unlock()
+	if refreshing != nil {
+		<-refreshing
+	}
+}
+
+func TestRemoteKeySet_CachesAndRefreshes(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(jwksResponse(jwksJSON(t, rsaJWK("rsa-1", &rsaKey.PublicKey))), nil).Once()
+	httpClient.On("Do", mock.Anything).Return(jwksResponse(jwksJSON(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey))), nil).Once()
+	httpClient.On("Do", mock.Anything).Return(nil, errors.New("connection refused"))
+	keySet := NewRemoteKeySet("https://idp.example.com/jwks", httpClient, 10*time.Minute).(*remoteKeySet)
+	clock := &fakeClock{now: testNow}
+	keySet.now = clock.Now
+
+	_, err := keySet.Key(context.Background(), "rsa-1")
+	require.NoError(t, err)
+	_, err = keySet.Key(context.Background(), "rsa-1")
+	require.NoError(t, err)
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+
+	// Um kid novo só antecipa a busca depois do intervalo mínimo
+	_, err = keySet.Key(context.Background(), "ec-1")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	clock.now = clock.now.Add(time.Minute)
+	_, err = keySet.Key(context.Background(), "ec-1")
+	require.NoError(t, err)
+	httpClient.AssertNumberOfCalls(t, "Do", 2)
+
+	// Vencido o cache, a chave atual atende sem esperar a busca, e uma falha nela mantém as chaves
+	clock.now = clock.now.Add(10 * time.Minute)
+	_, err = keySet.Key(context.Background(), "ec-1")
+	require.NoError(t, err)
+	waitRefresh(keySet)
+	httpClient.AssertNumberOfCalls(t, "Do", 3)
+	_, err = keySet.Key(context.Background(), "ec-1")
+	require.NoError(t, err)
+	req := httpClient.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, "https://idp.example.com/jwks", req.URL.String())
+}
+
+func TestRemoteKeySet_Unavailable(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(&http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(bytes.NewReader(nil))}, nil)
+	keySet := NewRemoteKeySet("https://idp.example.com/jwks", httpClient, 10*time.Minute)
+
+	_, err := keySet.Key(context.Background(), "rsa-1")
+	assert.ErrorContains(t, err, "JWKS unavailable")
+	// Sem chaves, as novas tentativas também respeitam o intervalo mínimo
+	_, err = keySet.Key(context.Background(), "rsa-1")
+	assert.ErrorContains(t, err, "JWKS unavailable")
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestRemoteKeySet_SingleFlight(t *testing.T) {
+	release := make(chan struct{})
+	var fetchCtxErr error
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		<-release
+		fetchCtxErr = args.Get(0).(*http.Request).Context().Err()
+	}).Return(jwksResponse(jwksJSON(t, rsaJWK("rsa-1", &rsaKey.PublicKey))), nil).Once()
+	keySet := NewRemoteKeySet("https://idp.example.com/jwks", httpClient, 10*time.Minute).(*remoteKeySet)
+
+	// Quem desiste de esperar não cancela a busca dos demais
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := keySet.Key(ctx, "rsa-1")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := keySet.Key(context.Background(), "rsa-1")
+		result <- err
+	}()
+	close(release)
+
+	require.NoError(t, <-result)
+	waitRefresh(keySet)
+	assert.NoError(t, fetchCtxErr)
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	// Registram os hashes dos algoritmos aceitos
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway é a tolerância de relógio na conferência de exp e nbf
+const DefaultLeeway = time.Minute
+
+// Erros da validação dos tokens
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// algorithm é um algoritmo de assinatura aceito (RFC 7518)
+type algorithm struct {
+	hash crypto.Hash
+	// curve é a curva exigida nos algoritmos ECDSA; nil nos RSA
+	curve elliptic.Curve
+}
+
+// algorithms são os algoritmos aceitos; none e os simétricos (HS*) são sempre recusados
+var algorithms = map[string]algorithm{
+	"RS256": {hash: crypto.SHA256},
+	"RS384": {hash: crypto.SHA384},
+	"RS512": {hash: crypto.SHA512},
+	"ES256": {hash: crypto.SHA256, curve: elliptic.P256()},
+	"ES384": {hash: crypto.SHA384, curve: elliptic.P384()},
+	"ES512": {hash: crypto.SHA512, curve: elliptic.P521()},
+}
+
+// Claims são as declarações do token usadas pelo serviço A
+type Claims struct {
+	Subject string
+	// ClientID é a aplicação para a qual o token foi emitido (azp ou client_id)
+	ClientID  string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// Verifier valida os tokens JWT do provedor de identidade: assinatura pelo JWKS, emissor,
+// audiência e validade
+type Verifier struct {
+	keys     KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// VerifierOption configura o Verifier
+type VerifierOption func(*Verifier)
+
+// WithLeeway troca a tolerância de relógio (DefaultLeeway)
+func WithLeeway(leeway time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// NewVerifier cria o Verifier dos tokens emitidos por issuer para audience
+func NewVerifier(keys KeySet, issuer, audience string, opts ...VerifierOption) *Verifier {
+	v := &Verifier{keys: keys, issuer: issuer, audience: audience, leeway: DefaultLeeway, now: time.Now}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// header é o cabeçalho JOSE do token
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Crit []string `json:"crit"`
+}
+
+// payload são as declarações lidas do token
+type payload struct {
+	Issuer    string     `json:"iss"`
+	Subject   string     `json:"sub"`
+	Audience  stringList `json:"aud"`
+	ExpiresAt *float64   `json:"exp"`
+	NotBefore *float64   `json:"nbf"`
+	Scope     string     `json:"scope"`
+	// scp é a forma usada por alguns provedores, em lista ou separada por espaços
+	Scp      stringList `json:"scp"`
+	AZP      string     `json:"azp"`
+	ClientID string     `json:"client_id"`
+}
+
+// stringList aceita um texto ou uma lista de textos
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Verify valida o token e retorna as declarações. Os erros envolvem ErrInvalidToken ou, para
+// tokens vencidos, ErrTokenExpired.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if len(h.Crit) > 0 {
+		return nil, fmt.Errorf("%w: unsupported critical headers %v", ErrInvalidToken, h.Crit)
+	}
+	alg, ok := algorithms[h.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, h.Alg)
+	}
+	key, err := v.keys.Key(ctx, h.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	if err := verifySignature(alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var p payload
+	if err := decodeSegment(parts[1], &p); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	return v.validate(p)
+}
+
+// validate confere emissor, audiência, sujeito e validade
+func (v *Verifier) validate(p payload) (*Claims, error) {
+	now := v.now()
+	switch {
+	case p.Issuer != v.issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, p.Issuer)
+	case !slices.Contains(p.Audience, v.audience):
+		return nil, fmt.Errorf("%w: audience %v does not include %q", ErrInvalidToken, []string(p.Audience), v.audience)
+	case p.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	case p.ExpiresAt == nil:
+		return nil, fmt.Errorf("%w: missing expiration", ErrInvalidToken)
+	}
+	expiresAt := numericDate(*p.ExpiresAt)
+	if !now.Before(expiresAt.Add(v.leeway)) {
+		return nil, fmt.Errorf("%w at %s", ErrTokenExpired, expiresAt.Format(time.RFC3339))
+	}
+	if p.NotBefore != nil && now.Add(v.leeway).Before(numericDate(*p.NotBefore)) {
+		return nil, fmt.Errorf("%w: not valid before %s", ErrInvalidToken, numericDate(*p.NotBefore).Format(time.RFC3339))
+	}
+
+	claims := &Claims{Subject: p.Subject, ClientID: p.AZP, ExpiresAt: expiresAt, Scopes: strings.Fields(p.Scope)}
+	if claims.ClientID == "" {
+		claims.ClientID = p.ClientID
+	}
+	for _, scp := range p.Scp {
+		claims.Scopes = append(claims.Scopes, strings.Fields(scp)...)
+	}
+	return claims, nil
+}
+
+// verifySignature confere a assinatura com a chave, que precisa ser do tipo do algoritmo
+func verifySignature(alg algorithm, key crypto.PublicKey, signed string, signature []byte) error {
+	hasher := alg.hash.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if alg.curve != nil {
+			return errors.New("key type does not match algorithm")
+		}
+		if err := rsa.VerifyPKCS1v15(key, alg.hash, digest, signature); err != nil {
+			return errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if alg.curve == nil || key.Curve != alg.curve {
+			return errors.New("key type does not match algorithm")
+		}
+		// A assinatura JWS ECDSA é r || s, cada um com o tamanho da curva
+		size := (alg.curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return errors.New("unsupported key type")
+	}
+	return nil
+}
+
+// decodeSegment decodifica uma parte base64url do token em out
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// numericDate converte um NumericDate (segundos desde a época) em time.Time
+func numericDate(seconds float64) time.Time {
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))).UTC()
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "service-a"
+)
+
+// testNow é o instante dos testes dos tokens
+var testNow = time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)
+
+// Chaves de assinatura dos testes, geradas uma vez por execução
+var (
+	rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32)))}
+}
+
+func jwksJSON(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+// testKeySet tem a chave RSA em "rsa-1" e a EC em "ec-1"
+func testKeySet(t *testing.T) KeySet {
+	t.Helper()
+	keys, err := ParseJWKS(jwksJSON(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)))
+	require.NoError(t, err)
+	return staticKeySet(keys)
+}
+
+func newVerifier(t *testing.T) *Verifier {
+	t.Helper()
+	v := NewVerifier(testKeySet(t), testIssuer, testAudience)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+// validClaims são as declarações de um token válido em testNow
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   testIssuer,
+		"sub":   "user-1",
+		"aud":   []string{testAudience, "other"},
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"scope": "weather:read history:read",
+		"azp":   "dashboard",
+	}
+}
+
+// signToken assina as declarações com a chave, no formato JWS compacto
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(header) + "." + b64(payload)
+
+	algo := algorithms[alg]
+	hasher := algo.hash.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, algo.hash, digest)
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		require.NoError(t, err)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+	}
+	return signed + "." + b64(signature)
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	token := signToken(t, "RS256", "rsa-1", rsaKey, validClaims())
+
+	claims, err := newVerifier(t).Verify(context.Background(), token)
+
+	require.NoError(t, err)
+	assert.Equal(t, &Claims{
+		Subject:   "user-1",
+		ClientID:  "dashboard",
+		Scopes:    []string{"weather:read", "history:read"},
+		ExpiresAt: testNow.Add(time.Hour),
+	}, claims)
+}
+
+func TestVerifier_ES256(t *testing.T) {
+	claims := validClaims()
+	claims["aud"] = testAudience
+	delete(claims, "scope")
+	delete(claims, "azp")
+	claims["scp"] = []string{"weather:batch"}
+	claims["client_id"] = "batch-job"
+	token := signToken(t, "ES256", "ec-1", ecKey, claims)
+
+	verified, err := newVerifier(t).Verify(context.Background(), token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "batch-job", verified.ClientID)
+	assert.Equal(t, []string{"weather:batch"}, verified.Scopes)
+}
+
+func TestVerifier_Leeway(t *testing.T) {
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-30 * time.Second).Unix()
+
+	_, err := newVerifier(t).Verify(context.Background(), signToken(t, "RS256", "rsa-1", rsaKey, claims))
+
+	assert.NoError(t, err)
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	valid := signToken(t, "RS256", "rsa-1", rsaKey, validClaims())
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "expired", token: signToken(t, "RS256", "rsa-1", rsaKey, with("exp", testNow.Add(-2*time.Minute).Unix())), err: ErrTokenExpired},
+		{name: "missing exp", token: signToken(t, "RS256", "rsa-1", rsaKey, with("exp", nil)), err: ErrInvalidToken},
+		{name: "not yet valid", token: signToken(t, "RS256", "rsa-1", rsaKey, with("nbf", testNow.Add(time.Hour).Unix())), err: ErrInvalidToken},
+		{name: "wrong issuer", token: signToken(t, "RS256", "rsa-1", rsaKey, with("iss", "https://evil.example.com")), err: ErrInvalidToken},
+		{name: "wrong audience", token: signToken(t, "RS256", "rsa-1", rsaKey, with("aud", "other")), err: ErrInvalidToken},
+		{name: "missing subject", token: signToken(t, "RS256", "rsa-1", rsaKey, with("sub", nil)), err: ErrInvalidToken},
+		{name: "unknown kid", token: signToken(t, "RS256", "rsa-2", rsaKey, validClaims()), err: ErrInvalidToken},
+		{name: "key does not match algorithm", token: signToken(t, "ES256", "rsa-1", ecKey, validClaims()), err: ErrInvalidToken},
+		{name: "tampered claims", token: parts[0] + "." + b64([]byte(`{"iss":"`+testIssuer+`","sub":"admin","aud":"service-a","exp":9999999999}`)) + "." + parts[2], err: ErrInvalidToken},
+		{name: "alg none", token: b64([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".", err: ErrInvalidToken},
+		{name: "alg HS256", token: b64([]byte(`{"alg":"HS256","kid":"rsa-1"}`)) + "." + parts[1] + "." + parts[2], err: ErrInvalidToken},
+		{name: "critical header", token: b64([]byte(`{"alg":"RS256","kid":"rsa-1","crit":["exp"]}`)) + "." + parts[1] + "." + parts[2], err: ErrInvalidToken},
+		{name: "malformed", token: "not-a-token", err: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newVerifier(t).Verify(context.Background(), tt.token)
+
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
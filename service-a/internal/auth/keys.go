@@ -0,0 +1,142 @@
+// Package auth autentica as requisições do serviço A pela chave de API, com o limite de taxa
+// (balde de fichas) e a cota diária de cada cliente, ou pelo token JWT do provedor de identidade,
+// com as operações autorizadas pelos escopos, e assina o autenticado para o serviço B.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Limits são os limites de requisições de um cliente
+type Limits struct {
+	// Rate é a reposição do balde, em requisições por segundo
+	Rate float64
+	// Burst é a capacidade do balde, a maior rajada aceita
+	Burst int
+	// DailyQuota é o máximo de requisições por dia (UTC); zero não limita
+	DailyQuota int
+}
+
+// validate confere se os limites são utilizáveis
+func (l Limits) validate() error {
+	if l.Rate <= 0 || l.Burst < 1 {
+		return errors.New("rate and burst must be positive")
+	}
+	if l.DailyQuota < 0 {
+		return errors.New("daily quota must not be negative")
+	}
+	return nil
+}
+
+// Client é o dono de uma chave de API. Um cliente pode ter várias chaves (ex.: durante a troca
+// de chave), que compartilham os limites.
+type Client struct {
+	ID string
+	Limits
+}
+
+// KeyStore guarda os clientes pelo SHA-256 da chave; a chave em si nunca é guardada
+type KeyStore struct {
+	clients map[[sha256.Size]byte]Client
+}
+
+// NewKeyStore cria um KeyStore vazio
+func NewKeyStore() *KeyStore {
+	return &KeyStore{clients: make(map[[sha256.Size]byte]Client)}
+}
+
+// Add registra o cliente com o SHA-256 da chave, em hexadecimal (ex.: echo -n chave | sha256sum)
+func (s *KeyStore) Add(client Client, keyHash string) error {
+	if client.ID == "" {
+		return errors.New("client id is required")
+	}
+	if err := client.Limits.validate(); err != nil {
+		return fmt.Errorf("client %s: %w", client.ID, err)
+	}
+	decoded, err := hex.DecodeString(strings.TrimSpace(keyHash))
+	if err != nil || len(decoded) != sha256.Size {
+		return fmt.Errorf("client %s: key hash must be a hex-encoded SHA-256", client.ID)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], decoded)
+	if existing, ok := s.clients[sum]; ok {
+		return fmt.Errorf("client %s: key already registered for client %s", client.ID, existing.ID)
+	}
+	s.clients[sum] = client
+	return nil
+}
+
+// Lookup retorna o cliente da chave. A comparação é feita pelo hash, então o tempo da busca não
+// revela nada sobre as chaves registradas.
+func (s *KeyStore) Lookup(key string) (Client, bool) {
+	client, ok := s.clients[sha256.Sum256([]byte(key))]
+	return client, ok
+}
+
+// Len é o número de chaves registradas
+func (s *KeyStore) Len() int {
+	return len(s.clients)
+}
+
+// ParseList registra as chaves de uma lista "cliente:sha256" separada por vírgulas (formato da
+// variável API_KEYS), todas com os limites padrão
+func (s *KeyStore) ParseList(raw string, defaults Limits) error {
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, keyHash, ok := strings.Cut(entry, ":")
+		if !ok {
+			return fmt.Errorf("invalid API key entry %q: expected client:sha256", entry)
+		}
+		if err := s.Add(Client{ID: strings.TrimSpace(id), Limits: defaults}, keyHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileEntry é uma chave no arquivo de chaves; limites omitidos usam os padrões
+type fileEntry struct {
+	ClientID   string   `json:"client_id"`
+	KeySHA256  string   `json:"key_sha256"`
+	Rate       *float64 `json:"rate"`
+	Burst      *int     `json:"burst"`
+	DailyQuota *int     `json:"daily_quota"`
+}
+
+// LoadFile registra as chaves de um arquivo JSON com a lista
+// [{"client_id", "key_sha256", "rate", "burst", "daily_quota"}]
+func (s *KeyStore) LoadFile(path string, defaults Limits) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read API keys file: %w", err)
+	}
+	var entries []fileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("decode API keys file: %w", err)
+	}
+	for _, entry := range entries {
+		limits := defaults
+		if entry.Rate != nil {
+			limits.Rate = *entry.Rate
+		}
+		if entry.Burst != nil {
+			limits.Burst = *entry.Burst
+		}
+		if entry.DailyQuota != nil {
+			limits.DailyQuota = *entry.DailyQuota
+		}
+		if err := s.Add(Client{ID: entry.ClientID, Limits: limits}, entry.KeySHA256); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultLimits = Limits{Rate: 10, Burst: 20, DailyQuota: 1000}
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestKeyStore_ParseList(t *testing.T) {
+	keys := NewKeyStore()
+
+	err := keys.ParseList(" acme:"+hash("acme-key")+", ,globex:"+hash("globex-key"), defaultLimits)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, keys.Len())
+	client, ok := keys.Lookup("globex-key")
+	require.True(t, ok)
+	assert.Equal(t, Client{ID: "globex", Limits: defaultLimits}, client)
+	_, ok = keys.Lookup(hash("globex-key"))
+	assert.False(t, ok, "the stored hash is not a valid key")
+}
+
+func TestKeyStore_ParseListInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"acme",
+		"acme:not-hex",
+		"acme:" + hash("acme-key")[:32],
+		":" + hash("acme-key"),
+		"acme:" + hash("acme-key") + ",globex:" + hash("acme-key"),
+	} {
+		assert.Error(t, NewKeyStore().ParseList(raw, defaultLimits), raw)
+	}
+}
+
+func TestKeyStore_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"client_id": "acme", "key_sha256": "`+hash("acme-key")+`", "rate": 1, "daily_quota": 0},
+		{"client_id": "acme", "key_sha256": "`+hash("acme-new-key")+`", "rate": 1, "daily_quota": 0},
+		{"client_id": "globex", "key_sha256": "`+hash("globex-key")+`"}
+	]`), 0o600))
+	keys := NewKeyStore()
+
+	require.NoError(t, keys.LoadFile(path, defaultLimits))
+
+	acme, ok := keys.Lookup("acme-new-key")
+	require.True(t, ok)
+	assert.Equal(t, Client{ID: "acme", Limits: Limits{Rate: 1, Burst: 20}}, acme)
+	globex, ok := keys.Lookup("globex-key")
+	require.True(t, ok)
+	assert.Equal(t, defaultLimits, globex.Limits)
+}
+
+func TestKeyStore_LoadFileInvalidLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"client_id": "acme", "key_sha256": "`+hash("acme-key")+`", "burst": 0}]`), 0o600))
+
+	assert.EqualError(t, NewKeyStore().LoadFile(path, defaultLimits), "client acme: rate and burst must be positive")
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Motivos da recusa de uma requisição
+const (
+	ExceededRate  = "rate"
+	ExceededQuota = "quota"
+)
+
+// Decision é o resultado do Limiter para uma requisição e o limite informado nos cabeçalhos
+// RateLimit-*: o balde de fichas ou, quando está mais perto do fim, a cota diária
+type Decision struct {
+	Allowed bool
+	// Exceeded é ExceededRate ou ExceededQuota quando a requisição é recusada
+	Exceeded string
+	// Limit, Remaining e Reset descrevem o limite informado
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+}
+
+// Limiter aplica o balde de fichas e a cota diária de cada cliente. Os contadores ficam em
+// memória, por instância do serviço A.
+type Limiter struct {
+	mu    sync.Mutex
+	usage map[string]*usage
+	now   func() time.Time
+}
+
+// usage é o consumo de um cliente
+type usage struct {
+	tokens  float64
+	updated time.Time
+	// day é o início do dia (UTC) contado em used
+	day  time.Time
+	used int
+}
+
+// NewLimiter cria o Limiter
+func NewLimiter() *Limiter {
+	return &Limiter{usage: make(map[string]*usage), now: time.Now}
+}
+
+// Allow decide se o cliente pode fazer mais uma requisição e, se puder, a consome do balde e da
+// cota. Requisições recusadas não consomem nada.
+func (l *Limiter) Allow(client Client) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	today := now.UTC().Truncate(24 * time.Hour)
+	u, ok := l.usage[client.ID]
+	if !ok {
+		u = &usage{tokens: float64(client.Burst), updated: now, day: today}
+		l.usage[client.ID] = u
+	}
+	u.tokens = math.Min(float64(client.Burst), u.tokens+now.Sub(u.updated).Seconds()*client.Rate)
+	u.updated = now
+	if !u.day.Equal(today) {
+		u.day, u.used = today, 0
+	}
+	untilTomorrow := today.Add(24 * time.Hour).Sub(now)
+
+	switch {
+	case client.DailyQuota > 0 && u.used >= client.DailyQuota:
+		return Decision{Exceeded: ExceededQuota, Limit: client.DailyQuota, Reset: untilTomorrow}
+	case u.tokens < 1:
+		return Decision{Exceeded: ExceededRate, Limit: client.Burst, Reset: refill(1-u.tokens, client.Rate)}
+	}
+
+	u.tokens--
+	u.used++
+	decision := Decision{
+		Allowed:   true,
+		Limit:     client.Burst,
+		Remaining: int(u.tokens),
+		Reset:     refill(float64(client.Burst)-u.tokens, client.Rate),
+	}
+	if remaining := client.DailyQuota - u.used; client.DailyQuota > 0 && remaining < decision.Remaining {
+		decision.Limit, decision.Remaining, decision.Reset = client.DailyQuota, remaining, untilTomorrow
+	}
+	return decision
+}
+
+// refill é o tempo para repor tokens fichas
+func refill(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock é um relógio controlado pelo teste
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newLimiter(now time.Time) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: now}
+	limiter := NewLimiter()
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	limiter, clock := newLimiter(time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC))
+	client := Client{ID: "acme", Limits: Limits{Rate: 2, Burst: 3}}
+
+	for i := 2; i >= 0; i-- {
+		decision := limiter.Allow(client)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 3, decision.Limit)
+		assert.Equal(t, i, decision.Remaining)
+		// Reset é o tempo até o balde encher de novo
+		assert.Equal(t, time.Duration(3-i)*500*time.Millisecond, decision.Reset)
+	}
+
+	// Sem fichas, a requisição é recusada até a reposição de uma ficha
+	decision := limiter.Allow(client)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ExceededRate, decision.Exceeded)
+	assert.Equal(t, 0, decision.Remaining)
+	assert.Equal(t, 500*time.Millisecond, decision.Reset)
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.Allow(client).Allowed)
+}
+
+func TestLimiter_ClientsAreIndependent(t *testing.T) {
+	limiter, _ := newLimiter(time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC))
+	limits := Limits{Rate: 1, Burst: 1}
+
+	assert.True(t, limiter.Allow(Client{ID: "acme", Limits: limits}).Allowed)
+	assert.False(t, limiter.Allow(Client{ID: "acme", Limits: limits}).Allowed)
+	assert.True(t, limiter.Allow(Client{ID: "globex", Limits: limits}).Allowed)
+}
+
+func TestLimiter_DailyQuota(t *testing.T) {
+	limiter, clock := newLimiter(time.Date(2025, 1, 20, 23, 0, 0, 0, time.UTC))
+	client := Client{ID: "acme", Limits: Limits{Rate: 100, Burst: 100, DailyQuota: 2}}
+
+	// Com menos requisições restantes que o balde, os cabeçalhos informam a cota
+	decision := limiter.Allow(client)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 2, decision.Limit)
+	assert.Equal(t, 1, decision.Remaining)
+	assert.Equal(t, time.Hour, decision.Reset)
+	assert.True(t, limiter.Allow(client).Allowed)
+
+	decision = limiter.Allow(client)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ExceededQuota, decision.Exceeded)
+	assert.Equal(t, time.Hour, decision.Reset)
+
+	// A cota volta no dia seguinte (UTC)
+	clock.now = clock.now.Add(time.Hour)
+	assert.True(t, limiter.Allow(client).Allowed)
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"service-a/internal/common"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header é o cabeçalho com a chave de API
+const Header = "X-API-Key"
+
+// Principal é quem fez a requisição: o cliente de uma chave de API ou o sujeito de um token
+type Principal struct {
+	// Subject é o sujeito do token ou, com chave de API, o próprio cliente
+	Subject string
+	// ClientID é o cliente da chave de API ou a aplicação do token
+	ClientID string
+	// Operations são as operações autorizadas
+	Operations []Operation
+}
+
+// Allows indica se o Principal pode executar a operação
+func (p Principal) Allows(op Operation) bool {
+	return slices.Contains(p.Operations, op)
+}
+
+type principalKey struct{}
+
+// WithPrincipal guarda o Principal autenticado no contexto
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext retorna o Principal autenticado do contexto
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// ClientID retorna o cliente autenticado do contexto, ou vazio sem autenticação
+func ClientID(ctx context.Context) string {
+	p, _ := PrincipalFromContext(ctx)
+	return p.ClientID
+}
+
+// Authenticator autentica as requisições pela chave de API, com os limites do cliente, e pelo
+// token JWT (Authorization: Bearer), com as operações autorizadas pelos escopos
+type Authenticator struct {
+	keys     *KeyStore
+	limiter  *Limiter
+	verifier *Verifier
+	scopes   ScopeMap
+}
+
+// AuthenticatorOption habilita uma forma de autenticação
+type AuthenticatorOption func(*Authenticator)
+
+// WithAPIKeys aceita as chaves de API de keys, aplicando os limites de cada cliente no limiter
+func WithAPIKeys(keys *KeyStore, limiter *Limiter) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.keys, a.limiter = keys, limiter
+	}
+}
+
+// WithBearer aceita os tokens validados pelo verifier, autorizando as operações pelos escopos
+func WithBearer(verifier *Verifier, scopes ScopeMap) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.verifier, a.scopes = verifier, scopes
+	}
+}
+
+// NewAuthenticator cria o Authenticator com as formas de autenticação das opções
+func NewAuthenticator(opts ...AuthenticatorOption) *Authenticator {
+	a := &Authenticator{}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// authError é a recusa de uma requisição
+type authError struct {
+	problem *common.Problem
+	// challenge é o WWW-Authenticate da recusa, quando há
+	challenge string
+	reason    string
+}
+
+// Middleware exige, para a operação op, uma chave de API ou um token válido. Sem credencial ou
+// com uma inválida, responde 401; com um token sem o escopo da operação, 403; com a chave acima
+// do limite de taxa ou da cota do cliente, 429. As respostas das chaves trazem os cabeçalhos
+// RateLimit-*. O Principal fica no contexto e o cliente e o sujeito, no span da requisição.
+func (a *Authenticator) Middleware(op Operation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tracer := otel.Tracer("service-a")
+			ctx, span := tracer.Start(r.Context(), "authenticate")
+			span.SetAttributes(attribute.String("operation", string(op)))
+
+			principal, method, authErr := a.authenticate(ctx, w, r, op)
+			span.SetAttributes(attribute.String("auth_method", method))
+			if authErr != nil {
+				log.Printf("Auth: Rejected %s %s: %s", r.Method, r.URL.Path, authErr.reason)
+				span.SetStatus(codes.Error, authErr.reason)
+				if authErr.challenge != "" {
+					w.Header().Set("WWW-Authenticate", authErr.challenge)
+				}
+				common.WriteProblem(ctx, w, authErr.problem)
+				span.End()
+				return
+			}
+
+			// O cliente e o sujeito identificam o uso no span da requisição, pai deste
+			attrs := []attribute.KeyValue{attribute.String("client_id", principal.ClientID), attribute.String("enduser.id", principal.Subject)}
+			trace.SpanFromContext(r.Context()).SetAttributes(attrs...)
+			span.SetAttributes(attrs...)
+			span.End()
+
+			log.Printf("Auth: %s %s by %s (client %s, %s)", r.Method, r.URL.Path, principal.Subject, principal.ClientID, method)
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// authenticate identifica o Principal pela credencial da requisição e confere a operação
+func (a *Authenticator) authenticate(ctx context.Context, w http.ResponseWriter, r *http.Request, op Operation) (Principal, string, *authError) {
+	if token, ok := bearerToken(r); ok && a.verifier != nil {
+		principal, err := a.bearer(ctx, token, op)
+		return principal, "bearer", err
+	}
+	if key := r.Header.Get(Header); key != "" && a.keys != nil {
+		principal, err := a.apiKey(w, key)
+		return principal, "api-key", err
+	}
+	return Principal{}, "none", &authError{
+		problem:   common.NewProblem(http.StatusUnauthorized, common.CodeUnauthorized, "missing credentials"),
+		challenge: a.challenge(""),
+		reason:    "Missing credentials",
+	}
+}
+
+// bearer valida o token e confere se os escopos autorizam a operação
+func (a *Authenticator) bearer(ctx context.Context, token string, op Operation) (Principal, *authError) {
+	claims, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		detail := "invalid bearer token"
+		if errors.Is(err, ErrTokenExpired) {
+			detail = "expired bearer token"
+		}
+		return Principal{}, &authError{
+			problem:   common.NewProblem(http.StatusUnauthorized, common.CodeUnauthorized, detail),
+			challenge: a.challenge(`error="invalid_token"`),
+			reason:    err.Error(),
+		}
+	}
+
+	principal := Principal{Subject: claims.Subject, ClientID: claims.ClientID, Operations: a.scopes.Allowed(claims.Scopes)}
+	if !principal.Allows(op) {
+		return principal, &authError{
+			problem:   common.NewProblemf(http.StatusForbidden, common.CodeForbidden, "token does not allow the %s operation", op),
+			challenge: a.challenge(fmt.Sprintf(`error="insufficient_scope", scope=%q`, a.scopes[op])),
+			reason:    fmt.Sprintf("subject %s lacks the scope for %s", claims.Subject, op),
+		}
+	}
+	return principal, nil
+}
+
+// apiKey identifica o cliente da chave e aplica os limites dele
+func (a *Authenticator) apiKey(w http.ResponseWriter, key string) (Principal, *authError) {
+	client, ok := a.keys.Lookup(key)
+	if !ok {
+		return Principal{}, &authError{
+			problem: common.NewProblem(http.StatusUnauthorized, common.CodeUnauthorized, "invalid API key"),
+			reason:  "Invalid API key",
+		}
+	}
+
+	principal := Principal{Subject: client.ID, ClientID: client.ID, Operations: Operations}
+	decision := a.limiter.Allow(client)
+	setRateLimitHeaders(w.Header(), client.Limits, decision)
+	if !decision.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(seconds(decision.Reset)))
+		detail := "rate limit exceeded"
+		if decision.Exceeded == ExceededQuota {
+			detail = "daily quota exceeded"
+		}
+		return principal, &authError{
+			problem: common.NewProblem(http.StatusTooManyRequests, common.CodeRateLimited, detail),
+			reason:  fmt.Sprintf("client %s exceeded its %s limit", client.ID, decision.Exceeded),
+		}
+	}
+	return principal, nil
+}
+
+// challenge é o WWW-Authenticate do esquema Bearer, quando os tokens são aceitos
+func (a *Authenticator) challenge(params string) string {
+	if a.verifier == nil {
+		return ""
+	}
+	if params == "" {
+		return "Bearer"
+	}
+	return "Bearer " + params
+}
+
+// bearerToken extrai o token do cabeçalho Authorization
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// setRateLimitHeaders informa o limite da decisão e as políticas do cliente
+// (draft-ietf-httpapi-ratelimit-headers)
+func setRateLimitHeaders(header http.Header, limits Limits, decision Decision) {
+	header.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(seconds(decision.Reset)))
+
+	// O balde equivale a Burst requisições na janela em que ele se enche de novo
+	policy := fmt.Sprintf("%d;w=%d", limits.Burst, seconds(refill(float64(limits.Burst), limits.Rate)))
+	if limits.DailyQuota > 0 {
+		policy += fmt.Sprintf(", %d;w=%d", limits.DailyQuota, int((24 * time.Hour).Seconds()))
+	}
+	header.Set("RateLimit-Policy", policy)
+}
+
+// seconds arredonda a duração para cima, em segundos inteiros
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-a/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiKeys aceita a chave "acme-key" do cliente acme, com os limites
+func apiKeys(t *testing.T, limits Limits) AuthenticatorOption {
+	t.Helper()
+	keys := NewKeyStore()
+	require.NoError(t, keys.Add(Client{ID: "acme", Limits: limits}, hash("acme-key")))
+	limiter, _ := newLimiter(time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC))
+	return WithAPIKeys(keys, limiter)
+}
+
+// bearer aceita os tokens de testKeySet, com um escopo por operação
+func bearer(t *testing.T) AuthenticatorOption {
+	t.Helper()
+	return WithBearer(newVerifier(t), ScopeMap{
+		OperationLookup:  "weather:read",
+		OperationBatch:   "weather:batch",
+		OperationHistory: "history:read",
+	})
+}
+
+// newHandler protege a operação com o Authenticator e guarda os Principals que chegam ao handler
+func newHandler(t *testing.T, op Operation, opts ...AuthenticatorOption) (http.Handler, *[]Principal) {
+	t.Helper()
+	var principals []Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		require.True(t, ok)
+		principals = append(principals, principal)
+		w.WriteHeader(http.StatusOK)
+	})
+	return NewAuthenticator(opts...).Middleware(op)(next), &principals
+}
+
+func serve(handler http.Handler, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/cep", nil)
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func withKey(key string) http.Header {
+	return http.Header{Header: []string{key}}
+}
+
+func withToken(token string) http.Header {
+	return http.Header{"Authorization": []string{"Bearer " + token}}
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) common.Problem {
+	t.Helper()
+	assert.Equal(t, common.ContentTypeProblem, rec.Header().Get("Content-Type"))
+	var problem common.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestMiddleware_ValidKey(t *testing.T) {
+	handler, principals := newHandler(t, OperationLookup, apiKeys(t, Limits{Rate: 1, Burst: 5, DailyQuota: 100}))
+
+	rec := serve(handler, withKey("acme-key"))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []Principal{{Subject: "acme", ClientID: "acme", Operations: Operations}}, *principals)
+	assert.Equal(t, "5", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "4", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "5;w=5, 100;w=86400", rec.Header().Get("RateLimit-Policy"))
+}
+
+func TestMiddleware_Unauthorized(t *testing.T) {
+	handler, principals := newHandler(t, OperationLookup, apiKeys(t, defaultLimits))
+
+	tests := []struct {
+		name   string
+		header http.Header
+		detail string
+	}{
+		{name: "missing", detail: "missing credentials"},
+		{name: "invalid key", header: withKey("other-key"), detail: "invalid API key"},
+		// Sem JWKS configurado, o token não é aceito
+		{name: "bearer not enabled", header: withToken(signToken(t, "RS256", "rsa-1", rsaKey, validClaims())), detail: "missing credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(handler, tt.header)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			problem := decodeProblem(t, rec)
+			assert.Equal(t, common.CodeUnauthorized, problem.Code)
+			assert.Equal(t, tt.detail, problem.Detail)
+			assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+			assert.Empty(t, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+	assert.Empty(t, *principals)
+}
+
+func TestMiddleware_RateLimited(t *testing.T) {
+	handler, principals := newHandler(t, OperationLookup, apiKeys(t, Limits{Rate: 0.5, Burst: 1}))
+
+	assert.Equal(t, http.StatusOK, serve(handler, withKey("acme-key")).Code)
+	rec := serve(handler, withKey("acme-key"))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, common.CodeRateLimited, problem.Code)
+	assert.Equal(t, "rate limit exceeded", problem.Detail)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Len(t, *principals, 1)
+}
+
+func TestMiddleware_DailyQuotaExceeded(t *testing.T) {
+	handler, _ := newHandler(t, OperationLookup, apiKeys(t, Limits{Rate: 10, Burst: 10, DailyQuota: 1}))
+
+	assert.Equal(t, http.StatusOK, serve(handler, withKey("acme-key")).Code)
+	rec := serve(handler, withKey("acme-key"))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "daily quota exceeded", decodeProblem(t, rec).Detail)
+	// Até a meia-noite (UTC)
+	assert.Equal(t, "30600", rec.Header().Get("Retry-After"))
+}
+
+func TestMiddleware_BearerToken(t *testing.T) {
+	handler, principals := newHandler(t, OperationLookup, apiKeys(t, defaultLimits), bearer(t))
+
+	rec := serve(handler, withToken(signToken(t, "RS256", "rsa-1", rsaKey, validClaims())))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []Principal{{Subject: "user-1", ClientID: "dashboard", Operations: []Operation{OperationLookup, OperationHistory}}}, *principals)
+	// Os tokens não passam pelos limites das chaves de API
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+}
+
+func TestMiddleware_BearerInsufficientScope(t *testing.T) {
+	handler, principals := newHandler(t, OperationBatch, bearer(t))
+
+	rec := serve(handler, withToken(signToken(t, "RS256", "rsa-1", rsaKey, validClaims())))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, common.CodeForbidden, problem.Code)
+	assert.Equal(t, "token does not allow the batch operation", problem.Detail)
+	assert.Equal(t, `Bearer error="insufficient_scope", scope="weather:batch"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Empty(t, *principals)
+}
+
+func TestMiddleware_BearerInvalid(t *testing.T) {
+	handler, _ := newHandler(t, OperationLookup, bearer(t))
+	expired := validClaims()
+	expired["exp"] = testNow.Add(-time.Hour).Unix()
+
+	for detail, token := range map[string]string{
+		"invalid bearer token": signToken(t, "RS256", "rsa-2", rsaKey, validClaims()),
+		"expired bearer token": signToken(t, "RS256", "rsa-1", rsaKey, expired),
+	} {
+		rec := serve(handler, withToken(token))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, detail, decodeProblem(t, rec).Detail)
+		assert.Equal(t, `Bearer error="invalid_token"`, rec.Header().Get("WWW-Authenticate"))
+	}
+
+	rec := serve(handler, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+}
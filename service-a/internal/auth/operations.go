@@ -0,0 +1,39 @@
+package auth
+
+// Operation é uma operação da API, autorizada pelos escopos do token
+type Operation string
+
+// Operações da API
+const (
+	// OperationLookup é a consulta de um CEP: POST /cep, stream e WebSocket
+	OperationLookup Operation = "lookup"
+	// OperationBatch é a consulta de vários CEPs pelo GraphQL
+	OperationBatch Operation = "batch"
+	// OperationHistory é o histórico de consultas: GET /history/..., repassado ao serviço B, que
+	// também exige a operação
+	OperationHistory Operation = "history"
+	// OperationAlerts são as inscrições de alerta: /alerts/..., repassado ao serviço B, que também
+	// exige a operação
+	OperationAlerts Operation = "alerts"
+)
+
+// Operations são todas as operações; uma chave de API autoriza todas
+var Operations = []Operation{OperationLookup, OperationBatch, OperationHistory, OperationAlerts}
+
+// ScopeMap associa cada operação ao escopo do token que a autoriza
+type ScopeMap map[Operation]string
+
+// Allowed retorna as operações autorizadas pelos escopos, na ordem de Operations
+func (m ScopeMap) Allowed(scopes []string) []Operation {
+	granted := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+	var allowed []Operation
+	for _, op := range Operations {
+		if scope, ok := m[op]; ok && granted[scope] {
+			allowed = append(allowed, op)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,64 @@
+package balancer
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Attempts guarda as instâncias usadas pelas tentativas de uma mesma requisição (ex.: a original
+// e o pedido duplicado do hedging). Cada tentativa feita com o contexto de WithAttempts vai a
+// uma instância que as anteriores não usaram, enquanto houver uma disponível.
+type Attempts struct {
+	mu       sync.Mutex
+	balancer *Balancer
+	hosts    []string
+}
+
+type attemptsKey struct{}
+
+// WithAttempts cria o registro de tentativas e o guarda no contexto
+func WithAttempts(ctx context.Context) (context.Context, *Attempts) {
+	a := &Attempts{}
+	return context.WithValue(ctx, attemptsKey{}, a), a
+}
+
+// attemptsFromContext retorna o registro de tentativas do contexto, ou nil
+func attemptsFromContext(ctx context.Context) *Attempts {
+	a, _ := ctx.Value(attemptsKey{}).(*Attempts)
+	return a
+}
+
+// HasAlternative indica se há uma instância disponível que nenhuma tentativa usou. Sem
+// tentativas que tenham passado por um Balancer, não há outra instância conhecida.
+func (a *Attempts) HasAlternative() bool {
+	a.mu.Lock()
+	b, hosts := a.balancer, slices.Clone(a.hosts)
+	a.mu.Unlock()
+
+	if b == nil {
+		return false
+	}
+	return b.hasAlternative(hosts)
+}
+
+// used retorna os hosts já usados; nil em um registro nil
+func (a *Attempts) used() []string {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.hosts)
+}
+
+// record registra a instância escolhida para uma tentativa
+func (a *Attempts) record(b *Balancer, host string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.balancer = b
+	a.hosts = append(a.hosts, host)
+}
@@ -0,0 +1,307 @@
+// Package balancer distribui as requisições HTTP ao serviço B entre as suas instâncias, descobertas
+// por uma lista fixa ou pelo DNS, e afasta temporariamente as instâncias que falham seguidamente.
+package balancer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Políticas de escolha da instância
+const (
+	// PolicyRoundRobin alterna entre as instâncias saudáveis
+	PolicyRoundRobin = "round-robin"
+	// PolicyLeastOutstanding escolhe a instância saudável com menos requisições em andamento
+	PolicyLeastOutstanding = "least-outstanding"
+)
+
+// Valores usados quando as opções correspondentes não são informadas
+const (
+	DefaultRefreshInterval = 30 * time.Second
+	DefaultMaxFailures     = 3
+	DefaultEjectDuration   = 30 * time.Second
+)
+
+// ErrNoEndpoints indica que nenhuma instância do serviço B foi descoberta
+var ErrNoEndpoints = errors.New("no service B endpoints")
+
+// HTTPClient é uma interface para o cliente HTTP
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// endpoint é uma instância do serviço B e o seu estado no balanceamento
+type endpoint struct {
+	base *url.URL
+	// outstanding são as requisições enviadas cujo corpo de resposta ainda não foi fechado
+	outstanding int
+	// failures são as falhas consecutivas; ao chegar a maxFailures, a instância é afastada
+	failures     int
+	ejectedUntil time.Time
+}
+
+// Balancer é um HTTPClient que envia cada requisição a uma instância do serviço B, trocando o
+// esquema e o host da URL pelos da instância escolhida. Falhas de conexão e respostas 5xx contam
+// como falhas da instância (checagem passiva); com maxFailures falhas seguidas, ela deixa de
+// receber requisições por ejectDuration. Se todas estiverem afastadas, todas voltam a ser usadas.
+type Balancer struct {
+	resolver        Resolver
+	next            HTTPClient
+	policy          string
+	refreshInterval time.Duration
+	maxFailures     int
+	ejectDuration   time.Duration
+	now             func() time.Time
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	nextIndex int
+}
+
+// Option configura o Balancer
+type Option func(*Balancer)
+
+// WithPolicy define a política de escolha (PolicyRoundRobin, o padrão, ou PolicyLeastOutstanding)
+func WithPolicy(policy string) Option {
+	return func(b *Balancer) {
+		b.policy = policy
+	}
+}
+
+// WithRefreshInterval define o intervalo entre as consultas ao Resolver em Run
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(b *Balancer) {
+		b.refreshInterval = interval
+	}
+}
+
+// WithOutlierDetection define quantas falhas seguidas afastam uma instância e por quanto tempo;
+// maxFailures zero desativa o afastamento
+func WithOutlierDetection(maxFailures int, ejectDuration time.Duration) Option {
+	return func(b *Balancer) {
+		b.maxFailures = maxFailures
+		b.ejectDuration = ejectDuration
+	}
+}
+
+// New cria o Balancer sobre next. As instâncias são carregadas por Refresh e atualizadas por Run.
+func New(resolver Resolver, next HTTPClient, opts ...Option) *Balancer {
+	b := &Balancer{
+		resolver:        resolver,
+		next:            next,
+		policy:          PolicyRoundRobin,
+		refreshInterval: DefaultRefreshInterval,
+		maxFailures:     DefaultMaxFailures,
+		ejectDuration:   DefaultEjectDuration,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Refresh consulta o Resolver e substitui as instâncias, mantendo o estado das que continuam.
+// Em caso de erro ou de lista vazia, as instâncias atuais são mantidas.
+func (b *Balancer) Refresh(ctx context.Context) error {
+	resolved, err := b.resolver.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if len(resolved) == 0 {
+		return ErrNoEndpoints
+	}
+
+	bases := make([]*url.URL, 0, len(resolved))
+	for _, raw := range resolved {
+		base, err := url.Parse(raw)
+		if err != nil || base.Scheme == "" || base.Host == "" {
+			return fmt.Errorf("invalid service B endpoint %q", raw)
+		}
+		bases = append(bases, base)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := make(map[string]*endpoint, len(b.endpoints))
+	for _, ep := range b.endpoints {
+		current[ep.base.String()] = ep
+	}
+	endpoints := make([]*endpoint, 0, len(bases))
+	for _, base := range bases {
+		if ep, ok := current[base.String()]; ok {
+			endpoints = append(endpoints, ep)
+			continue
+		}
+		endpoints = append(endpoints, &endpoint{base: base})
+	}
+	if len(endpoints) != len(b.endpoints) {
+		log.Printf("Balancer: %d service B endpoints", len(endpoints))
+	}
+	b.endpoints = endpoints
+	return nil
+}
+
+// Run atualiza as instâncias a cada refreshInterval até o contexto ser cancelado
+func (b *Balancer) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.Refresh(ctx); err != nil {
+				log.Printf("Balancer: Error refreshing service B endpoints: %v", err)
+			}
+		}
+	}
+}
+
+// Do envia a requisição a uma das instâncias
+func (b *Balancer) Do(req *http.Request) (*http.Response, error) {
+	attempts := attemptsFromContext(req.Context())
+	ep := b.pick(attempts.used())
+	if ep == nil {
+		return nil, ErrNoEndpoints
+	}
+	attempts.record(b, ep.base.Host)
+	trace.SpanFromContext(req.Context()).SetAttributes(attribute.String("service_b_endpoint", ep.base.Host))
+
+	out := req.Clone(req.Context())
+	out.URL.Scheme = ep.base.Scheme
+	out.URL.Host = ep.base.Host
+	out.Host = ""
+
+	resp, err := b.next.Do(out)
+	if err != nil {
+		// Cancelamento por quem chamou (ex.: o perdedor de um hedge) não diz nada sobre a
+		// instância: nem conta como falha, nem zera a sequência de falhas
+		if req.Context().Err() == nil {
+			b.report(ep, true)
+		}
+		b.release(ep)
+		return nil, err
+	}
+	b.report(ep, resp.StatusCode >= http.StatusInternalServerError)
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { b.release(ep) }}
+	return resp, nil
+}
+
+// pick escolhe a instância pela política, entre as não afastadas, e conta a requisição nela.
+// As instâncias em exclude (já usadas por outra tentativa da mesma requisição) só são escolhidas
+// se não houver outra.
+func (b *Balancer) pick(exclude []string) *endpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	available := b.availableLocked()
+	candidates := make([]*endpoint, 0, len(available))
+	for _, ep := range available {
+		if !slices.Contains(exclude, ep.base.Host) {
+			candidates = append(candidates, ep)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = available
+	}
+	if len(candidates) == 0 {
+		// Todas afastadas: melhor tentar alguma do que recusar todas as requisições
+		candidates = b.endpoints
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// O rodízio também desempata a least-outstanding, para não concentrar tudo na primeira
+	start := b.nextIndex % len(candidates)
+	b.nextIndex++
+	chosen := candidates[start]
+	if b.policy == PolicyLeastOutstanding {
+		for i := 1; i < len(candidates); i++ {
+			if ep := candidates[(start+i)%len(candidates)]; ep.outstanding < chosen.outstanding {
+				chosen = ep
+			}
+		}
+	}
+	chosen.outstanding++
+	return chosen
+}
+
+// hasAlternative indica se há uma instância não afastada fora de exclude
+func (b *Balancer) hasAlternative(exclude []string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ep := range b.availableLocked() {
+		if !slices.Contains(exclude, ep.base.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// availableLocked retorna as instâncias não afastadas
+func (b *Balancer) availableLocked() []*endpoint {
+	now := b.now()
+	available := make([]*endpoint, 0, len(b.endpoints))
+	for _, ep := range b.endpoints {
+		if !now.Before(ep.ejectedUntil) {
+			available = append(available, ep)
+		}
+	}
+	return available
+}
+
+// report registra o resultado da requisição na checagem passiva
+func (b *Balancer) report(ep *endpoint, failed bool) {
+	if b.maxFailures <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		ep.failures = 0
+		return
+	}
+	ep.failures++
+	if ep.failures >= b.maxFailures {
+		ep.ejectedUntil = b.now().Add(b.ejectDuration)
+		// Ao voltar, uma nova falha já a afasta de novo; um sucesso zera a contagem
+		ep.failures = b.maxFailures - 1
+		log.Printf("Balancer: Ejecting service B endpoint %s for %s", ep.base.Host, b.ejectDuration)
+	}
+}
+
+// release encerra a contagem da requisição em andamento
+func (b *Balancer) release(ep *endpoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ep.outstanding--
+}
+
+// releaseBody libera a requisição em andamento no primeiro Close do corpo
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseBody) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
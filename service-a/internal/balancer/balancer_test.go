@@ -0,0 +1,298 @@
+package balancer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockHTTPClient struct {
+	mock.Mock
+}
+
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	args := m.Called(req)
+	resp, _ := args.Get(0).(*http.Response)
+	if resp != nil {
+		// O Balancer troca o corpo da resposta; cada chamada recebe a sua cópia
+		copied := *resp
+		resp = &copied
+	}
+	return resp, args.Error(1)
+}
+
+type MockResolver struct {
+	mock.Mock
+}
+
+func (m *MockResolver) Resolve(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	endpoints, _ := args.Get(0).([]string)
+	return endpoints, args.Error(1)
+}
+
+// fakeClock é um relógio controlado pelo teste
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func response(statusCode int) *http.Response {
+	return &http.Response{StatusCode: statusCode, Body: io.NopCloser(bytes.NewBufferString(`{}`))}
+}
+
+func hostIs(host string) interface{} {
+	return mock.MatchedBy(func(req *http.Request) bool { return req.URL.Host == host })
+}
+
+func newBalancer(t *testing.T, next HTTPClient, endpoints []string, opts ...Option) *Balancer {
+	t.Helper()
+	b := New(NewStaticResolver(endpoints), next, opts...)
+	require.NoError(t, b.Refresh(context.Background()))
+	return b
+}
+
+func newRequest(t *testing.T) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "http://service-b:8090/v1/cep/01001000?detail=full", nil)
+	require.NoError(t, err)
+	return req
+}
+
+// hosts são as instâncias que receberam as requisições, na ordem
+func hosts(next *MockHTTPClient) []string {
+	var hosts []string
+	for _, call := range next.Calls {
+		hosts = append(hosts, call.Arguments.Get(0).(*http.Request).URL.Host)
+	}
+	return hosts
+}
+
+func TestBalancer_RoundRobin(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", mock.Anything).Return(response(http.StatusOK), nil)
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090", "http://10.0.0.2:8090", "http://10.0.0.3:8090"})
+
+	for i := 0; i < 6; i++ {
+		resp, err := b.Do(newRequest(t))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, []string{"10.0.0.1:8090", "10.0.0.2:8090", "10.0.0.3:8090", "10.0.0.1:8090", "10.0.0.2:8090", "10.0.0.3:8090"}, hosts(next))
+	req := next.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, "http", req.URL.Scheme)
+	assert.Equal(t, "/v1/cep/01001000", req.URL.Path)
+	assert.Equal(t, "detail=full", req.URL.RawQuery)
+}
+
+func TestBalancer_LeastOutstanding(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", mock.Anything).Return(response(http.StatusOK), nil)
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090", "http://10.0.0.2:8090"}, WithPolicy(PolicyLeastOutstanding))
+
+	// A primeira resposta fica aberta: a instância 1 continua com uma requisição em andamento
+	slow, err := b.Do(newRequest(t))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		resp, err := b.Do(newRequest(t))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	slow.Body.Close()
+	resp, err := b.Do(newRequest(t))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"10.0.0.1:8090", "10.0.0.2:8090", "10.0.0.2:8090", "10.0.0.2:8090", "10.0.0.1:8090"}, hosts(next))
+}
+
+func TestBalancer_EjectsFailingEndpoint(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", hostIs("10.0.0.1:8090")).Return(response(http.StatusServiceUnavailable), nil)
+	next.On("Do", hostIs("10.0.0.2:8090")).Return(response(http.StatusOK), nil)
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090", "http://10.0.0.2:8090"}, WithOutlierDetection(2, time.Minute))
+	clock := &fakeClock{now: time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)}
+	b.now = clock.Now
+
+	for i := 0; i < 8; i++ {
+		resp, err := b.Do(newRequest(t))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	// Duas falhas seguidas na instância 1 a afastam; as demais requisições vão para a 2
+	assert.Equal(t, []string{"10.0.0.1:8090", "10.0.0.2:8090", "10.0.0.1:8090", "10.0.0.2:8090", "10.0.0.2:8090", "10.0.0.2:8090", "10.0.0.2:8090", "10.0.0.2:8090"}, hosts(next))
+
+	// Passado o afastamento, ela volta ao rodízio e uma nova falha já a afasta de novo
+	clock.now = clock.now.Add(time.Minute)
+	next.Calls = nil
+	for i := 0; i < 4; i++ {
+		resp, err := b.Do(newRequest(t))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, 1, countHost(hosts(next), "10.0.0.1:8090"))
+}
+
+func countHost(hosts []string, host string) int {
+	count := 0
+	for _, h := range hosts {
+		if h == host {
+			count++
+		}
+	}
+	return count
+}
+
+func TestBalancer_SuccessResetsFailures(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", mock.Anything).Return(response(http.StatusBadGateway), nil).Once()
+	next.On("Do", mock.Anything).Return(response(http.StatusOK), nil).Once()
+	next.On("Do", mock.Anything).Return(response(http.StatusBadGateway), nil).Once()
+	next.On("Do", mock.Anything).Return(response(http.StatusOK), nil)
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090"}, WithOutlierDetection(2, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		resp, err := b.Do(newRequest(t))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.True(t, b.endpoints[0].ejectedUntil.IsZero())
+	assert.Equal(t, 1, b.endpoints[0].failures)
+}
+
+func TestBalancer_AllEjectedStillServes(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", mock.Anything).Return(nil, errors.New("connection refused"))
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090"}, WithOutlierDetection(1, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		_, err := b.Do(newRequest(t))
+		assert.EqualError(t, err, "connection refused")
+	}
+	next.AssertNumberOfCalls(t, "Do", 3)
+	assert.Equal(t, 0, b.endpoints[0].outstanding)
+}
+
+func TestBalancer_CancelledRequestIsNotAFailure(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", mock.Anything).Return(nil, context.Canceled)
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090"}, WithOutlierDetection(1, time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := b.Do(newRequest(t).WithContext(ctx))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, b.endpoints[0].ejectedUntil.IsZero())
+}
+
+func TestBalancer_CancelledRequestKeepsFailureStreak(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", mock.Anything).Return(response(http.StatusBadGateway), nil).Once()
+	next.On("Do", mock.Anything).Return(nil, context.Canceled).Once()
+	next.On("Do", mock.Anything).Return(response(http.StatusBadGateway), nil).Once()
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090"}, WithOutlierDetection(2, time.Minute))
+
+	resp, err := b.Do(newRequest(t))
+	require.NoError(t, err)
+	resp.Body.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = b.Do(newRequest(t).WithContext(ctx))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, b.endpoints[0].failures)
+	resp, err = b.Do(newRequest(t))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// O cancelamento no meio não interrompe a sequência: a segunda falha afasta a instância
+	assert.False(t, b.endpoints[0].ejectedUntil.IsZero())
+}
+
+func TestBalancer_Refresh(t *testing.T) {
+	resolver := new(MockResolver)
+	resolver.On("Resolve", mock.Anything).Return([]string{"http://10.0.0.1:8090", "http://10.0.0.2:8090"}, nil).Once()
+	resolver.On("Resolve", mock.Anything).Return(nil, errors.New("no such host")).Once()
+	resolver.On("Resolve", mock.Anything).Return([]string{"http://10.0.0.2:8090", "http://10.0.0.3:8090"}, nil).Once()
+	b := New(resolver, new(MockHTTPClient))
+
+	_, err := b.Do(newRequest(t))
+	assert.ErrorIs(t, err, ErrNoEndpoints)
+
+	require.NoError(t, b.Refresh(context.Background()))
+	b.endpoints[1].failures = 2
+
+	// Um erro do DNS mantém as instâncias atuais
+	assert.Error(t, b.Refresh(context.Background()))
+	assert.Len(t, b.endpoints, 2)
+
+	// As instâncias que continuam mantêm o estado
+	require.NoError(t, b.Refresh(context.Background()))
+	require.Len(t, b.endpoints, 2)
+	assert.Equal(t, "10.0.0.2:8090", b.endpoints[0].base.Host)
+	assert.Equal(t, 2, b.endpoints[0].failures)
+	assert.Equal(t, "10.0.0.3:8090", b.endpoints[1].base.Host)
+}
+
+func TestBalancer_RefreshRejectsInvalidEndpoint(t *testing.T) {
+	b := New(NewStaticResolver([]string{"10.0.0.1:8090"}), new(MockHTTPClient))
+
+	assert.Error(t, b.Refresh(context.Background()))
+}
+
+func TestBalancer_AttemptsUseDifferentEndpoints(t *testing.T) {
+	next := new(MockHTTPClient)
+	next.On("Do", mock.Anything).Return(response(http.StatusOK), nil)
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090", "http://10.0.0.2:8090"})
+	ctx, attempts := WithAttempts(context.Background())
+
+	// Sem tentativas, não se sabe de nenhuma instância
+	assert.False(t, attempts.HasAlternative())
+	for i := 0; i < 2; i++ {
+		resp, err := b.Do(newRequest(t).WithContext(ctx))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, i == 0, attempts.HasAlternative())
+	}
+	// Com todas usadas, a próxima tentativa repete uma instância em vez de falhar
+	resp, err := b.Do(newRequest(t).WithContext(ctx))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"10.0.0.1:8090", "10.0.0.2:8090", "10.0.0.1:8090"}, hosts(next))
+}
+
+func TestBalancer_AttemptsSkipEjectedAlternative(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)}
+	next := new(MockHTTPClient)
+	next.On("Do", hostIs("10.0.0.1:8090")).Return(response(http.StatusOK), nil)
+	next.On("Do", hostIs("10.0.0.2:8090")).Return(response(http.StatusBadGateway), nil)
+	b := newBalancer(t, next, []string{"http://10.0.0.1:8090", "http://10.0.0.2:8090"}, WithOutlierDetection(1, time.Minute))
+	b.now = clock.Now
+
+	// A segunda instância falha e é afastada
+	for i := 0; i < 2; i++ {
+		resp, err := b.Do(newRequest(t))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	ctx, attempts := WithAttempts(context.Background())
+	resp, err := b.Do(newRequest(t).WithContext(ctx))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.False(t, attempts.HasAlternative())
+}
@@ -0,0 +1,103 @@
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Esquemas aceitos por NewDNSResolver
+const (
+	// SchemeDNS resolve os registros A/AAAA do host, mantendo a porta (dns://service-b:8090)
+	SchemeDNS = "dns"
+	// SchemeDNSSRV resolve o registro SRV, com host e porta de cada alvo (dns+srv://_http._tcp.service-b)
+	SchemeDNSSRV = "dns+srv"
+)
+
+// Resolver descobre as instâncias do serviço B, como URLs base (esquema, host e porta)
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// staticResolver retorna sempre a mesma lista de instâncias
+type staticResolver []string
+
+// NewStaticResolver cria um Resolver com a lista fixa de URLs base
+func NewStaticResolver(endpoints []string) Resolver {
+	return staticResolver(endpoints)
+}
+
+// Resolve retorna a lista fixa
+func (r staticResolver) Resolve(context.Context) ([]string, error) {
+	return r, nil
+}
+
+// Lookuper é a parte do net.Resolver usada na descoberta por DNS
+type Lookuper interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type dnsResolver struct {
+	srv      bool
+	host     string
+	port     string
+	lookuper Lookuper
+}
+
+// NewDNSResolver cria um Resolver para o alvo dns://host:porta (registros A/AAAA) ou
+// dns+srv://nome (registro SRV). As instâncias são acessadas por HTTP.
+func NewDNSResolver(target string, lookuper Lookuper) (Resolver, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovery target %q: %w", target, err)
+	}
+	if lookuper == nil {
+		lookuper = net.DefaultResolver
+	}
+
+	switch u.Scheme {
+	case SchemeDNS:
+		if u.Hostname() == "" || u.Port() == "" {
+			return nil, fmt.Errorf("discovery target %q must have a host and a port", target)
+		}
+		return &dnsResolver{host: u.Hostname(), port: u.Port(), lookuper: lookuper}, nil
+	case SchemeDNSSRV:
+		if u.Host == "" {
+			return nil, fmt.Errorf("discovery target %q must have a name", target)
+		}
+		return &dnsResolver{srv: true, host: u.Host, lookuper: lookuper}, nil
+	default:
+		return nil, fmt.Errorf("discovery target %q must use %s:// or %s://", target, SchemeDNS, SchemeDNSSRV)
+	}
+}
+
+// Resolve consulta o DNS e retorna as instâncias em ordem estável
+func (r *dnsResolver) Resolve(ctx context.Context) ([]string, error) {
+	var endpoints []string
+	if r.srv {
+		// O nome já traz serviço e protocolo (_http._tcp.service-b), como no LookupSRV("", "", name)
+		_, records, err := r.lookuper.LookupSRV(ctx, "", "", r.host)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			endpoints = append(endpoints, "http://"+net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+		}
+	} else {
+		addrs, err := r.lookuper.LookupHost(ctx, r.host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			endpoints = append(endpoints, "http://"+net.JoinHostPort(addr, r.port))
+		}
+	}
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
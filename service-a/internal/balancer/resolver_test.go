@@ -0,0 +1,73 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockLookuper struct {
+	mock.Mock
+}
+
+func (m *MockLookuper) LookupHost(ctx context.Context, host string) ([]string, error) {
+	args := m.Called(ctx, host)
+	addrs, _ := args.Get(0).([]string)
+	return addrs, args.Error(1)
+}
+
+func (m *MockLookuper) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	args := m.Called(ctx, service, proto, name)
+	records, _ := args.Get(1).([]*net.SRV)
+	return args.String(0), records, args.Error(2)
+}
+
+func TestDNSResolver_Host(t *testing.T) {
+	lookuper := new(MockLookuper)
+	lookuper.On("LookupHost", mock.Anything, "service-b").Return([]string{"10.0.0.2", "10.0.0.1", "fd00::1"}, nil)
+
+	resolver, err := NewDNSResolver("dns://service-b:8090", lookuper)
+	require.NoError(t, err)
+	endpoints, err := resolver.Resolve(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://10.0.0.1:8090", "http://10.0.0.2:8090", "http://[fd00::1]:8090"}, endpoints)
+}
+
+func TestDNSResolver_SRV(t *testing.T) {
+	lookuper := new(MockLookuper)
+	lookuper.On("LookupSRV", mock.Anything, "", "", "_http._tcp.service-b").Return("", []*net.SRV{
+		{Target: "service-b-1.local.", Port: 8090},
+		{Target: "service-b-0.local.", Port: 8091},
+	}, nil)
+
+	resolver, err := NewDNSResolver("dns+srv://_http._tcp.service-b", lookuper)
+	require.NoError(t, err)
+	endpoints, err := resolver.Resolve(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://service-b-0.local:8091", "http://service-b-1.local:8090"}, endpoints)
+}
+
+func TestDNSResolver_LookupError(t *testing.T) {
+	lookuper := new(MockLookuper)
+	lookuper.On("LookupHost", mock.Anything, "service-b").Return(nil, errors.New("no such host"))
+
+	resolver, err := NewDNSResolver("dns://service-b:8090", lookuper)
+	require.NoError(t, err)
+	_, err = resolver.Resolve(context.Background())
+
+	assert.EqualError(t, err, "no such host")
+}
+
+func TestNewDNSResolver_InvalidTarget(t *testing.T) {
+	for _, target := range []string{"service-b:8090", "dns://service-b", "http://service-b:8090", "dns+srv://"} {
+		_, err := NewDNSResolver(target, new(MockLookuper))
+		assert.Error(t, err, target)
+	}
+}
@@ -1,31 +1,176 @@
+// Package common define o modelo de erro do serviço A: problemas RFC 7807
+// (application/problem+json) com type, código estável e trace ID, no mesmo formato do serviço B.
 package common
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"service-a/internal/i18n"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
-// ErrorResponse define o formato padronizado para respostas de erro
-type ErrorResponse struct {
-	Message string `json:"message"`
-	Code    int    `json:"code"`
-	TraceID string `json:"trace_id,omitempty"` // Campo opcional para correlação de tracing
+// ContentTypeProblem é o Content-Type das respostas de erro
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemTypeBase prefixa o type de cada problema: /problems/ seguido do código em minúsculas,
+// com hífens (ex.: /problems/invalid-zipcode)
+const ProblemTypeBase = "/problems/"
+
+// Códigos estáveis dos problemas; os códigos do serviço B são repassados sem alteração
+const (
+	CodeInvalidZipcode       = "INVALID_ZIPCODE"
+	CodeZipcodeNotFound      = "ZIPCODE_NOT_FOUND"
+	CodeInvalidParameter     = "INVALID_PARAMETER"
+	CodeInvalidRequestBody   = "INVALID_REQUEST_BODY"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeNotAcceptable        = "NOT_ACCEPTABLE"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeUpstreamTimeout      = "UPSTREAM_TIMEOUT"
+	CodeCircuitOpen          = "CIRCUIT_OPEN"
+	CodeUpstreamError        = "UPSTREAM_ERROR"
+	CodeInternal             = "INTERNAL_ERROR"
+)
+
+// titles é o resumo de cada código, igual em todas as ocorrências
+var titles = map[string]string{
+	CodeInvalidZipcode:       "Invalid zipcode",
+	CodeZipcodeNotFound:      "Zipcode not found",
+	CodeInvalidParameter:     "Invalid parameter",
+	CodeInvalidRequestBody:   "Invalid request body",
+	CodeUnsupportedMediaType: "Unsupported media type",
+	CodeNotAcceptable:        "Not acceptable",
+	CodeUnauthorized:         "Unauthorized",
+	CodeForbidden:            "Forbidden",
+	CodeRateLimited:          "Too many requests",
+	CodeUpstreamTimeout:      "Upstream timeout",
+	CodeCircuitOpen:          "Upstream circuit open",
+	CodeUpstreamError:        "Upstream error",
+	CodeInternal:             "Internal error",
+}
+
+// ErrCircuitOpen indica que o cliente do serviço B recusou a chamada com o circuito aberto
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Problem é uma resposta de erro no formato RFC 7807, acrescida do código estável e do trace ID.
+// Title e Detail ficam em inglês e são traduzidos ao responder.
+type Problem struct {
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Status  int    `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+	Code    string `json:"code"`
+	TraceID string `json:"trace_id,omitempty"`
+
+	// detailKey e detailArgs geram o Detail no idioma da requisição; vazios nos problemas do
+	// serviço B, que já chegam traduzidos
+	detailKey  string
+	detailArgs []interface{}
+}
+
+// NewProblem cria o problema do código, com o título padrão e o detalhe desta ocorrência
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{Type: TypeURI(code), Title: titles[code], Status: status, Detail: detail, Code: code, detailKey: detail}
 }
 
-// NewErrorResponse cria uma nova resposta de erro padronizada
-func NewErrorResponse(w http.ResponseWriter, statusCode int, message string, traceID string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// NewProblemf cria o problema com o detalhe formatado; format é a chave do catálogo de mensagens
+func NewProblemf(status int, code, format string, args ...interface{}) *Problem {
+	p := NewProblem(status, code, fmt.Sprintf(format, args...))
+	p.detailKey, p.detailArgs = format, args
+	return p
+}
+
+// TypeURI retorna o type do código
+func TypeURI(code string) string {
+	return ProblemTypeBase + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
 
-	response := ErrorResponse{
-		Message: message,
-		Code:    statusCode,
-		TraceID: traceID,
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// Localize retorna uma cópia do problema com título e detalhe no idioma do contexto
+func (p *Problem) Localize(ctx context.Context) *Problem {
+	problem := *p
+	problem.Title = i18n.Message(ctx, p.Title)
+	if p.detailKey != "" {
+		problem.Detail = i18n.Message(ctx, p.detailKey, p.detailArgs...)
 	}
+	return &problem
+}
 
-	// Log do erro
-	log.Printf("Error: %s, Code: %d, TraceID: %s", message, statusCode, traceID)
+// FromError converte uma falha de comunicação com o serviço B no problema correspondente:
+// timeout vira 504 e circuito aberto, 503. Outros erros resultam em fallback.
+func FromError(err error, fallback *Problem) *Problem {
+	var problem *Problem
+	var netErr net.Error
+	switch {
+	case errors.As(err, &problem):
+		return problem
+	case errors.Is(err, ErrCircuitOpen):
+		return NewProblem(http.StatusServiceUnavailable, CodeCircuitOpen, "service B circuit open")
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return NewProblem(http.StatusGatewayTimeout, CodeUpstreamTimeout, "service B timed out")
+	}
+	return fallback
+}
 
-	json.NewEncoder(w).Encode(response)
+// FromServiceB traduz a resposta de erro do serviço B. Problemas são mantidos com o código
+// original, sem o trace ID; corpos em outro formato ({"error": ...} ou texto) recebem o código
+// equivalente ao status. Status inesperados viram 502.
+func FromServiceB(status int, body []byte) *Problem {
+	var decoded struct {
+		Problem
+		Error string `json:"error"`
+	}
+	json.Unmarshal(body, &decoded)
+	if decoded.Code != "" {
+		problem := decoded.Problem
+		problem.Status = status
+		problem.TraceID = ""
+		if problem.Type == "" {
+			problem.Type = TypeURI(problem.Code)
+		}
+		return &problem
+	}
+
+	detail := decoded.Detail
+	if detail == "" {
+		detail = decoded.Error
+	}
+	var problem *Problem
+	switch {
+	case status == http.StatusNotFound:
+		problem = NewProblem(status, CodeZipcodeNotFound, detail)
+	case status == http.StatusUnprocessableEntity:
+		problem = NewProblem(status, CodeInvalidZipcode, detail)
+	case status == http.StatusBadRequest:
+		problem = NewProblem(status, CodeInvalidParameter, detail)
+	case status >= http.StatusInternalServerError:
+		problem = NewProblem(status, CodeUpstreamError, detail)
+	default:
+		return NewProblemf(http.StatusBadGateway, CodeUpstreamError, "service B returned status %d", status)
+	}
+	// O detalhe veio do serviço B, no idioma pedido a ele
+	problem.detailKey = ""
+	return problem
+}
+
+// WriteProblem responde com o problema no idioma do contexto, preenchendo o trace ID com o span
+// do contexto
+func WriteProblem(ctx context.Context, w http.ResponseWriter, p *Problem) {
+	problem := p.Localize(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		problem.TraceID = sc.TraceID().String()
+	}
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
 }
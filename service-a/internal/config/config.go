@@ -2,16 +2,101 @@ package config
 
 import (
 	"log"
+	"service-a/internal/balancer"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Transportes para o serviço B
+const (
+	ServiceBTransportHTTP = "http"
+	ServiceBTransportGRPC = "grpc"
+)
+
 type Config struct {
 	ServiceBURL   string `mapstructure:"SERVICE_B_URL"`
 	ViaCEPAPIURL  string `mapstructure:"VIACEP_API_URL"`
 	WeatherAPIURL string `mapstructure:"WEATHERAPI_URL"`
 	WeatherAPIKey string `mapstructure:"WEATHERAPI_KEY"`
 	OTLPEndpoint  string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+
+	// ServiceBTransport escolhe como o POST /cep consulta o serviço B: "http" ou "grpc"
+	ServiceBTransport string `mapstructure:"SERVICE_B_TRANSPORT"`
+	ServiceBGRPCAddr  string `mapstructure:"SERVICE_B_GRPC_ADDR"`
+
+	// Instâncias do serviço B no HTTP: lista fixa de URLs base separadas por vírgula ou descoberta
+	// pelo DNS (dns://host:porta ou dns+srv://nome). Sem nenhuma, vale o host de SERVICE_B_URL.
+	ServiceBEndpoints         string        `mapstructure:"SERVICE_B_ENDPOINTS"`
+	ServiceBDiscovery         string        `mapstructure:"SERVICE_B_DISCOVERY"`
+	ServiceBDiscoveryInterval time.Duration `mapstructure:"SERVICE_B_DISCOVERY_INTERVAL"`
+	// Balanceamento entre as instâncias: round-robin ou least-outstanding, afastando por
+	// SERVICE_B_EJECT_DURATION a instância com SERVICE_B_EJECT_FAILURES falhas seguidas (0 desativa)
+	ServiceBLBPolicy      string        `mapstructure:"SERVICE_B_LB_POLICY"`
+	ServiceBEjectFailures int           `mapstructure:"SERVICE_B_EJECT_FAILURES"`
+	ServiceBEjectDuration time.Duration `mapstructure:"SERVICE_B_EJECT_DURATION"`
+
+	// Pedidos duplicados (hedging) nas chamadas HTTP ao serviço B: sem resposta no percentil
+	// SERVICE_B_HEDGE_PERCENTILE das latências (0 usa só o atraso) ou em SERVICE_B_HEDGE_DELAY, o
+	// que for maior, a requisição é repetida em outra instância; no máximo SERVICE_B_HEDGE_BUDGET
+	// das requisições. Exige SERVICE_B_ENDPOINTS ou SERVICE_B_DISCOVERY; o gRPC não tem hedging.
+	ServiceBHedge           bool          `mapstructure:"SERVICE_B_HEDGE"`
+	ServiceBHedgeDelay      time.Duration `mapstructure:"SERVICE_B_HEDGE_DELAY"`
+	ServiceBHedgePercentile float64       `mapstructure:"SERVICE_B_HEDGE_PERCENTILE"`
+	ServiceBHedgeBudget     float64       `mapstructure:"SERVICE_B_HEDGE_BUDGET"`
+
+	// Stream de temperatura (GET /cep/{cep}/stream)
+	StreamPollInterval      time.Duration `mapstructure:"STREAM_POLL_INTERVAL"`
+	StreamHeartbeatInterval time.Duration `mapstructure:"STREAM_HEARTBEAT_INTERVAL"`
+	StreamReplaySize        int           `mapstructure:"STREAM_REPLAY_SIZE"`
+
+	// Assinaturas por WebSocket (GET /ws)
+	WSMaxSubscriptions int           `mapstructure:"WS_MAX_SUBSCRIPTIONS"`
+	WSPingInterval     time.Duration `mapstructure:"WS_PING_INTERVAL"`
+	WSAllowedOrigins   string        `mapstructure:"WS_ALLOWED_ORIGINS"`
+
+	// Gateway GraphQL (POST /graphql)
+	GraphQLMaxCEPs  int `mapstructure:"GRAPHQL_MAX_CEPS"`
+	GraphQLMaxDepth int `mapstructure:"GRAPHQL_MAX_DEPTH"`
+
+	// Contrato OpenAPI (GET /openapi.json): com a validação de respostas, as divergentes viram 500
+	OpenAPIValidateResponses bool `mapstructure:"OPENAPI_VALIDATE_RESPONSES"`
+
+	// Idioma das respostas quando o Accept-Language não pede um suportado (pt-BR, en ou es)
+	DefaultLanguage string `mapstructure:"DEFAULT_LANGUAGE"`
+
+	// Data (AAAA-MM-DD) informada no cabeçalho Sunset das respostas v1; vazio omite o cabeçalho
+	APIV1Sunset string `mapstructure:"API_V1_SUNSET"`
+
+	// Chaves de API, guardadas pelo SHA-256: lista "cliente:sha256" separada por vírgulas e/ou
+	// arquivo JSON com limites por cliente. Sem nenhuma chave, a API fica aberta.
+	APIKeys     string `mapstructure:"API_KEYS"`
+	APIKeysFile string `mapstructure:"API_KEYS_FILE"`
+	// Limites padrão de cada cliente: balde de API_KEY_BURST requisições reposto a API_KEY_RATE
+	// por segundo e API_KEY_DAILY_QUOTA requisições por dia (0 não limita)
+	APIKeyRate       float64 `mapstructure:"API_KEY_RATE"`
+	APIKeyBurst      int     `mapstructure:"API_KEY_BURST"`
+	APIKeyDailyQuota int     `mapstructure:"API_KEY_DAILY_QUOTA"`
+
+	// Tokens JWT (Authorization: Bearer) do provedor de identidade, verificados pelo JWKS de um
+	// arquivo local ou de uma URL (reutilizado por JWKS_CACHE_TTL), com emissor JWT_ISSUER e
+	// audiência JWT_AUDIENCE. Sem JWKS, os tokens não são aceitos.
+	JWKSFile     string        `mapstructure:"JWKS_FILE"`
+	JWKSURL      string        `mapstructure:"JWKS_URL"`
+	JWKSCacheTTL time.Duration `mapstructure:"JWKS_CACHE_TTL"`
+	JWTIssuer    string        `mapstructure:"JWT_ISSUER"`
+	JWTAudience  string        `mapstructure:"JWT_AUDIENCE"`
+	JWTLeeway    time.Duration `mapstructure:"JWT_LEEWAY"`
+	// Escopo do token que autoriza cada operação: consulta de um CEP, lote (GraphQL), histórico e
+	// alertas
+	JWTScopeLookup  string `mapstructure:"JWT_SCOPE_LOOKUP"`
+	JWTScopeBatch   string `mapstructure:"JWT_SCOPE_BATCH"`
+	JWTScopeHistory string `mapstructure:"JWT_SCOPE_HISTORY"`
+	JWTScopeAlerts  string `mapstructure:"JWT_SCOPE_ALERTS"`
+
+	// Segredo compartilhado com o serviço B para assinar quem fez a requisição no cabeçalho
+	// X-Internal-Subject; vazio não envia o cabeçalho
+	InternalAuthSecret string `mapstructure:"INTERNAL_AUTH_SECRET"`
 }
 
 var AppConfig *Config
@@ -29,6 +114,45 @@ func LoadConfig() *Config {
 	viper.SetDefault("VIACEP_API_URL", "https://viacep.com.br/ws/")
 	viper.SetDefault("WEATHERAPI_URL", "http://api.weatherapi.com/v1/current.json")
 	viper.SetDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "otel-collector:4317")
+	viper.SetDefault("SERVICE_B_TRANSPORT", ServiceBTransportHTTP)
+	viper.SetDefault("SERVICE_B_GRPC_ADDR", "service-b:9090")
+	viper.SetDefault("SERVICE_B_ENDPOINTS", "")
+	viper.SetDefault("SERVICE_B_DISCOVERY", "")
+	viper.SetDefault("SERVICE_B_DISCOVERY_INTERVAL", "30s")
+	viper.SetDefault("SERVICE_B_LB_POLICY", balancer.PolicyRoundRobin)
+	viper.SetDefault("SERVICE_B_EJECT_FAILURES", balancer.DefaultMaxFailures)
+	viper.SetDefault("SERVICE_B_EJECT_DURATION", "30s")
+	viper.SetDefault("SERVICE_B_HEDGE", false)
+	viper.SetDefault("SERVICE_B_HEDGE_DELAY", "50ms")
+	viper.SetDefault("SERVICE_B_HEDGE_PERCENTILE", 0.95)
+	viper.SetDefault("SERVICE_B_HEDGE_BUDGET", 0.1)
+	viper.SetDefault("STREAM_POLL_INTERVAL", "10s")
+	viper.SetDefault("STREAM_HEARTBEAT_INTERVAL", "15s")
+	viper.SetDefault("STREAM_REPLAY_SIZE", 32)
+	viper.SetDefault("WS_MAX_SUBSCRIPTIONS", 250)
+	viper.SetDefault("WS_PING_INTERVAL", "30s")
+	viper.SetDefault("WS_ALLOWED_ORIGINS", "")
+	viper.SetDefault("GRAPHQL_MAX_CEPS", 50)
+	viper.SetDefault("GRAPHQL_MAX_DEPTH", 6)
+	viper.SetDefault("OPENAPI_VALIDATE_RESPONSES", false)
+	viper.SetDefault("DEFAULT_LANGUAGE", "en")
+	viper.SetDefault("API_V1_SUNSET", "")
+	viper.SetDefault("API_KEYS", "")
+	viper.SetDefault("API_KEYS_FILE", "")
+	viper.SetDefault("API_KEY_RATE", 10)
+	viper.SetDefault("API_KEY_BURST", 20)
+	viper.SetDefault("API_KEY_DAILY_QUOTA", 10000)
+	viper.SetDefault("JWKS_FILE", "")
+	viper.SetDefault("JWKS_URL", "")
+	viper.SetDefault("JWKS_CACHE_TTL", "10m")
+	viper.SetDefault("JWT_ISSUER", "")
+	viper.SetDefault("JWT_AUDIENCE", "")
+	viper.SetDefault("JWT_LEEWAY", "1m")
+	viper.SetDefault("JWT_SCOPE_LOOKUP", "weather:read")
+	viper.SetDefault("JWT_SCOPE_BATCH", "weather:batch")
+	viper.SetDefault("JWT_SCOPE_HISTORY", "history:read")
+	viper.SetDefault("JWT_SCOPE_ALERTS", "alerts:write")
+	viper.SetDefault("INTERNAL_AUTH_SECRET", "")
 
 	// Lê as configurações
 	if err := viper.ReadInConfig(); err != nil {
@@ -44,6 +168,75 @@ func LoadConfig() *Config {
 	if config.ServiceBURL == "" {
 		log.Fatalf("SERVICE_B_URL is required")
 	}
+	switch config.ServiceBTransport {
+	case ServiceBTransportHTTP:
+	case ServiceBTransportGRPC:
+		if config.ServiceBGRPCAddr == "" {
+			log.Fatalf("SERVICE_B_GRPC_ADDR is required when SERVICE_B_TRANSPORT is %q", ServiceBTransportGRPC)
+		}
+	default:
+		log.Fatalf("SERVICE_B_TRANSPORT must be %q or %q", ServiceBTransportHTTP, ServiceBTransportGRPC)
+	}
+	if config.ServiceBEndpoints != "" && config.ServiceBDiscovery != "" {
+		log.Fatalf("SERVICE_B_ENDPOINTS and SERVICE_B_DISCOVERY are mutually exclusive")
+	}
+	if config.ServiceBDiscoveryInterval <= 0 {
+		log.Fatalf("SERVICE_B_DISCOVERY_INTERVAL must be positive")
+	}
+	if config.ServiceBLBPolicy != balancer.PolicyRoundRobin && config.ServiceBLBPolicy != balancer.PolicyLeastOutstanding {
+		log.Fatalf("SERVICE_B_LB_POLICY must be %q or %q", balancer.PolicyRoundRobin, balancer.PolicyLeastOutstanding)
+	}
+	if config.ServiceBEjectFailures < 0 || config.ServiceBEjectDuration <= 0 {
+		log.Fatalf("SERVICE_B_EJECT_FAILURES must not be negative and SERVICE_B_EJECT_DURATION must be positive")
+	}
+	if config.ServiceBHedge {
+		if config.ServiceBHedgeDelay <= 0 {
+			log.Fatalf("SERVICE_B_HEDGE_DELAY must be positive")
+		}
+		if config.ServiceBHedgePercentile < 0 || config.ServiceBHedgePercentile >= 1 {
+			log.Fatalf("SERVICE_B_HEDGE_PERCENTILE must be between 0 and 1")
+		}
+		if config.ServiceBHedgeBudget <= 0 || config.ServiceBHedgeBudget > 1 {
+			log.Fatalf("SERVICE_B_HEDGE_BUDGET must be greater than 0 and at most 1")
+		}
+		if config.ServiceBEndpoints == "" && config.ServiceBDiscovery == "" {
+			log.Fatalf("SERVICE_B_HEDGE requires SERVICE_B_ENDPOINTS or SERVICE_B_DISCOVERY")
+		}
+	}
+	if config.StreamPollInterval <= 0 || config.StreamHeartbeatInterval <= 0 {
+		log.Fatalf("STREAM_POLL_INTERVAL and STREAM_HEARTBEAT_INTERVAL must be positive")
+	}
+	if config.WSMaxSubscriptions < 1 || config.WSPingInterval <= 0 {
+		log.Fatalf("WS_MAX_SUBSCRIPTIONS and WS_PING_INTERVAL must be positive")
+	}
+	if config.GraphQLMaxCEPs < 1 || config.GraphQLMaxDepth < 1 {
+		log.Fatalf("GRAPHQL_MAX_CEPS and GRAPHQL_MAX_DEPTH must be positive")
+	}
+	if config.APIV1Sunset != "" {
+		if _, err := time.Parse(time.DateOnly, config.APIV1Sunset); err != nil {
+			log.Fatalf("API_V1_SUNSET must be a date (YYYY-MM-DD)")
+		}
+	}
+	if config.APIKeyRate <= 0 || config.APIKeyBurst < 1 || config.APIKeyDailyQuota < 0 {
+		log.Fatalf("API_KEY_RATE and API_KEY_BURST must be positive and API_KEY_DAILY_QUOTA must not be negative")
+	}
+	if config.JWKSFile != "" || config.JWKSURL != "" {
+		if config.JWKSFile != "" && config.JWKSURL != "" {
+			log.Fatalf("JWKS_FILE and JWKS_URL are mutually exclusive")
+		}
+		if config.JWTIssuer == "" || config.JWTAudience == "" {
+			log.Fatalf("JWT_ISSUER and JWT_AUDIENCE are required with JWKS_FILE or JWKS_URL")
+		}
+		if config.JWKSCacheTTL <= 0 || config.JWTLeeway < 0 {
+			log.Fatalf("JWKS_CACHE_TTL must be positive and JWT_LEEWAY must not be negative")
+		}
+		if config.JWTScopeLookup == "" || config.JWTScopeBatch == "" || config.JWTScopeHistory == "" || config.JWTScopeAlerts == "" {
+			log.Fatalf("JWT_SCOPE_LOOKUP, JWT_SCOPE_BATCH, JWT_SCOPE_HISTORY and JWT_SCOPE_ALERTS must not be empty")
+		}
+	}
+	if config.InternalAuthSecret != "" && len(config.InternalAuthSecret) < 32 {
+		log.Fatalf("INTERNAL_AUTH_SECRET must have at least 32 bytes")
+	}
 
 	AppConfig = &config
 	return AppConfig
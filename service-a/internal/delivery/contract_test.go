@@ -0,0 +1,235 @@
+package delivery
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"service-a/internal/auth"
+	"service-a/internal/openapi"
+	weatherv1 "service-a/internal/pb/weather/v1"
+	"service-a/internal/serviceb"
+	"service-a/internal/stream"
+	"shared/encoder"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newContractServer registra as rotas como no main, atrás da negociação de formato e do
+// validador com validação de respostas: qualquer divergência entre os handlers e o openapi.yaml vira um 500
+func newContractServer(t *testing.T, cepHandler *CEPHandler, executor GraphQLExecutor) http.Handler {
+	t.Helper()
+	return newProtectedContractServer(t, cepHandler, executor, func(h http.Handler) http.Handler { return h })
+}
+
+// newProtectedContractServer é o newContractServer com as rotas atrás de protect, como o main
+// faz com a autenticação
+func newProtectedContractServer(t *testing.T, cepHandler *CEPHandler, executor GraphQLExecutor, protect func(http.Handler) http.Handler) http.Handler {
+	t.Helper()
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	validator, err := openapi.NewValidator(doc, openapi.WithResponseValidation())
+	require.NoError(t, err)
+	specHandler, err := openapi.NewSpecHandler(doc)
+	require.NoError(t, err)
+
+	hub := stream.NewHub(new(MockSource), time.Minute, 1)
+
+	mux := http.NewServeMux()
+	mux.Handle("/cep", protect(http.HandlerFunc(cepHandler.Handle)))
+	mux.Handle("POST /v1/cep", protect(http.HandlerFunc(cepHandler.Handle)))
+	mux.Handle("POST /v2/cep", protect(http.HandlerFunc(cepHandler.Handle)))
+	mux.Handle("GET /cep/{cep}/stream", protect(http.HandlerFunc(NewStreamHandler(hub, time.Minute).Handle)))
+	mux.Handle("POST /graphql", protect(http.HandlerFunc(NewGraphQLHandler(executor).Handle)))
+	mux.Handle("GET /openapi.json", specHandler)
+	return encoder.NewRegistry().Middleware(validator.Middleware(mux))
+}
+
+// serviceBResponse é uma resposta JSON do serviço B
+func serviceBResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func TestContract(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		accept string
+		body   string
+		setup  func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor)
+		status int
+	}{
+		{
+			name: "cep full detail", method: http.MethodPost, target: "/cep?detail=full&units=C,F", body: `{"cep":"01001000"}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				httpClient.On("Do", mock.Anything).Return(serviceBResponse(http.StatusOK,
+					`{"city":"São Paulo","temp_C":31.2,"temp_F":88.16,"feelslike_C":34.1,"feelslike_F":93.38,"humidity":70,
+					"wind_kph":12.5,"wind_degree":90,"wind_dir":"E","pressure_mb":1012,"uv":7,"condition":"Sunny",
+					"condition_code":1000,"observed_at":"2024-01-10T12:00:00Z","heat_index_C":36.2,"wind_chill_C":31.2,"heat_risk":"extreme_caution"}`), nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep v1", method: http.MethodPost, target: "/v1/cep", body: `{"cep":"01001000"}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				httpClient.On("Do", mock.Anything).Return(serviceBResponse(http.StatusOK, `{"city":"São Paulo","temp_C":31.2,"temp_F":88.16,"temp_K":304.35}`), nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep v2", method: http.MethodPost, target: "/v2/cep?fields=wind", body: `{"cep":"01001000"}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				httpClient.On("Do", mock.Anything).Return(serviceBResponse(http.StatusOK,
+					`{"cep":"01001000","location":{"city":"São Paulo","state":"SP","region":"Sudeste","country":"Brazil","lat":-23.53,"lon":-46.62},
+					"observed_at":"2024-01-10T12:00:00Z","temperatures":[{"unit":"C","value":31.2}],
+					"details":{"wind":{"speed_kph":12.5,"degree":90,"direction":"E"}}}`), nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep v2 by accept", method: http.MethodPost, target: "/cep", accept: MediaTypeV2, body: `{"cep":"29902555"}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				httpClient.On("Do", mock.Anything).Return(serviceBResponse(http.StatusOK,
+					`{"cep":"29902555","location":{"city":"Vitória","state":"ES","region":"Sudeste","lat":0,"lon":0},
+					"temperatures":[{"unit":"C","value":30}],"fallback":"state-capital"}`), nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep xml", method: http.MethodPost, target: "/cep", accept: encoder.MediaTypeXML, body: `{"cep":"01001000"}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				httpClient.On("Do", mock.Anything).Return(&http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"city":"São Paulo","temp_C":31.2}`)),
+				}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{name: "cep not acceptable", method: http.MethodPost, target: "/cep", accept: "text/html", body: `{"cep":"01001000"}`, status: http.StatusNotAcceptable},
+		{name: "cep v2 invalid zipcode", method: http.MethodPost, target: "/v2/cep", body: `{"cep":"123"}`, status: http.StatusUnprocessableEntity},
+		{name: "cep invalid zipcode", method: http.MethodPost, target: "/cep", body: `{"cep":"123"}`, status: http.StatusUnprocessableEntity},
+		{name: "cep malformed body", method: http.MethodPost, target: "/cep", body: `{`, status: http.StatusBadRequest},
+		{name: "cep invalid precision", method: http.MethodPost, target: "/cep?precision=7", body: `{"cep":"01001000"}`, status: http.StatusBadRequest},
+		{
+			name: "cep not found in service B", method: http.MethodPost, target: "/cep", body: `{"cep":"01001000"}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				httpClient.On("Do", mock.Anything).Return(serviceBResponse(http.StatusNotFound, `{"type":"/problems/zipcode-not-found","title":"Zipcode not found","status":404,"detail":"can not find zipcode","code":"ZIPCODE_NOT_FOUND"}`), nil)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "cep service B unavailable", method: http.MethodPost, target: "/cep", body: `{"cep":"01001000"}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				httpClient.On("Do", mock.Anything).Return((*http.Response)(nil), errors.New("connection refused"))
+			},
+			status: http.StatusInternalServerError,
+		},
+		{name: "stream invalid zipcode", method: http.MethodGet, target: "/cep/123/stream", status: http.StatusUnprocessableEntity},
+		{
+			name: "graphql partial errors", method: http.MethodPost, target: "/graphql",
+			body: `{"query":"{ weather(cep: \"01001000\") { location { city } } }","variables":{}}`,
+			setup: func(httpClient *MockHTTPClient, executor *MockGraphQLExecutor) {
+				executor.On("Exec", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&graphql.Response{
+					Data: json.RawMessage(`{"weather":{"location":null}}`),
+					Errors: []*gqlerrors.QueryError{{
+						Message:    "can not find zipcode",
+						Path:       []interface{}{"weather", "location"},
+						Extensions: map[string]interface{}{"code": "NOT_FOUND", "status": 404},
+					}},
+				})
+			},
+			status: http.StatusOK,
+		},
+		{name: "graphql missing query", method: http.MethodPost, target: "/graphql", body: `{"variables":{}}`, status: http.StatusBadRequest},
+		{name: "openapi document", method: http.MethodGet, target: "/openapi.json", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := new(MockHTTPClient)
+			executor := new(MockGraphQLExecutor)
+			if tt.setup != nil {
+				tt.setup(httpClient, executor)
+			}
+			server := newContractServer(t, NewCEPHandler(serviceb.NewClient("http://service-b:8090", httpClient)), executor)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.NotContains(t, w.Body.String(), "response does not match the api contract")
+		})
+	}
+}
+
+func TestContract_GRPCTransport(t *testing.T) {
+	grpcClient := new(MockWeatherServiceClient)
+	grpcClient.On("GetByCEP", mock.Anything, mock.Anything).Return(&weatherv1.GetByCEPResponse{
+		City:         "Vitória",
+		Temperatures: []*weatherv1.Temperature{{Unit: "C", Value: 30}, {Unit: "F", Value: 86}},
+		Fallback:     "state-capital",
+	}, nil)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", new(MockHTTPClient), serviceb.WithGRPCClient(grpcClient)))
+	server := newContractServer(t, handler, new(MockGraphQLExecutor))
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cep", strings.NewReader(`{"cep":"29902555"}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "state-capital", w.Header().Get(FallbackHeader))
+}
+
+func TestContract_APIKeyAuth(t *testing.T) {
+	sum := sha256.Sum256([]byte("acme-key"))
+	keys := auth.NewKeyStore()
+	require.NoError(t, keys.Add(auth.Client{ID: "acme", Limits: auth.Limits{Rate: 1, Burst: 1}}, hex.EncodeToString(sum[:])))
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(serviceBResponse(http.StatusOK, `{"city":"São Paulo","temp_C":28.5}`), nil)
+	server := newProtectedContractServer(t, NewCEPHandler(serviceb.NewClient("http://service-b:8090", httpClient)), new(MockGraphQLExecutor),
+		auth.NewAuthenticator(auth.WithAPIKeys(keys, auth.NewLimiter())).Middleware(auth.OperationLookup))
+
+	for _, tt := range []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{name: "missing key", status: http.StatusUnauthorized},
+		{name: "valid key", key: "acme-key", status: http.StatusOK},
+		{name: "rate limited", key: "acme-key", status: http.StatusTooManyRequests},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/cep", strings.NewReader(`{"cep":"01001000"}`))
+		if tt.key != "" {
+			req.Header.Set(auth.Header, tt.key)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.status, w.Code, tt.name+": "+w.Body.String())
+	}
+
+	// O documento continua aberto
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
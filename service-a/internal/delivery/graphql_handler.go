@@ -0,0 +1,69 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"service-a/internal/common"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// maxGraphQLBodySize limita o corpo do POST /graphql
+const maxGraphQLBodySize = 1 << 20
+
+// GraphQLExecutor executa uma consulta GraphQL
+type GraphQLExecutor interface {
+	Exec(ctx context.Context, query, operationName string, variables map[string]interface{}) *graphql.Response
+}
+
+// GraphQLHandler expõe o gateway GraphQL por HTTP
+type GraphQLHandler struct {
+	executor GraphQLExecutor
+}
+
+// NewGraphQLHandler cria um novo handler
+func NewGraphQLHandler(executor GraphQLExecutor) *GraphQLHandler {
+	return &GraphQLHandler{executor: executor}
+}
+
+// Handle processa POST /graphql com {"query","operationName","variables"}. Erros da consulta
+// seguem a convenção GraphQL: status 200 com a lista "errors" e os dados parciais.
+func (h *GraphQLHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log.Println("GraphQLHandler: Request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "process-graphql-handler")
+	defer span.End()
+
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBodySize)).Decode(&params); err != nil || params.Query == "" {
+		log.Printf("GraphQLHandler: Invalid request body: %v", err)
+		span.SetStatus(codes.Error, "Invalid request body")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body")
+		return
+	}
+	if params.OperationName != "" {
+		span.SetAttributes(attribute.String("operation", params.OperationName))
+	}
+
+	response := h.executor.Exec(ctx, params.Query, params.OperationName, params.Variables)
+	if len(response.Errors) > 0 {
+		span.SetAttributes(attribute.Int("errors", len(response.Errors)))
+		span.SetStatus(codes.Error, response.Errors[0].Message)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,62 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"service-a/internal/common"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGraphQLExecutor struct {
+	mock.Mock
+}
+
+func (m *MockGraphQLExecutor) Exec(ctx context.Context, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	args := m.Called(ctx, query, operationName, variables)
+	return args.Get(0).(*graphql.Response)
+}
+
+func postGraphQL(handler *GraphQLHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handler.Handle(w, req)
+	return w
+}
+
+func TestGraphQLHandler_Success(t *testing.T) {
+	executor := new(MockGraphQLExecutor)
+	handler := NewGraphQLHandler(executor)
+
+	query := `query City($cep: String!) { weather(cep: $cep) { location { city } } }`
+	executor.On("Exec", mock.Anything, query, "City", map[string]interface{}{"cep": "01001000"}).
+		Return(&graphql.Response{Data: json.RawMessage(`{"weather":{"location":{"city":"São Paulo"}}}`)})
+
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "operationName": "City", "variables": map[string]interface{}{"cep": "01001000"}})
+	w := postGraphQL(handler, string(body))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"data":{"weather":{"location":{"city":"São Paulo"}}}}`, w.Body.String())
+	executor.AssertExpectations(t)
+}
+
+func TestGraphQLHandler_InvalidBody(t *testing.T) {
+	for _, body := range []string{`not json`, `{"variables":{}}`} {
+		executor := new(MockGraphQLExecutor)
+		handler := NewGraphQLHandler(executor)
+
+		w := postGraphQL(handler, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body"), w.Body.String())
+		executor.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
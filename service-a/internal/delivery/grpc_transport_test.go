@@ -0,0 +1,188 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	weatherv1 "service-a/internal/pb/weather/v1"
+	"testing"
+
+	"service-a/internal/common"
+	"service-a/internal/serviceb"
+	"shared/encoder"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MockWeatherServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockWeatherServiceClient) GetByCEP(ctx context.Context, in *weatherv1.GetByCEPRequest, opts ...grpc.CallOption) (*weatherv1.GetByCEPResponse, error) {
+	args := m.Called(ctx, in)
+	resp, _ := args.Get(0).(*weatherv1.GetByCEPResponse)
+	return resp, args.Error(1)
+}
+
+func (m *MockWeatherServiceClient) BatchGetByCEP(ctx context.Context, in *weatherv1.BatchGetByCEPRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[weatherv1.CEPResult], error) {
+	args := m.Called(ctx, in)
+	return nil, args.Error(1)
+}
+
+func (m *MockWeatherServiceClient) StreamGetByCEP(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[weatherv1.GetByCEPRequest, weatherv1.CEPResult], error) {
+	args := m.Called(ctx)
+	return nil, args.Error(1)
+}
+
+func badRequest(field, message string) error {
+	st, _ := status.New(codes.InvalidArgument, message).WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: message}},
+	})
+	return st.Err()
+}
+
+func postCEP(handler *CEPHandler, target, cep string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewBufferString(`{"cep":"`+cep+`"}`))
+	w := httptest.NewRecorder()
+	handler.Handle(w, req)
+	return w
+}
+
+func TestCEPHandler_GRPCSuccess(t *testing.T) {
+	mockClient := new(MockWeatherServiceClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", new(MockHTTPClient), serviceb.WithGRPCClient(mockClient)))
+
+	humidity := int32(70)
+	mockClient.On("GetByCEP", mock.Anything, mock.MatchedBy(func(req *weatherv1.GetByCEPRequest) bool {
+		return req.Cep == "01001000" && len(req.Options.Fields) == 1 && req.Options.Fields[0] == "humidity"
+	})).Return(&weatherv1.GetByCEPResponse{
+		Cep:  "01001000",
+		City: "São Paulo",
+		Temperatures: []*weatherv1.Temperature{
+			{Unit: "C", Value: 28.5}, {Unit: "F", Value: 83.3}, {Unit: "K", Value: 301.65},
+		},
+		Observation: &weatherv1.Observation{Humidity: &humidity},
+	}, nil)
+
+	w := postCEP(handler, "/cep?fields=humidity", "01001000")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65,"humidity":70}`, w.Body.String())
+	mockClient.AssertExpectations(t)
+}
+
+func TestCEPHandler_GRPCFallback(t *testing.T) {
+	mockClient := new(MockWeatherServiceClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", new(MockHTTPClient), serviceb.WithGRPCClient(mockClient)))
+
+	mockClient.On("GetByCEP", mock.Anything, mock.Anything).Return(&weatherv1.GetByCEPResponse{
+		City:         "Vitória",
+		Temperatures: []*weatherv1.Temperature{{Unit: "C", Value: 30}},
+		Fallback:     "state-capital",
+	}, nil)
+
+	w := postCEP(handler, "/cep", "29902555")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "state-capital", w.Header().Get(FallbackHeader))
+	assert.JSONEq(t, `{"city":"Vitória","temp_C":30}`, w.Body.String())
+}
+
+func TestCEPHandler_GRPCEncodedFormat(t *testing.T) {
+	mockClient := new(MockWeatherServiceClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", new(MockHTTPClient), serviceb.WithGRPCClient(mockClient)))
+
+	mockClient.On("GetByCEP", mock.Anything, mock.Anything).Return(&weatherv1.GetByCEPResponse{
+		City:         "São Paulo",
+		Temperatures: []*weatherv1.Temperature{{Unit: "C", Value: 28.5}, {Unit: "F", Value: 83.3}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"01001000"}`))
+	req = req.WithContext(encoder.WithEncoder(req.Context(), encoder.XML))
+	w := httptest.NewRecorder()
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, xml.Header+"<response><city>São Paulo</city><temp_C>28.5</temp_C><temp_F>83.3</temp_F></response>\n", w.Body.String())
+}
+
+func TestCEPHandler_GRPCErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"invalid zipcode", badRequest("cep", "invalid zipcode"), http.StatusUnprocessableEntity, problemBody(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode")},
+		{"invalid options", badRequest("options", "invalid units parameter"), http.StatusBadRequest, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid units parameter")},
+		{"not found", status.Error(codes.NotFound, "can not find zipcode"), http.StatusNotFound, problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")},
+		{"internal", status.Error(codes.Internal, "error fetching temperature"), http.StatusInternalServerError, problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching temperature")},
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), http.StatusInternalServerError, problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error contacting service B")},
+		{"deadline", status.Error(codes.DeadlineExceeded, "context deadline exceeded"), http.StatusGatewayTimeout, problemBody(http.StatusGatewayTimeout, common.CodeUpstreamTimeout, "service B timed out")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockWeatherServiceClient)
+			handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", new(MockHTTPClient), serviceb.WithGRPCClient(mockClient)))
+			mockClient.On("GetByCEP", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := postCEP(handler, "/cep", "01001000")
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestCEPHandler_GRPCInvalidPrecision(t *testing.T) {
+	mockClient := new(MockWeatherServiceClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", new(MockHTTPClient), serviceb.WithGRPCClient(mockClient)))
+
+	w := postCEP(handler, "/cep?precision=abc", "01001000")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid units parameter"), w.Body.String())
+	mockClient.AssertNotCalled(t, "GetByCEP", mock.Anything, mock.Anything)
+}
+
+func TestCEPHandler_GRPCV2UsesHTTP(t *testing.T) {
+	mockClient := new(MockWeatherServiceClient)
+	httpClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", httpClient, serviceb.WithGRPCClient(mockClient)))
+
+	responseBody := `{"cep":"01001000","location":{"city":"São Paulo","state":"SP","region":"Sudeste","lat":0,"lon":0},"temperatures":[{"unit":"C","value":28.5}]}`
+	httpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Path == "/v2/cep/01001000"
+	})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(responseBody))}, nil)
+
+	w := postCEP(handler, "/v2/cep", "01001000")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, responseBody, w.Body.String())
+	mockClient.AssertNotCalled(t, "GetByCEP", mock.Anything, mock.Anything)
+}
+
+func TestResponseOptions_MapsQuery(t *testing.T) {
+	opts, problem := responseOptions(map[string][]string{
+		"detail":    {"full"},
+		"fields":    {"wind, uv", "comfort"},
+		"units":     {"C,R"},
+		"precision": {"1"},
+	})
+
+	assert.Nil(t, problem)
+	assert.True(t, opts.Detail)
+	assert.Equal(t, []string{"wind", "uv", "comfort"}, opts.Fields)
+	assert.Equal(t, []string{"C", "R"}, opts.Units)
+	assert.Equal(t, 1, *opts.Precision)
+}
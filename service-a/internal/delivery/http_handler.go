@@ -1,10 +1,20 @@
 package delivery
 
 import (
+	"context"
 	"encoding/json"
-	"io/ioutil"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
+	"service-a/internal/auth"
+	"service-a/internal/common"
+	"service-a/internal/serviceb"
+	"shared/cep"
+	"shared/encoder"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -12,23 +22,37 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 )
 
-// HTTPClient é uma interface para o cliente HTTP
-type HTTPClient interface {
-	Do(req *http.Request) (*http.Response, error)
-}
+// FallbackHeader sinaliza que a temperatura é da capital do estado (mesmo cabeçalho do serviço B)
+const FallbackHeader = serviceb.FallbackHeader
 
 // CEPHandler gerencia as requisições para enviar o CEP ao serviço B
 type CEPHandler struct {
-	serviceBURL string
-	httpClient  HTTPClient
+	client   serviceb.Client
+	v1Sunset time.Time
+}
+
+// CEPHandlerOption configura dependências opcionais do CEPHandler
+type CEPHandlerOption func(*CEPHandler)
+
+// WithV1Sunset informa, no cabeçalho Sunset das respostas v1, a data em que a v1 deixará de existir
+func WithV1Sunset(sunset time.Time) CEPHandlerOption {
+	return func(h *CEPHandler) {
+		h.v1Sunset = sunset
+	}
 }
 
-// NewCEPHandler cria um novo handler
-func NewCEPHandler(serviceBURL string, httpClient HTTPClient) *CEPHandler {
-	return &CEPHandler{serviceBURL: serviceBURL, httpClient: httpClient}
+// NewCEPHandler cria um novo handler que consulta o serviço B pelo client
+func NewCEPHandler(client serviceb.Client, opts ...CEPHandlerOption) *CEPHandler {
+	h := &CEPHandler{client: client}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-// Handle processa a requisição para enviar o CEP ao serviço B
+// Handle processa a requisição para enviar o CEP ao serviço B. A versão da resposta vem do
+// caminho (/v1/cep ou /v2/cep) ou, em /cep, do cabeçalho Accept, e é pedida ao serviço B. A
+// resposta decodificada é recodificada no formato negociado pelo Accept (JSON, XML, CSV ou protobuf).
 func (h *CEPHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	log.Println("CEPHandler: Request received")
 
@@ -37,6 +61,26 @@ func (h *CEPHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	ctx, span := tracer.Start(ctx, "process-cep-handler")
 	defer span.End()
 	log.Printf("CEPHandler: Received TraceID=%s", span.SpanContext().TraceID().String())
+	if clientID := auth.ClientID(ctx); clientID != "" {
+		log.Printf("CEPHandler: Client %s", clientID)
+		span.SetAttributes(attribute.String("client_id", clientID))
+	}
+
+	version := requestVersion(r)
+	span.SetAttributes(attribute.String("api_version", version))
+	if version == APIVersion1 {
+		h.setDeprecationHeaders(w)
+	}
+
+	// Recusa antes de consultar o serviço B quando nenhum formato aceito é suportado
+	enc, ok := encoder.FromContext(r.Context())
+	if !ok {
+		log.Printf("CEPHandler: Not acceptable: %s", r.Header.Get("Accept"))
+		span.SetStatus(codes.Error, "Not acceptable")
+		writeErrorResponse(ctx, w, http.StatusNotAcceptable, common.CodeNotAcceptable, "no acceptable response format")
+		return
+	}
+	span.SetAttributes(attribute.String("response_format", enc.ContentType()))
 
 	var requestBody struct {
 		CEP string `json:"cep"`
@@ -44,65 +88,117 @@ func (h *CEPHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
 		log.Printf("CEPHandler: Invalid request body: %v", err)
 		span.SetStatus(codes.Error, "Invalid request body")
-		h.writeErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body")
 		return
 	}
 
 	// Validação do CEP
-	if len(requestBody.CEP) != 8 {
+	if cep.Validate(requestBody.CEP) != nil {
 		log.Printf("CEPHandler: Invalid CEP: %s", requestBody.CEP)
-		span.SetStatus(codes.Error, "Invalid CEP length")
-		h.writeErrorResponse(w, http.StatusUnprocessableEntity, "invalid zipcode")
+		span.SetStatus(codes.Error, "Invalid CEP")
+		writeErrorResponse(ctx, w, http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode")
 		return
 	}
 	span.SetAttributes(attribute.String("cep", requestBody.CEP))
 
-	// Enviar CEP ao serviço B
-	serviceBURL := h.serviceBURL + "/cep/" + requestBody.CEP
-	req, err := http.NewRequestWithContext(ctx, "GET", serviceBURL, nil)
-	if err != nil {
-		log.Printf("CEPHandler: Error creating request to service B: %v", err)
-		span.SetStatus(codes.Error, "Error creating request to service B")
-		h.writeErrorResponse(w, http.StatusInternalServerError, "error creating request to service B")
+	// Opções de resposta (ex.: ?detail=full) repassadas ao serviço B
+	opts, problem := responseOptions(r.URL.Query())
+	if problem != nil {
+		log.Printf("CEPHandler: Invalid response options: %s", problem.Detail)
+		span.SetStatus(codes.Error, "Invalid response options")
+		common.WriteProblem(ctx, w, problem)
 		return
 	}
 
-	// Propagar o contexto de rastreamento na requisição HTTP
-	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
-
-	resp, err := h.httpClient.Do(req)
+	var response interface{}
+	var fallback string
+	var err error
+	if version == APIVersion2 {
+		var weather *serviceb.WeatherV2
+		if weather, err = h.client.WeatherV2(ctx, requestBody.CEP, opts); err == nil {
+			response, fallback = weather, weather.Fallback
+		}
+	} else {
+		var weather *serviceb.Weather
+		if weather, err = h.client.Weather(ctx, requestBody.CEP, opts); err == nil {
+			response, fallback = weather, weather.Fallback
+		}
+	}
 	if err != nil {
-		log.Printf("CEPHandler: Error contacting service B: %v", err)
-		span.SetStatus(codes.Error, "Error contacting service B")
-		h.writeErrorResponse(w, http.StatusInternalServerError, "error contacting service B")
+		log.Printf("CEPHandler: Error fetching weather from service B: %v", err)
+		span.SetStatus(codes.Error, "Service B returned error")
+		common.WriteProblem(ctx, w, serviceBProblem(err))
 		return
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := encoder.Encode(enc, response)
 	if err != nil {
-		log.Printf("CEPHandler: Error reading response from service B: %v", err)
-		span.SetStatus(codes.Error, "Error reading response from service B")
-		h.writeErrorResponse(w, http.StatusInternalServerError, "error reading response from service B")
+		log.Printf("CEPHandler: Error encoding response: %v", err)
+		span.SetStatus(codes.Error, "Error encoding response")
+		writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "error encoding response")
 		return
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("CEPHandler: Service B returned error: %s", body)
-		span.SetStatus(codes.Error, "Service B returned error")
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(resp.StatusCode)
-		w.Write(body)
-		return
+	if fallback != "" {
+		w.Header().Set(FallbackHeader, fallback)
 	}
-
-	// Responder com a resposta do serviço B
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", responseContentType(version, enc))
 	w.WriteHeader(http.StatusOK)
 	w.Write(body)
 }
 
-func (h *CEPHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.WriteHeader(statusCode)
-	w.Write([]byte(`{"error": "` + message + `"}`))
+// responseOptions converte a query (detail, fields, units, precision) nas opções do serviço B.
+// Os valores são validados pelo serviço B; aqui só é rejeitado o que não cabe nas opções.
+func responseOptions(query url.Values) (serviceb.Options, *common.Problem) {
+	var opts serviceb.Options
+
+	switch query.Get("detail") {
+	case "", "basic":
+	case "full":
+		opts.Detail = true
+	default:
+		return opts, common.NewProblem(http.StatusBadRequest, common.CodeInvalidParameter, "invalid fields parameter")
+	}
+	for _, raw := range query["fields"] {
+		opts.Fields = append(opts.Fields, splitNonEmpty(raw)...)
+	}
+	if raw := query.Get("units"); raw != "" {
+		opts.Units = strings.Split(raw, ",")
+	}
+	if raw := query.Get("precision"); raw != "" {
+		precision, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return opts, common.NewProblem(http.StatusBadRequest, common.CodeInvalidParameter, "invalid units parameter")
+		}
+		p := int(precision)
+		opts.Precision = &p
+	}
+	return opts, nil
+}
+
+// serviceBProblem converte o erro do cliente do serviço B no problema da resposta: erros de
+// status mantêm o problema do serviço B e respostas inválidas ou grandes demais viram 502
+func serviceBProblem(err error) *common.Problem {
+	switch {
+	case errors.Is(err, serviceb.ErrResponseTooLarge):
+		return common.NewProblem(http.StatusBadGateway, common.CodeUpstreamError, "service B response too large")
+	case errors.Is(err, serviceb.ErrInvalidResponse):
+		return common.NewProblem(http.StatusBadGateway, common.CodeUpstreamError, "invalid response from service B")
+	}
+	return common.FromError(err, common.NewProblem(http.StatusInternalServerError, common.CodeUpstreamError, "error contacting service B"))
+}
+
+func splitNonEmpty(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// writeErrorResponse responde com um problema RFC 7807 com o código estável e o trace ID do contexto
+func writeErrorResponse(ctx context.Context, w http.ResponseWriter, statusCode int, code, detail string) {
+	common.WriteProblem(ctx, w, common.NewProblem(statusCode, code, detail))
 }
@@ -2,10 +2,20 @@ package delivery
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"service-a/internal/common"
+	"service-a/internal/i18n"
+	"service-a/internal/serviceb"
+	"shared/encoder"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,7 +33,7 @@ func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
 func TestCEPHandler_Success(t *testing.T) {
 	serviceBURL := "http://service-b:8090"
 	mockClient := new(MockHTTPClient)
-	handler := NewCEPHandler(serviceBURL, mockClient)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient))
 
 	cep := "01001000"
 	requestBody := `{"cep":"` + cep + `"}`
@@ -50,7 +60,7 @@ func TestCEPHandler_Success(t *testing.T) {
 func TestCEPHandler_InvalidCEP(t *testing.T) {
 	serviceBURL := "http://service-b:8090"
 	mockClient := new(MockHTTPClient)
-	handler := NewCEPHandler(serviceBURL, mockClient)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient))
 
 	cep := "123"
 	requestBody := `{"cep":"` + cep + `"}`
@@ -61,19 +71,19 @@ func TestCEPHandler_InvalidCEP(t *testing.T) {
 	handler.Handle(w, req)
 
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
-	expectedResponse := `{"error":"invalid zipcode"}`
+	expectedResponse := problemBody(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode")
 	assert.JSONEq(t, expectedResponse, w.Body.String())
 }
 
 func TestCEPHandler_CEPNotFound(t *testing.T) {
 	serviceBURL := "http://service-b:8090"
 	mockClient := new(MockHTTPClient)
-	handler := NewCEPHandler(serviceBURL, mockClient)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient))
 
 	cep := "99999999"
 	requestBody := `{"cep":"` + cep + `"}`
 
-	responseBody := `{"error":"can not find zipcode"}`
+	responseBody := `{"type":"/problems/zipcode-not-found","title":"Zipcode not found","status":404,"detail":"can not find zipcode","code":"ZIPCODE_NOT_FOUND","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}`
 	mockResponse := &http.Response{
 		StatusCode: http.StatusNotFound,
 		Body:       ioutil.NopCloser(bytes.NewBufferString(responseBody)),
@@ -87,7 +97,8 @@ func TestCEPHandler_CEPNotFound(t *testing.T) {
 	handler.Handle(w, req)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.JSONEq(t, responseBody, w.Body.String())
+	assert.Equal(t, common.ContentTypeProblem, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode"), w.Body.String())
 
 	mockClient.AssertExpectations(t)
 }
@@ -95,7 +106,7 @@ func TestCEPHandler_CEPNotFound(t *testing.T) {
 func TestCEPHandler_FetchCityError(t *testing.T) {
 	serviceBURL := "http://service-b:8090"
 	mockClient := new(MockHTTPClient)
-	handler := NewCEPHandler(serviceBURL, mockClient)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient))
 
 	cep := "01001000"
 	requestBody := `{"cep":"` + cep + `"}`
@@ -114,7 +125,7 @@ func TestCEPHandler_FetchCityError(t *testing.T) {
 	handler.Handle(w, req)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.JSONEq(t, responseBody, w.Body.String())
+	assert.JSONEq(t, problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching city"), w.Body.String())
 
 	mockClient.AssertExpectations(t)
 }
@@ -122,12 +133,12 @@ func TestCEPHandler_FetchCityError(t *testing.T) {
 func TestCEPHandler_FetchTempError(t *testing.T) {
 	serviceBURL := "http://service-b:8090"
 	mockClient := new(MockHTTPClient)
-	handler := NewCEPHandler(serviceBURL, mockClient)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient))
 
 	cep := "01001000"
 	requestBody := `{"cep":"` + cep + `"}`
 
-	responseBody := `{"error":"error fetching temperature"}`
+	responseBody := `{"type":"/problems/upstream-error","title":"Upstream error","status":500,"detail":"error fetching temperature","code":"UPSTREAM_ERROR"}`
 	mockResponse := &http.Response{
 		StatusCode: http.StatusInternalServerError,
 		Body:       ioutil.NopCloser(bytes.NewBufferString(responseBody)),
@@ -145,3 +156,254 @@ func TestCEPHandler_FetchTempError(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestCEPHandler_ServiceBTimeout(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", mockClient))
+
+	mockClient.On("Do", mock.Anything).Return((*http.Response)(nil), fmt.Errorf("Get service-b: %w", context.DeadlineExceeded))
+
+	req := httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"01001000"}`))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusGatewayTimeout, common.CodeUpstreamTimeout, "service B timed out"), w.Body.String())
+}
+
+func TestCEPHandler_ServiceBUnexpectedStatus(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", mockClient))
+
+	mockClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusMethodNotAllowed,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("Method Not Allowed\n")),
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"01001000"}`))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusBadGateway, common.CodeUpstreamError, "service B returned status 405"), w.Body.String())
+}
+
+func TestCEPHandler_Localized(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", mockClient))
+
+	// O detalhe do serviço B já vem traduzido e é mantido; o código não muda
+	responseBody := `{"type":"/problems/zipcode-not-found","title":"CEP não encontrado","status":404,"detail":"CEP não encontrado","code":"ZIPCODE_NOT_FOUND"}`
+	mockClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.Header.Get("Accept-Language") == "pt-BR"
+	})).Return(&http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(responseBody)),
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"99999999"}`))
+	req = req.WithContext(i18n.WithLanguage(req.Context(), i18n.BrazilianPortuguese))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, responseBody, w.Body.String())
+	mockClient.AssertExpectations(t)
+
+	// Os problemas do próprio serviço A são traduzidos pelo catálogo
+	req = httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"123"}`))
+	req = req.WithContext(i18n.WithLanguage(req.Context(), i18n.Spanish))
+	w = httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"type":"/problems/invalid-zipcode","title":"Código postal inválido","status":422,"detail":"código postal inválido","code":"INVALID_ZIPCODE"}`, w.Body.String())
+}
+
+func TestCEPHandler_ForwardsQueryString(t *testing.T) {
+	serviceBURL := "http://service-b:8090"
+	mockClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient))
+
+	requestBody := `{"cep":"01001000"}`
+	responseBody := `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65,"humidity":70}`
+	mockResponse := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(responseBody)),
+	}
+
+	mockClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == serviceBURL+"/v1/cep/01001000?fields=humidity"
+	})).Return(mockResponse, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/cep?fields=humidity", bytes.NewBufferString(requestBody))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, responseBody, w.Body.String())
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestCEPHandler_V1DeprecationHeaders(t *testing.T) {
+	serviceBURL := "http://service-b:8090"
+	mockClient := new(MockHTTPClient)
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient), WithV1Sunset(sunset))
+
+	responseBody := `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65}`
+	mockClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == serviceBURL+"/v1/cep/01001000"
+	})).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(responseBody))}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/cep", bytes.NewBufferString(`{"cep":"01001000"}`))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</v2/cep>; rel="successor-version"`, w.Header().Get("Link"))
+	assert.Equal(t, "Thu, 31 Dec 2026 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Empty(t, w.Header().Get("Vary"))
+	assert.JSONEq(t, responseBody, w.Body.String())
+	mockClient.AssertExpectations(t)
+}
+
+func TestCEPHandler_V2(t *testing.T) {
+	serviceBURL := "http://service-b:8090"
+	mockClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient(serviceBURL, mockClient))
+
+	responseBody := `{"cep":"01001000","location":{"city":"São Paulo","state":"SP","region":"Sudeste","lat":-23.53,"lon":-46.62},"temperatures":[{"unit":"C","value":28.5}]}`
+	mockClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == serviceBURL+"/v2/cep/01001000?units=C"
+	})).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(responseBody))}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/cep?units=C", bytes.NewBufferString(`{"cep":"01001000"}`))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MediaTypeV2, w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.JSONEq(t, responseBody, w.Body.String())
+	mockClient.AssertExpectations(t)
+}
+
+func TestCEPHandler_AcceptNegotiation(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		wantPath        string
+		wantContentType string
+		wantDeprecation string
+	}{
+		{"no accept", "", "/v1/cep/01001000", "application/json", "true"},
+		{"json", "application/json", "/v1/cep/01001000", "application/json", "true"},
+		{"v2", MediaTypeV2, "/v2/cep/01001000", MediaTypeV2, ""},
+		{"v2 among others", "text/html, application/vnd.weather.v2+json;q=0.9", "/v2/cep/01001000", MediaTypeV2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockHTTPClient)
+			handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", mockClient))
+			mockClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				return req.URL.Path == tt.wantPath
+			})).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(`{}`))}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"01001000"}`))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantDeprecation, w.Header().Get("Deprecation"))
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCEPHandler_InvalidServiceBResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		detail string
+	}{
+		{"not json", `<response><city>São Paulo</city></response>`, "invalid response from service B"},
+		{"too large", `{"city":"` + strings.Repeat("a", serviceb.DefaultMaxResponseBytes) + `"}`, "service B response too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockHTTPClient)
+			handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", mockClient))
+			mockClient.On("Do", mock.Anything).Return(&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(tt.body)),
+			}, nil)
+
+			w := postCEP(handler, "/cep", "01001000")
+
+			assert.Equal(t, http.StatusBadGateway, w.Code)
+			assert.JSONEq(t, problemBody(http.StatusBadGateway, common.CodeUpstreamError, tt.detail), w.Body.String())
+		})
+	}
+}
+
+// problemBody é o corpo esperado para o problema; sem span no contexto, não há trace_id
+func problemBody(status int, code, detail string) string {
+	body, _ := json.Marshal(common.NewProblem(status, code, detail))
+	return string(body)
+}
+
+func TestCEPHandler_EncodesAcceptedFormat(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", mockClient))
+
+	// O serviço B responde sempre em JSON; o formato pedido é gerado aqui
+	mockClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Path == "/v1/cep/01001000" && req.Header.Get("Accept") == "application/json"
+	})).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65}`)),
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"01001000"}`))
+	req.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+
+	encoder.NewRegistry().Middleware(http.HandlerFunc(handler.Handle)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "city,temp_C,temp_F,temp_K\nSão Paulo,28.5,83.3,301.65\n", w.Body.String())
+	mockClient.AssertExpectations(t)
+}
+
+func TestCEPHandler_NotAcceptable(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewCEPHandler(serviceb.NewClient("http://service-b:8090", mockClient))
+
+	req := httptest.NewRequest(http.MethodPost, "/cep", bytes.NewBufferString(`{"cep":"01001000"}`))
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+
+	encoder.NewRegistry().Middleware(http.HandlerFunc(handler.Handle)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	assert.JSONEq(t, problemBody(http.StatusNotAcceptable, common.CodeNotAcceptable, "no acceptable response format"), w.Body.String())
+	mockClient.AssertNotCalled(t, "Do", mock.Anything)
+}
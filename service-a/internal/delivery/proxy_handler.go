@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"service-a/internal/common"
+	"service-a/internal/serviceb"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// proxyRequestHeaders são os cabeçalhos do cliente repassados ao serviço B. A credencial do
+// cliente fica no serviço A; o serviço B recebe o X-Internal-Subject.
+var proxyRequestHeaders = []string{"Accept", "Accept-Language", "Content-Type"}
+
+// ProxyHandler repassa rotas do serviço B que o serviço A expõe sem alterar (histórico e
+// alertas). O cliente HTTP é o mesmo das consultas, com o balanceamento e o X-Internal-Subject
+// assinado com quem fez a requisição.
+type ProxyHandler struct {
+	baseURL string
+	client  serviceb.HTTPClient
+}
+
+// NewProxyHandler cria um novo handler que repassa as requisições ao serviço B em baseURL
+func NewProxyHandler(baseURL string, client serviceb.HTTPClient) *ProxyHandler {
+	return &ProxyHandler{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// Handle repassa método, caminho, query e corpo ao serviço B. A resposta, inclusive a exportação
+// do histórico, é transmitida como veio, sem passar pelo limite de tamanho das consultas.
+func (h *ProxyHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log.Printf("ProxyHandler: Request received: %s %s", r.Method, r.URL.Path)
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "proxy-service-b")
+	defer span.End()
+	span.SetAttributes(attribute.String("method", r.Method), attribute.String("path", r.URL.Path))
+
+	target := h.baseURL + r.URL.EscapedPath()
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, target, r.Body)
+	if err != nil {
+		log.Printf("ProxyHandler: Error creating request: %v", err)
+		span.SetStatus(codes.Error, "Error creating request")
+		writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "internal error")
+		return
+	}
+	req.ContentLength = r.ContentLength
+	for _, key := range proxyRequestHeaders {
+		if value := r.Header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		log.Printf("ProxyHandler: Error contacting service B: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error contacting service B")
+		common.WriteProblem(ctx, w, common.FromError(err, common.NewProblem(http.StatusInternalServerError, common.CodeUpstreamError, "error contacting service B")))
+		return
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("status", resp.StatusCode))
+
+	for key, values := range resp.Header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		// O status já foi enviado: a falha apenas interrompe a resposta
+		log.Printf("ProxyHandler: Error copying service B response: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error copying service B response")
+		panic(http.ErrAbortHandler)
+	}
+}
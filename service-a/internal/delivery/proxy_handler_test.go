@@ -0,0 +1,108 @@
+package delivery
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"service-a/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProxyHandler_ForwardsRequest(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewProxyHandler("http://service-b:8090/", mockClient)
+
+	responseBody := "time,cep\n"
+	header := http.Header{}
+	header.Set("Content-Type", "text/csv")
+	header.Set("Content-Disposition", `attachment; filename="history.csv"`)
+	mockClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/history/export?window=24h&format=csv", nil)
+	req.Header.Set("Accept-Language", "pt-BR")
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, responseBody, w.Body.String())
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="history.csv"`, w.Header().Get("Content-Disposition"))
+
+	sent := mockClient.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, "http://service-b:8090/history/export?window=24h&format=csv", sent.URL.String())
+	assert.Equal(t, "pt-BR", sent.Header.Get("Accept-Language"))
+	// A credencial do cliente fica no serviço A; o serviço B recebe o X-Internal-Subject
+	assert.Empty(t, sent.Header.Get("Authorization"))
+}
+
+func TestProxyHandler_ForwardsBody(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewProxyHandler("http://service-b:8090", mockClient)
+
+	body := `{"cep":"01001000","above_C":35,"webhook_url":"https://example.com/hook"}`
+	var sentBody string
+	mockClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		data, _ := io.ReadAll(args.Get(0).(*http.Request).Body)
+		sentBody = string(data)
+	}).Return(&http.Response{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"id":"abc"}`)),
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/alerts/subscriptions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id":"abc"}`, w.Body.String())
+	sent := mockClient.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, http.MethodPost, sent.Method)
+	assert.Equal(t, "http://service-b:8090/alerts/subscriptions", sent.URL.String())
+	assert.Equal(t, "application/json", sent.Header.Get("Content-Type"))
+	assert.Equal(t, body, sentBody)
+}
+
+func TestProxyHandler_ForwardsErrorStatus(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewProxyHandler("http://service-b:8090", mockClient)
+
+	problem := `{"type":"about:blank","title":"Bad Request","status":400,"code":"INVALID_HISTORY_QUERY","detail":"invalid history query"}`
+	mockClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": {"application/problem+json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(problem)),
+	}, nil)
+
+	w := httptest.NewRecorder()
+	handler.Handle(w, httptest.NewRequest(http.MethodGet, "/history/errors?interval=x", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, problem, w.Body.String())
+}
+
+func TestProxyHandler_ServiceBUnavailable(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	handler := NewProxyHandler("http://service-b:8090", mockClient)
+	mockClient.On("Do", mock.Anything).Return((*http.Response)(nil), errors.New("connection refused"))
+
+	w := httptest.NewRecorder()
+	handler.Handle(w, httptest.NewRequest(http.MethodGet, "/history/top-ceps", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error contacting service B"), w.Body.String())
+}
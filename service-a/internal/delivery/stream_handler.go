@@ -0,0 +1,115 @@
+package delivery
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"service-a/internal/common"
+	"service-a/internal/stream"
+	"shared/cep"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// StreamRetry é o tempo sugerido ao navegador para reconectar após uma queda
+const StreamRetry = 3 * time.Second
+
+// StreamHandler transmite as leituras de temperatura de um CEP como Server-Sent Events
+type StreamHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+}
+
+// NewStreamHandler cria um novo handler que envia um comentário de heartbeat a cada heartbeat
+func NewStreamHandler(hub *stream.Hub, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{hub: hub, heartbeat: heartbeat}
+}
+
+// Handle processa GET /cep/{cep}/stream. Cada mudança da leitura é enviada como um evento
+// "temperature" (ou "error") com id; o cabeçalho Last-Event-ID retoma de onde o cliente parou.
+func (h *StreamHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log.Println("StreamHandler: Request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "process-cep-stream-handler")
+	defer span.End()
+
+	code := r.PathValue("cep")
+	if cep.Validate(code) != nil {
+		log.Printf("StreamHandler: Invalid CEP: %s", code)
+		span.SetStatus(codes.Error, "Invalid CEP")
+		writeErrorResponse(ctx, w, http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode")
+		return
+	}
+	span.SetAttributes(attribute.String("cep", code))
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		span.SetStatus(codes.Error, "Streaming not supported")
+		writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "streaming not supported")
+		return
+	}
+
+	// Um Last-Event-ID inválido é tratado como uma conexão nova
+	lastEventID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	sub := h.hub.Subscribe(code, lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", StreamRetry.Milliseconds())
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	sent := 0
+	for {
+		select {
+		case <-ctx.Done():
+			span.SetAttributes(attribute.Int("events", sent))
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				// O poller terminou (CEP inválido) ou o cliente não acompanhou o ritmo
+				span.SetAttributes(attribute.Int("events", sent))
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				log.Printf("StreamHandler: Error writing event: %v", err)
+				return
+			}
+			flusher.Flush()
+			sent++
+		}
+	}
+}
+
+// writeEvent grava um evento no formato text/event-stream
+func writeEvent(w http.ResponseWriter, event stream.Event) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "id: %d\nevent: %s\n", event.ID, event.Type)
+	for _, line := range bytes.Split(event.Data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,147 @@
+package delivery
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"service-a/internal/common"
+	"service-a/internal/serviceb"
+	"service-a/internal/stream"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSource struct {
+	mock.Mock
+}
+
+func (m *MockSource) Fetch(ctx context.Context, cep string) ([]byte, error) {
+	args := m.Called(ctx, cep)
+	body, _ := args.Get(0).([]byte)
+	return body, args.Error(1)
+}
+
+func newStreamServer(t *testing.T, source stream.Source, heartbeat time.Duration) *httptest.Server {
+	hub := stream.NewHub(source, time.Hour, 8)
+	handler := NewStreamHandler(hub, heartbeat)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cep/{cep}/stream", handler.Handle)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readUntil lê o stream até encontrar uma linha com o prefixo informado
+func readUntil(t *testing.T, reader *bufio.Reader, prefix string) string {
+	t.Helper()
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line)
+		}
+	}
+}
+
+func TestStreamHandler_SendsTemperatureEvents(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, "01001000").
+		Return([]byte(`{"city":"São Paulo","temp_C":28.5}`), nil)
+	server := newStreamServer(t, source, time.Hour)
+
+	resp, err := http.Get(server.URL + "/cep/01001000/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "retry: 3000", readUntil(t, reader, "retry:"))
+	assert.Equal(t, "id: 1", readUntil(t, reader, "id:"))
+	assert.Equal(t, "event: temperature", readUntil(t, reader, "event:"))
+	assert.Equal(t, `data: {"city":"São Paulo","temp_C":28.5}`, readUntil(t, reader, "data:"))
+}
+
+func TestStreamHandler_ResumesWithLastEventID(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, "01001000").
+		Return([]byte(`{"city":"São Paulo","temp_C":28.5}`), nil)
+	server := newStreamServer(t, source, time.Hour)
+
+	first, err := http.Get(server.URL + "/cep/01001000/stream")
+	require.NoError(t, err)
+	defer first.Body.Close()
+	assert.Equal(t, "id: 1", readUntil(t, bufio.NewReader(first.Body), "id:"))
+
+	// O cliente já tem o evento 1: nada é reenviado até a leitura mudar, só os heartbeats
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/cep/01001000/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	resumed, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer resumed.Body.Close()
+
+	reader := bufio.NewReader(resumed.Body)
+	readUntil(t, reader, "retry:")
+	_, err = reader.ReadString('i')
+	assert.Error(t, err)
+	source.AssertNumberOfCalls(t, "Fetch", 1)
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, "01001000").
+		Return([]byte(`{"city":"São Paulo","temp_C":28.5}`), nil)
+	server := newStreamServer(t, source, 10*time.Millisecond)
+
+	resp, err := http.Get(server.URL + "/cep/01001000/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, ": heartbeat", readUntil(t, bufio.NewReader(resp.Body), ":"))
+}
+
+func TestStreamHandler_CEPNotFoundEndsStream(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, "99999999").
+		Return(nil, &serviceb.StatusError{StatusCode: http.StatusNotFound, Problem: common.NewProblem(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")})
+	server := newStreamServer(t, source, time.Hour)
+
+	resp, err := http.Get(server.URL + "/cep/99999999/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "event: error", readUntil(t, reader, "event:"))
+	assert.Equal(t, "data: "+problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode"), readUntil(t, reader, "data:"))
+	_, err = reader.ReadString('x')
+	assert.Error(t, err)
+}
+
+func TestStreamHandler_InvalidCEP(t *testing.T) {
+	for _, cep := range []string{"123", "0100100a", "..%2F..%2F"} {
+		t.Run(cep, func(t *testing.T) {
+			source := new(MockSource)
+			handler := NewStreamHandler(stream.NewHub(source, time.Hour, 8), time.Hour)
+
+			req := httptest.NewRequest(http.MethodGet, "/cep/"+cep+"/stream", nil)
+			req.SetPathValue("cep", cep)
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			assert.JSONEq(t, problemBody(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode"), w.Body.String())
+			source.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package delivery
+
+import (
+	"mime"
+	"net/http"
+	"shared/encoder"
+	"strings"
+)
+
+// Versões da resposta do POST /cep, as mesmas do GET /cep/{cep} do serviço B
+const (
+	// APIVersion1 é o formato original {city,temp_C,temp_F,temp_K}, marcado como obsoleto
+	APIVersion1 = "v1"
+	// APIVersion2 é a resposta tipada do serviço B, com localização estruturada e unidades
+	APIVersion2 = "v2"
+)
+
+// Media types que pedem uma versão específica pelo cabeçalho Accept
+const (
+	MediaTypeV1 = "application/vnd.weather.v1+json"
+	MediaTypeV2 = "application/vnd.weather.v2+json"
+)
+
+// requestVersion retorna a versão pedida pelo caminho (/v1/cep ou /v2/cep) ou, em /cep, pelo
+// Accept
+func requestVersion(r *http.Request) string {
+	for _, v := range []string{APIVersion1, APIVersion2} {
+		if strings.HasPrefix(r.URL.Path, "/"+v+"/") {
+			return v
+		}
+	}
+	return acceptedVersion(r.Header.Get("Accept"))
+}
+
+// acceptedVersion retorna a versão pedida pelo Accept. application/json, */* e media types
+// desconhecidos mantêm a v1.
+func acceptedVersion(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case MediaTypeV2:
+			return APIVersion2
+		case MediaTypeV1:
+			return APIVersion1
+		}
+	}
+	return APIVersion1
+}
+
+// setDeprecationHeaders marca a resposta v1 como obsoleta (Deprecation, RFC 9745) e aponta o
+// POST /v2/cep; Sunset (RFC 8594) é enviado quando a data de desligamento foi definida
+func (h *CEPHandler) setDeprecationHeaders(w http.ResponseWriter) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", "</"+APIVersion2+`/cep>; rel="successor-version"`)
+	if !h.v1Sunset.IsZero() {
+		w.Header().Set("Sunset", h.v1Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// responseContentType é o Content-Type da resposta de sucesso: o media type da versão no JSON e,
+// nos demais formatos, o do encoder
+func responseContentType(version string, enc encoder.Encoder) string {
+	switch {
+	case encoder.IsJSON(enc) && version == APIVersion2:
+		return MediaTypeV2
+	case encoder.IsJSON(enc):
+		return "application/json"
+	default:
+		return enc.ContentType()
+	}
+}
@@ -0,0 +1,326 @@
+package delivery
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"service-a/internal/stream"
+	"shared/cep"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Tipos das mensagens do protocolo WebSocket. O cliente envia subscribe e unsubscribe; o
+// servidor responde com subscribed/unsubscribed e envia temperature e error.
+const (
+	WSMessageSubscribe    = "subscribe"
+	WSMessageUnsubscribe  = "unsubscribe"
+	WSMessageSubscribed   = "subscribed"
+	WSMessageUnsubscribed = "unsubscribed"
+	WSMessageTemperature  = "temperature"
+	WSMessageError        = "error"
+)
+
+// Códigos das mensagens de erro que não se referem a uma leitura
+const (
+	WSErrorInvalidMessage = "invalid_message"
+	WSErrorInvalidCEP     = "invalid_zipcode"
+	WSErrorLimitExceeded  = "subscription_limit_exceeded"
+)
+
+const (
+	// wsMaxMessageSize limita o tamanho de uma mensagem do cliente (cabe com folga uma lista de centenas de CEPs)
+	wsMaxMessageSize = 64 * 1024
+	// wsWriteWait é o prazo para gravar uma mensagem no socket
+	wsWriteWait = 10 * time.Second
+	// wsSendBuffer é quantas mensagens podem aguardar a gravação antes do cliente ser desconectado
+	wsSendBuffer = 256
+)
+
+// WSClientMessage é uma mensagem enviada pelo cliente
+type WSClientMessage struct {
+	Type string   `json:"type"`
+	CEPs []string `json:"ceps"`
+}
+
+// WSServerMessage é uma mensagem enviada pelo servidor. Data é o corpo da leitura (mesmo formato
+// de /cep/{cep}) nos eventos temperature e error de um CEP.
+type WSServerMessage struct {
+	Type    string          `json:"type"`
+	CEP     string          `json:"cep,omitempty"`
+	CEPs    []string        `json:"ceps,omitempty"`
+	EventID uint64          `json:"event_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Code    string          `json:"code,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Reason  string          `json:"reason,omitempty"`
+}
+
+// WSHandler aceita conexões WebSocket em que o cliente assina vários CEPs e recebe as leituras
+// multiplexadas, usando os mesmos pollers compartilhados do stream SSE
+type WSHandler struct {
+	hub              *stream.Hub
+	maxSubscriptions int
+	pingInterval     time.Duration
+	upgrader         websocket.Upgrader
+}
+
+// NewWSHandler cria um novo handler. Cada conexão pode assinar até maxSubscriptions CEPs e recebe
+// um ping a cada pingInterval; sem pong em dobro desse tempo, a conexão é encerrada. Com
+// allowedOrigins vazio, só são aceitas conexões da mesma origem; "*" aceita qualquer origem.
+func NewWSHandler(hub *stream.Hub, maxSubscriptions int, pingInterval time.Duration, allowedOrigins []string) *WSHandler {
+	h := &WSHandler{hub: hub, maxSubscriptions: maxSubscriptions, pingInterval: pingInterval}
+	h.upgrader = websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 4096}
+	if len(allowedOrigins) > 0 {
+		h.upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			for _, allowed := range allowedOrigins {
+				if allowed == "*" || allowed == origin {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return h
+}
+
+// wsConn é o estado de uma conexão: as assinaturas ativas e a fila de mensagens a gravar
+type wsConn struct {
+	handler *WSHandler
+	conn    *websocket.Conn
+	send    chan WSServerMessage
+	done    chan struct{}
+	closed  chan struct{}
+
+	mu   sync.Mutex
+	subs map[string]*stream.Subscription
+	wg   sync.WaitGroup
+}
+
+// Handle processa GET /ws
+func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log.Println("WSHandler: Request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-a")
+	_, span := tracer.Start(ctx, "process-cep-ws-handler")
+	defer span.End()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// O upgrader já respondeu com o erro HTTP
+		log.Printf("WSHandler: Upgrade failed: %v", err)
+		span.SetStatus(codes.Error, "WebSocket upgrade failed")
+		return
+	}
+
+	c := &wsConn{
+		handler: h,
+		conn:    conn,
+		send:    make(chan WSServerMessage, wsSendBuffer),
+		done:    make(chan struct{}),
+		closed:  make(chan struct{}),
+		subs:    map[string]*stream.Subscription{},
+	}
+	go c.writeLoop()
+	received := c.readLoop()
+
+	c.close()
+	span.SetAttributes(attribute.Int("messages_received", received))
+}
+
+// readLoop processa as mensagens do cliente até a conexão cair ou o pong deixar de chegar
+func (c *wsConn) readLoop() int {
+	pongWait := 2 * c.handler.pingInterval
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	received := 0
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("WSHandler: Connection closed: %v", err)
+			}
+			return received
+		}
+		received++
+
+		var msg WSClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.enqueue(WSServerMessage{Type: WSMessageError, Code: WSErrorInvalidMessage, Message: "message must be a JSON object"})
+			continue
+		}
+
+		switch msg.Type {
+		case WSMessageSubscribe:
+			c.subscribe(msg.CEPs)
+		case WSMessageUnsubscribe:
+			c.unsubscribe(msg.CEPs)
+		default:
+			c.enqueue(WSServerMessage{Type: WSMessageError, Code: WSErrorInvalidMessage, Message: "type must be subscribe or unsubscribe"})
+		}
+	}
+}
+
+// subscribe assina os CEPs informados. A mensagem é rejeitada por inteiro se algum CEP for
+// inválido ou se o limite de assinaturas da conexão for ultrapassado.
+func (c *wsConn) subscribe(ceps []string) {
+	if len(ceps) == 0 {
+		c.enqueue(WSServerMessage{Type: WSMessageError, Code: WSErrorInvalidMessage, Message: "ceps is required"})
+		return
+	}
+	var invalid []string
+	for _, code := range ceps {
+		if cep.Validate(code) != nil {
+			invalid = append(invalid, code)
+		}
+	}
+	if len(invalid) > 0 {
+		c.enqueue(WSServerMessage{Type: WSMessageError, Code: WSErrorInvalidCEP, CEPs: invalid, Message: "invalid zipcode"})
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var added []string
+	for _, cep := range ceps {
+		if _, ok := c.subs[cep]; !ok && !contains(added, cep) {
+			added = append(added, cep)
+		}
+	}
+	if len(c.subs)+len(added) > c.handler.maxSubscriptions {
+		c.enqueue(WSServerMessage{
+			Type:    WSMessageError,
+			Code:    WSErrorLimitExceeded,
+			CEPs:    added,
+			Message: "subscription limit exceeded",
+		})
+		return
+	}
+
+	// A confirmação vai para a fila antes da primeira leitura de cada CEP
+	c.enqueue(WSServerMessage{Type: WSMessageSubscribed, CEPs: ceps})
+	for _, cep := range added {
+		sub := c.handler.hub.Subscribe(cep, 0)
+		c.subs[cep] = sub
+		c.wg.Add(1)
+		go c.forward(cep, sub)
+	}
+}
+
+// unsubscribe cancela as assinaturas informadas; CEPs não assinados são ignorados
+func (c *wsConn) unsubscribe(ceps []string) {
+	c.mu.Lock()
+	for _, cep := range ceps {
+		if sub, ok := c.subs[cep]; ok {
+			delete(c.subs, cep)
+			sub.Close()
+		}
+	}
+	c.mu.Unlock()
+	c.enqueue(WSServerMessage{Type: WSMessageUnsubscribed, CEPs: ceps})
+}
+
+// forward repassa os eventos de um CEP para a conexão. Quando o hub encerra a assinatura (CEP
+// inexistente ou cliente lento), o cliente é avisado e pode assinar de novo.
+func (c *wsConn) forward(cep string, sub *stream.Subscription) {
+	defer c.wg.Done()
+
+	lastType := ""
+	for event := range sub.Events() {
+		lastType = event.Type
+		c.enqueue(WSServerMessage{Type: event.Type, CEP: cep, EventID: event.ID, Data: event.Data})
+	}
+
+	c.mu.Lock()
+	current, ok := c.subs[cep]
+	ended := ok && current == sub
+	if ended {
+		delete(c.subs, cep)
+	}
+	c.mu.Unlock()
+	if !ended {
+		// Cancelada pelo próprio cliente ou pelo fim da conexão
+		return
+	}
+
+	reason := "lagging"
+	if lastType == stream.EventError {
+		reason = "error"
+	}
+	c.enqueue(WSServerMessage{Type: WSMessageUnsubscribed, CEPs: []string{cep}, Reason: reason})
+}
+
+// enqueue coloca a mensagem na fila de gravação. Um cliente que não consome a fila é desconectado.
+func (c *wsConn) enqueue(msg WSServerMessage) {
+	select {
+	case <-c.done:
+	case c.send <- msg:
+	default:
+		log.Printf("WSHandler: Send buffer full, closing connection")
+		c.conn.Close()
+	}
+}
+
+// writeLoop é o único a gravar no socket: as mensagens da fila e os pings periódicos
+func (c *wsConn) writeLoop() {
+	ping := time.NewTicker(c.handler.pingInterval)
+	defer ping.Stop()
+	defer close(c.closed)
+	defer c.conn.Close()
+
+	for {
+		select {
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				log.Printf("WSHandler: Error writing message: %v", err)
+				return
+			}
+		case <-ping.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// close cancela todas as assinaturas da conexão e espera o envio da mensagem de fechamento
+func (c *wsConn) close() {
+	c.mu.Lock()
+	for cep, sub := range c.subs {
+		delete(c.subs, cep)
+		sub.Close()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+
+	close(c.done)
+	<-c.closed
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
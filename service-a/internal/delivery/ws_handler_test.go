@@ -0,0 +1,155 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"service-a/internal/common"
+	"service-a/internal/serviceb"
+	"service-a/internal/stream"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newWSServer(t *testing.T, source stream.Source, maxSubscriptions int) (*websocket.Conn, *stream.Hub) {
+	hub := stream.NewHub(source, time.Hour, 8)
+	handler := NewWSHandler(hub, maxSubscriptions, time.Minute, nil)
+	server := httptest.NewServer(http.HandlerFunc(handler.Handle))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, hub
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) WSServerMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var msg WSServerMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestWSHandler_SubscribeMultipleCEPs(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, "01001000").
+		Return([]byte(`{"city":"São Paulo","temp_C":28.5}`), nil)
+	source.On("Fetch", mock.Anything, "29902555").
+		Return([]byte(`{"city":"Linhares","temp_C":31}`), nil)
+	conn, _ := newWSServer(t, source, 10)
+
+	require.NoError(t, conn.WriteJSON(WSClientMessage{Type: WSMessageSubscribe, CEPs: []string{"01001000", "29902555"}}))
+
+	ack := readWS(t, conn)
+	assert.Equal(t, WSMessageSubscribed, ack.Type)
+	assert.Equal(t, []string{"01001000", "29902555"}, ack.CEPs)
+
+	readings := map[string]string{}
+	for i := 0; i < 2; i++ {
+		msg := readWS(t, conn)
+		assert.Equal(t, WSMessageTemperature, msg.Type)
+		assert.NotZero(t, msg.EventID)
+		readings[msg.CEP] = string(msg.Data)
+	}
+	assert.JSONEq(t, `{"city":"São Paulo","temp_C":28.5}`, readings["01001000"])
+	assert.JSONEq(t, `{"city":"Linhares","temp_C":31}`, readings["29902555"])
+}
+
+func TestWSHandler_Unsubscribe(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, "01001000").
+		Return([]byte(`{"city":"São Paulo","temp_C":28.5}`), nil)
+	conn, hub := newWSServer(t, source, 10)
+
+	require.NoError(t, conn.WriteJSON(WSClientMessage{Type: WSMessageSubscribe, CEPs: []string{"01001000"}}))
+	readWS(t, conn)
+	readWS(t, conn)
+	assert.Equal(t, 1, hub.Active())
+
+	require.NoError(t, conn.WriteJSON(WSClientMessage{Type: WSMessageUnsubscribe, CEPs: []string{"01001000"}}))
+	msg := readWS(t, conn)
+	assert.Equal(t, WSMessageUnsubscribed, msg.Type)
+	assert.Equal(t, []string{"01001000"}, msg.CEPs)
+	assert.Equal(t, 0, hub.Active())
+}
+
+func TestWSHandler_SubscriptionLimit(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, mock.Anything).
+		Return([]byte(`{"city":"São Paulo","temp_C":28.5}`), nil)
+	conn, hub := newWSServer(t, source, 2)
+
+	require.NoError(t, conn.WriteJSON(WSClientMessage{Type: WSMessageSubscribe, CEPs: []string{"01001000", "01001001", "01001002"}}))
+
+	msg := readWS(t, conn)
+	assert.Equal(t, WSMessageError, msg.Type)
+	assert.Equal(t, WSErrorLimitExceeded, msg.Code)
+	assert.Equal(t, 0, hub.Active())
+}
+
+func TestWSHandler_InvalidMessages(t *testing.T) {
+	conn, _ := newWSServer(t, new(MockSource), 10)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	assert.Equal(t, WSErrorInvalidMessage, readWS(t, conn).Code)
+
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": "publish"}))
+	assert.Equal(t, WSErrorInvalidMessage, readWS(t, conn).Code)
+
+	require.NoError(t, conn.WriteJSON(WSClientMessage{Type: WSMessageSubscribe, CEPs: []string{"123", "01001000", "0100100a"}}))
+	msg := readWS(t, conn)
+	assert.Equal(t, WSErrorInvalidCEP, msg.Code)
+	assert.Equal(t, []string{"123", "0100100a"}, msg.CEPs)
+}
+
+func TestWSHandler_CEPNotFoundEndsSubscription(t *testing.T) {
+	source := new(MockSource)
+	source.On("Fetch", mock.Anything, "99999999").
+		Return(nil, &serviceb.StatusError{StatusCode: http.StatusNotFound, Problem: common.NewProblem(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")})
+	conn, _ := newWSServer(t, source, 10)
+
+	require.NoError(t, conn.WriteJSON(WSClientMessage{Type: WSMessageSubscribe, CEPs: []string{"99999999"}}))
+	readWS(t, conn)
+
+	msg := readWS(t, conn)
+	assert.Equal(t, WSMessageError, msg.Type)
+	assert.Equal(t, "99999999", msg.CEP)
+	assert.JSONEq(t, problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode"), string(msg.Data))
+
+	msg = readWS(t, conn)
+	assert.Equal(t, WSMessageUnsubscribed, msg.Type)
+	assert.Equal(t, "error", msg.Reason)
+}
+
+func TestWSHandler_SendsPings(t *testing.T) {
+	handler := NewWSHandler(stream.NewHub(new(MockSource), time.Hour, 8), 10, 20*time.Millisecond, nil)
+	server := httptest.NewServer(http.HandlerFunc(handler.Handle))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ping := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case ping <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	// Os pings são processados durante a leitura
+	go conn.ReadMessage()
+	select {
+	case <-ping:
+	case <-time.After(time.Second):
+		t.Fatal("no ping received")
+	}
+}
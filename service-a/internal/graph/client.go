@@ -0,0 +1,338 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"service-a/internal/i18n"
+	weatherv1 "service-a/internal/pb/weather/v1"
+	"service-a/internal/serviceb"
+	"sync"
+	"time"
+
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// fetchPrecision é a precisão pedida ao serviço B; o arredondamento final é feito por campo
+	fetchPrecision = 6
+	// httpConcurrency limita as chamadas HTTP simultâneas de um mesmo lote
+	httpConcurrency = 8
+)
+
+// fetchUnits são todas as escalas do serviço B, buscadas de uma vez para que cada campo
+// escolha a sua unidade sem nova chamada
+var fetchUnits = []string{"C", "F", "K", "R", "Re"}
+
+// Weather é o clima atual de um CEP, com as temperaturas em todas as unidades
+type Weather struct {
+	City          string
+	Fallback      string
+	Temperatures  map[string]float64
+	FeelsLike     map[string]float64
+	Humidity      *int32
+	WindKph       *float64
+	WindDegree    *int32
+	WindDir       *string
+	PressureMb    *float64
+	UV            *float64
+	Condition     *string
+	ConditionCode *int32
+	ObservedAt    *time.Time
+}
+
+// ForecastDay é a previsão de um dia, com as temperaturas em todas as unidades
+type ForecastDay struct {
+	Date          string
+	Max           map[string]float64
+	Min           map[string]float64
+	Avg           map[string]float64
+	ChanceOfRain  int32
+	TotalPrecipMm float64
+	AvgHumidity   int32
+	Condition     string
+	ConditionCode int32
+}
+
+// Forecast é a previsão diária de um CEP
+type Forecast struct {
+	City     string
+	Fallback string
+	Days     []ForecastDay
+}
+
+// WeatherResult é o resultado de um CEP dentro de um lote
+type WeatherResult struct {
+	Weather *Weather
+	Err     error
+}
+
+// ServiceBError é uma falha reportada pelo serviço B (ou no contato com ele), com o status
+// HTTP equivalente
+type ServiceBError struct {
+	Status  int
+	Message string
+}
+
+func (e *ServiceBError) Error() string {
+	return e.Message
+}
+
+// Extensions expõe o código do erro no campo "extensions" da resposta GraphQL
+func (e *ServiceBError) Extensions() map[string]interface{} {
+	code := "SERVICE_B_ERROR"
+	switch e.Status {
+	case http.StatusBadRequest:
+		code = "BAD_REQUEST"
+	case http.StatusNotFound:
+		code = "NOT_FOUND"
+	case http.StatusUnprocessableEntity:
+		code = "INVALID_ZIPCODE"
+	}
+	return map[string]interface{}{"code": code, "status": e.Status}
+}
+
+var errContactingServiceB = &ServiceBError{Status: http.StatusInternalServerError, Message: "error contacting service B"}
+
+// ServiceBClient consulta o clima atual e a previsão no serviço B
+type ServiceBClient interface {
+	// BatchWeather busca o clima atual de vários CEPs, com um resultado por CEP na mesma ordem
+	BatchWeather(ctx context.Context, ceps []string) []WeatherResult
+	Forecast(ctx context.Context, cep string, days int) (*Forecast, error)
+}
+
+type serviceBClient struct {
+	client     serviceb.Client
+	grpcClient weatherv1.WeatherServiceClient
+}
+
+// ClientOption configura dependências opcionais do ServiceBClient
+type ClientOption func(*serviceBClient)
+
+// WithGRPCClient faz o clima atual ser buscado em um único BatchGetByCEP por lote
+func WithGRPCClient(client weatherv1.WeatherServiceClient) ClientOption {
+	return func(c *serviceBClient) {
+		c.grpcClient = client
+	}
+}
+
+// NewServiceBClient cria um cliente do serviço B sobre o cliente tipado. Sem gRPC, cada CEP do
+// lote é uma chamada ao client, com até httpConcurrency em paralelo; a previsão é sempre
+// buscada por ele.
+func NewServiceBClient(client serviceb.Client, opts ...ClientOption) ServiceBClient {
+	c := &serviceBClient{client: client}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BatchWeather busca o clima atual de vários CEPs
+func (c *serviceBClient) BatchWeather(ctx context.Context, ceps []string) []WeatherResult {
+	if c.grpcClient != nil {
+		return c.batchWeatherGRPC(ctx, ceps)
+	}
+
+	results := make([]WeatherResult, len(ceps))
+	slots := make(chan struct{}, httpConcurrency)
+	var wg sync.WaitGroup
+	for i, cep := range ceps {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, cep string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			weather, err := c.fetchWeather(ctx, cep)
+			results[i] = WeatherResult{Weather: weather, Err: err}
+		}(i, cep)
+	}
+	wg.Wait()
+	return results
+}
+
+// fetchWeather busca o clima atual de um CEP com todas as unidades e campos opcionais
+func (c *serviceBClient) fetchWeather(ctx context.Context, cep string) (*Weather, error) {
+	precision := fetchPrecision
+	weather, err := c.client.Weather(ctx, cep, serviceb.Options{Detail: true, Units: fetchUnits, Precision: &precision})
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	return &Weather{
+		City:          weather.City,
+		Fallback:      weather.Fallback,
+		Temperatures:  weather.Temperatures,
+		FeelsLike:     weather.FeelsLike,
+		Humidity:      int32Ptr(weather.Humidity),
+		WindKph:       weather.WindKph,
+		WindDegree:    int32Ptr(weather.WindDegree),
+		WindDir:       weather.WindDir,
+		PressureMb:    weather.PressureMb,
+		UV:            weather.UV,
+		Condition:     weather.Condition,
+		ConditionCode: int32Ptr(weather.ConditionCode),
+		ObservedAt:    weather.ObservedAt,
+	}, nil
+}
+
+// batchWeatherGRPC busca todos os CEPs em um único BatchGetByCEP
+func (c *serviceBClient) batchWeatherGRPC(ctx context.Context, ceps []string) []WeatherResult {
+	results := make([]WeatherResult, len(ceps))
+	fail := func(err error) []WeatherResult {
+		for i := range results {
+			if results[i].Weather == nil && results[i].Err == nil {
+				results[i].Err = err
+			}
+		}
+		return results
+	}
+
+	precision := int32(fetchPrecision)
+	stream, err := c.grpcClient.BatchGetByCEP(i18n.AppendToOutgoingContext(ctx), &weatherv1.BatchGetByCEPRequest{
+		Ceps:    ceps,
+		Options: &weatherv1.ResponseOptions{Units: fetchUnits, Precision: &precision, Detail: true},
+	})
+	if err != nil {
+		log.Printf("GraphQL: Error calling BatchGetByCEP: %v", err)
+		return fail(grpcError(err))
+	}
+
+	// O serviço B responde na ordem em que cada CEP fica pronto; CEPs repetidos recebem o mesmo resultado
+	positions := make(map[string][]int, len(ceps))
+	for i, cep := range ceps {
+		positions[cep] = append(positions[cep], i)
+	}
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			log.Printf("GraphQL: Error receiving BatchGetByCEP result: %v", err)
+			return fail(grpcError(err))
+		}
+
+		item := WeatherResult{}
+		if e := result.GetError(); e != nil {
+			item.Err = grpcError(status.Error(grpccodes.Code(e.GetCode()), e.GetMessage()))
+		} else {
+			item.Weather = weatherFromGRPC(result.GetWeather())
+		}
+		for _, i := range positions[result.GetCep()] {
+			results[i] = item
+		}
+	}
+	return fail(&ServiceBError{Status: http.StatusInternalServerError, Message: "missing result from service B"})
+}
+
+// Forecast busca a previsão diária de um CEP pelo GET /cep/{cep}/forecast do serviço B
+func (c *serviceBClient) Forecast(ctx context.Context, cep string, days int) (*Forecast, error) {
+	precision := fetchPrecision
+	response, err := c.client.Forecast(ctx, cep, days, serviceb.Options{Units: fetchUnits, Precision: &precision})
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	forecast := &Forecast{City: response.City, Fallback: response.Fallback}
+	for _, day := range response.Days {
+		forecast.Days = append(forecast.Days, ForecastDay{
+			Date:          day.Date,
+			Max:           day.Max,
+			Min:           day.Min,
+			Avg:           day.Avg,
+			ChanceOfRain:  int32(day.ChanceOfRain),
+			TotalPrecipMm: day.TotalPrecipMm,
+			AvgHumidity:   int32(day.AvgHumidity),
+			Condition:     day.Condition,
+			ConditionCode: int32(day.ConditionCode),
+		})
+	}
+	return forecast, nil
+}
+
+// clientError mapeia os erros do serviceb.Client para o mesmo ServiceBError do gRPC
+func clientError(err error) error {
+	var statusErr *serviceb.StatusError
+	switch {
+	case errors.As(err, &statusErr):
+		message := statusErr.Problem.Detail
+		if message == "" {
+			message = fmt.Sprintf("service B returned status %d", statusErr.StatusCode)
+		}
+		return &ServiceBError{Status: statusErr.StatusCode, Message: message}
+	case errors.Is(err, serviceb.ErrInvalidResponse), errors.Is(err, serviceb.ErrResponseTooLarge):
+		return decodeError(err)
+	default:
+		return errContactingServiceB
+	}
+}
+
+// grpcError mapeia o status gRPC para o mesmo ServiceBError do transporte HTTP. Como as opções
+// do lote são fixas, INVALID_ARGUMENT em um item só pode ser CEP inválido.
+func grpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return errContactingServiceB
+	}
+	switch st.Code() {
+	case grpccodes.InvalidArgument:
+		return &ServiceBError{Status: http.StatusUnprocessableEntity, Message: st.Message()}
+	case grpccodes.NotFound:
+		return &ServiceBError{Status: http.StatusNotFound, Message: st.Message()}
+	case grpccodes.Internal:
+		return &ServiceBError{Status: http.StatusInternalServerError, Message: st.Message()}
+	default:
+		return errContactingServiceB
+	}
+}
+
+func weatherFromGRPC(resp *weatherv1.GetByCEPResponse) *Weather {
+	weather := &Weather{
+		City:         resp.GetCity(),
+		Fallback:     resp.GetFallback(),
+		Temperatures: grpcUnitValues(resp.GetTemperatures()),
+	}
+	o := resp.GetObservation()
+	if o == nil {
+		return weather
+	}
+	weather.FeelsLike = grpcUnitValues(o.GetFeelsLike())
+	weather.Humidity = o.Humidity
+	weather.WindKph = o.WindKph
+	weather.WindDegree = o.WindDegree
+	weather.WindDir = o.WindDir
+	weather.PressureMb = o.PressureMb
+	weather.UV = o.Uv
+	weather.Condition = o.Condition
+	weather.ConditionCode = o.ConditionCode
+	if o.ObservedAt != nil {
+		observedAt := o.ObservedAt.AsTime()
+		weather.ObservedAt = &observedAt
+	}
+	return weather
+}
+
+func grpcUnitValues(temps []*weatherv1.Temperature) map[string]float64 {
+	values := make(map[string]float64, len(temps))
+	for _, t := range temps {
+		values[t.GetUnit()] = t.GetValue()
+	}
+	return values
+}
+
+func int32Ptr(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	v := int32(*value)
+	return &v
+}
+
+func decodeError(err error) error {
+	log.Printf("GraphQL: Error decoding response from service B: %v", err)
+	return &ServiceBError{Status: http.StatusInternalServerError, Message: "error decoding response from service B"}
+}
@@ -0,0 +1,181 @@
+package graph
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	weatherv1 "service-a/internal/pb/weather/v1"
+	"service-a/internal/serviceb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newServiceB(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/cep/{cep}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "full", r.URL.Query().Get("detail"))
+		assert.Equal(t, "C,F,K,R,Re", r.URL.Query().Get("units"))
+		assert.Equal(t, "6", r.URL.Query().Get("precision"))
+		switch r.PathValue("cep") {
+		case "01001000":
+			w.Write([]byte(`{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"feelslike_C":30.1,"humidity":70,"wind_dir":"SSE","observed_at":"2026-10-19T12:00:00Z","heat_risk":"caution"}`))
+		case "29902555":
+			w.Header().Set(serviceb.FallbackHeader, "state-capital")
+			w.Write([]byte(`{"city":"Vitória","temp_C":30}`))
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"/problems/zipcode-not-found","title":"Zipcode not found","status":404,"detail":"can not find zipcode","code":"ZIPCODE_NOT_FOUND"}`))
+		}
+	})
+	mux.HandleFunc("GET /cep/{cep}/forecast", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("days"))
+		w.Write([]byte(`{"city":"São Paulo","forecast":[{"date":"2026-10-19","max_temp_C":30,"max_temp_F":86,"min_temp_C":19.5,"avg_temp_C":24.25,"chance_of_rain":80,"total_precip_mm":12.4,"avg_humidity":75,"condition":"Patchy rain nearby","condition_code":1063}]}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServiceBClient_BatchWeatherHTTP(t *testing.T) {
+	server := newServiceB(t)
+	client := NewServiceBClient(serviceb.NewClient(server.URL, http.DefaultClient))
+
+	results := client.BatchWeather(context.Background(), []string{"01001000", "29902555", "99999999"})
+
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	sp := results[0].Weather
+	assert.Equal(t, "São Paulo", sp.City)
+	assert.Equal(t, map[string]float64{"C": 28.5, "F": 83.3}, sp.Temperatures)
+	assert.Equal(t, map[string]float64{"C": 30.1}, sp.FeelsLike)
+	assert.Equal(t, int32(70), *sp.Humidity)
+	assert.Equal(t, "SSE", *sp.WindDir)
+	assert.Nil(t, sp.WindKph)
+	assert.Equal(t, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), sp.ObservedAt.UTC())
+
+	require.NoError(t, results[1].Err)
+	assert.Equal(t, "state-capital", results[1].Weather.Fallback)
+
+	assert.Equal(t, &ServiceBError{Status: http.StatusNotFound, Message: "can not find zipcode"}, results[2].Err)
+}
+
+func TestServiceBClient_Forecast(t *testing.T) {
+	server := newServiceB(t)
+	client := NewServiceBClient(serviceb.NewClient(server.URL, http.DefaultClient))
+
+	forecast, err := client.Forecast(context.Background(), "01001000", 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, &Forecast{City: "São Paulo", Days: []ForecastDay{{
+		Date:          "2026-10-19",
+		Max:           map[string]float64{"C": 30, "F": 86},
+		Min:           map[string]float64{"C": 19.5},
+		Avg:           map[string]float64{"C": 24.25},
+		ChanceOfRain:  80,
+		TotalPrecipMm: 12.4,
+		AvgHumidity:   75,
+		Condition:     "Patchy rain nearby",
+		ConditionCode: 1063,
+	}}}, forecast)
+}
+
+func TestServiceBClient_Unreachable(t *testing.T) {
+	server := newServiceB(t)
+	server.Close()
+	client := NewServiceBClient(serviceb.NewClient(server.URL, http.DefaultClient))
+
+	results := client.BatchWeather(context.Background(), []string{"01001000"})
+	assert.Equal(t, errContactingServiceB, results[0].Err)
+}
+
+// fakeWeatherServer responde ao BatchGetByCEP com um resultado por CEP, em ordem inversa
+type fakeWeatherServer struct {
+	weatherv1.UnimplementedWeatherServiceServer
+	requests []*weatherv1.BatchGetByCEPRequest
+}
+
+func (s *fakeWeatherServer) BatchGetByCEP(req *weatherv1.BatchGetByCEPRequest, stream weatherv1.WeatherService_BatchGetByCEPServer) error {
+	s.requests = append(s.requests, req)
+	ceps := req.GetCeps()
+	for i := len(ceps) - 1; i >= 0; i-- {
+		result := &weatherv1.CEPResult{Cep: ceps[i]}
+		if ceps[i] == "99999999" {
+			result.Result = &weatherv1.CEPResult_Error{Error: &weatherv1.Error{Code: int32(codes.NotFound), Message: "can not find zipcode"}}
+		} else {
+			humidity := int32(70)
+			result.Result = &weatherv1.CEPResult_Weather{Weather: &weatherv1.GetByCEPResponse{
+				Cep:          ceps[i],
+				City:         "São Paulo",
+				Temperatures: []*weatherv1.Temperature{{Unit: "C", Value: 28.5}, {Unit: "Re", Value: 22.8}},
+				Observation: &weatherv1.Observation{
+					Humidity:   &humidity,
+					ObservedAt: timestamppb.New(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)),
+				},
+			}}
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newGRPCWeatherClient(t *testing.T, server weatherv1.WeatherServiceServer) weatherv1.WeatherServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	weatherv1.RegisterWeatherServiceServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return weatherv1.NewWeatherServiceClient(conn)
+}
+
+func TestServiceBClient_BatchWeatherGRPC(t *testing.T) {
+	server := &fakeWeatherServer{}
+	client := NewServiceBClient(serviceb.NewClient("http://unused", http.DefaultClient), WithGRPCClient(newGRPCWeatherClient(t, server)))
+
+	results := client.BatchWeather(context.Background(), []string{"01001000", "99999999", "01001000"})
+
+	require.Len(t, server.requests, 1)
+	assert.Equal(t, []string{"C", "F", "K", "R", "Re"}, server.requests[0].GetOptions().GetUnits())
+	assert.True(t, server.requests[0].GetOptions().GetDetail())
+	assert.Equal(t, int32(fetchPrecision), server.requests[0].GetOptions().GetPrecision())
+
+	require.Len(t, results, 3)
+	for _, i := range []int{0, 2} {
+		require.NoError(t, results[i].Err)
+		assert.Equal(t, "São Paulo", results[i].Weather.City)
+		assert.Equal(t, map[string]float64{"C": 28.5, "Re": 22.8}, results[i].Weather.Temperatures)
+		assert.Equal(t, int32(70), *results[i].Weather.Humidity)
+		assert.Equal(t, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), *results[i].Weather.ObservedAt)
+	}
+	assert.Equal(t, &ServiceBError{Status: http.StatusNotFound, Message: "can not find zipcode"}, results[1].Err)
+}
+
+func TestServiceBClient_BatchWeatherGRPCUnavailable(t *testing.T) {
+	client := NewServiceBClient(serviceb.NewClient("http://unused", http.DefaultClient), WithGRPCClient(newGRPCWeatherClient(t, &weatherv1.UnimplementedWeatherServiceServer{})))
+
+	results := client.BatchWeather(context.Background(), []string{"01001000", "20040002"})
+
+	for _, r := range results {
+		assert.Equal(t, errContactingServiceB, r.Err)
+	}
+	assert.Equal(t, errContactingServiceB, grpcError(status.Error(codes.Unavailable, "connection refused")))
+}
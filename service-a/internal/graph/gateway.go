@@ -0,0 +1,41 @@
+// Package graph implementa o gateway GraphQL do serviço A: o cliente escolhe exatamente os campos
+// de localização, clima atual e previsão, e os resolvers consultam o serviço B em lote.
+package graph
+
+import (
+	"context"
+	_ "embed"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	graphqlotel "github.com/graph-gophers/graphql-go/trace/otel"
+	"go.opentelemetry.io/otel"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Gateway executa consultas GraphQL sobre o serviço B
+type Gateway struct {
+	schema *graphql.Schema
+	client ServiceBClient
+}
+
+// NewGateway cria o gateway. maxCEPs limita o argumento ceps de weathers, e maxDepth a
+// profundidade da consulta. Cada resolver que consulta o serviço B gera um span.
+func NewGateway(client ServiceBClient, maxCEPs, maxDepth int) (*Gateway, error) {
+	schema, err := graphql.ParseSchema(schemaSDL, &Resolver{maxCEPs: maxCEPs},
+		graphql.MaxDepth(maxDepth),
+		graphql.Tracer(&graphqlotel.Tracer{Tracer: otel.Tracer("service-a")}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Gateway{schema: schema, client: client}, nil
+}
+
+// Exec executa uma consulta com loaders novos, que agrupam e deduplicam as chamadas ao
+// serviço B apenas dentro dela
+func (g *Gateway) Exec(ctx context.Context, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	ctx = withLoaders(ctx, newLoaders(g.client))
+	return g.schema.Exec(ctx, query, operationName, variables)
+}
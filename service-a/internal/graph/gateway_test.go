@@ -0,0 +1,190 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient devolve o clima e a previsão configurados e registra cada lote recebido
+type fakeClient struct {
+	mu        sync.Mutex
+	weather   map[string]*Weather
+	forecast  map[string]*Forecast
+	batches   [][]string
+	forecasts []forecastKey
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{weather: map[string]*Weather{}, forecast: map[string]*Forecast{}}
+}
+
+func (f *fakeClient) BatchWeather(ctx context.Context, ceps []string) []WeatherResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	batch := append([]string(nil), ceps...)
+	sort.Strings(batch)
+	f.batches = append(f.batches, batch)
+
+	results := make([]WeatherResult, len(ceps))
+	for i, cep := range ceps {
+		if w, ok := f.weather[cep]; ok {
+			results[i].Weather = w
+		} else {
+			results[i].Err = &ServiceBError{Status: http.StatusNotFound, Message: "can not find zipcode"}
+		}
+	}
+	return results
+}
+
+func (f *fakeClient) Forecast(ctx context.Context, cep string, days int) (*Forecast, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.forecasts = append(f.forecasts, forecastKey{cep: cep, days: days})
+	forecast, ok := f.forecast[cep]
+	if !ok {
+		return nil, &ServiceBError{Status: http.StatusNotFound, Message: "can not find zipcode"}
+	}
+	return &Forecast{City: forecast.City, Days: forecast.Days[:min(days, len(forecast.Days))]}, nil
+}
+
+func saoPaulo() *Weather {
+	humidity := int32(70)
+	return &Weather{
+		City:         "São Paulo",
+		Temperatures: map[string]float64{"C": 28.512345, "F": 83.322221, "K": 301.662345, "R": 542.992221, "Re": 22.809876},
+		FeelsLike:    map[string]float64{"C": 30.1, "F": 86.18},
+		Humidity:     &humidity,
+	}
+}
+
+// execute roda a consulta e devolve data e errors já decodificados
+func execute(t *testing.T, client ServiceBClient, query string, variables map[string]interface{}) (map[string]interface{}, []map[string]interface{}) {
+	t.Helper()
+	gateway, err := NewGateway(client, 3, 6)
+	require.NoError(t, err)
+
+	response := gateway.Exec(context.Background(), query, "", variables)
+	raw, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Data   map[string]interface{}   `json:"data"`
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	return decoded.Data, decoded.Errors
+}
+
+func TestGateway_SelectsOnlyRequestedFields(t *testing.T) {
+	client := newFakeClient()
+	client.weather["01001000"] = saoPaulo()
+
+	data, errs := execute(t, client, `{
+		weather(cep: "01001000") {
+			location { city fallback }
+			current { temperature(unit: FAHRENHEIT, precision: 1) humidity windKph }
+		}
+	}`, nil)
+
+	require.Empty(t, errs)
+	assert.Equal(t, map[string]interface{}{
+		"location": map[string]interface{}{"city": "São Paulo", "fallback": nil},
+		"current":  map[string]interface{}{"temperature": 83.3, "humidity": 70.0, "windKph": nil},
+	}, data["weather"])
+	// location e current usam o mesmo resultado do loader
+	assert.Equal(t, [][]string{{"01001000"}}, client.batches)
+	assert.Empty(t, client.forecasts)
+}
+
+func TestGateway_BatchesCEPs(t *testing.T) {
+	client := newFakeClient()
+	client.weather["01001000"] = saoPaulo()
+	client.weather["20040002"] = &Weather{City: "Rio de Janeiro", Temperatures: map[string]float64{"C": 33}}
+
+	data, errs := execute(t, client, `{
+		weathers(ceps: ["01001000", "20040002", "01001000"]) { cep location { city } }
+		rio: weather(cep: "20040002") { current { temperature } }
+	}`, nil)
+
+	require.Empty(t, errs)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"cep": "01001000", "location": map[string]interface{}{"city": "São Paulo"}},
+		map[string]interface{}{"cep": "20040002", "location": map[string]interface{}{"city": "Rio de Janeiro"}},
+		map[string]interface{}{"cep": "01001000", "location": map[string]interface{}{"city": "São Paulo"}},
+	}, data["weathers"])
+	assert.Equal(t, map[string]interface{}{"current": map[string]interface{}{"temperature": 33.0}}, data["rio"])
+	assert.Equal(t, [][]string{{"01001000", "20040002"}}, client.batches)
+}
+
+func TestGateway_PartialErrors(t *testing.T) {
+	client := newFakeClient()
+	client.weather["01001000"] = saoPaulo()
+
+	data, errs := execute(t, client, `{
+		weathers(ceps: ["01001000", "99999999"]) { cep location { city } }
+	}`, nil)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"cep": "01001000", "location": map[string]interface{}{"city": "São Paulo"}},
+		map[string]interface{}{"cep": "99999999", "location": nil},
+	}, data["weathers"])
+	require.Len(t, errs, 1)
+	assert.Equal(t, "can not find zipcode", errs[0]["message"])
+	assert.Equal(t, []interface{}{"weathers", 1.0, "location"}, errs[0]["path"])
+	assert.Equal(t, map[string]interface{}{"code": "NOT_FOUND", "status": 404.0}, errs[0]["extensions"])
+}
+
+func TestGateway_Forecast(t *testing.T) {
+	client := newFakeClient()
+	client.forecast["01001000"] = &Forecast{City: "São Paulo", Days: []ForecastDay{
+		{Date: "2026-10-19", Max: map[string]float64{"C": 30.04, "K": 303.19}, Min: map[string]float64{"C": 19}, ChanceOfRain: 80, Condition: "Patchy rain nearby"},
+		{Date: "2026-10-20", Max: map[string]float64{"C": 27, "K": 300.15}, Min: map[string]float64{"C": 18}},
+		{Date: "2026-10-21", Max: map[string]float64{"C": 25, "K": 298.15}, Min: map[string]float64{"C": 17}},
+	}}
+
+	data, errs := execute(t, client, `query($days: Int) {
+		weather(cep: "01001000") {
+			forecast(days: $days) { date maxTemperature(unit: KELVIN, precision: 0) minTemperature chanceOfRain condition }
+		}
+	}`, map[string]interface{}{"days": 2})
+
+	require.Empty(t, errs)
+	assert.Equal(t, map[string]interface{}{"forecast": []interface{}{
+		map[string]interface{}{"date": "2026-10-19", "maxTemperature": 303.0, "minTemperature": 19.0, "chanceOfRain": 80.0, "condition": "Patchy rain nearby"},
+		map[string]interface{}{"date": "2026-10-20", "maxTemperature": 300.0, "minTemperature": 18.0, "chanceOfRain": 0.0, "condition": ""},
+	}}, data["weather"])
+	assert.Equal(t, []forecastKey{{cep: "01001000", days: 2}}, client.forecasts)
+	assert.Empty(t, client.batches)
+}
+
+func TestGateway_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"invalid zipcode", `{ weather(cep: "123") { cep } }`, "invalid zipcode"},
+		{"invalid zipcode in list", `{ weathers(ceps: ["01001000", "123"]) { cep } }`, "invalid zipcode"},
+		{"too many ceps", `{ weathers(ceps: ["01001000", "01001001", "01001002", "01001003"]) { cep } }`, "at most 3 ceps per query"},
+		{"invalid precision", `{ weather(cep: "01001000") { current { temperature(precision: 7) } } }`, "precision must be an integer between 0 and 6"},
+		{"invalid days", `{ weather(cep: "01001000") { forecast(days: 15) { date } } }`, "days must be between 1 and 14"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient()
+			client.weather["01001000"] = saoPaulo()
+
+			_, errs := execute(t, client, tt.query, nil)
+
+			require.NotEmpty(t, errs)
+			assert.Equal(t, tt.message, errs[0]["message"])
+		})
+	}
+}
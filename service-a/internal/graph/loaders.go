@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// loaderWait é a janela em que os resolvers de uma mesma consulta acumulam CEPs no lote
+	loaderWait = 5 * time.Millisecond
+	// maxBatchSize limita os CEPs enviados ao serviço B por lote
+	maxBatchSize = 100
+)
+
+// forecastKey identifica uma previsão no loader: o mesmo CEP com dias diferentes são chamadas distintas
+type forecastKey struct {
+	cep  string
+	days int
+}
+
+// loaders agrupa as chamadas ao serviço B de uma única consulta GraphQL. O cache dos loaders
+// vive só durante a consulta, então CEPs repetidos são buscados uma vez por requisição.
+type loaders struct {
+	weather  *dataloader.Loader[string, *Weather]
+	forecast *dataloader.Loader[forecastKey, *Forecast]
+}
+
+type loadersKey struct{}
+
+func newLoaders(client ServiceBClient) *loaders {
+	return &loaders{
+		weather: dataloader.NewBatchedLoader(weatherBatch(client),
+			dataloader.WithWait[string, *Weather](loaderWait),
+			dataloader.WithBatchCapacity[string, *Weather](maxBatchSize),
+		),
+		forecast: dataloader.NewBatchedLoader(forecastBatch(client),
+			dataloader.WithWait[forecastKey, *Forecast](loaderWait),
+			dataloader.WithBatchCapacity[forecastKey, *Forecast](maxBatchSize),
+		),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// weatherBatch busca o clima atual de todos os CEPs acumulados em uma única chamada ao cliente
+func weatherBatch(client ServiceBClient) dataloader.BatchFunc[string, *Weather] {
+	return func(ctx context.Context, ceps []string) []*dataloader.Result[*Weather] {
+		tracer := otel.Tracer("service-a")
+		ctx, span := tracer.Start(ctx, "graphql-weather-batch")
+		defer span.End()
+		span.SetAttributes(attribute.Int("batch_size", len(ceps)), attribute.StringSlice("ceps", ceps))
+
+		results := make([]*dataloader.Result[*Weather], len(ceps))
+		for i, r := range client.BatchWeather(ctx, ceps) {
+			results[i] = &dataloader.Result[*Weather]{Data: r.Weather, Error: r.Err}
+		}
+		return results
+	}
+}
+
+// forecastBatch busca as previsões acumuladas; o serviço B não tem previsão em lote, então as
+// chamadas são feitas em paralelo, até httpConcurrency por vez
+func forecastBatch(client ServiceBClient) dataloader.BatchFunc[forecastKey, *Forecast] {
+	return func(ctx context.Context, keys []forecastKey) []*dataloader.Result[*Forecast] {
+		tracer := otel.Tracer("service-a")
+		ctx, span := tracer.Start(ctx, "graphql-forecast-batch")
+		defer span.End()
+		span.SetAttributes(attribute.Int("batch_size", len(keys)))
+
+		results := make([]*dataloader.Result[*Forecast], len(keys))
+		slots := make(chan struct{}, httpConcurrency)
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int, key forecastKey) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				forecast, err := client.Forecast(ctx, key.cep, key.days)
+				results[i] = &dataloader.Result[*Forecast]{Data: forecast, Error: err}
+			}(i, key)
+		}
+		wg.Wait()
+		return results
+	}
+}
@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"shared/cep"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	// maxPrecision é o limite de casas decimais do serviço B
+	maxPrecision = 6
+	// maxForecastDays é o limite de dias da previsão do serviço B
+	maxForecastDays = 14
+)
+
+// unitSymbols mapeia o enum TemperatureUnit para o símbolo usado pelo serviço B
+var unitSymbols = map[string]string{
+	"CELSIUS":    "C",
+	"FAHRENHEIT": "F",
+	"KELVIN":     "K",
+	"RANKINE":    "R",
+	"REAUMUR":    "Re",
+}
+
+// errInvalidZipcode tem a mesma mensagem e status do POST /cep
+var errInvalidZipcode = &ServiceBError{Status: http.StatusUnprocessableEntity, Message: "invalid zipcode"}
+
+// Resolver é a raiz do schema
+type Resolver struct {
+	maxCEPs int
+}
+
+type cepArgs struct {
+	CEP string
+}
+
+type cepsArgs struct {
+	CEPs []string
+}
+
+// Weather resolve o clima de um CEP. Os campos só chamam o serviço B quando selecionados.
+func (r *Resolver) Weather(ctx context.Context, args cepArgs) (*weatherResolver, error) {
+	if cep.Validate(args.CEP) != nil {
+		return nil, errInvalidZipcode
+	}
+	return &weatherResolver{cep: args.CEP}, nil
+}
+
+// Weathers resolve o clima de vários CEPs. Quando location ou current são selecionados, todos os
+// CEPs entram no loader de uma vez, para que o lote não dependa do paralelismo da execução.
+func (r *Resolver) Weathers(ctx context.Context, args cepsArgs) ([]*weatherResolver, error) {
+	if len(args.CEPs) > r.maxCEPs {
+		return nil, &ServiceBError{Status: http.StatusBadRequest, Message: fmt.Sprintf("at most %d ceps per query", r.maxCEPs)}
+	}
+	resolvers := make([]*weatherResolver, 0, len(args.CEPs))
+	for _, code := range args.CEPs {
+		if cep.Validate(code) != nil {
+			return nil, errInvalidZipcode
+		}
+		resolvers = append(resolvers, &weatherResolver{cep: code})
+	}
+
+	if graphql.HasSelectedField(ctx, "location") || graphql.HasSelectedField(ctx, "current") {
+		loadersFrom(ctx).weather.LoadMany(ctx, args.CEPs)
+	}
+	return resolvers, nil
+}
+
+type weatherResolver struct {
+	cep string
+}
+
+func (w *weatherResolver) CEP() string {
+	return w.cep
+}
+
+func (w *weatherResolver) Location(ctx context.Context) (*locationResolver, error) {
+	weather, err := loadersFrom(ctx).weather.Load(ctx, w.cep)()
+	if err != nil {
+		return nil, err
+	}
+	return &locationResolver{city: weather.City, fallback: weather.Fallback}, nil
+}
+
+func (w *weatherResolver) Current(ctx context.Context) (*currentResolver, error) {
+	weather, err := loadersFrom(ctx).weather.Load(ctx, w.cep)()
+	if err != nil {
+		return nil, err
+	}
+	return &currentResolver{weather: weather}, nil
+}
+
+type forecastArgs struct {
+	Days int32
+}
+
+func (w *weatherResolver) Forecast(ctx context.Context, args forecastArgs) (*[]*forecastDayResolver, error) {
+	if args.Days < 1 || args.Days > maxForecastDays {
+		return nil, &ServiceBError{Status: http.StatusBadRequest, Message: fmt.Sprintf("days must be between 1 and %d", maxForecastDays)}
+	}
+	forecast, err := loadersFrom(ctx).forecast.Load(ctx, forecastKey{cep: w.cep, days: int(args.Days)})()
+	if err != nil {
+		return nil, err
+	}
+	days := make([]*forecastDayResolver, 0, len(forecast.Days))
+	for i := range forecast.Days {
+		days = append(days, &forecastDayResolver{day: &forecast.Days[i]})
+	}
+	return &days, nil
+}
+
+type locationResolver struct {
+	city     string
+	fallback string
+}
+
+func (l *locationResolver) City() string {
+	return l.city
+}
+
+func (l *locationResolver) Fallback() *string {
+	if l.fallback == "" {
+		return nil
+	}
+	return &l.fallback
+}
+
+type temperatureArgs struct {
+	Unit      string
+	Precision int32
+}
+
+type currentResolver struct {
+	weather *Weather
+}
+
+func (c *currentResolver) Temperature(args temperatureArgs) (float64, error) {
+	return temperature(c.weather.Temperatures, args)
+}
+
+func (c *currentResolver) FeelsLike(args temperatureArgs) (*float64, error) {
+	if len(c.weather.FeelsLike) == 0 {
+		return nil, nil
+	}
+	value, err := temperature(c.weather.FeelsLike, args)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func (c *currentResolver) Humidity() *int32 {
+	return c.weather.Humidity
+}
+
+func (c *currentResolver) WindKph() *float64 {
+	return c.weather.WindKph
+}
+
+func (c *currentResolver) WindDegree() *int32 {
+	return c.weather.WindDegree
+}
+
+func (c *currentResolver) WindDir() *string {
+	return c.weather.WindDir
+}
+
+func (c *currentResolver) PressureMb() *float64 {
+	return c.weather.PressureMb
+}
+
+func (c *currentResolver) UV() *float64 {
+	return c.weather.UV
+}
+
+func (c *currentResolver) Condition() *string {
+	return c.weather.Condition
+}
+
+func (c *currentResolver) ConditionCode() *int32 {
+	return c.weather.ConditionCode
+}
+
+func (c *currentResolver) ObservedAt() *graphql.Time {
+	if c.weather.ObservedAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *c.weather.ObservedAt}
+}
+
+type forecastDayResolver struct {
+	day *ForecastDay
+}
+
+func (f *forecastDayResolver) Date() string {
+	return f.day.Date
+}
+
+func (f *forecastDayResolver) MaxTemperature(args temperatureArgs) (float64, error) {
+	return temperature(f.day.Max, args)
+}
+
+func (f *forecastDayResolver) MinTemperature(args temperatureArgs) (float64, error) {
+	return temperature(f.day.Min, args)
+}
+
+func (f *forecastDayResolver) AvgTemperature(args temperatureArgs) (float64, error) {
+	return temperature(f.day.Avg, args)
+}
+
+func (f *forecastDayResolver) ChanceOfRain() int32 {
+	return f.day.ChanceOfRain
+}
+
+func (f *forecastDayResolver) TotalPrecipMm() float64 {
+	return f.day.TotalPrecipMm
+}
+
+func (f *forecastDayResolver) AvgHumidity() int32 {
+	return f.day.AvgHumidity
+}
+
+func (f *forecastDayResolver) Condition() string {
+	return f.day.Condition
+}
+
+func (f *forecastDayResolver) ConditionCode() int32 {
+	return f.day.ConditionCode
+}
+
+// temperature escolhe a unidade pedida e arredonda para a precisão do campo
+func temperature(values map[string]float64, args temperatureArgs) (float64, error) {
+	if args.Precision < 0 || args.Precision > maxPrecision {
+		return 0, &ServiceBError{Status: http.StatusBadRequest, Message: fmt.Sprintf("precision must be an integer between 0 and %d", maxPrecision)}
+	}
+	value, ok := values[unitSymbols[args.Unit]]
+	if !ok {
+		return 0, &ServiceBError{Status: http.StatusInternalServerError, Message: "temperature unit missing from service B response"}
+	}
+	scale := math.Pow(10, float64(args.Precision))
+	return math.Round(value*scale) / scale, nil
+}
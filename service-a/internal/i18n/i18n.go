@@ -0,0 +1,116 @@
+// Package i18n negocia o idioma das respostas pelo Accept-Language, traduz as mensagens do
+// serviço A (pt-BR, en e es) e repassa o idioma ao serviço B. Os códigos de erro não são traduzidos.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/metadata"
+)
+
+// Idiomas suportados
+var (
+	English             = language.English
+	BrazilianPortuguese = language.BrazilianPortuguese
+	Spanish             = language.Spanish
+)
+
+var supported = []language.Tag{English, BrazilianPortuguese, Spanish}
+
+// MetadataKey é a chave do idioma nos metadados gRPC, equivalente ao cabeçalho Accept-Language
+// (a mesma lida pelo serviço B)
+const MetadataKey = "accept-language"
+
+type languageKey struct{}
+
+// WithLanguage guarda o idioma negociado no contexto
+func WithLanguage(ctx context.Context, tag language.Tag) context.Context {
+	return context.WithValue(ctx, languageKey{}, tag)
+}
+
+// FromContext retorna o idioma do contexto, ou inglês quando nenhum foi negociado
+// (ex.: pollers compartilhados do stream)
+func FromContext(ctx context.Context) language.Tag {
+	if tag, ok := ctx.Value(languageKey{}).(language.Tag); ok {
+		return tag
+	}
+	return English
+}
+
+// Message traduz a mensagem para o idioma do contexto. key é o texto em inglês (formato do
+// fmt quando há args); mensagens fora do catálogo são retornadas em inglês.
+func Message(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(FromContext(ctx), key, args...)
+}
+
+// Translate traduz a mensagem para o idioma informado
+func Translate(tag language.Tag, key string, args ...interface{}) string {
+	format := key
+	if translated, ok := catalog[tag][key]; ok {
+		format = translated
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Negotiator escolhe, entre os idiomas suportados, o que melhor atende ao Accept-Language
+type Negotiator struct {
+	tags    []language.Tag
+	matcher language.Matcher
+}
+
+// NewNegotiator cria o Negotiator com o idioma usado quando o cliente não informa nenhum
+// suportado (ex.: "en", "pt-BR" ou "es")
+func NewNegotiator(defaultLanguage string) (*Negotiator, error) {
+	tag, err := language.Parse(defaultLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("parse default language: %w", err)
+	}
+	_, index, confidence := language.NewMatcher(supported).Match(tag)
+	if confidence == language.No {
+		return nil, fmt.Errorf("unsupported default language %q", defaultLanguage)
+	}
+
+	tags := []language.Tag{supported[index]}
+	for _, t := range supported {
+		if t != supported[index] {
+			tags = append(tags, t)
+		}
+	}
+	return &Negotiator{tags: tags, matcher: language.NewMatcher(tags)}, nil
+}
+
+// Match retorna o idioma suportado para o valor do Accept-Language
+func (n *Negotiator) Match(acceptLanguage string) language.Tag {
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return n.tags[0]
+	}
+	_, index, _ := n.matcher.Match(preferred...)
+	return n.tags[index]
+}
+
+// Middleware guarda o idioma negociado no contexto da requisição e o informa em Content-Language
+func (n *Negotiator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag := n.Match(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", tag.String())
+		next.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), tag)))
+	})
+}
+
+// SetHeader repassa o idioma do contexto ao serviço B no cabeçalho Accept-Language
+func SetHeader(ctx context.Context, header http.Header) {
+	header.Set("Accept-Language", FromContext(ctx).String())
+}
+
+// AppendToOutgoingContext repassa o idioma do contexto ao serviço B nos metadados gRPC
+func AppendToOutgoingContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, FromContext(ctx).String())
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNegotiator_Match(t *testing.T) {
+	negotiator, err := NewNegotiator("en")
+	require.NoError(t, err)
+
+	tests := []struct {
+		accept string
+		want   language.Tag
+	}{
+		{"", English},
+		{"pt-BR,pt;q=0.9,en;q=0.8", BrazilianPortuguese},
+		{"pt", BrazilianPortuguese},
+		{"es-MX", Spanish},
+		{"de", English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			assert.Equal(t, tt.want, negotiator.Match(tt.accept))
+		})
+	}
+
+	_, err = NewNegotiator("ja")
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	negotiator, err := NewNegotiator("pt-BR")
+	require.NoError(t, err)
+
+	var got language.Tag
+	handler := negotiator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cep", nil))
+
+	assert.Equal(t, BrazilianPortuguese, got)
+	assert.Equal(t, "pt-BR", w.Header().Get("Content-Language"))
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "erro ao contatar o serviço B", Translate(BrazilianPortuguese, "error contacting service B"))
+	assert.Equal(t, "el servicio B respondió con el estado 405", Translate(Spanish, "service B returned status %d", 405))
+	assert.Equal(t, "invalid zipcode", Translate(English, "invalid zipcode"))
+}
+
+func TestCatalogsCoverTheSameKeys(t *testing.T) {
+	for key := range catalog[BrazilianPortuguese] {
+		assert.Contains(t, catalog[Spanish], key)
+	}
+	for key := range catalog[Spanish] {
+		assert.Contains(t, catalog[BrazilianPortuguese], key)
+	}
+}
+
+func TestForwarding(t *testing.T) {
+	ctx := WithLanguage(context.Background(), Spanish)
+
+	header := http.Header{}
+	SetHeader(ctx, header)
+	assert.Equal(t, "es", header.Get("Accept-Language"))
+
+	md, ok := metadata.FromOutgoingContext(AppendToOutgoingContext(ctx))
+	require.True(t, ok)
+	assert.Equal(t, []string{"es"}, md.Get(MetadataKey))
+}
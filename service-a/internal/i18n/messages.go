@@ -0,0 +1,96 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// catalog traduz as mensagens do serviço A, indexadas pelo texto em inglês. As mensagens do
+// serviço B já chegam traduzidas.
+var catalog = map[language.Tag]map[string]string{
+	BrazilianPortuguese: {
+		// Títulos dos problemas
+		"Invalid zipcode":        "CEP inválido",
+		"Zipcode not found":      "CEP não encontrado",
+		"Invalid parameter":      "Parâmetro inválido",
+		"Invalid request body":   "Corpo da requisição inválido",
+		"Unsupported media type": "Tipo de conteúdo não suportado",
+		"Not acceptable":         "Formato de resposta não aceitável",
+		"Unauthorized":           "Não autenticado",
+		"Forbidden":              "Não autorizado",
+		"Too many requests":      "Requisições demais",
+		"Upstream timeout":       "Tempo esgotado na dependência",
+		"Upstream circuit open":  "Circuito aberto para a dependência",
+		"Upstream error":         "Erro na dependência",
+		"Internal error":         "Erro interno",
+
+		// Detalhes
+		"invalid zipcode":                          "CEP inválido",
+		"invalid request body":                     "corpo da requisição inválido",
+		"invalid %s parameter":                     "parâmetro %s inválido",
+		"invalid fields parameter":                 "parâmetro fields inválido",
+		"invalid units parameter":                  "parâmetro units inválido",
+		"invalid request":                          "requisição inválida",
+		"unsupported content type":                 "tipo de conteúdo não suportado",
+		"no acceptable response format":            "nenhum formato de resposta aceitável",
+		"error encoding response":                  "erro ao codificar a resposta",
+		"error creating request to service B":      "erro ao criar a requisição ao serviço B",
+		"error contacting service B":               "erro ao contatar o serviço B",
+		"error reading response from service B":    "erro ao ler a resposta do serviço B",
+		"invalid response from service B":          "resposta inválida do serviço B",
+		"service B response too large":             "resposta do serviço B grande demais",
+		"service B timed out":                      "o serviço B não respondeu a tempo",
+		"service B circuit open":                   "circuito aberto para o serviço B",
+		"service B returned status %d":             "o serviço B respondeu com o status %d",
+		"streaming not supported":                  "streaming não suportado",
+		"response does not match the api contract": "a resposta não segue o contrato da API",
+		"missing credentials":                      "credenciais ausentes",
+		"invalid bearer token":                     "token bearer inválido",
+		"expired bearer token":                     "token bearer expirado",
+		"token does not allow the %s operation":    "o token não permite a operação %s",
+		"invalid API key":                          "chave de API inválida",
+		"rate limit exceeded":                      "limite de requisições excedido",
+		"daily quota exceeded":                     "cota diária excedida",
+	},
+	Spanish: {
+		// Títulos dos problemas
+		"Invalid zipcode":        "Código postal inválido",
+		"Zipcode not found":      "Código postal no encontrado",
+		"Invalid parameter":      "Parámetro inválido",
+		"Invalid request body":   "Cuerpo de la solicitud inválido",
+		"Unsupported media type": "Tipo de contenido no soportado",
+		"Not acceptable":         "Formato de respuesta no aceptable",
+		"Unauthorized":           "No autenticado",
+		"Forbidden":              "No autorizado",
+		"Too many requests":      "Demasiadas solicitudes",
+		"Upstream timeout":       "Tiempo agotado en la dependencia",
+		"Upstream circuit open":  "Circuito abierto para la dependencia",
+		"Upstream error":         "Error en la dependencia",
+		"Internal error":         "Error interno",
+
+		// Detalhes
+		"invalid zipcode":                          "código postal inválido",
+		"invalid request body":                     "cuerpo de la solicitud inválido",
+		"invalid %s parameter":                     "parámetro %s inválido",
+		"invalid fields parameter":                 "parámetro fields inválido",
+		"invalid units parameter":                  "parámetro units inválido",
+		"invalid request":                          "solicitud inválida",
+		"unsupported content type":                 "tipo de contenido no soportado",
+		"no acceptable response format":            "ningún formato de respuesta aceptable",
+		"error encoding response":                  "error al codificar la respuesta",
+		"error creating request to service B":      "error al crear la solicitud al servicio B",
+		"error contacting service B":               "error al contactar el servicio B",
+		"error reading response from service B":    "error al leer la respuesta del servicio B",
+		"invalid response from service B":          "respuesta inválida del servicio B",
+		"service B response too large":             "respuesta del servicio B demasiado grande",
+		"service B timed out":                      "el servicio B no respondió a tiempo",
+		"service B circuit open":                   "circuito abierto para el servicio B",
+		"service B returned status %d":             "el servicio B respondió con el estado %d",
+		"streaming not supported":                  "streaming no soportado",
+		"response does not match the api contract": "la respuesta no cumple el contrato de la API",
+		"missing credentials":                      "faltan las credenciales",
+		"invalid bearer token":                     "token bearer inválido",
+		"expired bearer token":                     "token bearer expirado",
+		"token does not allow the %s operation":    "el token no permite la operación %s",
+		"invalid API key":                          "clave de API inválida",
+		"rate limit exceeded":                      "límite de solicitudes excedido",
+		"daily quota exceeded":                     "cuota diaria excedida",
+	},
+}
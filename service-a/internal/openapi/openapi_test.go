@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"service-a/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValidator(t *testing.T, opts ...ValidatorOption) *Validator {
+	t.Helper()
+	doc, err := Load()
+	require.NoError(t, err)
+	validator, err := NewValidator(doc, opts...)
+	require.NoError(t, err)
+	return validator
+}
+
+// jsonHandler responde sempre com o mesmo status e corpo JSON
+func jsonHandler(status int, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	})
+}
+
+func TestSpecHandler(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	handler, err := NewSpecHandler(doc)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "3.0.3", body["openapi"])
+	assert.Contains(t, body["paths"], "/cep")
+}
+
+func TestMiddleware_InvalidParameter(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	handler := newTestValidator(t).Middleware(next)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cep?detail=everything", strings.NewReader(`{"cep":"01001000"}`)))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid detail parameter"), w.Body.String())
+}
+
+func TestMiddleware_RequestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		expected    string
+	}{
+		{"without content type", "", `{"cep":"01001000"}`, http.StatusOK, `{"city":"São Paulo"}`},
+		{"json with charset", "application/json; charset=utf-8", `{"cep":"01001000"}`, http.StatusOK, `{"city":"São Paulo"}`},
+		{"wrong type", "application/json", `{"cep":1001000}`, http.StatusBadRequest, problemBody(http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body")},
+		{"missing body", "application/json", ``, http.StatusBadRequest, problemBody(http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body")},
+		{"form", "application/x-www-form-urlencoded", `cep=01001000`, http.StatusUnsupportedMediaType, problemBody(http.StatusUnsupportedMediaType, common.CodeUnsupportedMediaType, "unsupported content type")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestValidator(t, WithResponseValidation()).Middleware(jsonHandler(http.StatusOK, `{"city":"São Paulo"}`))
+
+			req := httptest.NewRequest(http.MethodPost, "/cep", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestMiddleware_UndocumentedMethodPassesThrough(t *testing.T) {
+	handler := newTestValidator(t, WithResponseValidation()).Middleware(jsonHandler(http.StatusMethodNotAllowed, `{}`))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cep", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestMiddleware_ResponseDrift(t *testing.T) {
+	handler := newTestValidator(t, WithResponseValidation()).Middleware(jsonHandler(http.StatusUnprocessableEntity, `{"message":"invalid zipcode","code":422}`))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cep", strings.NewReader(`{"cep":"123"}`)))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusInternalServerError, common.CodeInternal, "response does not match the api contract"), w.Body.String())
+}
+
+// problemBody é o corpo esperado para o problema; sem span no contexto, não há trace_id
+func problemBody(status int, code, detail string) string {
+	body, _ := json.Marshal(common.NewProblem(status, code, detail))
+	return string(body)
+}
@@ -0,0 +1,223 @@
+// Package serviceb é o cliente tipado do clima por CEP do serviço B: monta a consulta, propaga
+// rastreamento e idioma, decodifica as respostas v1 e v2 e traduz os status de erro.
+package serviceb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"service-a/internal/i18n"
+	weatherv1 "service-a/internal/pb/weather/v1"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultMaxResponseBytes é o maior corpo de resposta aceito quando WithMaxResponseBytes não é usado
+const DefaultMaxResponseBytes = 1 << 20
+
+// FallbackHeader sinaliza que o clima é da capital do estado
+const FallbackHeader = "X-Weather-Fallback"
+
+// HTTPClient é uma interface para o cliente HTTP
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options são as opções de resposta do serviço B (parâmetros detail, fields, units e precision)
+type Options struct {
+	// Detail pede todos os campos opcionais (detail=full)
+	Detail bool
+	// Fields pede campos opcionais específicos
+	Fields []string
+	// Units são as escalas de temperatura; vazio usa o padrão do serviço B
+	Units []string
+	// Precision é o número de casas decimais; nil usa o padrão do serviço B
+	Precision *int
+}
+
+// query monta os parâmetros da consulta HTTP
+func (o Options) query() url.Values {
+	query := url.Values{}
+	if o.Detail {
+		query.Set("detail", "full")
+	}
+	if len(o.Fields) > 0 {
+		query.Set("fields", strings.Join(o.Fields, ","))
+	}
+	if len(o.Units) > 0 {
+		query.Set("units", strings.Join(o.Units, ","))
+	}
+	if o.Precision != nil {
+		query.Set("precision", strconv.Itoa(*o.Precision))
+	}
+	return query
+}
+
+// Client consulta o clima de um CEP no serviço B. Os erros de status são *StatusError; falhas
+// de comunicação são retornadas como vieram do transporte, para common.FromError.
+type Client interface {
+	// Weather busca a resposta v1 (GET /v1/cep/{cep} ou GetByCEP no gRPC)
+	Weather(ctx context.Context, cep string, opts Options) (*Weather, error)
+	// WeatherV2 busca a resposta v2 (GET /v2/cep/{cep}), que só existe no HTTP
+	WeatherV2(ctx context.Context, cep string, opts Options) (*WeatherV2, error)
+	// Forecast busca a previsão de days dias (GET /cep/{cep}/forecast), que só existe no HTTP;
+	// Detail e Fields não se aplicam
+	Forecast(ctx context.Context, cep string, days int, opts Options) (*Forecast, error)
+}
+
+type client struct {
+	baseURL          string
+	httpClient       HTTPClient
+	grpcClient       weatherv1.WeatherServiceClient
+	maxResponseBytes int64
+	hedging          *HedgePolicy
+}
+
+// ClientOption configura dependências opcionais do Client
+type ClientOption func(*client)
+
+// WithGRPCClient faz a resposta v1 ser buscada pelo WeatherService gRPC em vez do HTTP
+func WithGRPCClient(grpcClient weatherv1.WeatherServiceClient) ClientOption {
+	return func(c *client) {
+		c.grpcClient = grpcClient
+	}
+}
+
+// WithMaxResponseBytes limita o tamanho do corpo das respostas HTTP; corpos maiores resultam em
+// ErrResponseTooLarge
+func WithMaxResponseBytes(n int64) ClientOption {
+	return func(c *client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// NewClient cria o cliente do serviço B em baseURL
+func NewClient(baseURL string, httpClient HTTPClient, opts ...ClientOption) Client {
+	c := &client{baseURL: baseURL, httpClient: httpClient, maxResponseBytes: DefaultMaxResponseBytes}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.hedging != nil {
+		c.httpClient = newHedgedClient(c.httpClient, *c.hedging)
+	}
+	return c
+}
+
+// Weather busca a resposta v1 do CEP
+func (c *client) Weather(ctx context.Context, cep string, opts Options) (*Weather, error) {
+	if c.grpcClient != nil {
+		return c.weatherGRPC(ctx, cep, opts)
+	}
+
+	var weather Weather
+	header, err := c.get(ctx, "/v1/cep/"+url.PathEscape(cep), opts.query(), &weather)
+	if err != nil {
+		return nil, err
+	}
+	weather.Fallback = header.Get(FallbackHeader)
+	return &weather, nil
+}
+
+// WeatherV2 busca a resposta v2 do CEP
+func (c *client) WeatherV2(ctx context.Context, cep string, opts Options) (*WeatherV2, error) {
+	var weather WeatherV2
+	if _, err := c.get(ctx, "/v2/cep/"+url.PathEscape(cep), opts.query(), &weather); err != nil {
+		return nil, err
+	}
+	return &weather, nil
+}
+
+// Forecast busca a previsão diária do CEP
+func (c *client) Forecast(ctx context.Context, cep string, days int, opts Options) (*Forecast, error) {
+	query := opts.query()
+	query.Del("detail")
+	query.Del("fields")
+	query.Set("days", strconv.Itoa(days))
+
+	var forecast Forecast
+	header, err := c.get(ctx, "/cep/"+url.PathEscape(cep)+"/forecast", query, &forecast)
+	if err != nil {
+		return nil, err
+	}
+	forecast.Fallback = header.Get(FallbackHeader)
+	return &forecast, nil
+}
+
+// get faz o GET no serviço B, propagando rastreamento e idioma, e decodifica a resposta de
+// sucesso em out
+func (c *client) get(ctx context.Context, path string, query url.Values, out interface{}) (http.Header, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "service-b-request")
+	defer span.End()
+	span.SetAttributes(attribute.String("path", path))
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		log.Printf("ServiceBClient: Error creating request to service B: %v", err)
+		span.SetStatus(codes.Error, "Error creating request to service B")
+		return nil, fmt.Errorf("creating request to service B: %w", err)
+	}
+	// Propagar o contexto de rastreamento na requisição HTTP
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	// O serviço B responde erros e condições no mesmo idioma negociado aqui
+	i18n.SetHeader(ctx, req.Header)
+	// O formato final é gerado aqui, a partir do JSON
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Printf("ServiceBClient: Error contacting service B: %v", err)
+		span.SetStatus(codes.Error, "Error contacting service B")
+		return nil, err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("status", resp.StatusCode))
+
+	body, err := c.readBody(resp)
+	if err != nil {
+		log.Printf("ServiceBClient: Error reading response from service B: %v", err)
+		span.SetStatus(codes.Error, "Error reading response from service B")
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("ServiceBClient: Service B returned error: %s", body)
+		span.SetStatus(codes.Error, "Service B returned error")
+		return nil, newStatusError(resp.StatusCode, body)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		log.Printf("ServiceBClient: Error decoding response from service B: %v", err)
+		span.SetStatus(codes.Error, "Error decoding response from service B")
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return resp.Header, nil
+}
+
+// readBody lê o corpo até maxResponseBytes; um byte a mais indica que o limite foi excedido
+func (c *client) readBody(resp *http.Response) ([]byte, error) {
+	if resp.ContentLength > c.maxResponseBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, resp.ContentLength)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response from service B: %w", err)
+	}
+	if int64(len(body)) > c.maxResponseBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, c.maxResponseBytes)
+	}
+	return body, nil
+}
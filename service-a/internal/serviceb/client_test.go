@@ -0,0 +1,317 @@
+package serviceb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"service-a/internal/common"
+	"service-a/internal/i18n"
+	weatherv1 "service-a/internal/pb/weather/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const baseURL = "http://service-b:8090"
+
+type MockHTTPClient struct {
+	mock.Mock
+}
+
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	args := m.Called(req)
+	resp, _ := args.Get(0).(*http.Response)
+	return resp, args.Error(1)
+}
+
+type MockWeatherServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockWeatherServiceClient) GetByCEP(ctx context.Context, in *weatherv1.GetByCEPRequest, opts ...grpc.CallOption) (*weatherv1.GetByCEPResponse, error) {
+	args := m.Called(ctx, in)
+	resp, _ := args.Get(0).(*weatherv1.GetByCEPResponse)
+	return resp, args.Error(1)
+}
+
+func (m *MockWeatherServiceClient) BatchGetByCEP(ctx context.Context, in *weatherv1.BatchGetByCEPRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[weatherv1.CEPResult], error) {
+	args := m.Called(ctx, in)
+	return nil, args.Error(1)
+}
+
+func (m *MockWeatherServiceClient) StreamGetByCEP(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[weatherv1.GetByCEPRequest, weatherv1.CEPResult], error) {
+	args := m.Called(ctx)
+	return nil, args.Error(1)
+}
+
+func response(statusCode int, body string) *http.Response {
+	return &http.Response{StatusCode: statusCode, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(body))}
+}
+
+func TestClient_Weather(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	client := NewClient(baseURL, httpClient)
+
+	precision := 1
+	resp := response(http.StatusOK, `{"city":"Vitória","temp_C":30.1,"temp_F":86.2,"feelslike_C":33,"humidity":70,"observed_at":"2025-01-20T15:30:00Z","heat_risk":"caution"}`)
+	resp.Header.Set(FallbackHeader, "state-capital")
+	httpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == baseURL+"/v1/cep/29902555?fields=humidity%2Ccomfort&precision=1&units=C%2CF" &&
+			req.Header.Get("Accept") == "application/json" &&
+			req.Header.Get("Accept-Language") == "pt-BR"
+	})).Return(resp, nil)
+
+	ctx := i18n.WithLanguage(context.Background(), i18n.BrazilianPortuguese)
+	weather, err := client.Weather(ctx, "29902555", Options{Fields: []string{"humidity", "comfort"}, Units: []string{"C", "F"}, Precision: &precision})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Vitória", weather.City)
+	assert.Equal(t, map[string]float64{"C": 30.1, "F": 86.2}, weather.Temperatures)
+	assert.Equal(t, map[string]float64{"C": 33}, weather.FeelsLike)
+	assert.Equal(t, 70, *weather.Humidity)
+	assert.Equal(t, time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC), *weather.ObservedAt)
+	assert.Equal(t, "caution", *weather.HeatRisk)
+	assert.Nil(t, weather.WindKph)
+	assert.Equal(t, "state-capital", weather.Fallback)
+	httpClient.AssertExpectations(t)
+}
+
+func TestClient_WeatherV2(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	client := NewClient(baseURL, httpClient)
+
+	body := `{"cep":"01001000","location":{"city":"São Paulo","state":"SP","region":"Sudeste","lat":-23.55,"lon":-46.63},"temperatures":[{"unit":"C","value":28.5}],"details":{"humidity":70}}`
+	httpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == baseURL+"/v2/cep/01001000?detail=full"
+	})).Return(response(http.StatusOK, body), nil)
+
+	weather, err := client.WeatherV2(context.Background(), "01001000", Options{Detail: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SP", weather.Location.State)
+	assert.Equal(t, []TemperatureV2{{Unit: "C", Value: 28.5}}, weather.Temperatures)
+	assert.Equal(t, 70, *weather.Details.Humidity)
+	encoded, err := json.Marshal(weather)
+	require.NoError(t, err)
+	assert.JSONEq(t, body, string(encoded))
+}
+
+func TestClient_Forecast(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	client := NewClient(baseURL, httpClient)
+
+	resp := response(http.StatusOK, `{"city":"São Paulo","forecast":[{"date":"2025-01-21","max_temp_C":31,"max_temp_F":87.8,"min_temp_C":20,"avg_temp_C":25.5,"chance_of_rain":80,"total_precip_mm":12.4,"avg_humidity":75,"condition":"Chuva moderada","condition_code":1189}]}`)
+	resp.Header.Set(FallbackHeader, "state-capital")
+	httpClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == baseURL+"/cep/01001000/forecast?days=3&units=C%2CF"
+	})).Return(resp, nil)
+
+	forecast, err := client.Forecast(context.Background(), "01001000", 3, Options{Detail: true, Units: []string{"C", "F"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "São Paulo", forecast.City)
+	assert.Equal(t, "state-capital", forecast.Fallback)
+	assert.Equal(t, []ForecastDay{{
+		Date:          "2025-01-21",
+		Max:           map[string]float64{"C": 31, "F": 87.8},
+		Min:           map[string]float64{"C": 20},
+		Avg:           map[string]float64{"C": 25.5},
+		ChanceOfRain:  80,
+		TotalPrecipMm: 12.4,
+		AvgHumidity:   75,
+		Condition:     "Chuva moderada",
+		ConditionCode: 1189,
+	}}, forecast.Days)
+	httpClient.AssertExpectations(t)
+}
+
+func TestClient_EscapesCEP(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(response(http.StatusUnprocessableEntity, `{"error":"invalid zipcode"}`), nil)
+
+	_, err := NewClient(baseURL, httpClient).Weather(context.Background(), "../history", Options{})
+
+	assert.ErrorIs(t, err, ErrInvalidZipcode)
+	sent := httpClient.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, "/v1/cep/..%2Fhistory", sent.URL.EscapedPath())
+}
+
+func TestClient_StatusErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       error
+		wantCode   string
+	}{
+		{"invalid zipcode", http.StatusUnprocessableEntity, `{"type":"/problems/invalid-zipcode","title":"Invalid zipcode","status":422,"detail":"invalid zipcode","code":"INVALID_ZIPCODE"}`, ErrInvalidZipcode, common.CodeInvalidZipcode},
+		{"not found", http.StatusNotFound, `{"error":"can not find zipcode"}`, ErrNotFound, common.CodeZipcodeNotFound},
+		{"invalid request", http.StatusBadRequest, `{"error":"invalid units parameter"}`, ErrInvalidRequest, common.CodeInvalidParameter},
+		{"upstream", http.StatusInternalServerError, `{"error":"error fetching temperature"}`, ErrUpstream, common.CodeUpstreamError},
+		{"unexpected status", http.StatusTeapot, `short and stout`, ErrUpstream, common.CodeUpstreamError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := new(MockHTTPClient)
+			httpClient.On("Do", mock.Anything).Return(response(tt.statusCode, tt.body), nil)
+
+			_, err := NewClient(baseURL, httpClient).Weather(context.Background(), "01001000", Options{})
+
+			assert.ErrorIs(t, err, tt.want)
+			var statusErr *StatusError
+			require.ErrorAs(t, err, &statusErr)
+			assert.Equal(t, tt.statusCode, statusErr.StatusCode)
+			var problem *common.Problem
+			require.ErrorAs(t, err, &problem)
+			assert.Equal(t, tt.wantCode, problem.Code)
+		})
+	}
+}
+
+func TestClient_ResponseTooLarge(t *testing.T) {
+	body := `{"city":"São Paulo","temp_C":28.5}`
+
+	t.Run("declared length", func(t *testing.T) {
+		httpClient := new(MockHTTPClient)
+		resp := response(http.StatusOK, body)
+		resp.ContentLength = int64(len(body))
+		httpClient.On("Do", mock.Anything).Return(resp, nil)
+
+		_, err := NewClient(baseURL, httpClient, WithMaxResponseBytes(16)).Weather(context.Background(), "01001000", Options{})
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+
+	t.Run("unknown length", func(t *testing.T) {
+		httpClient := new(MockHTTPClient)
+		resp := response(http.StatusOK, body)
+		resp.ContentLength = -1
+		httpClient.On("Do", mock.Anything).Return(resp, nil)
+
+		_, err := NewClient(baseURL, httpClient, WithMaxResponseBytes(16)).Weather(context.Background(), "01001000", Options{})
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		httpClient := new(MockHTTPClient)
+		httpClient.On("Do", mock.Anything).Return(response(http.StatusOK, body), nil)
+
+		_, err := NewClient(baseURL, httpClient, WithMaxResponseBytes(int64(len(body)))).Weather(context.Background(), "01001000", Options{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestClient_InvalidResponse(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(response(http.StatusOK, `<response><city>São Paulo</city></response>`), nil)
+
+	_, err := NewClient(baseURL, httpClient).Weather(context.Background(), "01001000", Options{})
+
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+}
+
+func TestClient_TransportError(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(nil, context.DeadlineExceeded)
+
+	_, err := NewClient(baseURL, httpClient).WeatherV2(context.Background(), "01001000", Options{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_WeatherGRPC(t *testing.T) {
+	grpcClient := new(MockWeatherServiceClient)
+	client := NewClient(baseURL, new(MockHTTPClient), WithGRPCClient(grpcClient))
+
+	humidity := int32(70)
+	heatRisk := "caution"
+	observedAt := time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)
+	grpcClient.On("GetByCEP", mock.Anything, mock.MatchedBy(func(req *weatherv1.GetByCEPRequest) bool {
+		return req.Cep == "29902555" && req.Options.Detail && req.Options.GetPrecision() == 2
+	})).Return(&weatherv1.GetByCEPResponse{
+		City:         "Vitória",
+		Temperatures: []*weatherv1.Temperature{{Unit: "C", Value: 30}, {Unit: "F", Value: 86}},
+		Observation: &weatherv1.Observation{
+			FeelsLike:  []*weatherv1.Temperature{{Unit: "C", Value: 33}},
+			Humidity:   &humidity,
+			ObservedAt: timestamppb.New(observedAt),
+			HeatRisk:   &heatRisk,
+		},
+		Fallback: "state-capital",
+	}, nil)
+
+	precision := 2
+	weather, err := client.Weather(context.Background(), "29902555", Options{Detail: true, Precision: &precision})
+
+	require.NoError(t, err)
+	encoded, err := json.Marshal(weather)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"city":"Vitória","temp_C":30,"temp_F":86,"feelslike_C":33,"humidity":70,"observed_at":"2025-01-20T15:30:00Z","heat_risk":"caution"}`, string(encoded))
+	assert.Equal(t, "state-capital", weather.Fallback)
+}
+
+func TestClient_WeatherGRPCErrors(t *testing.T) {
+	badRequest := func(field, message string) error {
+		st, _ := status.New(grpccodes.InvalidArgument, message).WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: message}},
+		})
+		return st.Err()
+	}
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"invalid zipcode", badRequest("cep", "invalid zipcode"), ErrInvalidZipcode},
+		{"invalid options", badRequest("options", "invalid units parameter"), ErrInvalidRequest},
+		{"not found", status.Error(grpccodes.NotFound, "can not find zipcode"), ErrNotFound},
+		{"internal", status.Error(grpccodes.Internal, "error fetching temperature"), ErrUpstream},
+		{"deadline", status.Error(grpccodes.DeadlineExceeded, "context deadline exceeded"), context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcClient := new(MockWeatherServiceClient)
+			grpcClient.On("GetByCEP", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			_, err := NewClient(baseURL, new(MockHTTPClient), WithGRPCClient(grpcClient)).Weather(context.Background(), "01001000", Options{})
+
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("unavailable", func(t *testing.T) {
+		grpcClient := new(MockWeatherServiceClient)
+		unavailable := status.Error(grpccodes.Unavailable, "connection refused")
+		grpcClient.On("GetByCEP", mock.Anything, mock.Anything).Return(nil, unavailable)
+
+		_, err := NewClient(baseURL, new(MockHTTPClient), WithGRPCClient(grpcClient)).Weather(context.Background(), "01001000", Options{})
+
+		assert.Equal(t, unavailable, err)
+		assert.False(t, errors.Is(err, ErrUpstream))
+	})
+}
+
+func TestWeather_JSONRoundTrip(t *testing.T) {
+	body := `{"city":"São Paulo","temp_C":28.5,"temp_Re":22.8,"feelslike_F":88.1,"wind_kph":12.5,"wind_dir":"SE","condition_code":1000,"dew_point_C":18.2}`
+
+	var weather Weather
+	require.NoError(t, json.Unmarshal([]byte(body), &weather))
+	assert.Equal(t, map[string]float64{"C": 28.5, "Re": 22.8}, weather.Temperatures)
+	assert.Equal(t, map[string]float64{"F": 88.1}, weather.FeelsLike)
+	assert.Equal(t, 1000, *weather.ConditionCode)
+
+	encoded, err := json.Marshal(weather)
+	require.NoError(t, err)
+	assert.JSONEq(t, body, string(encoded))
+}
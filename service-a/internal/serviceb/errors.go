@@ -0,0 +1,59 @@
+package serviceb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"service-a/internal/common"
+)
+
+// Erros retornados pelo Client; use errors.Is para identificá-los
+var (
+	// ErrInvalidZipcode indica que o serviço B recusou o CEP (422)
+	ErrInvalidZipcode = errors.New("invalid zipcode")
+	// ErrNotFound indica que o CEP não foi encontrado (404)
+	ErrNotFound = errors.New("zipcode not found")
+	// ErrInvalidRequest indica que o serviço B recusou as opções da consulta (400)
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrUpstream indica uma falha do serviço B ou um status inesperado
+	ErrUpstream = errors.New("service B error")
+	// ErrResponseTooLarge indica que a resposta passou do limite de WithMaxResponseBytes
+	ErrResponseTooLarge = errors.New("service B response too large")
+	// ErrInvalidResponse indica uma resposta de sucesso que não pôde ser decodificada
+	ErrInvalidResponse = errors.New("invalid response from service B")
+)
+
+// StatusError é uma resposta de erro do serviço B, com o problema RFC 7807 equivalente. Além
+// do Problem, errors.Is reconhece o erro do status (ErrInvalidZipcode, ErrNotFound, ...).
+type StatusError struct {
+	StatusCode int
+	Problem    *common.Problem
+}
+
+// newStatusError traduz o status e o corpo de erro do serviço B
+func newStatusError(statusCode int, body []byte) *StatusError {
+	return &StatusError{StatusCode: statusCode, Problem: common.FromServiceB(statusCode, body)}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("service B returned status %d: %s", e.StatusCode, e.Problem.Detail)
+}
+
+// Unwrap expõe o erro do status e o Problem, usado por common.FromError
+func (e *StatusError) Unwrap() []error {
+	return []error{statusErr(e.StatusCode), e.Problem}
+}
+
+// statusErr é o erro correspondente ao status HTTP de erro do serviço B
+func statusErr(statusCode int) error {
+	switch statusCode {
+	case http.StatusUnprocessableEntity:
+		return ErrInvalidZipcode
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusBadRequest:
+		return ErrInvalidRequest
+	default:
+		return ErrUpstream
+	}
+}
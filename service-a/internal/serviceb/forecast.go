@@ -0,0 +1,73 @@
+package serviceb
+
+import "encoding/json"
+
+// Prefixos das temperaturas por unidade em cada dia da previsão (ex.: max_temp_C, avg_temp_F)
+const (
+	maxTemperaturePrefix = "max_temp_"
+	minTemperaturePrefix = "min_temp_"
+	avgTemperaturePrefix = "avg_temp_"
+)
+
+// Forecast é a resposta do GET /cep/{cep}/forecast
+type Forecast struct {
+	City string        `json:"city"`
+	Days []ForecastDay `json:"forecast"`
+	// Fallback é state-capital quando a previsão é da capital do estado (cabeçalho X-Weather-Fallback)
+	Fallback string `json:"-"`
+}
+
+// ForecastDay é a previsão de um dia. No JSON, as temperaturas ficam em um campo por unidade
+// (max_temp_C, min_temp_F, ...).
+type ForecastDay struct {
+	Date string
+	// Max, Min e Avg são indexados pelo símbolo da unidade (C, F, K, R, Re)
+	Max           map[string]float64
+	Min           map[string]float64
+	Avg           map[string]float64
+	ChanceOfRain  int
+	TotalPrecipMm float64
+	AvgHumidity   int
+	Condition     string
+	ConditionCode int
+}
+
+// forecastDayFields são os campos do dia com nome fixo
+type forecastDayFields struct {
+	Date          string  `json:"date"`
+	ChanceOfRain  int     `json:"chance_of_rain"`
+	TotalPrecipMm float64 `json:"total_precip_mm"`
+	AvgHumidity   int     `json:"avg_humidity"`
+	Condition     string  `json:"condition"`
+	ConditionCode int     `json:"condition_code"`
+}
+
+// UnmarshalJSON lê um dia da previsão do serviço B
+func (d *ForecastDay) UnmarshalJSON(data []byte) error {
+	var fields forecastDayFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*d = ForecastDay{
+		Date:          fields.Date,
+		ChanceOfRain:  fields.ChanceOfRain,
+		TotalPrecipMm: fields.TotalPrecipMm,
+		AvgHumidity:   fields.AvgHumidity,
+		Condition:     fields.Condition,
+		ConditionCode: fields.ConditionCode,
+	}
+	var err error
+	if d.Max, err = unitValues(raw, maxTemperaturePrefix); err != nil {
+		return err
+	}
+	if d.Min, err = unitValues(raw, minTemperaturePrefix); err != nil {
+		return err
+	}
+	d.Avg, err = unitValues(raw, avgTemperaturePrefix)
+	return err
+}
@@ -0,0 +1,131 @@
+package serviceb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"service-a/internal/common"
+	"service-a/internal/i18n"
+	weatherv1 "service-a/internal/pb/weather/v1"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fieldViolationCEP é o campo do BadRequest que o serviço B usa para CEP inválido
+const fieldViolationCEP = "cep"
+
+// weatherGRPC busca a resposta v1 pelo GetByCEP, com os mesmos erros do transporte HTTP
+func (c *client) weatherGRPC(ctx context.Context, cep string, opts Options) (*Weather, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "service-b-grpc-request")
+	defer span.End()
+	span.SetAttributes(attribute.String("cep", cep))
+
+	resp, err := c.grpcClient.GetByCEP(i18n.AppendToOutgoingContext(ctx), &weatherv1.GetByCEPRequest{Cep: cep, Options: opts.grpc()})
+	if err != nil {
+		log.Printf("ServiceBClient: Service B returned error over gRPC: %v", err)
+		span.SetStatus(codes.Error, "Service B returned error")
+		return nil, grpcError(err)
+	}
+	return weatherFromGRPC(resp), nil
+}
+
+// grpc converte as opções nas ResponseOptions do WeatherService
+func (o Options) grpc() *weatherv1.ResponseOptions {
+	opts := &weatherv1.ResponseOptions{Detail: o.Detail, Fields: o.Fields, Units: o.Units}
+	if o.Precision != nil {
+		p := int32(*o.Precision)
+		opts.Precision = &p
+	}
+	return opts
+}
+
+// grpcError mapeia o status gRPC para o erro equivalente do transporte HTTP
+func grpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case grpccodes.InvalidArgument:
+		for _, detail := range st.Details() {
+			br, ok := detail.(*errdetails.BadRequest)
+			if !ok {
+				continue
+			}
+			for _, violation := range br.GetFieldViolations() {
+				if violation.GetField() == fieldViolationCEP {
+					return &StatusError{StatusCode: http.StatusUnprocessableEntity, Problem: common.NewProblem(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, st.Message())}
+				}
+			}
+		}
+		return &StatusError{StatusCode: http.StatusBadRequest, Problem: common.NewProblem(http.StatusBadRequest, common.CodeInvalidParameter, st.Message())}
+	case grpccodes.NotFound:
+		return &StatusError{StatusCode: http.StatusNotFound, Problem: common.NewProblem(http.StatusNotFound, common.CodeZipcodeNotFound, st.Message())}
+	case grpccodes.Internal:
+		return &StatusError{StatusCode: http.StatusInternalServerError, Problem: common.NewProblem(http.StatusInternalServerError, common.CodeUpstreamError, st.Message())}
+	case grpccodes.DeadlineExceeded:
+		return fmt.Errorf("service B timed out: %w", context.DeadlineExceeded)
+	default:
+		// Unavailable etc.: o serviço B não respondeu
+		return err
+	}
+}
+
+// weatherFromGRPC converte a resposta gRPC na mesma resposta v1 do HTTP
+func weatherFromGRPC(resp *weatherv1.GetByCEPResponse) *Weather {
+	weather := &Weather{
+		City:         resp.GetCity(),
+		Fallback:     resp.GetFallback(),
+		Temperatures: grpcUnitValues(resp.GetTemperatures()),
+	}
+	o := resp.GetObservation()
+	if o == nil {
+		return weather
+	}
+	weather.FeelsLike = grpcUnitValues(o.GetFeelsLike())
+	weather.Humidity = intPtr(o.Humidity)
+	weather.WindKph = o.WindKph
+	weather.WindDegree = intPtr(o.WindDegree)
+	weather.WindDir = o.WindDir
+	weather.PressureMb = o.PressureMb
+	weather.UV = o.Uv
+	weather.Condition = o.Condition
+	weather.ConditionCode = intPtr(o.ConditionCode)
+	if o.ObservedAt != nil {
+		observedAt := o.ObservedAt.AsTime()
+		weather.ObservedAt = &observedAt
+	}
+	weather.HeatIndexC = o.HeatIndexC
+	weather.WindChillC = o.WindChillC
+	weather.DewPointC = o.DewPointC
+	weather.Humidex = o.Humidex
+	weather.HeatRisk = o.HeatRisk
+	return weather
+}
+
+func grpcUnitValues(temps []*weatherv1.Temperature) map[string]float64 {
+	if len(temps) == 0 {
+		return nil
+	}
+	values := make(map[string]float64, len(temps))
+	for _, t := range temps {
+		values[t.GetUnit()] = t.GetValue()
+	}
+	return values
+}
+
+func intPtr(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+	v := int(*value)
+	return &v
+}
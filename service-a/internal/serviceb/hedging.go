@@ -0,0 +1,249 @@
+package serviceb
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"service-a/internal/balancer"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// latencyWindow é quantas latências recentes entram no cálculo do percentil
+	latencyWindow = 256
+	// minLatencySamples é quantas latências são necessárias antes de usar o percentil
+	minLatencySamples = 20
+	// maxHedgeTokens limita a rajada de pedidos duplicados acumulada pelo orçamento
+	maxHedgeTokens = 10
+)
+
+// HedgePolicy configura os pedidos duplicados (hedging) ao serviço B
+type HedgePolicy struct {
+	// Delay é o atraso até o pedido duplicado enquanto não há latências suficientes e, com
+	// Percentile, o menor atraso usado
+	Delay time.Duration
+	// Percentile é o percentil das latências observadas usado como atraso (ex.: 0.95); zero
+	// usa sempre Delay
+	Percentile float64
+	// Budget é a fração das requisições que pode receber um pedido duplicado (ex.: 0.1)
+	Budget float64
+}
+
+// WithHedging faz as requisições HTTP que demoram mais que o atraso da política serem repetidas,
+// em paralelo, em outra instância; vale a primeira resposta e a outra é cancelada. A outra
+// instância é escolhida pelo balancer.Balancer do HTTPClient: sem ele, ou sem outra instância
+// disponível, a requisição não é repetida. As chamadas gRPC não são repetidas.
+func WithHedging(policy HedgePolicy) ClientOption {
+	return func(c *client) {
+		c.hedging = &policy
+	}
+}
+
+// hedgedClient é um HTTPClient que duplica os GET lentos, dentro do orçamento
+type hedgedClient struct {
+	next   HTTPClient
+	policy HedgePolicy
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	nextIndex int
+	tokens    float64
+}
+
+// newHedgedClient cria o hedgedClient com o orçamento cheio
+func newHedgedClient(next HTTPClient, policy HedgePolicy) *hedgedClient {
+	return &hedgedClient{next: next, policy: policy, tokens: maxHedgeTokens}
+}
+
+// attempt é o resultado de uma das tentativas
+type attempt struct {
+	index   int
+	resp    *http.Response
+	err     error
+	latency time.Duration
+	cancel  context.CancelFunc
+	span    trace.Span
+}
+
+// failed indica uma falha de conexão ou uma resposta 5xx
+func (a attempt) failed() bool {
+	return a.err != nil || a.resp.StatusCode >= http.StatusInternalServerError
+}
+
+// Do envia a requisição e, se não houver resposta em delay, um pedido duplicado
+func (h *hedgedClient) Do(req *http.Request) (*http.Response, error) {
+	// Só requisições idempotentes e sem corpo podem ser repetidas
+	if req.Method != http.MethodGet || req.Body != nil && req.Body != http.NoBody {
+		return h.next.Do(req)
+	}
+	h.credit()
+
+	// As tentativas compartilham o registro das instâncias usadas, para que o pedido duplicado
+	// vá para outra
+	ctx, attempts := balancer.WithAttempts(req.Context())
+	span := trace.SpanFromContext(ctx)
+	results := make(chan attempt, 2)
+	cancels := make([]context.CancelFunc, 0, 2)
+	start := func(index int) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		attemptCtx, attemptSpan := otel.Tracer("service-a").Start(attemptCtx, "service-b-attempt")
+		attemptSpan.SetAttributes(attribute.Int("hedge_attempt", index), attribute.Bool("hedged", index > 0))
+		go func() {
+			started := time.Now()
+			resp, err := h.next.Do(req.Clone(attemptCtx))
+			results <- attempt{index: index, resp: resp, err: err, latency: time.Since(started), cancel: cancel, span: attemptSpan}
+		}()
+	}
+
+	start(0)
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+
+	pending, hedged := 1, false
+	for {
+		select {
+		case <-timer.C:
+			if !attempts.HasAlternative() {
+				span.SetAttributes(attribute.Bool("hedge_no_alternate_endpoint", true))
+				continue
+			}
+			if !h.spend() {
+				span.SetAttributes(attribute.Bool("hedge_budget_exhausted", true))
+				continue
+			}
+			hedged = true
+			pending++
+			span.SetAttributes(attribute.Bool("hedged", true))
+			log.Printf("ServiceBClient: Hedging request to %s", req.URL.Path)
+			start(1)
+		case result := <-results:
+			pending--
+			if result.failed() && pending > 0 {
+				// Com a outra tentativa em andamento, uma falha não encerra a requisição
+				endAttempt(result, "superseded")
+				continue
+			}
+			if pending > 0 {
+				// A outra tentativa perdeu: é cancelada já e o corpo, se chegar, é descartado
+				for i, cancel := range cancels {
+					if i != result.index {
+						cancel()
+					}
+				}
+				go drain(results, pending)
+			}
+			if hedged {
+				span.SetAttributes(attribute.Int("hedge_winner", result.index))
+			}
+			return h.finish(result)
+		}
+	}
+}
+
+// finish devolve o resultado vencedor, mantendo o contexto dele vivo até o corpo ser fechado
+func (h *hedgedClient) finish(result attempt) (*http.Response, error) {
+	if result.err != nil {
+		result.span.SetStatus(codes.Error, result.err.Error())
+		result.span.End()
+		result.cancel()
+		return nil, result.err
+	}
+	if !result.failed() {
+		h.observe(result.latency)
+	}
+	result.span.SetAttributes(attribute.Int("status", result.resp.StatusCode))
+	result.span.End()
+	result.resp.Body = &cancelBody{ReadCloser: result.resp.Body, cancel: result.cancel}
+	return result.resp, nil
+}
+
+// drain cancela e descarta as tentativas perdedoras
+func drain(results <-chan attempt, pending int) {
+	for ; pending > 0; pending-- {
+		result := <-results
+		endAttempt(result, "cancelled")
+	}
+}
+
+// endAttempt encerra a tentativa descartada
+func endAttempt(result attempt, outcome string) {
+	result.cancel()
+	if result.resp != nil {
+		result.resp.Body.Close()
+	}
+	result.span.SetAttributes(attribute.String("hedge_outcome", outcome))
+	result.span.End()
+}
+
+// delay é o atraso até o pedido duplicado: o percentil das latências recentes, com Delay como mínimo
+func (h *hedgedClient) delay() time.Duration {
+	if h.policy.Percentile <= 0 {
+		return h.policy.Delay
+	}
+
+	h.mu.Lock()
+	if len(h.latencies) < minLatencySamples {
+		h.mu.Unlock()
+		return h.policy.Delay
+	}
+	sorted := append([]time.Duration(nil), h.latencies...)
+	h.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	observed := sorted[int(h.policy.Percentile*float64(len(sorted)-1))]
+	return max(observed, h.policy.Delay)
+}
+
+// observe guarda a latência de uma resposta de sucesso
+func (h *hedgedClient) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < latencyWindow {
+		h.latencies = append(h.latencies, latency)
+	} else {
+		h.latencies[h.nextIndex] = latency
+		h.nextIndex = (h.nextIndex + 1) % latencyWindow
+	}
+}
+
+// spend consome o orçamento de um pedido duplicado. Cada requisição credita Budget; um pedido
+// duplicado custa 1, então no longo prazo no máximo essa fração das requisições é duplicada.
+func (h *hedgedClient) spend() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// credit soma ao orçamento a parte de uma requisição
+func (h *hedgedClient) credit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = min(h.tokens+h.policy.Budget, maxHedgeTokens)
+}
+
+// cancelBody cancela o contexto da tentativa vencedora ao ser fechado
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
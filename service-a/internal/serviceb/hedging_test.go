@@ -0,0 +1,168 @@
+package serviceb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"service-a/internal/balancer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// balanced distribui as requisições entre as instâncias, como com SERVICE_B_ENDPOINTS
+func balanced(t *testing.T, next *MockHTTPClient, endpoints ...string) HTTPClient {
+	t.Helper()
+	lb := balancer.New(balancer.NewStaticResolver(endpoints), next)
+	require.NoError(t, lb.Refresh(context.Background()))
+	return lb
+}
+
+// hosts retorna a instância de cada chamada ao mock, na ordem
+func hosts(next *MockHTTPClient) []string {
+	var hosts []string
+	for _, call := range next.Calls {
+		hosts = append(hosts, call.Arguments.Get(0).(*http.Request).URL.Host)
+	}
+	return hosts
+}
+
+// waitCancel faz a tentativa só terminar quando o contexto dela for cancelado
+func waitCancel(args mock.Arguments) {
+	<-args.Get(0).(*http.Request).Context().Done()
+}
+
+func TestClient_HedgesSlowRequest(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	cancelled := make(chan struct{})
+	httpClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		waitCancel(args)
+		close(cancelled)
+	}).Return(nil, context.Canceled).Once()
+	httpClient.On("Do", mock.Anything).Return(response(http.StatusOK, `{"city":"São Paulo","temp_C":28.5}`), nil).Once()
+	client := NewClient(baseURL, balanced(t, httpClient, "http://10.0.0.1:8090", "http://10.0.0.2:8090"), WithHedging(HedgePolicy{Delay: 10 * time.Millisecond, Budget: 0.1}))
+
+	weather, err := client.Weather(context.Background(), "01001000", Options{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "São Paulo", weather.City)
+	// A tentativa original perdeu e foi cancelada
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("primary attempt was not cancelled")
+	}
+	httpClient.AssertNumberOfCalls(t, "Do", 2)
+	// O pedido duplicado foi para a outra instância
+	assert.Equal(t, []string{"10.0.0.1:8090", "10.0.0.2:8090"}, hosts(httpClient))
+}
+
+func TestHedgedClient_SingleEndpointIsNotHedged(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).WaitUntil(time.After(20*time.Millisecond)).Return(response(http.StatusOK, `{}`), nil)
+	hedged := newHedgedClient(balanced(t, httpClient, "http://10.0.0.1:8090"), HedgePolicy{Delay: time.Millisecond, Budget: 1})
+
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/v1/cep/01001000", nil)
+	resp, err := hedged.Do(req)
+
+	require.NoError(t, err)
+	resp.Body.Close()
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+	// O orçamento não é gasto quando não há outra instância
+	assert.InDelta(t, maxHedgeTokens, hedged.tokens, 1e-9)
+}
+
+func TestHedgedClient_WithoutBalancerIsNotHedged(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).WaitUntil(time.After(20*time.Millisecond)).Return(response(http.StatusOK, `{}`), nil)
+	hedged := newHedgedClient(httpClient, HedgePolicy{Delay: time.Millisecond, Budget: 1})
+
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/v1/cep/01001000", nil)
+	resp, err := hedged.Do(req)
+
+	require.NoError(t, err)
+	resp.Body.Close()
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestHedgedClient_FastResponseIsNotHedged(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).Return(response(http.StatusOK, `{}`), nil)
+	hedged := newHedgedClient(httpClient, HedgePolicy{Delay: time.Second, Budget: 0.1})
+
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/v1/cep/01001000", nil)
+	resp, err := hedged.Do(req)
+
+	require.NoError(t, err)
+	resp.Body.Close()
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestHedgedClient_FailureWaitsForHedge(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	release := make(chan struct{})
+	// A original demora e falha depois que o pedido duplicado já saiu; vale a resposta dele
+	httpClient.On("Do", mock.Anything).WaitUntil(time.After(20*time.Millisecond)).Return(response(http.StatusServiceUnavailable, `{}`), nil).Once()
+	httpClient.On("Do", mock.Anything).Run(func(mock.Arguments) { <-release }).Return(response(http.StatusOK, `{}`), nil).Once()
+	hedged := newHedgedClient(balanced(t, httpClient, "http://10.0.0.1:8090", "http://10.0.0.2:8090"), HedgePolicy{Delay: 5 * time.Millisecond, Budget: 0.1})
+
+	go func() {
+		time.Sleep(40 * time.Millisecond)
+		close(release)
+	}()
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/v1/cep/01001000", nil)
+	resp, err := hedged.Do(req)
+
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHedgedClient_BudgetExhausted(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).WaitUntil(time.After(20*time.Millisecond)).Return(response(http.StatusOK, `{}`), nil)
+	hedged := newHedgedClient(balanced(t, httpClient, "http://10.0.0.1:8090", "http://10.0.0.2:8090"), HedgePolicy{Delay: time.Millisecond, Budget: 0.1})
+	hedged.tokens = 0
+
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/v1/cep/01001000", nil)
+	resp, err := hedged.Do(req)
+
+	require.NoError(t, err)
+	resp.Body.Close()
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+	assert.InDelta(t, 0.1, hedged.tokens, 1e-9)
+}
+
+func TestHedgedClient_NonIdempotentIsNotHedged(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Do", mock.Anything).WaitUntil(time.After(20*time.Millisecond)).Return(response(http.StatusOK, `{}`), nil)
+	hedged := newHedgedClient(httpClient, HedgePolicy{Delay: time.Millisecond, Budget: 1})
+
+	req, _ := http.NewRequest(http.MethodPost, baseURL+"/v1/cep/01001000", nil)
+	resp, err := hedged.Do(req)
+
+	require.NoError(t, err)
+	resp.Body.Close()
+	httpClient.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestHedgedClient_DelayFollowsPercentile(t *testing.T) {
+	hedged := newHedgedClient(new(MockHTTPClient), HedgePolicy{Delay: 20 * time.Millisecond, Percentile: 0.95, Budget: 0.1})
+
+	// Sem latências suficientes, vale Delay
+	assert.Equal(t, 20*time.Millisecond, hedged.delay())
+
+	for i := 1; i <= 100; i++ {
+		hedged.observe(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 95*time.Millisecond, hedged.delay())
+
+	// A janela guarda só as latências recentes, e Delay é o menor atraso
+	for i := 0; i < latencyWindow; i++ {
+		hedged.observe(time.Millisecond)
+	}
+	assert.Equal(t, 20*time.Millisecond, hedged.delay())
+}
@@ -0,0 +1,213 @@
+package serviceb
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Prefixos das temperaturas por unidade na resposta v1 (ex.: temp_C, feelslike_F)
+const (
+	temperaturePrefix = "temp_"
+	feelsLikePrefix   = "feelslike_"
+)
+
+// Weather é a resposta v1 do GET /v1/cep/{cep}. No JSON, as temperaturas ficam em um campo por
+// unidade (temp_C, temp_F, ...) e os campos opcionais só aparecem quando pedidos em Options.
+type Weather struct {
+	City string
+	// Temperatures e FeelsLike são indexados pelo símbolo da unidade (C, F, K, R, Re)
+	Temperatures  map[string]float64
+	FeelsLike     map[string]float64
+	Humidity      *int
+	WindKph       *float64
+	WindDegree    *int
+	WindDir       *string
+	PressureMb    *float64
+	UV            *float64
+	Condition     *string
+	ConditionCode *int
+	ObservedAt    *time.Time
+	HeatIndexC    *float64
+	WindChillC    *float64
+	DewPointC     *float64
+	Humidex       *float64
+	HeatRisk      *string
+	// Fallback é state-capital quando o clima é da capital do estado (cabeçalho X-Weather-Fallback)
+	Fallback string
+}
+
+// weatherFields são os campos da resposta v1 com nome fixo
+type weatherFields struct {
+	City          string     `json:"city"`
+	Humidity      *int       `json:"humidity,omitempty"`
+	WindKph       *float64   `json:"wind_kph,omitempty"`
+	WindDegree    *int       `json:"wind_degree,omitempty"`
+	WindDir       *string    `json:"wind_dir,omitempty"`
+	PressureMb    *float64   `json:"pressure_mb,omitempty"`
+	UV            *float64   `json:"uv,omitempty"`
+	Condition     *string    `json:"condition,omitempty"`
+	ConditionCode *int       `json:"condition_code,omitempty"`
+	ObservedAt    *time.Time `json:"observed_at,omitempty"`
+	HeatIndexC    *float64   `json:"heat_index_C,omitempty"`
+	WindChillC    *float64   `json:"wind_chill_C,omitempty"`
+	DewPointC     *float64   `json:"dew_point_C,omitempty"`
+	Humidex       *float64   `json:"humidex,omitempty"`
+	HeatRisk      *string    `json:"heat_risk,omitempty"`
+}
+
+// UnmarshalJSON lê o JSON v1 do serviço B
+func (w *Weather) UnmarshalJSON(data []byte) error {
+	var fields weatherFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*w = Weather{
+		City:          fields.City,
+		Humidity:      fields.Humidity,
+		WindKph:       fields.WindKph,
+		WindDegree:    fields.WindDegree,
+		WindDir:       fields.WindDir,
+		PressureMb:    fields.PressureMb,
+		UV:            fields.UV,
+		Condition:     fields.Condition,
+		ConditionCode: fields.ConditionCode,
+		ObservedAt:    fields.ObservedAt,
+		HeatIndexC:    fields.HeatIndexC,
+		WindChillC:    fields.WindChillC,
+		DewPointC:     fields.DewPointC,
+		Humidex:       fields.Humidex,
+		HeatRisk:      fields.HeatRisk,
+	}
+	var err error
+	if w.Temperatures, err = unitValues(raw, temperaturePrefix); err != nil {
+		return err
+	}
+	w.FeelsLike, err = unitValues(raw, feelsLikePrefix)
+	return err
+}
+
+// MarshalJSON grava o mesmo JSON v1 do serviço B
+func (w Weather) MarshalJSON() ([]byte, error) {
+	fields := weatherFields{
+		City:          w.City,
+		Humidity:      w.Humidity,
+		WindKph:       w.WindKph,
+		WindDegree:    w.WindDegree,
+		WindDir:       w.WindDir,
+		PressureMb:    w.PressureMb,
+		UV:            w.UV,
+		Condition:     w.Condition,
+		ConditionCode: w.ConditionCode,
+		HeatIndexC:    w.HeatIndexC,
+		WindChillC:    w.WindChillC,
+		DewPointC:     w.DewPointC,
+		Humidex:       w.Humidex,
+		HeatRisk:      w.HeatRisk,
+	}
+	if w.ObservedAt != nil {
+		observedAt := w.ObservedAt.UTC()
+		fields.ObservedAt = &observedAt
+	}
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, err
+	}
+	for unit, value := range w.Temperatures {
+		object[temperaturePrefix+unit] = value
+	}
+	for unit, value := range w.FeelsLike {
+		object[feelsLikePrefix+unit] = value
+	}
+	return json.Marshal(object)
+}
+
+// unitValues lê os campos numéricos com o prefixo, indexados pelo símbolo da unidade
+func unitValues(raw map[string]json.RawMessage, prefix string) (map[string]float64, error) {
+	var values map[string]float64
+	for key, value := range raw {
+		unit, ok := strings.CutPrefix(key, prefix)
+		if !ok || unit == "" {
+			continue
+		}
+		var v float64
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, err
+		}
+		if values == nil {
+			values = make(map[string]float64)
+		}
+		values[unit] = v
+	}
+	return values, nil
+}
+
+// WeatherV2 é a resposta do GET /v2/cep/{cep}
+type WeatherV2 struct {
+	CEP          string           `json:"cep"`
+	Location     LocationV2       `json:"location"`
+	ObservedAt   *time.Time       `json:"observed_at,omitempty"`
+	Temperatures []TemperatureV2  `json:"temperatures"`
+	Details      *WeatherDetailV2 `json:"details,omitempty"`
+	// Fallback é state-capital quando o clima é da capital do estado
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// LocationV2 é a cidade consultada, com a UF resolvida pelo CEP e as coordenadas do provedor
+type LocationV2 struct {
+	City    string  `json:"city"`
+	State   string  `json:"state"`
+	Region  string  `json:"region"`
+	Country string  `json:"country,omitempty"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+// TemperatureV2 é uma temperatura na unidade indicada pelo símbolo (C, F, K, ...)
+type TemperatureV2 struct {
+	Unit  string  `json:"unit"`
+	Value float64 `json:"value"`
+}
+
+// WeatherDetailV2 reúne os campos opcionais pedidos em Options
+type WeatherDetailV2 struct {
+	FeelsLike  []TemperatureV2 `json:"feels_like,omitempty"`
+	Humidity   *int            `json:"humidity,omitempty"`
+	Wind       *WindV2         `json:"wind,omitempty"`
+	PressureMb *float64        `json:"pressure_mb,omitempty"`
+	UV         *float64        `json:"uv,omitempty"`
+	Condition  *ConditionV2    `json:"condition,omitempty"`
+	Comfort    *ComfortV2      `json:"comfort,omitempty"`
+}
+
+// WindV2 é o vento em km/h, com a direção em graus e em pontos cardeais
+type WindV2 struct {
+	SpeedKph  float64 `json:"speed_kph"`
+	Degree    int     `json:"degree"`
+	Direction string  `json:"direction"`
+}
+
+// ConditionV2 é a condição do tempo, com o texto no idioma da requisição
+type ConditionV2 struct {
+	Text string `json:"text"`
+	Code int    `json:"code"`
+}
+
+// ComfortV2 são os índices de conforto térmico em Celsius
+type ComfortV2 struct {
+	HeatIndexC float64  `json:"heat_index_C"`
+	WindChillC float64  `json:"wind_chill_C"`
+	HeatRisk   string   `json:"heat_risk"`
+	DewPointC  *float64 `json:"dew_point_C,omitempty"`
+	Humidex    *float64 `json:"humidex,omitempty"`
+}
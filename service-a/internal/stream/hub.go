@@ -0,0 +1,237 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"service-a/internal/common"
+	"service-a/internal/serviceb"
+	"sync"
+	"time"
+)
+
+// Tipos de evento publicados no stream
+const (
+	EventTemperature = "temperature"
+	EventError       = "error"
+)
+
+// subscriberBuffer é quantos eventos um assinante pode acumular, além do replay, antes de ser
+// desconectado por lentidão
+const subscriberBuffer = 16
+
+// Event é uma leitura publicada para os assinantes de um CEP. O ID cresce em todo o hub, então
+// continua único mesmo quando o poller de um CEP é encerrado e recriado.
+type Event struct {
+	ID   uint64
+	Type string
+	Data []byte
+}
+
+// Hub mantém um único poller por CEP, compartilhado por todos os assinantes. O poller consulta a
+// fonte a cada intervalo, publica apenas quando a leitura muda e é encerrado quando o último
+// assinante sai.
+type Hub struct {
+	source   Source
+	interval time.Duration
+	replay   int
+
+	mu      sync.Mutex
+	pollers map[string]*poller
+	lastID  uint64
+}
+
+type poller struct {
+	cep    string
+	subs   map[*Subscription]struct{}
+	recent []Event
+	cancel context.CancelFunc
+}
+
+// Subscription recebe os eventos de um CEP até ser fechada
+type Subscription struct {
+	hub    *Hub
+	poller *poller
+	events chan Event
+}
+
+// NewHub cria um Hub que consulta a fonte a cada interval e guarda os últimos replay eventos de
+// cada CEP para retomada por Last-Event-ID
+func NewHub(source Source, interval time.Duration, replay int) *Hub {
+	if replay < 1 {
+		replay = 1
+	}
+	return &Hub{
+		source:   source,
+		interval: interval,
+		replay:   replay,
+		pollers:  map[string]*poller{},
+	}
+}
+
+// Subscribe inscreve-se nos eventos do CEP, iniciando o poller se ainda não houver um. Com
+// lastEventID, os eventos posteriores ainda guardados são reenviados; se ele não for mais
+// conhecido, apenas a leitura mais recente é enviada.
+func (h *Hub) Subscribe(cep string, lastEventID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p, ok := h.pollers[cep]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		p = &poller{cep: cep, subs: map[*Subscription]struct{}{}, cancel: cancel}
+		h.pollers[cep] = p
+		log.Printf("StreamHub: Starting poller for CEP %s", cep)
+		go h.run(ctx, p)
+	}
+
+	sub := &Subscription{hub: h, poller: p, events: make(chan Event, h.replay+subscriberBuffer)}
+	p.subs[sub] = struct{}{}
+	for _, event := range p.replayFrom(lastEventID) {
+		sub.events <- event
+	}
+	return sub
+}
+
+// Active retorna quantos CEPs estão sendo consultados
+func (h *Hub) Active() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.pollers)
+}
+
+// Events retorna o canal de eventos, fechado quando a inscrição termina (pelo assinante, por
+// lentidão ou por um erro definitivo do CEP)
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close encerra a inscrição; o poller é encerrado junto com o último assinante
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.removeLocked(s)
+}
+
+func (h *Hub) removeLocked(sub *Subscription) {
+	p := sub.poller
+	if _, ok := p.subs[sub]; !ok {
+		return
+	}
+	delete(p.subs, sub)
+	close(sub.events)
+	if len(p.subs) == 0 {
+		h.stopLocked(p)
+	}
+}
+
+func (h *Hub) stopLocked(p *poller) {
+	if h.pollers[p.cep] != p {
+		return
+	}
+	delete(h.pollers, p.cep)
+	p.cancel()
+	log.Printf("StreamHub: Stopped poller for CEP %s", p.cep)
+}
+
+// run consulta a fonte imediatamente e depois a cada intervalo, até o poller ser encerrado
+func (h *Hub) run(ctx context.Context, p *poller) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.poll(ctx, p)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Hub) poll(ctx context.Context, p *poller) {
+	pollCtx, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+
+	body, err := h.source.Fetch(pollCtx, p.cep)
+	if ctx.Err() != nil {
+		return
+	}
+
+	event := Event{Type: EventTemperature, Data: bytes.TrimSpace(body)}
+	if err != nil {
+		var statusErr *serviceb.StatusError
+		if !errors.As(err, &statusErr) {
+			log.Printf("StreamHub: Error polling CEP %s: %v", p.cep, err)
+		}
+		event = errorEvent(common.FromError(err, common.NewProblem(http.StatusInternalServerError, common.CodeUpstreamError, "error contacting service B")))
+	}
+	// CEP inválido ou inexistente não vai mudar: avisa os assinantes e encerra
+	terminal := errors.Is(err, serviceb.ErrNotFound) || errors.Is(err, serviceb.ErrInvalidZipcode)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	h.publishLocked(p, event)
+	if terminal {
+		for sub := range p.subs {
+			delete(p.subs, sub)
+			close(sub.events)
+		}
+		h.stopLocked(p)
+	}
+}
+
+// errorEvent cria o evento de erro com o problema. O problema não leva trace ID, que muda a cada
+// consulta, para que a mesma falha não seja reenviada a cada intervalo.
+func errorEvent(problem *common.Problem) Event {
+	data, _ := json.Marshal(problem)
+	return Event{Type: EventError, Data: data}
+}
+
+// publishLocked numera e envia o evento se a leitura mudou. Assinantes com o buffer cheio são
+// desconectados e podem retomar com Last-Event-ID.
+func (h *Hub) publishLocked(p *poller, event Event) {
+	if n := len(p.recent); n > 0 {
+		last := p.recent[n-1]
+		if last.Type == event.Type && bytes.Equal(last.Data, event.Data) {
+			return
+		}
+	}
+
+	h.lastID++
+	event.ID = h.lastID
+	p.recent = append(p.recent, event)
+	if len(p.recent) > h.replay {
+		p.recent = p.recent[len(p.recent)-h.replay:]
+	}
+
+	for sub := range p.subs {
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("StreamHub: Dropping slow subscriber for CEP %s", p.cep)
+			h.removeLocked(sub)
+		}
+	}
+}
+
+// replayFrom retorna os eventos a reenviar para quem retoma a partir de lastEventID
+func (p *poller) replayFrom(lastEventID uint64) []Event {
+	if len(p.recent) == 0 {
+		return nil
+	}
+	if lastEventID != 0 {
+		for i, event := range p.recent {
+			if event.ID == lastEventID {
+				return p.recent[i+1:]
+			}
+		}
+	}
+	return p.recent[len(p.recent)-1:]
+}
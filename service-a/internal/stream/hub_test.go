@@ -0,0 +1,184 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"service-a/internal/common"
+	"service-a/internal/serviceb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource devolve a leitura configurada e conta as consultas por CEP
+type fakeSource struct {
+	mu     sync.Mutex
+	body   map[string]string
+	status map[string]int
+	calls  map[string]int
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{body: map[string]string{}, status: map[string]int{}, calls: map[string]int{}}
+}
+
+func (f *fakeSource) set(cep string, status int, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status[cep] = status
+	f.body[cep] = body
+}
+
+func (f *fakeSource) callCount(cep string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[cep]
+}
+
+// Fetch devolve o corpo configurado; status de erro viram *serviceb.StatusError, como no cliente
+func (f *fakeSource) Fetch(ctx context.Context, cep string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[cep]++
+	if status := f.status[cep]; status != http.StatusOK {
+		return nil, &serviceb.StatusError{StatusCode: status, Problem: common.FromServiceB(status, []byte(f.body[cep]))}
+	}
+	return []byte(f.body[cep]), nil
+}
+
+func temperature(c float64) string {
+	return fmt.Sprintf(`{"city":"São Paulo","temp_C":%.1f}`, c)
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events():
+		require.True(t, ok, "subscription closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return Event{}
+	}
+}
+
+func TestHub_SharesPollerBetweenSubscribers(t *testing.T) {
+	source := newFakeSource()
+	source.set("01001000", http.StatusOK, temperature(28.5))
+	hub := NewHub(source, 10*time.Millisecond, 8)
+
+	first := hub.Subscribe("01001000", 0)
+	second := hub.Subscribe("01001000", 0)
+	defer first.Close()
+	defer second.Close()
+
+	assert.Equal(t, 1, hub.Active())
+	for _, sub := range []*Subscription{first, second} {
+		event := receive(t, sub)
+		assert.Equal(t, EventTemperature, event.Type)
+		assert.JSONEq(t, temperature(28.5), string(event.Data))
+	}
+}
+
+func TestHub_PublishesOnlyOnChange(t *testing.T) {
+	source := newFakeSource()
+	source.set("01001000", http.StatusOK, temperature(28.5))
+	hub := NewHub(source, 5*time.Millisecond, 8)
+
+	sub := hub.Subscribe("01001000", 0)
+	defer sub.Close()
+
+	first := receive(t, sub)
+	assert.Eventually(t, func() bool { return source.callCount("01001000") >= 3 }, time.Second, time.Millisecond)
+	select {
+	case event := <-sub.Events():
+		t.Fatalf("unexpected event for unchanged reading: %+v", event)
+	default:
+	}
+
+	source.set("01001000", http.StatusOK, temperature(29))
+	second := receive(t, sub)
+	assert.Greater(t, second.ID, first.ID)
+	assert.JSONEq(t, temperature(29), string(second.Data))
+}
+
+func TestHub_ResumesFromLastEventID(t *testing.T) {
+	source := newFakeSource()
+	hub := NewHub(source, 5*time.Millisecond, 8)
+
+	source.set("01001000", http.StatusOK, temperature(20))
+	sub := hub.Subscribe("01001000", 0)
+	defer sub.Close()
+
+	var events []Event
+	for i := 1; i <= 3; i++ {
+		events = append(events, receive(t, sub))
+		source.set("01001000", http.StatusOK, temperature(20+float64(i)))
+	}
+
+	resumed := hub.Subscribe("01001000", events[0].ID)
+	defer resumed.Close()
+	assert.Equal(t, events[1], receive(t, resumed))
+	assert.Equal(t, events[2], receive(t, resumed))
+}
+
+func TestHub_UnknownLastEventIDGetsLatest(t *testing.T) {
+	source := newFakeSource()
+	source.set("01001000", http.StatusOK, temperature(20))
+	hub := NewHub(source, time.Hour, 8)
+
+	sub := hub.Subscribe("01001000", 0)
+	defer sub.Close()
+	latest := receive(t, sub)
+
+	resumed := hub.Subscribe("01001000", 999)
+	defer resumed.Close()
+	assert.Equal(t, latest, receive(t, resumed))
+}
+
+func TestHub_StopsPollerWhenLastSubscriberLeaves(t *testing.T) {
+	source := newFakeSource()
+	source.set("01001000", http.StatusOK, temperature(20))
+	hub := NewHub(source, 5*time.Millisecond, 8)
+
+	first := hub.Subscribe("01001000", 0)
+	second := hub.Subscribe("01001000", 0)
+	receive(t, first)
+
+	first.Close()
+	assert.Equal(t, 1, hub.Active())
+	second.Close()
+	assert.Equal(t, 0, hub.Active())
+
+	for range second.Events() {
+		// Descarta os eventos ainda no buffer; o canal precisa estar fechado
+	}
+
+	// Dá tempo a uma consulta em andamento terminar e confirma que o poller parou
+	time.Sleep(20 * time.Millisecond)
+	calls := source.callCount("01001000")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, calls, source.callCount("01001000"))
+}
+
+func TestHub_TerminalErrorClosesSubscriptions(t *testing.T) {
+	source := newFakeSource()
+	source.set("99999999", http.StatusNotFound, `{"type":"/problems/zipcode-not-found","title":"Zipcode not found","status":404,"detail":"can not find zipcode","code":"ZIPCODE_NOT_FOUND","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}`)
+	hub := NewHub(source, 5*time.Millisecond, 8)
+
+	sub := hub.Subscribe("99999999", 0)
+	event := receive(t, sub)
+	assert.Equal(t, EventError, event.Type)
+	// O trace ID do serviço B é descartado, para que a mesma falha não gere um evento a cada consulta
+	assert.JSONEq(t, `{"type":"/problems/zipcode-not-found","title":"Zipcode not found","status":404,"detail":"can not find zipcode","code":"ZIPCODE_NOT_FOUND"}`, string(event.Data))
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.Equal(t, 0, hub.Active())
+	sub.Close()
+}
@@ -0,0 +1,41 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"service-a/internal/serviceb"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Source busca a leitura atual de um CEP em JSON. Os erros seguem o serviceb.Client:
+// *serviceb.StatusError para respostas de erro e o erro do transporte nas falhas de comunicação.
+type Source interface {
+	Fetch(ctx context.Context, cep string) ([]byte, error)
+}
+
+type serviceBSource struct {
+	client serviceb.Client
+}
+
+// NewServiceBSource cria um Source que consulta a resposta v1 do CEP pelo cliente do serviço B
+func NewServiceBSource(client serviceb.Client) Source {
+	return &serviceBSource{client: client}
+}
+
+// Fetch consulta o serviço B e grava a leitura no mesmo JSON v1 do serviço B
+func (s *serviceBSource) Fetch(ctx context.Context, cep string) ([]byte, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "stream-poll")
+	defer span.End()
+	span.SetAttributes(attribute.String("cep", cep))
+
+	weather, err := s.client.Weather(ctx, cep, serviceb.Options{})
+	if err != nil {
+		span.SetStatus(codes.Error, "Error fetching weather from service B")
+		return nil, err
+	}
+	return json.Marshal(weather)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"service-b/internal/cepindex"
+	"time"
+	"unicode/utf8"
+)
+
+func main() {
+	defaultIndex := os.Getenv("CEP_INDEX_PATH")
+	if defaultIndex == "" {
+		defaultIndex = "cep-index.db"
+	}
+
+	indexPath := flag.String("index", defaultIndex, "caminho do índice de CEPs (padrão: $CEP_INDEX_PATH)")
+	format := flag.String("format", string(cepindex.FormatCSV), "formato do conjunto de dados: csv ou dne")
+	delimiter := flag.String("delimiter", "", "delimitador do CSV (detectado pelo cabeçalho quando vazio)")
+	checksum := flag.String("checksum", "", "SHA-256 esperado do conjunto de dados")
+	force := flag.Bool("force", false, "reimporta mesmo que o conjunto de dados não tenha mudado")
+	batchSize := flag.Int("batch", 5000, "quantidade de registros por transação")
+	stats := flag.Bool("stats", false, "apenas exibe as estatísticas do índice")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Uso: cep-import [opções] <arquivo CSV | diretório e-DNE>\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	index, err := cepindex.Open(*indexPath, false)
+	if err != nil {
+		log.Fatalf("Failed to open index: %v", err)
+	}
+	defer index.Close()
+
+	if *stats {
+		printStats(index)
+		return
+	}
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := cepindex.ImportOptions{
+		Format:           cepindex.Format(*format),
+		BatchSize:        *batchSize,
+		Force:            *force,
+		ExpectedChecksum: *checksum,
+	}
+	if *delimiter != "" {
+		r, _ := utf8.DecodeRuneInString(*delimiter)
+		opts.Delimiter = r
+	}
+
+	started := time.Now()
+	opts.Progress = func(p cepindex.ImportProgress) {
+		rate := float64(p.Processed) / time.Since(started).Seconds()
+		log.Printf("Progress: %d records processed (%d upserted, %d deleted, %.0f records/s)", p.Processed, p.Upserted, p.Deleted, rate)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("Importing %s (%s) into %s", flag.Arg(0), opts.Format, *indexPath)
+	result, err := cepindex.Import(ctx, index, flag.Arg(0), opts)
+	if err != nil {
+		log.Printf("Import failed after %d records: %v", result.Processed, err)
+		index.Close()
+		os.Exit(1)
+	}
+
+	if result.Unchanged {
+		log.Printf("Dataset unchanged since last import (sha256 %s), nothing to do. Use -force to reimport.", result.Checksum)
+	} else {
+		log.Printf("Import finished in %s: %d records processed, %d upserted, %d deleted (sha256 %s)",
+			time.Since(started).Round(time.Millisecond), result.Processed, result.Upserted, result.Deleted, result.Checksum)
+	}
+	printStats(index)
+}
+
+func printStats(index *cepindex.Index) {
+	stats, err := index.Stats()
+	if err != nil {
+		log.Fatalf("Failed to read index stats: %v", err)
+	}
+	log.Printf("Index contains %d CEPs (last updated: %s)", stats.Count, stats.UpdatedAt.Format(time.RFC3339))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"service-b/internal/historyexport"
+	"service-b/internal/repository"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func main() {
+	defaultHistory := os.Getenv("HISTORY_PATH")
+	if defaultHistory == "" {
+		defaultHistory = "history.db"
+	}
+
+	historyPath := flag.String("history", defaultHistory, "caminho do histórico de consultas (padrão: $HISTORY_PATH)")
+	from := flag.String("from", "", "início do período em RFC 3339 (padrão: desde o primeiro registro)")
+	to := flag.String("to", "", "fim do período em RFC 3339, exclusivo (padrão: até o último registro)")
+	window := flag.Duration("window", 0, "janela terminando agora (ex.: 24h); ignorada quando -from é informado")
+	format := flag.String("format", string(historyexport.FormatCSV), "formato do arquivo: csv ou parquet")
+	columns := flag.String("columns", "", "colunas separadas por vírgula (padrão: "+strings.Join(historyexport.ColumnNames(), ",")+")")
+	gzipped := flag.Bool("gzip", false, "comprime com gzip (o arquivo inteiro no CSV, as colunas no Parquet)")
+	output := flag.String("o", "", "arquivo de saída (padrão: saída padrão)")
+	serviceURL := flag.String("url", "", "URL do serviço B em execução (ex.: http://localhost:8090); exporta pelo endpoint /history/export em vez de abrir -history. O cabeçalho de autenticação é assinado com $INTERNAL_AUTH_SECRET")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Uso: history-export [opções]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	opts, err := buildOptions(*from, *to, *window, *format, *columns, *gzipped)
+	if err != nil {
+		log.Fatalf("Invalid options: %v", err)
+	}
+
+	// Somente leitura: o serviço em execução mantém o arquivo bloqueado, e a abertura falha após
+	// o tempo limite; nesse caso a exportação passa pelo endpoint do serviço (-url)
+	var repo repository.HistoryRepository
+	if *serviceURL == "" {
+		repo, err = repository.NewBoltHistoryRepository(*historyPath, true)
+		if errors.Is(err, bolt.ErrTimeout) {
+			log.Fatalf("History %s is locked by a running service-b; use -url, stop the service or export a copy of the file", *historyPath)
+		}
+		if err != nil {
+			log.Fatalf("Failed to open history: %v", err)
+		}
+		defer repo.Close()
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+	}
+	w := bufio.NewWriter(out)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	started := time.Now()
+	var count int64
+	unit := "lookups"
+	if repo == nil {
+		unit = "bytes"
+		count, err = exportRemote(ctx, w, *serviceURL, os.Getenv("INTERNAL_AUTH_SECRET"), opts)
+	} else {
+		var rows int
+		rows, err = historyexport.Write(ctx, w, repo, opts)
+		count = int64(rows)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil && out != os.Stdout {
+		err = out.Close()
+	}
+	if err != nil {
+		log.Printf("Export failed after %d %s: %v", count, unit, err)
+		if repo != nil {
+			repo.Close()
+		}
+		os.Exit(1)
+	}
+	log.Printf("Exported %d %s (%s) in %s", count, unit, opts.Format, time.Since(started).Round(time.Millisecond))
+}
+
+func buildOptions(from, to string, window time.Duration, format, columns string, gzipped bool) (historyexport.Options, error) {
+	opts := historyexport.Options{Gzip: gzipped}
+
+	var err error
+	if opts.Format, err = historyexport.ParseFormat(format); err != nil {
+		return opts, err
+	}
+	if opts.Columns, err = historyexport.ParseColumns(columns); err != nil {
+		return opts, err
+	}
+
+	if to != "" {
+		if opts.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return opts, fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+	switch {
+	case from != "":
+		if opts.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return opts, fmt.Errorf("invalid -from: %w", err)
+		}
+	case window > 0:
+		end := opts.To
+		if end.IsZero() {
+			end = time.Now()
+		}
+		opts.From = end.Add(-window)
+	}
+
+	if !opts.From.IsZero() && !opts.To.IsZero() && !opts.From.Before(opts.To) {
+		return opts, fmt.Errorf("-from must be before -to")
+	}
+	return opts, nil
+}
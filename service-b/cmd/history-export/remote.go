@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"service-b/internal/auth"
+	"service-b/internal/historyexport"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// remoteSubject é quem aparece no histórico de acesso do serviço B nas exportações remotas
+const remoteSubject = "history-export"
+
+// exportRemote baixa o arquivo do endpoint /history/export do serviço B em execução, que mantém o
+// histórico bloqueado para escrita. Com secret, assina o cabeçalho X-Internal-Subject exigido
+// pelas rotas de histórico. Retorna o número de bytes gravados.
+func exportRemote(ctx context.Context, w io.Writer, baseURL, secret string, opts historyexport.Options) (int64, error) {
+	query := url.Values{}
+	query.Set("format", string(opts.Format))
+	// Sem -from, o comando exporta desde o primeiro registro; o endpoint usaria a janela padrão
+	from := opts.From
+	if from.IsZero() {
+		from = time.Unix(0, 0)
+	}
+	query.Set("from", from.UTC().Format(time.RFC3339))
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.UTC().Format(time.RFC3339))
+	}
+	if len(opts.Columns) > 0 {
+		query.Set("columns", strings.Join(opts.Columns, ","))
+	}
+	if opts.Gzip {
+		query.Set("gzip", strconv.FormatBool(opts.Gzip))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/history/export?"+query.Encode(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("invalid -url: %w", err)
+	}
+	if secret != "" {
+		value, err := auth.Sign([]byte(secret), auth.Subject{
+			Subject:    remoteSubject,
+			Operations: []string{auth.OperationHistory},
+			ExpiresAt:  time.Now().Add(time.Minute).Unix(),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("signing internal subject: %w", err)
+		}
+		req.Header.Set(auth.SubjectHeader, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return 0, fmt.Errorf("service-b responded %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	// O serviço interrompe a resposta quando a exportação falha no meio, o que aparece aqui como erro de leitura
+	return io.Copy(w, resp.Body)
+}
@@ -1,22 +1,34 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"net/http"
+	"service-b/internal/auth"
+	"service-b/internal/cepindex"
 	"service-b/internal/config"
 	"service-b/internal/delivery"
+	"service-b/internal/i18n"
+	"service-b/internal/netguard"
+	"service-b/internal/openapi"
+	weatherv1 "service-b/internal/pb/weather/v1"
 	"service-b/internal/repository"
 	"service-b/internal/tracing"
 	"service-b/internal/usecase"
+	"shared/encoder"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
 )
 
 func main() {
 	// Carregar a configuração
-	_, err := config.LoadConfig()
+	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
@@ -30,20 +42,194 @@ func main() {
 
 	// Criar instâncias dos repositórios
 	cityRepo := repository.NewCityRepository()
+	if cfg.CEPLookupMode != config.CEPLookupOnline {
+		index, err := cepindex.Open(cfg.CEPIndexPath, true)
+		if err != nil {
+			log.Fatalf("Failed to open CEP index: %v", err)
+		}
+		defer index.Close()
+
+		offlineRepo := repository.NewOfflineCityRepository(index)
+		if cfg.CEPLookupMode == config.CEPLookupOffline {
+			cityRepo = offlineRepo
+		} else {
+			cityRepo = repository.NewFallbackCityRepository(offlineRepo, cityRepo)
+		}
+		log.Printf("CEP lookup mode: %s (index: %s)", cfg.CEPLookupMode, cfg.CEPIndexPath)
+	}
 	tempRepo := repository.NewTemperatureRepository()
+	// Cache da cidade de cada CEP e do clima de cada cidade, também usado no max-age das respostas
+	if cfg.WeatherCacheTTL > 0 {
+		cityRepo = repository.NewCachedCityRepository(cityRepo, cfg.WeatherCacheTTL)
+		tempRepo = repository.NewCachedTemperatureRepository(tempRepo, cfg.WeatherCacheTTL)
+		log.Printf("Weather cache TTL: %s", cfg.WeatherCacheTTL)
+	}
+	forecastRepo := repository.NewForecastRepository()
 
 	// Criar instâncias dos casos de uso
 	fetchCityService := usecase.NewFetchCityService(cityRepo)
 	fetchTempService := usecase.NewFetchTempService(tempRepo)
+	forecastService := usecase.NewForecastService(forecastRepo)
+	locationService := usecase.NewLocationService(cityRepo, tempRepo)
+	searchAddressService := usecase.NewSearchAddressService(cityRepo, tempRepo)
+
+	// Registro de unidades com a convenção Kelvin configurada
+	kelvinOffset, err := usecase.KelvinOffsetForConvention(cfg.KelvinConvention)
+	if err != nil {
+		log.Fatalf("Invalid Kelvin convention: %v", err)
+	}
+	units := usecase.NewUnitRegistry(kelvinOffset)
+
+	// Histórico de consultas, quando habilitado
+	handlerOpts := []delivery.CEPHandlerOption{delivery.WithUnitRegistry(units), delivery.WithCacheTTL(cfg.WeatherCacheTTL)}
+	var historyService usecase.HistoryService
+	if cfg.HistoryStore != config.HistoryStoreNone {
+		historyRepo, err := repository.NewBoltHistoryRepository(cfg.HistoryPath, false)
+		if err != nil {
+			log.Fatalf("Failed to open history store: %v", err)
+		}
+		defer historyRepo.Close()
+
+		historyService = usecase.NewHistoryService(historyRepo)
+		defer historyService.Close()
+		handlerOpts = append(handlerOpts, delivery.WithHistory(historyService))
+		log.Printf("Lookup history: %s (%s)", cfg.HistoryStore, cfg.HistoryPath)
+	}
+
+	// Data de desligamento da v1, anunciada no cabeçalho Sunset
+	if cfg.APIV1Sunset != "" {
+		sunset, err := time.Parse(time.DateOnly, cfg.APIV1Sunset)
+		if err != nil {
+			log.Fatalf("Invalid API v1 sunset date: %v", err)
+		}
+		handlerOpts = append(handlerOpts, delivery.WithV1Sunset(sunset))
+	}
+
+	// Alertas de temperatura, verificados periodicamente em segundo plano
+	var alertService usecase.AlertService
+	if cfg.AlertsStore != config.AlertsStoreNone {
+		alertRepo, err := repository.NewBoltAlertRepository(cfg.AlertsPath)
+		if err != nil {
+			log.Fatalf("Failed to open alert store: %v", err)
+		}
+		defer alertRepo.Close()
+
+		// Os webhooks são URLs dos clientes: a conexão recusa endereços da rede interna
+		webhookClient := &http.Client{
+			Timeout:   cfg.WebhookTimeout,
+			Transport: otelhttp.NewTransport(netguard.NewTransport()),
+		}
+		notifier := repository.NewWebhookNotifier(webhookClient, cfg.WebhookMaxAttempts, cfg.WebhookBackoff)
+		alertService = usecase.NewAlertService(alertRepo, fetchCityService, fetchTempService, notifier, cfg.AlertHysteresis)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go usecase.RunAlertScheduler(ctx, alertService, cfg.AlertCheckInterval)
+		log.Printf("Temperature alerts: checking every %s (%s)", cfg.AlertCheckInterval, cfg.AlertsPath)
+	}
 
 	// Criar instância do handler passando os valores corretamente
-	handler := delivery.NewCEPHandler(fetchCityService, fetchTempService)
+	handler := delivery.NewCEPHandler(fetchCityService, fetchTempService, handlerOpts...)
+	forecastHandler := delivery.NewForecastHandler(handler, forecastService)
+	locationHandler := delivery.NewLocationHandler(locationService, units)
+	addressHandler := delivery.NewAddressHandler(searchAddressService, units)
+
+	// Quem fez a consulta no serviço A, conferido com o segredo compartilhado
+	var subjectVerifier *auth.Verifier
+	if cfg.InternalAuthSecret != "" {
+		subjectVerifier = auth.NewVerifier([]byte(cfg.InternalAuthSecret))
+		log.Println("Internal subject verification enabled")
+	}
+
+	// Com o segredo configurado, o histórico e os alertas exigem quem fez a consulta, com a
+	// operação da rota
+	requireHistory := subjectVerifier.Require(auth.OperationHistory)
+	requireAlerts := subjectVerifier.Require(auth.OperationAlerts)
 
 	mux := http.NewServeMux()
 	mux.Handle("/cep/", otelhttp.NewHandler(http.HandlerFunc(handler.Handle), "cep-handler"))
+	mux.Handle("GET /v1/cep/{cep}", otelhttp.NewHandler(http.HandlerFunc(handler.Handle), "cep-v1-handler"))
+	mux.Handle("GET /v2/cep/{cep}", otelhttp.NewHandler(http.HandlerFunc(handler.Handle), "cep-v2-handler"))
+	mux.Handle("GET /cep/{cep}/forecast", otelhttp.NewHandler(http.HandlerFunc(forecastHandler.Handle), "forecast-handler"))
+	mux.Handle("GET /location", otelhttp.NewHandler(http.HandlerFunc(locationHandler.HandleCoordinates), "location-handler"))
+	mux.Handle("GET /city/{uf}/{name}", otelhttp.NewHandler(http.HandlerFunc(locationHandler.HandleCity), "city-handler"))
+	mux.Handle("GET /addresses", otelhttp.NewHandler(http.HandlerFunc(addressHandler.Handle), "address-handler"))
+	if historyService != nil {
+		historyHandler := delivery.NewHistoryHandler(historyService)
+		mux.Handle("GET /history/top-ceps", otelhttp.NewHandler(requireHistory(http.HandlerFunc(historyHandler.HandleTopCEPs)), "history-top-ceps-handler"))
+		mux.Handle("GET /history/cities/{city}/temperatures", otelhttp.NewHandler(requireHistory(http.HandlerFunc(historyHandler.HandleCityTemperatures)), "history-city-temperatures-handler"))
+		mux.Handle("GET /history/errors", otelhttp.NewHandler(requireHistory(http.HandlerFunc(historyHandler.HandleErrorRates)), "history-errors-handler"))
+		mux.Handle("GET /history/export", otelhttp.NewHandler(requireHistory(http.HandlerFunc(historyHandler.HandleExport)), "history-export-handler"))
+	}
+	if alertService != nil {
+		delivery.NewAlertHandler(alertService).Register(mux, requireAlerts)
+	}
+
+	// Contrato OpenAPI: documento em /openapi.json e validação das requisições
+	apiDoc, err := openapi.Load()
+	if err != nil {
+		log.Fatalf("Failed to load OpenAPI spec: %v", err)
+	}
+	specHandler, err := openapi.NewSpecHandler(apiDoc)
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI spec: %v", err)
+	}
+	mux.Handle("GET /openapi.json", specHandler)
+
+	var validatorOpts []openapi.ValidatorOption
+	if cfg.OpenAPIValidateResponses {
+		validatorOpts = append(validatorOpts, openapi.WithResponseValidation())
+		log.Println("OpenAPI response validation enabled")
+	}
+	validator, err := openapi.NewValidator(apiDoc, validatorOpts...)
+	if err != nil {
+		log.Fatalf("Failed to create OpenAPI validator: %v", err)
+	}
+
+	// Idioma das mensagens e do texto das condições, negociado pelo Accept-Language
+	negotiator, err := i18n.NewNegotiator(cfg.DefaultLanguage)
+	if err != nil {
+		log.Fatalf("Failed to create language negotiator: %v", err)
+	}
+
+	// Formato das respostas (JSON, XML, CSV ou protobuf), negociado pelo Accept
+	encoders := encoder.NewRegistry()
+
+	// Servidor gRPC, com o mesmo fluxo e histórico do GET /cep/{cep}
+	if cfg.GRPCAddr != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC on %s: %v", cfg.GRPCAddr, err)
+		}
+		unaryInterceptors := []grpc.UnaryServerInterceptor{negotiator.UnaryServerInterceptor()}
+		streamInterceptors := []grpc.StreamServerInterceptor{negotiator.StreamServerInterceptor()}
+		if subjectVerifier != nil {
+			unaryInterceptors = append(unaryInterceptors, subjectVerifier.UnaryServerInterceptor())
+			streamInterceptors = append(streamInterceptors, subjectVerifier.StreamServerInterceptor())
+		}
+		grpcServer := grpc.NewServer(
+			grpc.StatsHandler(otelgrpc.NewServerHandler()),
+			grpc.ChainUnaryInterceptor(unaryInterceptors...),
+			grpc.ChainStreamInterceptor(streamInterceptors...),
+		)
+		weatherv1.RegisterWeatherServiceServer(grpcServer, delivery.NewWeatherGRPCServer(handler))
+		defer grpcServer.GracefulStop()
+
+		go func() {
+			log.Printf("Starting gRPC server on %s", cfg.GRPCAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
+	apiHandler := validator.Middleware(mux)
+	if subjectVerifier != nil {
+		apiHandler = subjectVerifier.Middleware(apiHandler)
+	}
 
 	log.Println("Starting server on :8090")
-	if err := http.ListenAndServe(":8090", mux); err != nil {
+	if err := http.ListenAndServe(":8090", negotiator.Middleware(encoders.Middleware(apiHandler))); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"service-b/internal/common"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Middleware confere o cabeçalho X-Internal-Subject: com assinatura inválida ou vencida, responde
+// 401; válido, guarda o Subject no contexto. Requisições sem o cabeçalho seguem sem Subject; as
+// rotas que exigem quem fez a consulta usam RequireOperation.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value := r.Header.Get(SubjectHeader)
+		if value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		tracer := otel.Tracer("service-b")
+		ctx, span := tracer.Start(ctx, "verify-internal-subject")
+
+		subject, err := v.Verify(value)
+		if err != nil {
+			log.Printf("Auth: Rejected %s %s: %v", r.Method, r.URL.Path, err)
+			span.SetStatus(codes.Error, err.Error())
+			common.WriteProblem(ctx, w, common.NewProblem(http.StatusUnauthorized, common.CodeUnauthorized, "invalid internal subject"))
+			span.End()
+			return
+		}
+		setSubjectAttributes(span, subject)
+		span.End()
+
+		log.Printf("Auth: %s %s by %s (client %s)", r.Method, r.URL.Path, subject.Subject, subject.ClientID)
+		next.ServeHTTP(w, r.WithContext(WithSubject(r.Context(), subject)))
+	})
+}
+
+// Require retorna o middleware RequireOperation da operação op. Um Verifier nil (sem o segredo
+// configurado) não exige nada, e as rotas seguem abertas na rede interna.
+func (v *Verifier) Require(op string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if v == nil {
+			return next
+		}
+		return v.RequireOperation(op, next)
+	}
+}
+
+// RequireOperation exige que a requisição tenha um Subject verificado com a operação op: sem o
+// cabeçalho, responde 401; sem a operação, 403. Deve ficar dentro de Middleware, que guarda o
+// Subject no contexto.
+func (v *Verifier) RequireOperation(op string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject, ok := SubjectFromContext(r.Context())
+		if !ok {
+			log.Printf("Auth: Rejected %s %s: missing internal subject", r.Method, r.URL.Path)
+			common.WriteProblem(r.Context(), w, common.NewProblem(http.StatusUnauthorized, common.CodeUnauthorized, "missing internal subject"))
+			return
+		}
+		if !subject.Allows(op) {
+			log.Printf("Auth: Rejected %s %s: subject %s lacks the %s operation", r.Method, r.URL.Path, subject.Subject, op)
+			common.WriteProblem(r.Context(), w, common.NewProblemf(http.StatusForbidden, common.CodeForbidden, "subject does not allow the %s operation", op))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryServerInterceptor confere o metadado x-internal-subject das chamadas gRPC, recusando as
+// inválidas com Unauthenticated
+func (v *Verifier) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := v.withIncomingSubject(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor faz o mesmo que UnaryServerInterceptor para os streams
+func (v *Verifier) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := v.withIncomingSubject(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &subjectStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (v *Verifier) withIncomingSubject(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(SubjectMetadataKey)
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	subject, err := v.Verify(values[0])
+	if err != nil {
+		log.Printf("Auth: Rejected %s: %v", method, err)
+		return nil, status.Error(grpccodes.Unauthenticated, "invalid internal subject")
+	}
+	// O span da chamada é criado pelo stats handler do otelgrpc
+	setSubjectAttributes(trace.SpanFromContext(ctx), subject)
+	log.Printf("Auth: %s by %s (client %s)", method, subject.Subject, subject.ClientID)
+	return WithSubject(ctx, subject), nil
+}
+
+func setSubjectAttributes(span trace.Span, subject Subject) {
+	span.SetAttributes(attribute.String("client_id", subject.ClientID), attribute.String("enduser.id", subject.Subject))
+}
+
+// subjectStream troca o contexto do stream pelo contexto com o Subject
+type subjectStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *subjectStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"service-b/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// subjectHandler guarda o Subject que chega ao handler, se houver
+func subjectHandler(got *[]Subject) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subject, ok := SubjectFromContext(r.Context()); ok {
+			*got = append(*got, subject)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serve(handler http.Handler, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/history/top-ceps", nil)
+	if value != "" {
+		req.Header.Set(SubjectHeader, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) common.Problem {
+	t.Helper()
+	assert.Equal(t, common.ContentTypeProblem, rec.Header().Get("Content-Type"))
+	var problem common.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestMiddleware(t *testing.T) {
+	var got []Subject
+	handler := newVerifier().Middleware(subjectHandler(&got))
+
+	assert.Equal(t, http.StatusOK, serve(handler, sign(t, testSecret, validSubject("lookup"))).Code)
+	// Sem o cabeçalho, a requisição segue sem Subject
+	assert.Equal(t, http.StatusOK, serve(handler, "").Code)
+
+	assert.Equal(t, []Subject{validSubject("lookup")}, got)
+}
+
+func TestMiddleware_InvalidSubject(t *testing.T) {
+	var got []Subject
+	handler := newVerifier().Middleware(subjectHandler(&got))
+
+	rec := serve(handler, sign(t, []byte("another-secret-with-at-least-32-bytes"), validSubject("lookup")))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, common.CodeUnauthorized, problem.Code)
+	assert.Equal(t, "invalid internal subject", problem.Detail)
+	assert.Empty(t, got)
+}
+
+func TestRequireOperation(t *testing.T) {
+	var got []Subject
+	verifier := newVerifier()
+	handler := verifier.Middleware(verifier.RequireOperation(OperationHistory, subjectHandler(&got)))
+
+	assert.Equal(t, http.StatusOK, serve(handler, sign(t, testSecret, validSubject("lookup", "history"))).Code)
+	rec := serve(handler, sign(t, testSecret, validSubject("lookup")))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, common.CodeForbidden, problem.Code)
+	assert.Equal(t, "subject does not allow the history operation", problem.Detail)
+	assert.Len(t, got, 1)
+}
+
+func TestRequire_WithoutVerifier(t *testing.T) {
+	var got []Subject
+	var verifier *Verifier
+
+	// Sem o segredo configurado, a rota segue aberta
+	assert.Equal(t, http.StatusOK, serve(verifier.Require(OperationAlerts)(subjectHandler(&got)), "").Code)
+}
+
+func TestRequireOperation_MissingSubject(t *testing.T) {
+	var got []Subject
+	verifier := newVerifier()
+	handler := verifier.Middleware(verifier.RequireOperation(OperationHistory, subjectHandler(&got)))
+
+	rec := serve(handler, "")
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, common.CodeUnauthorized, problem.Code)
+	assert.Equal(t, "missing internal subject", problem.Detail)
+	assert.Empty(t, got)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := newVerifier().UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/weather.v1.WeatherService/GetByCEP"}
+	var got []Subject
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if subject, ok := SubjectFromContext(ctx); ok {
+			got = append(got, subject)
+		}
+		return "ok", nil
+	}
+	incoming := func(value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(SubjectMetadataKey, value))
+	}
+
+	resp, err := interceptor(incoming(sign(t, testSecret, validSubject("lookup"))), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	_, err = interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	_, err = interceptor(incoming("not-signed"), nil, info, handler)
+
+	assert.Equal(t, grpccodes.Unauthenticated, status.Code(err))
+	assert.Equal(t, []Subject{validSubject("lookup")}, got)
+}
+
+// fakeServerStream é um stream gRPC com apenas o contexto
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := newVerifier().StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/weather.v1.WeatherService/BatchGetByCEP", IsServerStream: true}
+	var got Subject
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		got, _ = SubjectFromContext(ss.Context())
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(SubjectMetadataKey, sign(t, testSecret, validSubject("batch"))))
+	require.NoError(t, interceptor(nil, &fakeServerStream{ctx: ctx}, info, handler))
+	assert.Equal(t, validSubject("batch"), got)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(SubjectMetadataKey, "not-signed"))
+	err := interceptor(nil, &fakeServerStream{ctx: ctx}, info, handler)
+	assert.Equal(t, grpccodes.Unauthenticated, status.Code(err))
+}
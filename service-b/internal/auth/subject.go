@@ -0,0 +1,116 @@
+// Package auth confere o cabeçalho X-Internal-Subject enviado pelo serviço A, com quem fez a
+// consulta e as operações autorizadas, assinado com o segredo compartilhado entre os serviços.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SubjectHeader é o cabeçalho com o autenticado no serviço A (mesmo cabeçalho enviado por ele)
+const SubjectHeader = "X-Internal-Subject"
+
+// SubjectMetadataKey é o equivalente de SubjectHeader nos metadados gRPC
+const SubjectMetadataKey = "x-internal-subject"
+
+// Operações exigidas nas rotas restritas
+const (
+	// OperationHistory é exigida nas rotas de histórico
+	OperationHistory = "history"
+	// OperationAlerts é exigida nas rotas de inscrições e notificações de alerta
+	OperationAlerts = "alerts"
+)
+
+var (
+	// ErrInvalidSubject indica um cabeçalho malformado ou com a assinatura errada
+	ErrInvalidSubject = errors.New("invalid internal subject")
+	// ErrSubjectExpired indica um cabeçalho com a assinatura vencida
+	ErrSubjectExpired = errors.New("internal subject expired")
+)
+
+// Subject é quem fez a consulta no serviço A
+type Subject struct {
+	Subject    string   `json:"sub"`
+	ClientID   string   `json:"client_id,omitempty"`
+	Operations []string `json:"ops"`
+	ExpiresAt  int64    `json:"exp"`
+}
+
+// Allows indica se o Subject pode executar a operação
+func (s Subject) Allows(op string) bool {
+	return slices.Contains(s.Operations, op)
+}
+
+type subjectKey struct{}
+
+// WithSubject guarda o Subject verificado no contexto
+func WithSubject(ctx context.Context, s Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, s)
+}
+
+// SubjectFromContext retorna o Subject verificado do contexto
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	s, ok := ctx.Value(subjectKey{}).(Subject)
+	return s, ok
+}
+
+// Sign assina o Subject no formato do cabeçalho, para ferramentas que chamam o serviço B
+// diretamente (ex.: o history-export)
+func Sign(secret []byte, s Subject) (string, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verifier confere o cabeçalho: o conteúdo em JSON e o HMAC-SHA256 dele, ambos em base64url e
+// separados por ponto
+type Verifier struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewVerifier cria o Verifier com o segredo compartilhado com o serviço A
+func NewVerifier(secret []byte) *Verifier {
+	return &Verifier{secret: secret, now: time.Now}
+}
+
+// Verify confere a assinatura e a validade do cabeçalho e retorna o Subject
+func (v *Verifier) Verify(value string) (Subject, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(value, ".")
+	if !ok {
+		return Subject{}, ErrInvalidSubject
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return Subject{}, ErrInvalidSubject
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return Subject{}, ErrInvalidSubject
+	}
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return Subject{}, ErrInvalidSubject
+	}
+
+	var subject Subject
+	if err := json.Unmarshal(payload, &subject); err != nil || subject.Subject == "" || subject.ExpiresAt == 0 {
+		return Subject{}, ErrInvalidSubject
+	}
+	if v.now().Unix() >= subject.ExpiresAt {
+		return Subject{}, ErrSubjectExpired
+	}
+	return subject, nil
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// testNow é o instante dos testes das assinaturas
+var testNow = time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)
+
+func newVerifier() *Verifier {
+	v := NewVerifier(testSecret)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+// sign monta o cabeçalho como o serviço A, assinado com secret
+func sign(t *testing.T, secret []byte, subject Subject) string {
+	t.Helper()
+	data, err := json.Marshal(subject)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validSubject é um Subject com a assinatura válida em testNow
+func validSubject(ops ...string) Subject {
+	return Subject{Subject: "user-1", ClientID: "dashboard", Operations: ops, ExpiresAt: testNow.Add(time.Minute).Unix()}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	subject, err := newVerifier().Verify(sign(t, testSecret, validSubject("lookup", "history")))
+
+	require.NoError(t, err)
+	assert.Equal(t, validSubject("lookup", "history"), subject)
+	assert.True(t, subject.Allows(OperationHistory))
+	assert.False(t, subject.Allows("batch"))
+}
+
+func TestSign(t *testing.T) {
+	value, err := Sign(testSecret, validSubject("history"))
+	require.NoError(t, err)
+
+	assert.Equal(t, sign(t, testSecret, validSubject("history")), value)
+	subject, err := newVerifier().Verify(value)
+	require.NoError(t, err)
+	assert.Equal(t, validSubject("history"), subject)
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	_, signature, _ := strings.Cut(sign(t, testSecret, validSubject("lookup")), ".")
+	expired := validSubject("lookup")
+	expired.ExpiresAt = testNow.Unix()
+	anonymous := validSubject("lookup")
+	anonymous.Subject = ""
+
+	tests := []struct {
+		name  string
+		value string
+		err   error
+	}{
+		{name: "expired", value: sign(t, testSecret, expired), err: ErrSubjectExpired},
+		{name: "wrong secret", value: sign(t, []byte("another-secret-with-at-least-32-bytes"), validSubject("lookup")), err: ErrInvalidSubject},
+		{name: "tampered payload", value: base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","ops":["history"],"exp":9999999999}`)) + "." + signature, err: ErrInvalidSubject},
+		{name: "missing subject", value: sign(t, testSecret, anonymous), err: ErrInvalidSubject},
+		{name: "malformed", value: "not-signed", err: ErrInvalidSubject},
+		{name: "bad encoding", value: "!!!.???", err: ErrInvalidSubject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newVerifier().Verify(tt.value)
+
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package cepindex
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"shared/cep"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Format identifica o formato do conjunto de dados importado
+type Format string
+
+const (
+	// FormatCSV é um arquivo CSV com cabeçalho (cep, logradouro, complemento, bairro, cidade, uf, ibge)
+	FormatCSV Format = "csv"
+	// FormatDNE é um diretório no formato do e-DNE dos Correios (arquivos .TXT separados por @, ISO-8859-1)
+	FormatDNE Format = "dne"
+)
+
+const defaultBatchSize = 5000
+
+// ErrChecksumMismatch indica que o conjunto de dados não corresponde ao checksum esperado
+var ErrChecksumMismatch = errors.New("dataset checksum mismatch")
+
+// ImportOptions configura uma importação
+type ImportOptions struct {
+	Format Format
+	// Delimiter do CSV; quando zero, é detectado pelo cabeçalho (',' ou ';')
+	Delimiter rune
+	BatchSize int
+	// Force reimporta mesmo que o checksum da fonte não tenha mudado
+	Force bool
+	// ExpectedChecksum, quando informado, é comparado ao SHA-256 do conjunto de dados
+	ExpectedChecksum string
+	// Progress é chamado a cada lote aplicado
+	Progress func(ImportProgress)
+}
+
+// ImportProgress informa o andamento de uma importação
+type ImportProgress struct {
+	Processed int
+	Upserted  int
+	Deleted   int
+}
+
+// ImportResult resume uma importação
+type ImportResult struct {
+	Source    string
+	Checksum  string
+	Unchanged bool
+	ImportProgress
+}
+
+// Import carrega o conjunto de dados no índice. A importação é incremental: registros
+// existentes são atualizados, linhas marcadas com operação DEL são removidas e fontes cujo
+// checksum não mudou desde a última importação são ignoradas (a menos que Force seja usado).
+func Import(ctx context.Context, index *Index, path string, opts ImportOptions) (ImportResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	files, err := sourceFiles(path, opts.Format)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	checksum, err := checksumFiles(files)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	// A fonte é identificada pelo caminho absoluto: conjuntos com o mesmo nome em diretórios
+	// diferentes (ex.: ufA/ceps.csv e ufB/ceps.csv) têm checksums registrados separadamente
+	source, err := filepath.Abs(path)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("resolving dataset path %s: %w", path, err)
+	}
+	result := ImportResult{Source: source, Checksum: checksum}
+
+	if opts.ExpectedChecksum != "" && !strings.EqualFold(opts.ExpectedChecksum, checksum) {
+		return result, fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, opts.ExpectedChecksum, checksum)
+	}
+
+	previous, err := index.SourceChecksum(result.Source)
+	if err != nil {
+		return result, err
+	}
+	if previous == checksum && !opts.Force {
+		result.Unchanged = true
+		return result, nil
+	}
+
+	w := &batchWriter{ctx: ctx, index: index, size: opts.BatchSize, progress: opts.Progress, result: &result}
+	switch opts.Format {
+	case FormatCSV:
+		err = importCSV(files[0], opts.Delimiter, w)
+	case FormatDNE:
+		err = importDNE(path, files, w)
+	}
+	if err == nil {
+		err = w.flush()
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return result, index.SetSourceChecksum(result.Source, checksum)
+}
+
+// batchWriter acumula alterações e as aplica no índice em lotes
+type batchWriter struct {
+	ctx      context.Context
+	index    *Index
+	size     int
+	pending  []Change
+	progress func(ImportProgress)
+	result   *ImportResult
+}
+
+func (w *batchWriter) add(change Change) error {
+	if len(change.Record.CEP) != 8 {
+		return nil
+	}
+	w.pending = append(w.pending, change)
+	if len(w.pending) >= w.size {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *batchWriter) flush() error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	if len(w.pending) == 0 {
+		return nil
+	}
+	upserted, deleted, err := w.index.Apply(w.pending)
+	if err != nil {
+		return err
+	}
+	w.result.Processed += len(w.pending)
+	w.result.Upserted += upserted
+	w.result.Deleted += deleted
+	w.pending = w.pending[:0]
+	if w.progress != nil {
+		w.progress(w.result.ImportProgress)
+	}
+	return nil
+}
+
+// sourceFiles lista os arquivos da fonte, em ordem determinística
+func sourceFiles(path string, format Format) ([]string, error) {
+	switch format {
+	case FormatCSV:
+		return []string{path}, nil
+	case FormatDNE:
+		files, err := filepath.Glob(filepath.Join(path, "*.TXT"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no DNE .TXT files found in %s", path)
+		}
+		sort.Strings(files)
+		return files, nil
+	default:
+		return nil, fmt.Errorf("unknown dataset format %q", format)
+	}
+}
+
+// checksumFiles calcula o SHA-256 do conteúdo dos arquivos concatenados
+func checksumFiles(files []string) (string, error) {
+	h := sha256.New()
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// csvColumns mapeia os nomes de coluna aceitos para cada campo
+var csvColumns = map[string][]string{
+	"cep":        {"cep"},
+	"street":     {"logradouro", "street"},
+	"complement": {"complemento", "complement"},
+	"district":   {"bairro", "district"},
+	"city":       {"localidade", "cidade", "city"},
+	"uf":         {"uf", "estado", "state"},
+	"ibge":       {"ibge"},
+	"operation":  {"operacao", "operation", "op"},
+}
+
+func importCSV(path string, delimiter rune, w *batchWriter) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if delimiter == 0 {
+		header, err := br.Peek(1024)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		delimiter = detectDelimiter(header)
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("reading CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for field, aliases := range csvColumns {
+			for _, alias := range aliases {
+				if name == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, required := range []string{"cep", "city", "uf"} {
+		if _, ok := columns[required]; !ok {
+			return fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		change := Change{
+			Op: parseOperation(field(row, "operation")),
+			Record: Record{
+				CEP:        cep.Normalize(field(row, "cep")),
+				Street:     field(row, "street"),
+				Complement: field(row, "complement"),
+				District:   field(row, "district"),
+				City:       field(row, "city"),
+				UF:         strings.ToUpper(field(row, "uf")),
+				IBGE:       field(row, "ibge"),
+			},
+		}
+		if err := w.add(change); err != nil {
+			return err
+		}
+	}
+}
+
+func detectDelimiter(header []byte) rune {
+	line := string(header)
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if strings.Count(line, ";") > strings.Count(line, ",") {
+		return ';'
+	}
+	return ','
+}
+
+// importDNE lê o formato do e-DNE: LOG_LOCALIDADE.TXT e LOG_BAIRRO.TXT são carregados em
+// memória e os arquivos LOG_LOGRADOURO_XX.TXT (ou DELTA_LOG_LOGRADOURO_XX.TXT) são lidos em fluxo
+func importDNE(dir string, files []string, w *batchWriter) error {
+	type locality struct{ name, uf, ibge string }
+	localities := make(map[string]locality)
+	districts := make(map[string]string)
+
+	var localityFiles, districtFiles, streetFiles []string
+	for _, name := range files {
+		base := strings.TrimPrefix(strings.ToUpper(filepath.Base(name)), "DELTA_")
+		switch {
+		case base == "LOG_LOCALIDADE.TXT":
+			localityFiles = append(localityFiles, name)
+		case base == "LOG_BAIRRO.TXT":
+			districtFiles = append(districtFiles, name)
+		case strings.HasPrefix(base, "LOG_LOGRADOURO_"):
+			streetFiles = append(streetFiles, name)
+		}
+	}
+	if len(localityFiles) == 0 {
+		return fmt.Errorf("LOG_LOCALIDADE.TXT not found in %s", dir)
+	}
+
+	// LOC_NU@UFE_SG@LOC_NO@CEP@LOC_IN_SIT@LOC_IN_TIPO_LOC@LOC_NU_SUB@LOC_NO_ABREV@MUN_NU[@OPERACAO]
+	for _, name := range localityFiles {
+		err := readDNEFile(name, func(f []string) error {
+			if len(f) < 9 {
+				return nil
+			}
+			localities[f[0]] = locality{name: f[2], uf: f[1], ibge: f[8]}
+			// Localidades sem codificação por logradouro possuem um CEP único
+			if f[3] == "" {
+				return nil
+			}
+			return w.add(Change{
+				Op:     parseOperation(fieldAt(f, 9)),
+				Record: Record{CEP: cep.Normalize(f[3]), City: f[2], UF: f[1], IBGE: f[8]},
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// BAI_NU@UFE_SG@LOC_NU@BAI_NO@BAI_NO_ABREV
+	for _, name := range districtFiles {
+		err := readDNEFile(name, func(f []string) error {
+			if len(f) >= 4 {
+				districts[f[0]] = f[3]
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// LOG_NU@UFE_SG@LOC_NU@BAI_NU_INI@BAI_NU_FIM@LOG_NO@LOG_COMPLEMENTO@CEP@TLO_TX@LOG_STA_TLO@LOG_NO_ABREV[@OPERACAO]
+	for _, name := range streetFiles {
+		err := readDNEFile(name, func(f []string) error {
+			if len(f) < 10 {
+				return nil
+			}
+			loc := localities[f[2]]
+			street := f[5]
+			if f[9] == "S" && f[8] != "" {
+				street = f[8] + " " + f[5]
+			}
+			return w.add(Change{
+				Op: parseOperation(fieldAt(f, 11)),
+				Record: Record{
+					CEP:        cep.Normalize(f[7]),
+					Street:     street,
+					Complement: f[6],
+					District:   districts[f[3]],
+					City:       loc.name,
+					UF:         f[1],
+					IBGE:       loc.ibge,
+				},
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDNEFile lê um arquivo do e-DNE (ISO-8859-1, campos separados por @)
+func readDNEFile(path string, fn func(fields []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(charmap.ISO8859_1.NewDecoder().Reader(f))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "@")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if err := fn(fields); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func fieldAt(fields []string, i int) string {
+	if i < len(fields) {
+		return fields[i]
+	}
+	return ""
+}
+
+// parseOperation interpreta a coluna de operação (INS/UPD/DEL, como nos arquivos delta do e-DNE)
+func parseOperation(op string) Operation {
+	switch strings.ToUpper(op) {
+	case "DEL", "D", "DELETE":
+		return OpDelete
+	default:
+		return OpUpsert
+	}
+}
@@ -0,0 +1,206 @@
+package cepindex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func openTestIndex(t *testing.T) *Index {
+	t.Helper()
+	index, err := Open(filepath.Join(t.TempDir(), "cep.db"), false)
+	require.NoError(t, err)
+	t.Cleanup(func() { index.Close() })
+	return index
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestImport_CSV(t *testing.T) {
+	index := openTestIndex(t)
+	dataset := filepath.Join(t.TempDir(), "ceps.csv")
+	writeFile(t, dataset, "cep;logradouro;bairro;localidade;uf\n"+
+		"01001-000;Praça da Sé;Sé;São Paulo;SP\n"+
+		"01310-100;Avenida Paulista;Bela Vista;São Paulo;SP\n"+
+		"29902-555;Rua Ceará;Centro;Linhares;ES\n")
+
+	var progress []ImportProgress
+	result, err := Import(context.Background(), index, dataset, ImportOptions{
+		Format:    FormatCSV,
+		BatchSize: 2,
+		Progress:  func(p ImportProgress) { progress = append(progress, p) },
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Processed)
+	assert.Equal(t, 3, result.Upserted)
+	assert.Len(t, progress, 2)
+	assert.NotEmpty(t, result.Checksum)
+
+	record, err := index.Get("01310100")
+	require.NoError(t, err)
+	assert.Equal(t, Record{CEP: "01310100", Street: "Avenida Paulista", District: "Bela Vista", City: "São Paulo", UF: "SP"}, record)
+
+	_, err = index.Get("99999999")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	stats, err := index.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Count)
+}
+
+func TestImport_Search(t *testing.T) {
+	index := openTestIndex(t)
+	dataset := filepath.Join(t.TempDir(), "ceps.csv")
+	writeFile(t, dataset, "cep,logradouro,bairro,cidade,uf\n"+
+		"01001000,Praça da Sé,Sé,São Paulo,SP\n"+
+		"01310100,Avenida Paulista,Bela Vista,São Paulo,SP\n"+
+		"01311000,Avenida Paulista,Bela Vista,São Paulo,SP\n"+
+		"13015000,Rua Paulista,Centro,Campinas,SP\n")
+
+	_, err := Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV})
+	require.NoError(t, err)
+
+	records, err := index.Search("sp", "sao paulo", "PAULISTA", 0)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "01310100", records[0].CEP)
+	assert.Equal(t, "01311000", records[1].CEP)
+
+	records, err = index.Search("SP", "São Paulo", "se", 1)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestImport_Incremental(t *testing.T) {
+	index := openTestIndex(t)
+	dir := t.TempDir()
+	dataset := filepath.Join(dir, "ceps.csv")
+	writeFile(t, dataset, "cep,logradouro,cidade,uf\n01001000,Praça da Sé,São Paulo,SP\n01310100,Avenida Paulista,São Paulo,SP\n")
+
+	_, err := Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV})
+	require.NoError(t, err)
+
+	// Mesmo conteúdo: a importação é ignorada
+	result, err := Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV})
+	require.NoError(t, err)
+	assert.True(t, result.Unchanged)
+	assert.Zero(t, result.Processed)
+
+	// Atualização com remoção e alteração
+	writeFile(t, dataset, "cep,logradouro,cidade,uf,operacao\n01001000,,São Paulo,SP,DEL\n01310100,Av. Paulista,São Paulo,SP,UPD\n")
+	result, err = Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Upserted)
+	assert.Equal(t, 1, result.Deleted)
+
+	_, err = index.Get("01001000")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	records, err := index.Search("SP", "São Paulo", "av.", 0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	records, err = index.Search("SP", "São Paulo", "avenida", 0)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	stats, err := index.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Count)
+}
+
+func TestImport_SameFileNameInDifferentDirectories(t *testing.T) {
+	index := openTestIndex(t)
+	dir := t.TempDir()
+	datasets := []string{filepath.Join(dir, "ufA", "ceps.csv"), filepath.Join(dir, "ufB", "ceps.csv")}
+	require.NoError(t, os.Mkdir(filepath.Dir(datasets[0]), 0o755))
+	require.NoError(t, os.Mkdir(filepath.Dir(datasets[1]), 0o755))
+	writeFile(t, datasets[0], "cep;logradouro;bairro;localidade;uf\n01001-000;Praça da Sé;Sé;São Paulo;SP\n")
+	writeFile(t, datasets[1], "cep;logradouro;bairro;localidade;uf\n29902-555;Rua Ceará;Centro;Linhares;ES\n")
+
+	for _, dataset := range datasets {
+		result, err := Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV})
+		require.NoError(t, err)
+		assert.False(t, result.Unchanged, dataset)
+		assert.Equal(t, dataset, result.Source)
+	}
+
+	// Cada conjunto mantém o próprio checksum: reimportar qualquer um deles não muda nada
+	for _, dataset := range datasets {
+		result, err := Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV})
+		require.NoError(t, err)
+		assert.True(t, result.Unchanged, dataset)
+	}
+}
+
+func TestImport_ChecksumMismatch(t *testing.T) {
+	index := openTestIndex(t)
+	dataset := filepath.Join(t.TempDir(), "ceps.csv")
+	writeFile(t, dataset, "cep,cidade,uf\n01001000,São Paulo,SP\n")
+
+	_, err := Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV, ExpectedChecksum: "deadbeef"})
+
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = index.Get("01001000")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestImport_MissingColumns(t *testing.T) {
+	index := openTestIndex(t)
+	dataset := filepath.Join(t.TempDir(), "ceps.csv")
+	writeFile(t, dataset, "cep,logradouro\n01001000,Praça da Sé\n")
+
+	_, err := Import(context.Background(), index, dataset, ImportOptions{Format: FormatCSV})
+
+	assert.ErrorContains(t, err, "city")
+}
+
+func TestImport_DNE(t *testing.T) {
+	index := openTestIndex(t)
+	dir := t.TempDir()
+
+	latin1 := func(s string) string {
+		encoded, err := charmap.ISO8859_1.NewEncoder().String(s)
+		require.NoError(t, err)
+		return encoded
+	}
+	writeFile(t, filepath.Join(dir, "LOG_LOCALIDADE.TXT"), latin1(
+		"9668@SP@São Paulo@@1@M@@S PAULO@3550308\r\n"+
+			"5555@SP@Águas de São Pedro@13525000@0@M@@AGUAS S PEDRO@3500501\r\n"))
+	writeFile(t, filepath.Join(dir, "LOG_BAIRRO.TXT"), latin1("10@SP@9668@Bela Vista@B VISTA\r\n"))
+	writeFile(t, filepath.Join(dir, "LOG_LOGRADOURO_SP.TXT"), latin1(
+		"1@SP@9668@10@@Paulista@- até 610 - lado par@01310000@Avenida@S@Av Paulista\r\n"))
+
+	result, err := Import(context.Background(), index, dir, ImportOptions{Format: FormatDNE})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Upserted)
+
+	record, err := index.Get("01310000")
+	require.NoError(t, err)
+	assert.Equal(t, Record{
+		CEP:        "01310000",
+		Street:     "Avenida Paulista",
+		Complement: "- até 610 - lado par",
+		District:   "Bela Vista",
+		City:       "São Paulo",
+		UF:         "SP",
+		IBGE:       "3550308",
+	}, record)
+
+	record, err = index.Get("13525000")
+	require.NoError(t, err)
+	assert.Equal(t, "Águas de São Pedro", record.City)
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "sao paulo", Normalize("  São   Paulo "))
+	assert.Equal(t, "acai", Normalize("AÇAÍ"))
+}
@@ -0,0 +1,276 @@
+package cepindex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Buckets do índice: registros por CEP, índice secundário por UF/cidade/logradouro,
+// checksums das fontes importadas e metadados gerais
+var (
+	bucketCEPs    = []byte("ceps")
+	bucketByCity  = []byte("by_city")
+	bucketSources = []byte("sources")
+	bucketMeta    = []byte("meta")
+
+	metaCount     = []byte("count")
+	metaUpdatedAt = []byte("updated_at")
+)
+
+// ErrNotFound indica que o CEP não está no índice
+var ErrNotFound = errors.New("cep not found in index")
+
+// Record representa um endereço armazenado no índice
+type Record struct {
+	CEP        string `json:"cep"`
+	Street     string `json:"street,omitempty"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district,omitempty"`
+	City       string `json:"city"`
+	UF         string `json:"uf"`
+	IBGE       string `json:"ibge,omitempty"`
+}
+
+// Operation indica se uma alteração insere/atualiza ou remove um CEP
+type Operation int
+
+const (
+	OpUpsert Operation = iota
+	OpDelete
+)
+
+// Change é uma alteração a ser aplicada no índice
+type Change struct {
+	Op     Operation
+	Record Record
+}
+
+// Stats resume o conteúdo do índice
+type Stats struct {
+	Count     int
+	UpdatedAt time.Time
+}
+
+// Index é o índice de CEPs embutido em disco (bbolt)
+type Index struct {
+	db *bolt.DB
+}
+
+// Open abre (ou cria) o índice no caminho informado. O serviço abre em modo somente leitura;
+// o importador abre em modo escrita, o que exige acesso exclusivo ao arquivo.
+func Open(path string, readOnly bool) (*Index, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("opening cep index %s: %w", path, err)
+	}
+
+	if !readOnly {
+		err = db.Update(func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{bucketCEPs, bucketByCity, bucketSources, bucketMeta} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("initializing cep index: %w", err)
+		}
+	}
+	return &Index{db: db}, nil
+}
+
+// Close fecha o arquivo do índice
+func (i *Index) Close() error {
+	return i.db.Close()
+}
+
+// Get busca um CEP no índice
+func (i *Index) Get(cep string) (Record, error) {
+	var record Record
+	err := i.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketCEPs)
+		if b == nil {
+			return ErrNotFound
+		}
+		data := b.Get([]byte(cep))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &record)
+	})
+	return record, err
+}
+
+// Search busca endereços da cidade cujo logradouro contém o termo informado,
+// ignorando acentos e caixa, limitado a limit resultados
+func (i *Index) Search(uf, city, street string, limit int) ([]Record, error) {
+	prefix := []byte(cityKeyPrefix(uf, city))
+	wanted := Normalize(street)
+
+	records := []Record{}
+	err := i.db.View(func(tx *bolt.Tx) error {
+		byCity := tx.Bucket(bucketByCity)
+		ceps := tx.Bucket(bucketCEPs)
+		if byCity == nil || ceps == nil {
+			return nil
+		}
+
+		c := byCity.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			streetKey := string(k[len(prefix):bytes.LastIndexByte(k, keySeparator)])
+			if !strings.Contains(streetKey, wanted) {
+				continue
+			}
+
+			var record Record
+			if err := json.Unmarshal(ceps.Get(v), &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			if limit > 0 && len(records) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	return records, err
+}
+
+// Apply aplica um lote de alterações em uma única transação, mantendo o índice
+// secundário e o contador de registros consistentes. Retorna quantos registros foram
+// gravados e quantos foram removidos.
+func (i *Index) Apply(changes []Change) (upserted, deleted int, err error) {
+	err = i.db.Update(func(tx *bolt.Tx) error {
+		ceps := tx.Bucket(bucketCEPs)
+		byCity := tx.Bucket(bucketByCity)
+		meta := tx.Bucket(bucketMeta)
+		count := decodeInt(meta.Get(metaCount))
+
+		for _, change := range changes {
+			key := []byte(change.Record.CEP)
+
+			// Remove a entrada antiga do índice secundário, se houver
+			if old := ceps.Get(key); old != nil {
+				var previous Record
+				if err := json.Unmarshal(old, &previous); err != nil {
+					return err
+				}
+				if err := byCity.Delete([]byte(cityKey(previous))); err != nil {
+					return err
+				}
+				count--
+			}
+
+			if change.Op == OpDelete {
+				if err := ceps.Delete(key); err != nil {
+					return err
+				}
+				deleted++
+				continue
+			}
+
+			data, err := json.Marshal(change.Record)
+			if err != nil {
+				return err
+			}
+			if err := ceps.Put(key, data); err != nil {
+				return err
+			}
+			if err := byCity.Put([]byte(cityKey(change.Record)), key); err != nil {
+				return err
+			}
+			count++
+			upserted++
+		}
+
+		if err := meta.Put(metaCount, encodeInt(count)); err != nil {
+			return err
+		}
+		return meta.Put(metaUpdatedAt, []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+	return upserted, deleted, err
+}
+
+// SourceChecksum retorna o checksum registrado na última importação da fonte
+func (i *Index) SourceChecksum(source string) (string, error) {
+	var checksum string
+	err := i.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(bucketSources); b != nil {
+			checksum = string(b.Get([]byte(source)))
+		}
+		return nil
+	})
+	return checksum, err
+}
+
+// SetSourceChecksum registra o checksum da fonte importada
+func (i *Index) SetSourceChecksum(source, checksum string) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSources).Put([]byte(source), []byte(checksum))
+	})
+}
+
+// Stats retorna o total de registros e a data da última alteração
+func (i *Index) Stats() (Stats, error) {
+	var stats Stats
+	err := i.db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(bucketMeta)
+		if meta == nil {
+			return nil
+		}
+		stats.Count = decodeInt(meta.Get(metaCount))
+		if raw := meta.Get(metaUpdatedAt); raw != nil {
+			stats.UpdatedAt, _ = time.Parse(time.RFC3339, string(raw))
+		}
+		return nil
+	})
+	return stats, err
+}
+
+const keySeparator = '|'
+
+// cityKey monta a chave do índice secundário: uf|cidade|logradouro|cep
+func cityKey(r Record) string {
+	return cityKeyPrefix(r.UF, r.City) + Normalize(r.Street) + string(keySeparator) + r.CEP
+}
+
+func cityKeyPrefix(uf, city string) string {
+	return strings.ToUpper(uf) + string(keySeparator) + Normalize(city) + string(keySeparator)
+}
+
+var accentRemover = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// Normalize remove acentos, converte para minúsculas e elimina espaços extras
+func Normalize(s string) string {
+	out, _, err := transform.String(accentRemover, s)
+	if err != nil {
+		out = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(out)), " ")
+}
+
+func encodeInt(v int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(v))
+	return buf
+}
+
+func decodeInt(b []byte) int {
+	if len(b) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(b))
+}
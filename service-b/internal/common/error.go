@@ -1,25 +1,163 @@
+// Package common define o modelo de erro compartilhado pelos handlers: problemas RFC 7807
+// (application/problem+json) com type, código estável e trace ID.
 package common
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"service-b/internal/i18n"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"shared/cep"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContentTypeProblem é o Content-Type das respostas de erro
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemTypeBase prefixa o type de cada problema: /problems/ seguido do código em minúsculas,
+// com hífens (ex.: /problems/invalid-zipcode)
+const ProblemTypeBase = "/problems/"
+
+// Códigos estáveis dos problemas; os clientes devem tratar os erros por eles, não pelo texto
+const (
+	CodeInvalidZipcode       = "INVALID_ZIPCODE"
+	CodeZipcodeNotFound      = "ZIPCODE_NOT_FOUND"
+	CodeInvalidLocation      = "INVALID_LOCATION"
+	CodeLocationNotFound     = "LOCATION_NOT_FOUND"
+	CodeInvalidAddressSearch = "INVALID_ADDRESS_SEARCH"
+	CodeInvalidParameter     = "INVALID_PARAMETER"
+	CodeInvalidRequestBody   = "INVALID_REQUEST_BODY"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeNotAcceptable        = "NOT_ACCEPTABLE"
+	CodeInvalidSubscription  = "INVALID_SUBSCRIPTION"
+	CodeSubscriptionNotFound = "SUBSCRIPTION_NOT_FOUND"
+	CodeInvalidHistoryQuery  = "INVALID_HISTORY_QUERY"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeUpstreamTimeout      = "UPSTREAM_TIMEOUT"
+	CodeCircuitOpen          = "CIRCUIT_OPEN"
+	CodeUpstreamError        = "UPSTREAM_ERROR"
+	CodeInternal             = "INTERNAL_ERROR"
 )
 
-// ErrorResponse define o formato padronizado para respostas de erro
-type ErrorResponse struct {
-	Message string `json:"message"`
-	Code    int    `json:"code"`
+// titles é o resumo de cada código, igual em todas as ocorrências
+var titles = map[string]string{
+	CodeInvalidZipcode:       "Invalid zipcode",
+	CodeZipcodeNotFound:      "Zipcode not found",
+	CodeInvalidLocation:      "Invalid location",
+	CodeLocationNotFound:     "Location not found",
+	CodeInvalidAddressSearch: "Invalid address search",
+	CodeInvalidParameter:     "Invalid parameter",
+	CodeInvalidRequestBody:   "Invalid request body",
+	CodeUnsupportedMediaType: "Unsupported media type",
+	CodeNotAcceptable:        "Not acceptable",
+	CodeInvalidSubscription:  "Invalid subscription",
+	CodeSubscriptionNotFound: "Subscription not found",
+	CodeInvalidHistoryQuery:  "Invalid history query",
+	CodeUnauthorized:         "Unauthorized",
+	CodeForbidden:            "Forbidden",
+	CodeUpstreamTimeout:      "Upstream timeout",
+	CodeCircuitOpen:          "Upstream circuit open",
+	CodeUpstreamError:        "Upstream error",
+	CodeInternal:             "Internal error",
 }
 
-// NewErrorResponse cria uma nova resposta de erro padronizada
-func NewErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// ErrCircuitOpen indica que um cliente de upstream recusou a chamada com o circuito aberto
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Problem é uma resposta de erro no formato RFC 7807, acrescida do código estável e do trace ID.
+// Title e Detail ficam em inglês e são traduzidos ao responder.
+type Problem struct {
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Status  int    `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+	Code    string `json:"code"`
+	TraceID string `json:"trace_id,omitempty"`
 
-	response := ErrorResponse{
-		Message: message,
-		Code:    statusCode,
+	// detailKey e detailArgs geram o Detail no idioma da requisição
+	detailKey  string
+	detailArgs []interface{}
+}
+
+// NewProblem cria o problema do código, com o título padrão e o detalhe desta ocorrência
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{Type: TypeURI(code), Title: titles[code], Status: status, Detail: detail, Code: code, detailKey: detail}
+}
+
+// NewProblemf cria o problema com o detalhe formatado; format é a chave do catálogo de mensagens
+func NewProblemf(status int, code, format string, args ...interface{}) *Problem {
+	p := NewProblem(status, code, fmt.Sprintf(format, args...))
+	p.detailKey, p.detailArgs = format, args
+	return p
+}
+
+// TypeURI retorna o type do código
+func TypeURI(code string) string {
+	return ProblemTypeBase + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// Localize retorna uma cópia do problema com título e detalhe no idioma do contexto
+func (p *Problem) Localize(ctx context.Context) *Problem {
+	problem := *p
+	problem.Title = i18n.Message(ctx, p.Title)
+	if p.detailKey != "" {
+		problem.Detail = i18n.Message(ctx, p.detailKey, p.detailArgs...)
 	}
+	return &problem
+}
 
-	json.NewEncoder(w).Encode(response)
+// FromError converte um erro de domínio ou de upstream no problema correspondente. Erros sem
+// mapeamento resultam em fallback.
+func FromError(err error, fallback *Problem) *Problem {
+	var problem *Problem
+	var netErr net.Error
+	switch {
+	case errors.As(err, &problem):
+		return problem
+	case errors.Is(err, cep.ErrInvalidFormat):
+		return NewProblem(http.StatusUnprocessableEntity, CodeInvalidZipcode, "invalid zipcode")
+	case errors.Is(err, cep.ErrUnassigned), errors.Is(err, repository.ErrCEPNotFound):
+		return NewProblem(http.StatusNotFound, CodeZipcodeNotFound, "can not find zipcode")
+	case errors.Is(err, usecase.ErrInvalidLocation):
+		return NewProblem(http.StatusUnprocessableEntity, CodeInvalidLocation, "invalid location")
+	case errors.Is(err, repository.ErrLocationNotFound):
+		return NewProblem(http.StatusNotFound, CodeLocationNotFound, "can not find location")
+	case errors.Is(err, usecase.ErrInvalidForecastDays):
+		return NewProblem(http.StatusBadRequest, CodeInvalidParameter, "invalid days parameter")
+	case errors.Is(err, usecase.ErrInvalidSubscription):
+		return NewProblem(http.StatusUnprocessableEntity, CodeInvalidSubscription, "invalid subscription")
+	case errors.Is(err, repository.ErrSubscriptionNotFound):
+		return NewProblem(http.StatusNotFound, CodeSubscriptionNotFound, "can not find subscription")
+	case errors.Is(err, usecase.ErrInvalidHistoryQuery):
+		return NewProblem(http.StatusBadRequest, CodeInvalidHistoryQuery, "invalid history query")
+	case errors.Is(err, ErrCircuitOpen):
+		return NewProblem(http.StatusServiceUnavailable, CodeCircuitOpen, "upstream circuit open")
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return NewProblem(http.StatusGatewayTimeout, CodeUpstreamTimeout, "upstream request timed out")
+	}
+	return fallback
+}
+
+// WriteProblem responde com o problema no idioma do contexto, preenchendo o trace ID com o span
+// do contexto
+func WriteProblem(ctx context.Context, w http.ResponseWriter, p *Problem) {
+	problem := p.Localize(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		problem.TraceID = sc.TraceID().String()
+	}
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
 }
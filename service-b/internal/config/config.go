@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -13,8 +15,56 @@ type Config struct {
 	WeatherAPIKey string `mapstructure:"WEATHERAPI_KEY"`
 	OTLPEndpoint  string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	OTLPProtocol  string `mapstructure:"OTEL_EXPORTER_OTLP_PROTOCOL"`
+	// WeatherAPIForecastURL é o endpoint de previsão diária (forecast.json) da WeatherAPI
+	WeatherAPIForecastURL string `mapstructure:"WEATHERAPI_FORECAST_URL"`
+	// KelvinConvention escolhe o deslocamento Kelvin: "si" (273.15) ou "integer" (273, como no README)
+	KelvinConvention string `mapstructure:"KELVIN_CONVENTION"`
+	// CEPLookupMode define a origem dos CEPs: "online" (ViaCEP), "offline" (índice local) ou "offline-first"
+	CEPLookupMode string `mapstructure:"CEP_LOOKUP_MODE"`
+	CEPIndexPath  string `mapstructure:"CEP_INDEX_PATH"`
+	// HistoryStore define onde o histórico de consultas é gravado: "bolt" (arquivo embutido) ou "none"
+	HistoryStore string `mapstructure:"HISTORY_STORE"`
+	HistoryPath  string `mapstructure:"HISTORY_PATH"`
+	// AlertsStore define onde ficam as inscrições de alerta: "bolt" (arquivo embutido) ou "none"
+	AlertsStore        string        `mapstructure:"ALERTS_STORE"`
+	AlertsPath         string        `mapstructure:"ALERTS_PATH"`
+	AlertCheckInterval time.Duration `mapstructure:"ALERT_CHECK_INTERVAL"`
+	// AlertHysteresis é quanto (°C) a temperatura precisa recuar além do limite para encerrar o alerta
+	AlertHysteresis    float64       `mapstructure:"ALERT_HYSTERESIS"`
+	WebhookMaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBackoff     time.Duration `mapstructure:"WEBHOOK_BACKOFF"`
+	WebhookTimeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	// GRPCAddr é o endereço do servidor gRPC (WeatherService); vazio desabilita o gRPC
+	GRPCAddr string `mapstructure:"GRPC_ADDR"`
+	// OpenAPIValidateResponses confere cada resposta com o openapi.yaml e troca as divergentes por 500
+	OpenAPIValidateResponses bool `mapstructure:"OPENAPI_VALIDATE_RESPONSES"`
+	// DefaultLanguage é o idioma das respostas quando o Accept-Language não pede um suportado
+	DefaultLanguage string `mapstructure:"DEFAULT_LANGUAGE"`
+	// APIV1Sunset é a data (AAAA-MM-DD) informada no cabeçalho Sunset das respostas v1; vazio omite o cabeçalho
+	APIV1Sunset string `mapstructure:"API_V1_SUNSET"`
+	// WeatherCacheTTL é por quanto tempo a cidade do CEP e o clima da cidade são reaproveitados e
+	// define o max-age do Cache-Control; zero desabilita o cache
+	WeatherCacheTTL time.Duration `mapstructure:"WEATHER_CACHE_TTL"`
+	// InternalAuthSecret é o segredo compartilhado com o serviço A para conferir o cabeçalho
+	// X-Internal-Subject; vazio não confere o cabeçalho
+	InternalAuthSecret string `mapstructure:"INTERNAL_AUTH_SECRET"`
 }
 
+// Modos de busca de CEP
+const (
+	CEPLookupOnline       = "online"
+	CEPLookupOffline      = "offline"
+	CEPLookupOfflineFirst = "offline-first"
+)
+
+// Armazenamentos do histórico de consultas e das inscrições de alerta
+const (
+	HistoryStoreBolt = "bolt"
+	HistoryStoreNone = "none"
+	AlertsStoreBolt  = "bolt"
+	AlertsStoreNone  = "none"
+)
+
 var AppConfig *Config
 
 func LoadConfig() (*Config, error) {
@@ -29,8 +79,27 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("VIACEP_API_URL", "https://viacep.com.br/ws/")
 	viper.SetDefault("WEATHERAPI_URL", "http://api.weatherapi.com/v1/current.json")
 	viper.SetDefault("WEATHERAPI_KEY", "")
+	viper.SetDefault("WEATHERAPI_FORECAST_URL", "http://api.weatherapi.com/v1/forecast.json")
 	viper.SetDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "otel-collector:4317")
 	viper.SetDefault("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	viper.SetDefault("KELVIN_CONVENTION", "si")
+	viper.SetDefault("CEP_LOOKUP_MODE", CEPLookupOnline)
+	viper.SetDefault("CEP_INDEX_PATH", "cep-index.db")
+	viper.SetDefault("HISTORY_STORE", HistoryStoreBolt)
+	viper.SetDefault("HISTORY_PATH", "history.db")
+	viper.SetDefault("ALERTS_STORE", AlertsStoreBolt)
+	viper.SetDefault("ALERTS_PATH", "alerts.db")
+	viper.SetDefault("ALERT_CHECK_INTERVAL", "5m")
+	viper.SetDefault("ALERT_HYSTERESIS", 1.0)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 5)
+	viper.SetDefault("WEBHOOK_BACKOFF", "2s")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("GRPC_ADDR", ":9090")
+	viper.SetDefault("OPENAPI_VALIDATE_RESPONSES", false)
+	viper.SetDefault("DEFAULT_LANGUAGE", "en")
+	viper.SetDefault("API_V1_SUNSET", "")
+	viper.SetDefault("WEATHER_CACHE_TTL", "5m")
+	viper.SetDefault("INTERNAL_AUTH_SECRET", "")
 
 	// Tentar ler o arquivo de configuração
 	if err := viper.ReadInConfig(); err != nil {
@@ -42,6 +111,9 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("unable to decode into struct: %w", err)
 	}
 
+	// A convenção Kelvin é normalizada aqui; o restante do serviço recebe "si" ou "integer"
+	config.KelvinConvention = strings.ToLower(strings.TrimSpace(config.KelvinConvention))
+
 	// Validação das configurações obrigatórias
 	if err := validateConfig(&config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -58,6 +130,9 @@ func validateConfig(config *Config) error {
 	if config.WeatherAPIURL == "" {
 		return fmt.Errorf("WEATHERAPI_URL is required")
 	}
+	if config.WeatherAPIForecastURL == "" {
+		return fmt.Errorf("WEATHERAPI_FORECAST_URL is required")
+	}
 	if config.WeatherAPIKey == "" {
 		return fmt.Errorf("WEATHERAPI_KEY is required")
 	}
@@ -67,5 +142,55 @@ func validateConfig(config *Config) error {
 	if config.OTLPProtocol == "" {
 		return fmt.Errorf("OTEL_EXPORTER_OTLP_PROTOCOL is required")
 	}
+	if config.KelvinConvention != "si" && config.KelvinConvention != "integer" {
+		return fmt.Errorf("KELVIN_CONVENTION must be \"si\" or \"integer\"")
+	}
+	switch config.CEPLookupMode {
+	case CEPLookupOnline:
+	case CEPLookupOffline, CEPLookupOfflineFirst:
+		if config.CEPIndexPath == "" {
+			return fmt.Errorf("CEP_INDEX_PATH is required when CEP_LOOKUP_MODE is %q", config.CEPLookupMode)
+		}
+	default:
+		return fmt.Errorf("CEP_LOOKUP_MODE must be %q, %q or %q", CEPLookupOnline, CEPLookupOffline, CEPLookupOfflineFirst)
+	}
+	switch config.HistoryStore {
+	case HistoryStoreNone:
+	case HistoryStoreBolt:
+		if config.HistoryPath == "" {
+			return fmt.Errorf("HISTORY_PATH is required when HISTORY_STORE is %q", HistoryStoreBolt)
+		}
+	default:
+		return fmt.Errorf("HISTORY_STORE must be %q or %q", HistoryStoreBolt, HistoryStoreNone)
+	}
+	switch config.AlertsStore {
+	case AlertsStoreNone:
+	case AlertsStoreBolt:
+		if config.AlertsPath == "" {
+			return fmt.Errorf("ALERTS_PATH is required when ALERTS_STORE is %q", AlertsStoreBolt)
+		}
+		if config.AlertCheckInterval <= 0 {
+			return fmt.Errorf("ALERT_CHECK_INTERVAL must be positive")
+		}
+		if config.AlertHysteresis < 0 {
+			return fmt.Errorf("ALERT_HYSTERESIS must not be negative")
+		}
+		if config.WebhookMaxAttempts < 1 {
+			return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+		}
+	default:
+		return fmt.Errorf("ALERTS_STORE must be %q or %q", AlertsStoreBolt, AlertsStoreNone)
+	}
+	if config.APIV1Sunset != "" {
+		if _, err := time.Parse(time.DateOnly, config.APIV1Sunset); err != nil {
+			return fmt.Errorf("API_V1_SUNSET must be a date (YYYY-MM-DD)")
+		}
+	}
+	if config.WeatherCacheTTL < 0 {
+		return fmt.Errorf("WEATHER_CACHE_TTL must not be negative")
+	}
+	if config.InternalAuthSecret != "" && len(config.InternalAuthSecret) < 32 {
+		return fmt.Errorf("INTERNAL_AUTH_SECRET must have at least 32 bytes")
+	}
 	return nil
 }
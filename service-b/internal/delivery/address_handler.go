@@ -0,0 +1,115 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// AddressHandler gerencia a busca paginada de endereços por logradouro
+type AddressHandler struct {
+	search usecase.SearchAddressService
+	units  *usecase.UnitRegistry
+}
+
+// NewAddressHandler cria um novo handler
+func NewAddressHandler(search usecase.SearchAddressService, units *usecase.UnitRegistry) *AddressHandler {
+	return &AddressHandler{search: search, units: units}
+}
+
+// Handle processa GET /addresses?uf=&city=&street=[&page=&page_size=&weather=true]
+func (h *AddressHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log.Println("AddressHandler: Request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-address-handler")
+	defer span.End()
+
+	query := r.URL.Query()
+	search := usecase.AddressSearch{
+		UF:     query.Get("uf"),
+		City:   query.Get("city"),
+		Street: query.Get("street"),
+	}
+	span.SetAttributes(attribute.String("uf", search.UF), attribute.String("city", search.City), attribute.String("street", search.Street))
+
+	// Paginação
+	page, errPage := parsePositiveInt(query.Get("page"), 1)
+	pageSize, errSize := parsePositiveInt(query.Get("page_size"), usecase.DefaultPageSize)
+	if errPage != nil || errSize != nil || page > usecase.MaxPage || pageSize > usecase.MaxPageSize {
+		log.Printf("AddressHandler: Invalid pagination: page=%q page_size=%q", query.Get("page"), query.Get("page_size"))
+		span.SetStatus(codes.Error, "Invalid pagination parameters")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, "invalid pagination parameters")
+		return
+	}
+	search.Page = page
+	search.PageSize = pageSize
+
+	// Clima opcional da cidade dos resultados
+	search.IncludeWeather, _ = strconv.ParseBool(query.Get("weather"))
+	var opts responseOptions
+	if search.IncludeWeather {
+		var err error
+		opts, err = parseResponseOptions(query, h.units)
+		if err != nil {
+			span.SetStatus(codes.Error, "Invalid response options")
+			writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, err.Error())
+			return
+		}
+	}
+
+	result, err := h.search.Search(ctx, search)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidLocation):
+			log.Printf("AddressHandler: Invalid search: %v", err)
+			span.SetStatus(codes.Error, "Invalid address search")
+			writeErrorResponse(ctx, w, http.StatusUnprocessableEntity, common.CodeInvalidAddressSearch, "invalid address search")
+		case errors.Is(err, repository.ErrLocationNotFound):
+			log.Printf("AddressHandler: Weather location not found: %v", err)
+			span.SetStatus(codes.Error, "Location not found")
+			writeErrorResponse(ctx, w, http.StatusNotFound, common.CodeLocationNotFound, "can not find location")
+		default:
+			log.Printf("AddressHandler: Error searching addresses: %v", err)
+			span.SetStatus(codes.Error, "Error searching addresses")
+			writeUpstreamError(ctx, w, err, "error searching addresses")
+		}
+		return
+	}
+	span.SetAttributes(attribute.Int("results", result.Total))
+
+	response := map[string]interface{}{
+		"items":       result.Addresses,
+		"page":        result.Page,
+		"page_size":   result.PageSize,
+		"total":       result.Total,
+		"total_pages": result.TotalPages(),
+	}
+	if result.Weather != nil {
+		response["weather"] = buildWeatherResponse(result.City, result.Weather, opts)
+	}
+	writeResponse(ctx, w, http.StatusOK, response)
+}
+
+// parsePositiveInt converte um parâmetro inteiro positivo, usando o padrão quando ausente
+func parsePositiveInt(raw string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("invalid positive integer %q", raw)
+	}
+	return v, nil
+}
@@ -0,0 +1,110 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchAddressService struct {
+	mock.Mock
+}
+
+func (m *MockSearchAddressService) Search(ctx context.Context, search usecase.AddressSearch) (*usecase.AddressPage, error) {
+	args := m.Called(ctx, search)
+	page, _ := args.Get(0).(*usecase.AddressPage)
+	return page, args.Error(1)
+}
+
+func newTestAddressHandler(search usecase.SearchAddressService) *AddressHandler {
+	return NewAddressHandler(search, usecase.NewUnitRegistry(usecase.KelvinOffsetSI))
+}
+
+func TestAddressHandler_Success(t *testing.T) {
+	mockSearch := new(MockSearchAddressService)
+	handler := newTestAddressHandler(mockSearch)
+
+	search := usecase.AddressSearch{UF: "SP", City: "São Paulo", Street: "Paulista", Page: 2, PageSize: 1, IncludeWeather: true}
+	page := &usecase.AddressPage{
+		Addresses: []repository.Address{{CEP: "01310200", Street: "Avenida Paulista", District: "Bela Vista", City: "São Paulo", UF: "SP"}},
+		Page:      2,
+		PageSize:  1,
+		Total:     2,
+		City:      "São Paulo",
+		Weather:   &repository.WeatherObservation{TempC: 28.5},
+	}
+	mockSearch.On("Search", mock.Anything, search).Return(page, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/addresses?uf=SP&city=S%C3%A3o+Paulo&street=Paulista&page=2&page_size=1&weather=true&units=C", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedResponse := `{
+		"items":[{"cep":"01310200","street":"Avenida Paulista","district":"Bela Vista","city":"São Paulo","uf":"SP"}],
+		"page":2,"page_size":1,"total":2,"total_pages":2,
+		"weather":{"city":"São Paulo","temp_C":28.5}
+	}`
+	assert.JSONEq(t, expectedResponse, w.Body.String())
+
+	mockSearch.AssertExpectations(t)
+}
+
+func TestAddressHandler_InvalidPagination(t *testing.T) {
+	tests := []string{
+		"/addresses?uf=SP&city=Campinas&street=Rua&page=0",
+		"/addresses?uf=SP&city=Campinas&street=Rua&page=x",
+		"/addresses?uf=SP&city=Campinas&street=Rua&page_size=51",
+		"/addresses?uf=SP&city=Campinas&street=Rua&page=10001",
+		"/addresses?uf=SP&city=Campinas&street=Rua&page=9223372036854775807&page_size=50",
+	}
+
+	for _, target := range tests {
+		mockSearch := new(MockSearchAddressService)
+		handler := newTestAddressHandler(mockSearch)
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid pagination parameters"), w.Body.String())
+		mockSearch.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	}
+}
+
+func TestAddressHandler_Errors(t *testing.T) {
+	tests := []struct {
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{fmt.Errorf("%w: street too short", usecase.ErrInvalidLocation), http.StatusUnprocessableEntity, problemBody(http.StatusUnprocessableEntity, common.CodeInvalidAddressSearch, "invalid address search")},
+		{fmt.Errorf("viacep unavailable"), http.StatusInternalServerError, problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error searching addresses")},
+	}
+
+	for _, test := range tests {
+		mockSearch := new(MockSearchAddressService)
+		handler := newTestAddressHandler(mockSearch)
+		mockSearch.On("Search", mock.Anything, mock.Anything).Return(nil, test.err)
+
+		req := httptest.NewRequest(http.MethodGet, "/addresses?uf=SP&city=Campinas&street=Ru", nil)
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, test.expectedCode, w.Code)
+		assert.JSONEq(t, test.expectedBody, w.Body.String())
+	}
+}
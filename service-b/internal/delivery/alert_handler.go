@@ -0,0 +1,142 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// AlertHandler gerencia as inscrições de alerta de temperatura
+type AlertHandler struct {
+	alerts usecase.AlertService
+}
+
+// NewAlertHandler cria um novo handler
+func NewAlertHandler(alerts usecase.AlertService) *AlertHandler {
+	return &AlertHandler{alerts: alerts}
+}
+
+// Register registra as rotas de alerta no mux, todas protegidas por protect (ex.: a exigência da
+// operação alerts no X-Internal-Subject)
+func (h *AlertHandler) Register(mux *http.ServeMux, protect func(http.Handler) http.Handler) {
+	mux.Handle("POST /alerts/subscriptions", otelhttp.NewHandler(protect(http.HandlerFunc(h.HandleCreate)), "alert-create-handler"))
+	mux.Handle("GET /alerts/subscriptions", otelhttp.NewHandler(protect(http.HandlerFunc(h.HandleList)), "alert-list-handler"))
+	mux.Handle("DELETE /alerts/subscriptions/{id}", otelhttp.NewHandler(protect(http.HandlerFunc(h.HandleDelete)), "alert-delete-handler"))
+	mux.Handle("GET /alerts/dead-letters", otelhttp.NewHandler(protect(http.HandlerFunc(h.HandleDeadLetters)), "alert-dead-letters-handler"))
+}
+
+// HandleCreate processa POST /alerts/subscriptions
+func (h *AlertHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	log.Println("AlertHandler: Create request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-alert-create-handler")
+	defer span.End()
+
+	var req usecase.SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("AlertHandler: Invalid request body: %v", err)
+		span.SetStatus(codes.Error, "Invalid request body")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body")
+		return
+	}
+	span.SetAttributes(attribute.String("cep", req.CEP))
+
+	sub, err := h.alerts.Subscribe(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidSubscription):
+			log.Printf("AlertHandler: Invalid subscription: %v", err)
+			span.SetStatus(codes.Error, "Invalid subscription")
+			writeErrorResponse(ctx, w, http.StatusUnprocessableEntity, common.CodeInvalidSubscription, "invalid subscription")
+		case errors.Is(err, repository.ErrCEPNotFound):
+			log.Printf("AlertHandler: CEP not found: %s", req.CEP)
+			span.SetStatus(codes.Error, "CEP not found")
+			writeErrorResponse(ctx, w, http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")
+		default:
+			log.Printf("AlertHandler: Error creating subscription: %v", err)
+			span.SetStatus(codes.Error, "Error creating subscription")
+			common.WriteProblem(ctx, w, common.FromError(err, common.NewProblem(http.StatusInternalServerError, common.CodeInternal, "error creating subscription")))
+		}
+		return
+	}
+	span.SetAttributes(attribute.String("subscription_id", sub.ID))
+
+	writeResponse(ctx, w, http.StatusCreated, sub)
+}
+
+// HandleList processa GET /alerts/subscriptions
+func (h *AlertHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	log.Println("AlertHandler: List request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-alert-list-handler")
+	defer span.End()
+
+	subs, err := h.alerts.List(ctx)
+	if err != nil {
+		log.Printf("AlertHandler: Error listing subscriptions: %v", err)
+		span.SetStatus(codes.Error, "Error listing subscriptions")
+		writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "error listing subscriptions")
+		return
+	}
+	writeResponse(ctx, w, http.StatusOK, map[string]interface{}{"items": subs})
+}
+
+// HandleDelete processa DELETE /alerts/subscriptions/{id}
+func (h *AlertHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	log.Println("AlertHandler: Delete request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-alert-delete-handler")
+	defer span.End()
+
+	id := r.PathValue("id")
+	span.SetAttributes(attribute.String("subscription_id", id))
+
+	err := h.alerts.Unsubscribe(ctx, id)
+	if errors.Is(err, repository.ErrSubscriptionNotFound) {
+		span.SetStatus(codes.Error, "Subscription not found")
+		writeErrorResponse(ctx, w, http.StatusNotFound, common.CodeSubscriptionNotFound, "can not find subscription")
+		return
+	}
+	if err != nil {
+		log.Printf("AlertHandler: Error deleting subscription %s: %v", id, err)
+		span.SetStatus(codes.Error, "Error deleting subscription")
+		writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "error deleting subscription")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleDeadLetters processa GET /alerts/dead-letters
+func (h *AlertHandler) HandleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	log.Println("AlertHandler: Dead letters request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-alert-dead-letters-handler")
+	defer span.End()
+
+	letters, err := h.alerts.DeadLetters(ctx)
+	if err != nil {
+		log.Printf("AlertHandler: Error listing dead letters: %v", err)
+		span.SetStatus(codes.Error, "Error listing dead letters")
+		writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "error listing dead letters")
+		return
+	}
+	writeResponse(ctx, w, http.StatusOK, map[string]interface{}{"items": letters})
+}
@@ -0,0 +1,225 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"service-b/internal/auth"
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAlertService struct {
+	mock.Mock
+}
+
+func (m *MockAlertService) Subscribe(ctx context.Context, req usecase.SubscriptionRequest) (*repository.Subscription, error) {
+	args := m.Called(ctx, req)
+	sub, _ := args.Get(0).(*repository.Subscription)
+	return sub, args.Error(1)
+}
+
+func (m *MockAlertService) List(ctx context.Context) ([]repository.Subscription, error) {
+	args := m.Called(ctx)
+	subs, _ := args.Get(0).([]repository.Subscription)
+	return subs, args.Error(1)
+}
+
+func (m *MockAlertService) Unsubscribe(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockAlertService) DeadLetters(ctx context.Context) ([]repository.DeadLetter, error) {
+	args := m.Called(ctx)
+	letters, _ := args.Get(0).([]repository.DeadLetter)
+	return letters, args.Error(1)
+}
+
+func (m *MockAlertService) CheckAll(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func TestAlertHandler_Create(t *testing.T) {
+	mockAlerts := new(MockAlertService)
+	handler := NewAlertHandler(mockAlerts)
+
+	above := 35.0
+	created := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	mockAlerts.On("Subscribe", mock.Anything, usecase.SubscriptionRequest{CEP: "01001000", AboveC: &above, WebhookURL: "https://example.com/hook"}).
+		Return(&repository.Subscription{
+			ID: "abc", CEP: "01001000", City: "São Paulo", AboveC: &above, WebhookURL: "https://example.com/hook",
+			Secret: "generated-secret", State: repository.AlertStateNormal, CreatedAt: created,
+		}, nil)
+
+	body := `{"cep":"01001000","above_C":35,"webhook_url":"https://example.com/hook"}`
+	req := httptest.NewRequest(http.MethodPost, "/alerts/subscriptions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreate(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id":"abc","cep":"01001000","city":"São Paulo","above_C":35,"webhook_url":"https://example.com/hook",
+		"secret":"generated-secret","state":"normal","created_at":"2024-01-10T12:00:00Z"}`, w.Body.String())
+	mockAlerts.AssertExpectations(t)
+}
+
+func TestAlertHandler_CreateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		status   int
+		expected string
+	}{
+		{"malformed", `{"cep":`, nil, http.StatusBadRequest, problemBody(http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body")},
+		{"invalid", `{"cep":"123"}`, usecase.ErrInvalidSubscription, http.StatusUnprocessableEntity, problemBody(http.StatusUnprocessableEntity, common.CodeInvalidSubscription, "invalid subscription")},
+		{"not found", `{"cep":"99999999"}`, repository.ErrCEPNotFound, http.StatusNotFound, problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")},
+		{"store failure", `{"cep":"01001000"}`, errors.New("disk full"), http.StatusInternalServerError, problemBody(http.StatusInternalServerError, common.CodeInternal, "error creating subscription")},
+	}
+
+	for _, test := range tests {
+		mockAlerts := new(MockAlertService)
+		handler := NewAlertHandler(mockAlerts)
+		if test.err != nil {
+			mockAlerts.On("Subscribe", mock.Anything, mock.Anything).Return(nil, test.err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/alerts/subscriptions", strings.NewReader(test.body))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreate(w, req)
+
+		assert.Equal(t, test.status, w.Code, test.name)
+		assert.JSONEq(t, test.expected, w.Body.String(), test.name)
+	}
+}
+
+func TestAlertHandler_List(t *testing.T) {
+	mockAlerts := new(MockAlertService)
+	handler := NewAlertHandler(mockAlerts)
+
+	below := 2.0
+	mockAlerts.On("List", mock.Anything).Return([]repository.Subscription{
+		{ID: "abc", CEP: "90010000", City: "Porto Alegre", BelowC: &below, WebhookURL: "https://example.com/hook", State: repository.AlertStateBelow},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/alerts/subscriptions", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleList(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[{"id":"abc","cep":"90010000","city":"Porto Alegre","below_C":2,"webhook_url":"https://example.com/hook",
+		"state":"below","created_at":"0001-01-01T00:00:00Z"}]}`, w.Body.String())
+}
+
+func TestAlertHandler_Delete(t *testing.T) {
+	mockAlerts := new(MockAlertService)
+	handler := NewAlertHandler(mockAlerts)
+
+	mockAlerts.On("Unsubscribe", mock.Anything, "abc").Return(nil)
+	mockAlerts.On("Unsubscribe", mock.Anything, "missing").Return(repository.ErrSubscriptionNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/alerts/subscriptions/abc", nil)
+	req.SetPathValue("id", "abc")
+	w := httptest.NewRecorder()
+	handler.HandleDelete(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/alerts/subscriptions/missing", nil)
+	req.SetPathValue("id", "missing")
+	w = httptest.NewRecorder()
+	handler.HandleDelete(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusNotFound, common.CodeSubscriptionNotFound, "can not find subscription"), w.Body.String())
+}
+
+func TestAlertHandler_DeadLetters(t *testing.T) {
+	mockAlerts := new(MockAlertService)
+	handler := NewAlertHandler(mockAlerts)
+
+	failedAt := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	mockAlerts.On("DeadLetters", mock.Anything).Return([]repository.DeadLetter{{
+		Event:      repository.AlertEvent{ID: "evt", Type: usecase.AlertEventAbove, SubscriptionID: "abc", CEP: "01001000", City: "São Paulo", TempC: 36, State: repository.AlertStateAbove, PreviousState: repository.AlertStateNormal, OccurredAt: failedAt},
+		WebhookURL: "https://example.com/hook",
+		Attempts:   5,
+		LastError:  "webhook responded 503 Service Unavailable",
+		FailedAt:   failedAt,
+	}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/alerts/dead-letters", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleDeadLetters(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"attempts":5`)
+	assert.Contains(t, w.Body.String(), `"type":"temperature.above"`)
+}
+
+func TestAlertHandler_RoutesRequireSubject(t *testing.T) {
+	secret := []byte("alerts-secret-with-at-least-32-bytes")
+	verifier := auth.NewVerifier(secret)
+	mockAlerts := new(MockAlertService)
+	mux := http.NewServeMux()
+	NewAlertHandler(mockAlerts).Register(mux, verifier.Require(auth.OperationAlerts))
+	handler := verifier.Middleware(mux)
+
+	subject := func(ops ...string) string {
+		value, err := auth.Sign(secret, auth.Subject{Subject: "user-1", Operations: ops, ExpiresAt: time.Now().Add(time.Minute).Unix()})
+		assert.NoError(t, err)
+		return value
+	}
+	routes := []struct{ method, target, body string }{
+		{http.MethodPost, "/alerts/subscriptions", `{"cep":"01001000","above_C":35,"webhook_url":"https://example.com/hook"}`},
+		{http.MethodGet, "/alerts/subscriptions", ""},
+		{http.MethodDelete, "/alerts/subscriptions/abc", ""},
+		{http.MethodGet, "/alerts/dead-letters", ""},
+	}
+	headers := []struct {
+		name   string
+		value  string
+		status int
+	}{
+		{name: "missing", value: "", status: http.StatusUnauthorized},
+		{name: "invalid", value: subject("alerts") + "x", status: http.StatusUnauthorized},
+		{name: "without alerts operation", value: subject("lookup", "history"), status: http.StatusForbidden},
+	}
+
+	for _, route := range routes {
+		for _, header := range headers {
+			req := httptest.NewRequest(route.method, route.target, strings.NewReader(route.body))
+			if header.value != "" {
+				req.Header.Set(auth.SubjectHeader, header.value)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, header.status, w.Code, "%s %s with %s subject", route.method, route.target, header.name)
+		}
+	}
+	mockAlerts.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	mockAlerts.AssertNotCalled(t, "List", mock.Anything)
+	mockAlerts.AssertNotCalled(t, "Unsubscribe", mock.Anything, mock.Anything)
+	mockAlerts.AssertNotCalled(t, "DeadLetters", mock.Anything)
+
+	// Com a operação alerts, a requisição chega ao serviço
+	mockAlerts.On("List", mock.Anything).Return([]repository.Subscription{}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/alerts/subscriptions", nil)
+	req.Header.Set(auth.SubjectHeader, subject("alerts"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAlerts.AssertExpectations(t)
+}
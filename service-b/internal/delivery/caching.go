@@ -0,0 +1,82 @@
+package delivery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cachePolicy são os metadados de cache HTTP de uma resposta de sucesso
+type cachePolicy struct {
+	// maxAge é por quanto tempo a resposta pode ser reaproveitada sem revalidação; zero envia
+	// no-cache, e o cliente revalida sempre com If-None-Match ou If-Modified-Since
+	maxAge time.Duration
+	// lastModified é o horário da observação; zero omite Last-Modified
+	lastModified time.Time
+}
+
+// cacheControl é o valor do Cache-Control da política
+func (p cachePolicy) cacheControl() string {
+	seconds := int64(p.maxAge / time.Second)
+	if seconds <= 0 {
+		return "no-cache"
+	}
+	return "public, max-age=" + strconv.FormatInt(seconds, 10)
+}
+
+// writeCacheableResponse responde como writeResponseAs, com ETag forte calculado sobre o corpo
+// codificado, Cache-Control e Last-Modified. Quando as condições de If-None-Match ou
+// If-Modified-Since indicam que o cliente já tem a versão atual, responde 304 sem corpo.
+func writeCacheableResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, jsonContentType string, response interface{}, policy cachePolicy) {
+	body, ok := encodeResponse(ctx, w, jsonContentType, response)
+	if !ok {
+		return
+	}
+
+	etag := strongETag(body)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", policy.cacheControl())
+	if !policy.lastModified.IsZero() {
+		w.Header().Set("Last-Modified", policy.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, policy.lastModified) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// strongETag identifica o corpo exato da resposta; formato e idioma diferentes geram ETags diferentes
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified avalia as condições do GET condicional (RFC 9110, seção 13.2.2): If-None-Match tem
+// precedência e, na sua ausência, If-Modified-Since é comparado com o horário da observação
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,331 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"service-b/internal/openapi"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"shared/encoder"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// contractMocks são as dependências de todas as rotas do serviço
+type contractMocks struct {
+	fetchCity *MockFetchCityService
+	fetchTemp *MockFetchTempService
+	forecasts *MockForecastService
+	locations *MockLocationService
+	addresses *MockSearchAddressService
+	history   *MockHistoryService
+	alerts    *MockAlertService
+}
+
+// newContractServer registra as rotas como no main, atrás da negociação de formato e do
+// validador com validação de respostas: qualquer divergência entre os handlers e o openapi.yaml vira um 500
+func newContractServer(t *testing.T, m *contractMocks) http.Handler {
+	t.Helper()
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	validator, err := openapi.NewValidator(doc, openapi.WithResponseValidation())
+	require.NoError(t, err)
+	specHandler, err := openapi.NewSpecHandler(doc)
+	require.NoError(t, err)
+
+	units := usecase.NewUnitRegistry(usecase.KelvinOffsetSI)
+	cepHandler := NewCEPHandler(m.fetchCity, m.fetchTemp)
+	forecastHandler := NewForecastHandler(cepHandler, m.forecasts)
+	locationHandler := NewLocationHandler(m.locations, units)
+	addressHandler := NewAddressHandler(m.addresses, units)
+	historyHandler := newTestHistoryHandler(m.history)
+	alertHandler := NewAlertHandler(m.alerts)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cep/", cepHandler.Handle)
+	mux.HandleFunc("GET /v1/cep/{cep}", cepHandler.Handle)
+	mux.HandleFunc("GET /v2/cep/{cep}", cepHandler.Handle)
+	mux.HandleFunc("GET /cep/{cep}/forecast", forecastHandler.Handle)
+	mux.HandleFunc("GET /location", locationHandler.HandleCoordinates)
+	mux.HandleFunc("GET /city/{uf}/{name}", locationHandler.HandleCity)
+	mux.HandleFunc("GET /addresses", addressHandler.Handle)
+	mux.HandleFunc("GET /history/top-ceps", historyHandler.HandleTopCEPs)
+	mux.HandleFunc("GET /history/cities/{city}/temperatures", historyHandler.HandleCityTemperatures)
+	mux.HandleFunc("GET /history/errors", historyHandler.HandleErrorRates)
+	mux.HandleFunc("GET /history/export", historyHandler.HandleExport)
+	mux.HandleFunc("POST /alerts/subscriptions", alertHandler.HandleCreate)
+	mux.HandleFunc("GET /alerts/subscriptions", alertHandler.HandleList)
+	mux.HandleFunc("DELETE /alerts/subscriptions/{id}", alertHandler.HandleDelete)
+	mux.HandleFunc("GET /alerts/dead-letters", alertHandler.HandleDeadLetters)
+	mux.Handle("GET /openapi.json", specHandler)
+	return encoder.NewRegistry().Middleware(validator.Middleware(mux))
+}
+
+func TestContract(t *testing.T) {
+	observedAt := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	obs := &repository.WeatherObservation{
+		TempC: 31.2, FeelsLikeC: 34.1, Humidity: 70, WindKph: 12.5, WindDegree: 90, WindDir: "E",
+		PressureMb: 1012, UV: 7, ConditionText: "Sunny", ConditionCode: 1000, LastUpdated: observedAt,
+	}
+	above := 35.0
+	sub := &repository.Subscription{
+		ID: "abc", CEP: "01001000", City: "São Paulo", AboveC: &above, WebhookURL: "https://example.com/hook",
+		Secret: "generated-secret", State: repository.AlertStateNormal, CreatedAt: observedAt,
+	}
+	event := repository.AlertEvent{
+		ID: "evt", Type: "temperature.above", SubscriptionID: "abc", CEP: "01001000", City: "São Paulo",
+		TempC: 36, ThresholdC: &above, State: repository.AlertStateAbove, PreviousState: repository.AlertStateNormal, OccurredAt: observedAt,
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		accept      string
+		ifNoneMatch string
+		body        string
+		setup       func(m *contractMocks)
+		status      int
+	}{
+		{
+			name: "cep full detail", method: http.MethodGet, target: "/cep/01001000?detail=full&units=C,F,K,R,Re&precision=1",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep state capital fallback", method: http.MethodGet, target: "/cep/01001000",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("", errors.New("viacep unavailable"))
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep v1", method: http.MethodGet, target: "/v1/cep/01001000?detail=full",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep v2 full detail", method: http.MethodGet, target: "/v2/cep/01001000?detail=full&units=C,F,K,R,Re&precision=1",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep v2 by accept", method: http.MethodGet, target: "/cep/01001000", accept: MediaTypeV2,
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("", errors.New("viacep unavailable"))
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep xml", method: http.MethodGet, target: "/cep/01001000?detail=full", accept: encoder.MediaTypeXML,
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "cep not acceptable", method: http.MethodGet, target: "/cep/01001000", accept: "text/html",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusNotAcceptable,
+		},
+		{
+			name: "cep not modified", method: http.MethodGet, target: "/v2/cep/01001000", ifNoneMatch: "*",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+			},
+			status: http.StatusNotModified,
+		},
+		{name: "cep v2 invalid zipcode", method: http.MethodGet, target: "/v2/cep/123", status: http.StatusUnprocessableEntity},
+		{name: "cep invalid zipcode", method: http.MethodGet, target: "/cep/123", status: http.StatusUnprocessableEntity},
+		{
+			name: "cep not found", method: http.MethodGet, target: "/cep/01001000",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("", repository.ErrCEPNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{name: "cep invalid fields", method: http.MethodGet, target: "/cep/01001000?fields=bogus", status: http.StatusBadRequest},
+		{name: "cep invalid precision", method: http.MethodGet, target: "/cep/01001000?precision=9", status: http.StatusBadRequest},
+		{
+			name: "cep weather error", method: http.MethodGet, target: "/cep/01001000",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.fetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(nil, errors.New("weatherapi unavailable"))
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name: "forecast", method: http.MethodGet, target: "/cep/01001000/forecast?days=2&units=C,F",
+			setup: func(m *contractMocks) {
+				m.fetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+				m.forecasts.On("Forecast", mock.Anything, "São Paulo", 2).Return([]repository.ForecastDay{
+					{Date: observedAt, MaxTempC: 30, MinTempC: 20, AvgTempC: 25, ChanceOfRain: 80, TotalPrecipMm: 4.2, AvgHumidity: 75, ConditionText: "Rain", ConditionCode: 1063},
+				}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{name: "forecast invalid days", method: http.MethodGet, target: "/cep/01001000/forecast?days=0", status: http.StatusBadRequest},
+		{
+			name: "location with candidates", method: http.MethodGet, target: "/location?lat=-23.55&lon=-46.63&street=Pra%C3%A7a%20da%20S%C3%A9",
+			setup: func(m *contractMocks) {
+				m.locations.On("ByCoordinates", mock.Anything, -23.55, -46.63, "Praça da Sé").
+					Return(&usecase.LocationLookup{City: "São Paulo", UF: "SP", CEPs: []string{"01001000"}, Weather: obs}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{name: "location missing coordinates", method: http.MethodGet, target: "/location", status: http.StatusUnprocessableEntity},
+		{
+			name: "city not found", method: http.MethodGet, target: "/city/SP/Nowhere",
+			setup: func(m *contractMocks) {
+				m.locations.On("ByCity", mock.Anything, "SP", "Nowhere", "").Return(nil, repository.ErrLocationNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "addresses with weather", method: http.MethodGet, target: "/addresses?uf=SP&city=S%C3%A3o%20Paulo&street=Pra%C3%A7a&weather=true",
+			setup: func(m *contractMocks) {
+				m.addresses.On("Search", mock.Anything, mock.Anything).Return(&usecase.AddressPage{
+					Addresses: []repository.Address{{CEP: "01001000", Street: "Praça da Sé", Complement: "lado ímpar", District: "Sé", City: "São Paulo", UF: "SP", IBGE: "3550308"}},
+					Page:      1, PageSize: 10, Total: 1, City: "São Paulo", Weather: obs,
+				}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{name: "addresses invalid page size", method: http.MethodGet, target: "/addresses?uf=SP&city=x&street=y&page_size=51", status: http.StatusBadRequest},
+		{
+			name: "history top ceps", method: http.MethodGet, target: "/history/top-ceps?window=7d&limit=5",
+			setup: func(m *contractMocks) {
+				avg := 21.5
+				m.history.On("TopCEPs", mock.Anything, mock.Anything, mock.Anything, 5).
+					Return([]usecase.CEPCount{{CEP: "01001000", City: "São Paulo", Count: 3, AvgTempC: &avg}, {CEP: "20040002", Count: 1}}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "history city temperatures", method: http.MethodGet, target: "/history/cities/S%C3%A3o%20Paulo/temperatures?interval=6h",
+			setup: func(m *contractMocks) {
+				m.history.On("CityTemperatures", mock.Anything, "São Paulo", mock.Anything, mock.Anything, 6*time.Hour).
+					Return([]usecase.TemperaturePoint{{Time: observedAt, Count: 2, AvgTempC: 22, MinTempC: 20, MaxTempC: 24}}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "history errors", method: http.MethodGet, target: "/history/errors",
+			setup: func(m *contractMocks) {
+				m.history.On("ErrorRates", mock.Anything, mock.Anything, mock.Anything, DefaultHistoryInterval).
+					Return([]usecase.ErrorRatePoint{{Time: observedAt, Total: 4, ClientErrors: 1, ServerErrors: 1, ErrorRate: 0.5}}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{name: "history invalid window", method: http.MethodGet, target: "/history/errors?window=forever", status: http.StatusBadRequest},
+		{
+			name: "history export", method: http.MethodGet, target: "/history/export?format=csv",
+			setup: func(m *contractMocks) {
+				m.history.On("Export", mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "alert create", method: http.MethodPost, target: "/alerts/subscriptions",
+			body: `{"cep":"01001000","above_C":35,"webhook_url":"https://example.com/hook"}`,
+			setup: func(m *contractMocks) {
+				m.alerts.On("Subscribe", mock.Anything, mock.Anything).Return(sub, nil)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name: "alert create invalid", method: http.MethodPost, target: "/alerts/subscriptions",
+			body: `{"cep":"01001000","webhook_url":"https://example.com/hook"}`,
+			setup: func(m *contractMocks) {
+				m.alerts.On("Subscribe", mock.Anything, mock.Anything).Return(nil, usecase.ErrInvalidSubscription)
+			},
+			status: http.StatusUnprocessableEntity,
+		},
+		{name: "alert create malformed body", method: http.MethodPost, target: "/alerts/subscriptions", body: `{`, status: http.StatusBadRequest},
+		{
+			name: "alert list", method: http.MethodGet, target: "/alerts/subscriptions",
+			setup: func(m *contractMocks) {
+				m.alerts.On("List", mock.Anything).Return([]repository.Subscription{*sub}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "alert delete", method: http.MethodDelete, target: "/alerts/subscriptions/abc",
+			setup: func(m *contractMocks) {
+				m.alerts.On("Unsubscribe", mock.Anything, "abc").Return(nil)
+			},
+			status: http.StatusNoContent,
+		},
+		{
+			name: "alert delete not found", method: http.MethodDelete, target: "/alerts/subscriptions/abc",
+			setup: func(m *contractMocks) {
+				m.alerts.On("Unsubscribe", mock.Anything, "abc").Return(repository.ErrSubscriptionNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "alert dead letters", method: http.MethodGet, target: "/alerts/dead-letters",
+			setup: func(m *contractMocks) {
+				m.alerts.On("DeadLetters", mock.Anything).Return([]repository.DeadLetter{
+					{Event: event, WebhookURL: "https://example.com/hook", Attempts: 5, LastError: "status 500", FailedAt: observedAt},
+				}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{name: "openapi document", method: http.MethodGet, target: "/openapi.json", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &contractMocks{
+				fetchCity: new(MockFetchCityService),
+				fetchTemp: new(MockFetchTempService),
+				forecasts: new(MockForecastService),
+				locations: new(MockLocationService),
+				addresses: new(MockSearchAddressService),
+				history:   new(MockHistoryService),
+				alerts:    new(MockAlertService),
+			}
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+			server := newContractServer(t, m)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.NotContains(t, w.Body.String(), "response does not match the api contract")
+		})
+	}
+}
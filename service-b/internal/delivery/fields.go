@@ -0,0 +1,112 @@
+package delivery
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"strings"
+	"time"
+)
+
+// Grupos de campos opcionais que podem ser solicitados via ?fields= ou ?detail=full
+const (
+	fieldFeelsLike  = "feelslike"
+	fieldHumidity   = "humidity"
+	fieldWind       = "wind"
+	fieldPressure   = "pressure"
+	fieldUV         = "uv"
+	fieldCondition  = "condition"
+	fieldObservedAt = "observed_at"
+	fieldComfort    = "comfort"
+)
+
+var detailFields = []string{
+	fieldFeelsLike,
+	fieldHumidity,
+	fieldWind,
+	fieldPressure,
+	fieldUV,
+	fieldCondition,
+	fieldObservedAt,
+	fieldComfort,
+}
+
+// parseDetailFields interpreta os parâmetros detail e fields da query.
+// Sem nenhum dos dois, a resposta mantém o formato mínimo {city,temp_C,temp_F,temp_K}.
+func parseDetailFields(query url.Values) (map[string]bool, error) {
+	selected := make(map[string]bool)
+
+	switch detail := query.Get("detail"); detail {
+	case "", "basic":
+	case "full":
+		for _, f := range detailFields {
+			selected[f] = true
+		}
+	default:
+		return nil, fmt.Errorf("unknown detail level %q", detail)
+	}
+
+	for _, raw := range query["fields"] {
+		for _, f := range strings.Split(raw, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			if !isDetailField(f) {
+				return nil, fmt.Errorf("unknown field %q", f)
+			}
+			selected[f] = true
+		}
+	}
+	return selected, nil
+}
+
+func isDetailField(name string) bool {
+	for _, f := range detailFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// appendDetailFields adiciona à resposta os campos opcionais selecionados
+func appendDetailFields(response map[string]interface{}, obs *repository.WeatherObservation, selected map[string]bool, units unitSelection) {
+	if selected[fieldFeelsLike] {
+		units.apply(response, "feelslike_", obs.FeelsLikeC)
+	}
+	if selected[fieldHumidity] {
+		response["humidity"] = obs.Humidity
+	}
+	if selected[fieldWind] {
+		response["wind_kph"] = obs.WindKph
+		response["wind_degree"] = obs.WindDegree
+		response["wind_dir"] = obs.WindDir
+	}
+	if selected[fieldPressure] {
+		response["pressure_mb"] = obs.PressureMb
+	}
+	if selected[fieldUV] {
+		response["uv"] = obs.UV
+	}
+	if selected[fieldCondition] {
+		response["condition"] = obs.ConditionText
+		response["condition_code"] = obs.ConditionCode
+	}
+	if selected[fieldObservedAt] && !obs.LastUpdated.IsZero() {
+		response["observed_at"] = obs.LastUpdated.Format(time.RFC3339)
+	}
+	if selected[fieldComfort] {
+		comfort := usecase.ComputeThermalComfort(obs)
+		response["heat_index_C"] = comfort.HeatIndexC
+		response["wind_chill_C"] = comfort.WindChillC
+		response["heat_risk"] = comfort.Risk
+		// Sem umidade não há ponto de orvalho (NaN não é serializável em JSON)
+		if !math.IsNaN(comfort.DewPointC) {
+			response["dew_point_C"] = comfort.DewPointC
+			response["humidex"] = comfort.Humidex
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package delivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ForecastHandler gerencia a previsão diária por CEP
+type ForecastHandler struct {
+	cep       *CEPHandler
+	forecasts usecase.ForecastService
+}
+
+// NewForecastHandler cria o handler de previsão a partir do handler de CEP, compartilhando a
+// validação, a busca da cidade e as unidades
+func NewForecastHandler(cep *CEPHandler, forecasts usecase.ForecastService) *ForecastHandler {
+	return &ForecastHandler{cep: cep, forecasts: forecasts}
+}
+
+// Handle processa GET /cep/{cep}/forecast[?days=&units=&precision=]
+func (h *ForecastHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log.Println("ForecastHandler: Request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-forecast-handler")
+	defer span.End()
+
+	code := r.PathValue("cep")
+	state, problem := h.cep.resolveCEP(ctx, code, &repository.Lookup{})
+	if problem != nil {
+		common.WriteProblem(ctx, w, problem)
+		return
+	}
+
+	query := r.URL.Query()
+	days := usecase.DefaultForecastDays
+	if raw := query.Get("days"); raw != "" {
+		var err error
+		if days, err = strconv.Atoi(raw); err != nil {
+			span.SetStatus(codes.Error, "Invalid days parameter")
+			writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, "invalid days parameter")
+			return
+		}
+	}
+	units, err := parseUnitSelection(query, h.cep.units)
+	if err != nil {
+		log.Printf("Invalid units parameter: %v", err)
+		span.SetStatus(codes.Error, "Invalid response options")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, "invalid units parameter")
+		return
+	}
+	span.SetAttributes(attribute.Int("days", days))
+
+	// Mesma regra do GET /cep/{cep}: se a cidade não puder ser consultada, usa a capital
+	city, err := h.cep.fetchCity.Fetch(ctx, code)
+	if errors.Is(err, repository.ErrCEPNotFound) {
+		log.Printf("ForecastHandler: CEP not found: %s", code)
+		span.SetStatus(codes.Error, "CEP not found")
+		writeErrorResponse(ctx, w, http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")
+		return
+	}
+	if err != nil {
+		log.Printf("ForecastHandler: Error fetching city for CEP %s, falling back to %s capital: %v", code, state.UF, err)
+		span.RecordError(err)
+		span.SetAttributes(attribute.String("fallback", ProviderStateCapital))
+		city = state.Capital
+		w.Header().Set(FallbackHeader, ProviderStateCapital)
+	}
+	span.SetAttributes(attribute.String("city", city))
+
+	forecast, err := h.forecasts.Forecast(ctx, city, days)
+	if errors.Is(err, usecase.ErrInvalidForecastDays) {
+		span.SetStatus(codes.Error, "Invalid days parameter")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, "invalid days parameter")
+		return
+	}
+	if err != nil {
+		log.Printf("ForecastHandler: Error fetching forecast for city %s: %v", city, err)
+		span.SetStatus(codes.Error, "Error fetching forecast")
+		writeUpstreamError(ctx, w, err, "error fetching forecast")
+		return
+	}
+
+	writeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"city":     city,
+		"forecast": buildForecastDays(forecast, units),
+	})
+}
+
+// buildForecastDays monta cada dia com as temperaturas máxima, mínima e média nas unidades escolhidas
+func buildForecastDays(forecast []repository.ForecastDay, units unitSelection) []map[string]interface{} {
+	days := make([]map[string]interface{}, 0, len(forecast))
+	for _, d := range forecast {
+		day := map[string]interface{}{
+			"date":            d.Date.Format(time.DateOnly),
+			"chance_of_rain":  d.ChanceOfRain,
+			"total_precip_mm": d.TotalPrecipMm,
+			"avg_humidity":    d.AvgHumidity,
+			"condition":       d.ConditionText,
+			"condition_code":  d.ConditionCode,
+		}
+		units.apply(day, "max_temp_", d.MaxTempC)
+		units.apply(day, "min_temp_", d.MinTempC)
+		units.apply(day, "avg_temp_", d.AvgTempC)
+		days = append(days, day)
+	}
+	return days
+}
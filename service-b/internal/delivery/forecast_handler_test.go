@@ -0,0 +1,130 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockForecastService struct {
+	mock.Mock
+}
+
+func (m *MockForecastService) Forecast(ctx context.Context, city string, days int) ([]repository.ForecastDay, error) {
+	args := m.Called(ctx, city, days)
+	forecast, _ := args.Get(0).([]repository.ForecastDay)
+	return forecast, args.Error(1)
+}
+
+func getForecast(handler *ForecastHandler, cep, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep+"/forecast"+query, nil)
+	req.SetPathValue("cep", cep)
+	w := httptest.NewRecorder()
+	handler.Handle(w, req)
+	return w
+}
+
+func TestForecastHandler_Success(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockForecasts := new(MockForecastService)
+	handler := NewForecastHandler(NewCEPHandler(mockFetchCity, new(MockFetchTempService)), mockForecasts)
+
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockForecasts.On("Forecast", mock.Anything, "São Paulo", 1).Return([]repository.ForecastDay{{
+		Date:          time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		MaxTempC:      30,
+		MinTempC:      19.5,
+		AvgTempC:      24.25,
+		ChanceOfRain:  80,
+		TotalPrecipMm: 12.4,
+		AvgHumidity:   75,
+		ConditionText: "Patchy rain nearby",
+		ConditionCode: 1063,
+	}}, nil)
+
+	w := getForecast(handler, "01001000", "?days=1&units=C,F")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"city":"São Paulo","forecast":[{
+		"date":"2026-10-19","chance_of_rain":80,"total_precip_mm":12.4,"avg_humidity":75,
+		"condition":"Patchy rain nearby","condition_code":1063,
+		"max_temp_C":30,"max_temp_F":86,"min_temp_C":19.5,"min_temp_F":67.1,"avg_temp_C":24.25,"avg_temp_F":75.65
+	}]}`, w.Body.String())
+	mockForecasts.AssertExpectations(t)
+}
+
+func TestForecastHandler_DefaultDaysAndFallback(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockForecasts := new(MockForecastService)
+	handler := NewForecastHandler(NewCEPHandler(mockFetchCity, new(MockFetchTempService)), mockForecasts)
+
+	mockFetchCity.On("Fetch", mock.Anything, "29902555").Return("", errors.New("viacep down"))
+	mockForecasts.On("Forecast", mock.Anything, "Vitória", usecase.DefaultForecastDays).Return([]repository.ForecastDay{}, nil)
+
+	w := getForecast(handler, "29902555", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ProviderStateCapital, w.Header().Get(FallbackHeader))
+	assert.JSONEq(t, `{"city":"Vitória","forecast":[]}`, w.Body.String())
+}
+
+func TestForecastHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		cep        string
+		query      string
+		cityErr    error
+		forecastFn func(*MockForecastService)
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid zipcode", cep: "123", wantStatus: http.StatusUnprocessableEntity, wantBody: problemBody(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode")},
+		{name: "days not a number", cep: "01001000", query: "?days=abc", wantStatus: http.StatusBadRequest, wantBody: problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid days parameter")},
+		{name: "unknown unit", cep: "01001000", query: "?units=X", wantStatus: http.StatusBadRequest, wantBody: problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid units parameter")},
+		{name: "zipcode not found", cep: "99999999", cityErr: repository.ErrCEPNotFound, wantStatus: http.StatusNotFound, wantBody: problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")},
+		{
+			name: "days out of range", cep: "01001000", query: "?days=30",
+			forecastFn: func(m *MockForecastService) {
+				m.On("Forecast", mock.Anything, "São Paulo", 30).Return(nil, usecase.ErrInvalidForecastDays)
+			},
+			wantStatus: http.StatusBadRequest, wantBody: problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid days parameter"),
+		},
+		{
+			name: "weather api down", cep: "01001000",
+			forecastFn: func(m *MockForecastService) {
+				m.On("Forecast", mock.Anything, "São Paulo", usecase.DefaultForecastDays).Return(nil, errors.New("weather api down"))
+			},
+			wantStatus: http.StatusInternalServerError, wantBody: problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching forecast"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetchCity := new(MockFetchCityService)
+			mockForecasts := new(MockForecastService)
+			handler := NewForecastHandler(NewCEPHandler(mockFetchCity, new(MockFetchTempService)), mockForecasts)
+			if tt.cityErr != nil {
+				mockFetchCity.On("Fetch", mock.Anything, tt.cep).Return("", tt.cityErr)
+			} else {
+				mockFetchCity.On("Fetch", mock.Anything, tt.cep).Return("São Paulo", nil)
+			}
+			if tt.forecastFn != nil {
+				tt.forecastFn(mockForecasts)
+			}
+
+			w := getForecast(handler, tt.cep, tt.query)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
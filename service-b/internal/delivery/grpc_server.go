@@ -0,0 +1,328 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"service-b/internal/common"
+	"service-b/internal/i18n"
+	weatherv1 "service-b/internal/pb/weather/v1"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// MaxBatchSize é o número máximo de CEPs em um BatchGetByCEP
+	MaxBatchSize = 1000
+	// batchConcurrency limita as consultas simultâneas de um mesmo lote ou stream
+	batchConcurrency = 8
+)
+
+// Campos usados no BadRequest dos erros INVALID_ARGUMENT, para o cliente distinguir CEP
+// inválido (HTTP 422) de opções inválidas (HTTP 400)
+const (
+	FieldViolationCEP     = "cep"
+	FieldViolationOptions = "options"
+	FieldViolationCEPs    = "ceps"
+)
+
+// WeatherGRPCServer implementa o WeatherService com o mesmo fluxo do GET /cep/{cep}: validação,
+// faixas de CEP, fallback para a capital e registro no histórico
+type WeatherGRPCServer struct {
+	weatherv1.UnimplementedWeatherServiceServer
+	cep *CEPHandler
+}
+
+// NewWeatherGRPCServer cria o servidor gRPC a partir do handler HTTP de CEP, compartilhando
+// serviços, unidades e histórico
+func NewWeatherGRPCServer(cep *CEPHandler) *WeatherGRPCServer {
+	return &WeatherGRPCServer{cep: cep}
+}
+
+// GetByCEP busca o clima de um CEP
+func (s *WeatherGRPCServer) GetByCEP(ctx context.Context, req *weatherv1.GetByCEPRequest) (*weatherv1.GetByCEPResponse, error) {
+	opts, err := s.parseOptions(ctx, req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+	return s.lookup(ctx, req.GetCep(), opts)
+}
+
+// BatchGetByCEP busca vários CEPs em paralelo e envia cada resultado assim que fica pronto
+func (s *WeatherGRPCServer) BatchGetByCEP(req *weatherv1.BatchGetByCEPRequest, stream weatherv1.WeatherService_BatchGetByCEPServer) error {
+	ceps := req.GetCeps()
+	if len(ceps) == 0 || len(ceps) > MaxBatchSize {
+		return invalidArgument(FieldViolationCEPs, "ceps must have between 1 and "+strconv.Itoa(MaxBatchSize)+" items")
+	}
+	ctx := stream.Context()
+	opts, err := s.parseOptions(ctx, req.GetOptions())
+	if err != nil {
+		return err
+	}
+
+	results := make(chan *weatherv1.CEPResult)
+	work := make(chan string)
+	var workers sync.WaitGroup
+	for i := 0; i < min(batchConcurrency, len(ceps)); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for code := range work {
+				results <- s.result(ctx, code, opts)
+			}
+		}()
+	}
+	go func() {
+		defer close(work)
+		for _, code := range ceps {
+			select {
+			case work <- code:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	var sendErr error
+	for result := range results {
+		if sendErr != nil {
+			continue // Esvazia o canal para liberar os workers
+		}
+		sendErr = stream.Send(result)
+	}
+	return sendErr
+}
+
+// StreamGetByCEP responde a cada pedido do stream, com até batchConcurrency consultas em paralelo
+func (s *WeatherGRPCServer) StreamGetByCEP(stream weatherv1.WeatherService_StreamGetByCEPServer) error {
+	ctx := stream.Context()
+	var (
+		pending sync.WaitGroup
+		sendMu  sync.Mutex
+		sendErr error
+	)
+	slots := make(chan struct{}, batchConcurrency)
+	send := func(result *weatherv1.CEPResult) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if sendErr == nil {
+			sendErr = stream.Send(result)
+		}
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			pending.Wait()
+			return err
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			pending.Wait()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		pending.Add(1)
+		go func(req *weatherv1.GetByCEPRequest) {
+			defer pending.Done()
+			defer func() { <-slots }()
+
+			opts, err := s.parseOptions(ctx, req.GetOptions())
+			if err != nil {
+				send(errorResult(req.GetCep(), err))
+				return
+			}
+			send(s.result(ctx, req.GetCep(), opts))
+		}(req)
+	}
+
+	pending.Wait()
+	return sendErr
+}
+
+// result consulta um CEP e converte o erro, se houver, no resultado do lote
+func (s *WeatherGRPCServer) result(ctx context.Context, code string, opts responseOptions) *weatherv1.CEPResult {
+	weather, err := s.lookup(ctx, code, opts)
+	if err != nil {
+		return errorResult(code, err)
+	}
+	return &weatherv1.CEPResult{Cep: code, Result: &weatherv1.CEPResult_Weather{Weather: weather}}
+}
+
+// lookup executa a consulta de um CEP, registrando-a no histórico como uma consulta HTTP
+// com o status equivalente
+func (s *WeatherGRPCServer) lookup(ctx context.Context, code string, opts responseOptions) (*weatherv1.GetByCEPResponse, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-cep-grpc")
+	defer span.End()
+	log.Printf("WeatherGRPCServer: CEP received: %s", code)
+
+	h := s.cep
+	ctx, lookup, record := h.startLookup(ctx, code)
+	state, problem := h.resolveCEP(ctx, code, lookup)
+	if problem != nil {
+		record(problem.Status)
+		return nil, lookupStatus(ctx, problem)
+	}
+
+	result, problem := h.fetchWeather(ctx, code, state, lookup)
+	if problem != nil {
+		record(problem.Status)
+		return nil, lookupStatus(ctx, problem)
+	}
+	record(http.StatusOK)
+	span.SetAttributes(attribute.String("fallback", result.fallback))
+	return buildGRPCResponse(code, result, opts), nil
+}
+
+// parseOptions converte as opções do pedido nos parâmetros equivalentes do HTTP, para aplicar
+// exatamente as mesmas regras de validação
+func (s *WeatherGRPCServer) parseOptions(ctx context.Context, options *weatherv1.ResponseOptions) (responseOptions, error) {
+	query := url.Values{}
+	if options.GetDetail() {
+		query.Set("detail", "full")
+	}
+	if len(options.GetFields()) > 0 {
+		query.Set("fields", strings.Join(options.GetFields(), ","))
+	}
+	if len(options.GetUnits()) > 0 {
+		query.Set("units", strings.Join(options.GetUnits(), ","))
+	}
+	if options != nil && options.Precision != nil {
+		query.Set("precision", strconv.Itoa(int(options.GetPrecision())))
+	}
+
+	opts, err := parseResponseOptions(query, s.cep.units)
+	if err != nil {
+		return opts, invalidArgument(FieldViolationOptions, i18n.Message(ctx, err.Error()))
+	}
+	return opts, nil
+}
+
+// lookupStatus mapeia o problema da consulta para o status gRPC equivalente ao código HTTP, com a
+// mensagem no idioma da chamada
+func lookupStatus(ctx context.Context, p *common.Problem) error {
+	p = p.Localize(ctx)
+	switch p.Status {
+	case http.StatusUnprocessableEntity:
+		return invalidArgument(FieldViolationCEP, p.Detail)
+	case http.StatusNotFound:
+		return status.Error(grpccodes.NotFound, p.Detail)
+	case http.StatusGatewayTimeout:
+		return status.Error(grpccodes.DeadlineExceeded, p.Detail)
+	case http.StatusServiceUnavailable:
+		return status.Error(grpccodes.Unavailable, p.Detail)
+	default:
+		return status.Error(grpccodes.Internal, p.Detail)
+	}
+}
+
+// invalidArgument cria um INVALID_ARGUMENT com o campo inválido no detalhe BadRequest
+func invalidArgument(field, message string) error {
+	st := status.New(grpccodes.InvalidArgument, message)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: message}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func errorResult(code string, err error) *weatherv1.CEPResult {
+	st := status.Convert(err)
+	return &weatherv1.CEPResult{
+		Cep:    code,
+		Result: &weatherv1.CEPResult_Error{Error: &weatherv1.Error{Code: int32(st.Code()), Message: st.Message()}},
+	}
+}
+
+// buildGRPCResponse monta a resposta com as mesmas unidades, precisão e campos opcionais do HTTP
+func buildGRPCResponse(code string, result *weatherResult, opts responseOptions) *weatherv1.GetByCEPResponse {
+	return &weatherv1.GetByCEPResponse{
+		Cep:          code,
+		City:         result.city,
+		Temperatures: grpcTemperatures(opts.units, result.obs.TempC),
+		Observation:  buildObservation(result.obs, opts),
+		Fallback:     result.fallback,
+	}
+}
+
+func grpcTemperatures(units unitSelection, celsius float64) []*weatherv1.Temperature {
+	temps := make([]*weatherv1.Temperature, 0, len(units.units))
+	for _, u := range units.units {
+		temps = append(temps, &weatherv1.Temperature{Unit: u.Symbol, Value: usecase.RoundTo(u.FromCelsius(celsius), units.precision)})
+	}
+	return temps
+}
+
+// buildObservation preenche os grupos de campos opcionais selecionados, como appendDetailFields
+func buildObservation(obs *repository.WeatherObservation, opts responseOptions) *weatherv1.Observation {
+	selected := opts.fields
+	if len(selected) == 0 {
+		return nil
+	}
+
+	o := &weatherv1.Observation{}
+	if selected[fieldFeelsLike] {
+		o.FeelsLike = grpcTemperatures(opts.units, obs.FeelsLikeC)
+	}
+	if selected[fieldHumidity] {
+		o.Humidity = ptr(int32(obs.Humidity))
+	}
+	if selected[fieldWind] {
+		o.WindKph = ptr(obs.WindKph)
+		o.WindDegree = ptr(int32(obs.WindDegree))
+		o.WindDir = ptr(obs.WindDir)
+	}
+	if selected[fieldPressure] {
+		o.PressureMb = ptr(obs.PressureMb)
+	}
+	if selected[fieldUV] {
+		o.Uv = ptr(obs.UV)
+	}
+	if selected[fieldCondition] {
+		o.Condition = ptr(obs.ConditionText)
+		o.ConditionCode = ptr(int32(obs.ConditionCode))
+	}
+	if selected[fieldObservedAt] && !obs.LastUpdated.IsZero() {
+		o.ObservedAt = timestamppb.New(obs.LastUpdated)
+	}
+	if selected[fieldComfort] {
+		comfort := usecase.ComputeThermalComfort(obs)
+		o.HeatIndexC = ptr(comfort.HeatIndexC)
+		o.WindChillC = ptr(comfort.WindChillC)
+		o.HeatRisk = ptr(string(comfort.Risk))
+		if !math.IsNaN(comfort.DewPointC) {
+			o.DewPointC = ptr(comfort.DewPointC)
+			o.Humidex = ptr(comfort.Humidex)
+		}
+	}
+	return o
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,201 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"testing"
+
+	weatherv1 "service-b/internal/pb/weather/v1"
+	"service-b/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newGRPCClient(t *testing.T, handler *CEPHandler) weatherv1.WeatherServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	weatherv1.RegisterWeatherServiceServer(server, NewWeatherGRPCServer(handler))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return weatherv1.NewWeatherServiceClient(conn)
+}
+
+// violationField retorna o campo do BadRequest de um erro INVALID_ARGUMENT
+func violationField(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok && len(br.FieldViolations) > 0 {
+			return br.FieldViolations[0].Field
+		}
+	}
+	return ""
+}
+
+func TestWeatherGRPCServer_GetByCEP(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5, Humidity: 70}, nil)
+	client := newGRPCClient(t, NewCEPHandler(mockFetchCity, mockFetchTemp))
+
+	resp, err := client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{Cep: "01001000"})
+	require.NoError(t, err)
+	assert.Equal(t, "São Paulo", resp.City)
+	require.Len(t, resp.Temperatures, 3)
+	assert.Equal(t, "C", resp.Temperatures[0].Unit)
+	assert.Equal(t, 28.5, resp.Temperatures[0].Value)
+	assert.Equal(t, "F", resp.Temperatures[1].Unit)
+	assert.Equal(t, 83.3, resp.Temperatures[1].Value)
+	assert.Equal(t, "K", resp.Temperatures[2].Unit)
+	assert.Equal(t, 301.65, resp.Temperatures[2].Value)
+	assert.Nil(t, resp.Observation)
+	assert.Empty(t, resp.Fallback)
+
+	precision := int32(0)
+	resp, err = client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{
+		Cep:     "01001000",
+		Options: &weatherv1.ResponseOptions{Units: []string{"F"}, Precision: &precision, Fields: []string{"humidity"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Temperatures, 1)
+	assert.Equal(t, 83.0, resp.Temperatures[0].Value)
+	require.NotNil(t, resp.Observation)
+	assert.Equal(t, int32(70), resp.Observation.GetHumidity())
+	assert.Nil(t, resp.Observation.WindKph)
+}
+
+func TestWeatherGRPCServer_StatusMapping(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	mockFetchCity.On("Fetch", mock.Anything, "99999999").Return("", repository.ErrCEPNotFound)
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(nil, errors.New("weather api down"))
+	client := newGRPCClient(t, NewCEPHandler(mockFetchCity, mockFetchTemp))
+
+	_, err := client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{Cep: "123"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "invalid zipcode", status.Convert(err).Message())
+	assert.Equal(t, FieldViolationCEP, violationField(err))
+
+	_, err = client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{
+		Cep:     "01001000",
+		Options: &weatherv1.ResponseOptions{Units: []string{"X"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "invalid units parameter", status.Convert(err).Message())
+	assert.Equal(t, FieldViolationOptions, violationField(err))
+
+	_, err = client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{Cep: "99999999"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "can not find zipcode", status.Convert(err).Message())
+
+	_, err = client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{Cep: "00000100"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{Cep: "01001000"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "error fetching temperature", status.Convert(err).Message())
+}
+
+func TestWeatherGRPCServer_FallbackToCapital(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	mockFetchCity.On("Fetch", mock.Anything, "29902555").Return("", errors.New("viacep down"))
+	mockFetchTemp.On("Fetch", mock.Anything, "Vitória").Return(&repository.WeatherObservation{TempC: 30}, nil)
+	client := newGRPCClient(t, NewCEPHandler(mockFetchCity, mockFetchTemp))
+
+	resp, err := client.GetByCEP(context.Background(), &weatherv1.GetByCEPRequest{Cep: "29902555"})
+	require.NoError(t, err)
+	assert.Equal(t, "Vitória", resp.City)
+	assert.Equal(t, ProviderStateCapital, resp.Fallback)
+}
+
+func TestWeatherGRPCServer_BatchGetByCEP(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchCity.On("Fetch", mock.Anything, "20040002").Return("Rio de Janeiro", nil)
+	mockFetchCity.On("Fetch", mock.Anything, "99999999").Return("", repository.ErrCEPNotFound)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "Rio de Janeiro").Return(&repository.WeatherObservation{TempC: 33}, nil)
+	client := newGRPCClient(t, NewCEPHandler(mockFetchCity, mockFetchTemp))
+
+	stream, err := client.BatchGetByCEP(context.Background(), &weatherv1.BatchGetByCEPRequest{
+		Ceps: []string{"01001000", "20040002", "99999999", "123"},
+	})
+	require.NoError(t, err)
+
+	results := map[string]*weatherv1.CEPResult{}
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		results[result.Cep] = result
+	}
+
+	require.Len(t, results, 4)
+	assert.Equal(t, "São Paulo", results["01001000"].GetWeather().City)
+	assert.Equal(t, "Rio de Janeiro", results["20040002"].GetWeather().City)
+	assert.Equal(t, int32(codes.NotFound), results["99999999"].GetError().Code)
+	assert.Equal(t, int32(codes.InvalidArgument), results["123"].GetError().Code)
+}
+
+func TestWeatherGRPCServer_BatchGetByCEPEmpty(t *testing.T) {
+	client := newGRPCClient(t, NewCEPHandler(new(MockFetchCityService), new(MockFetchTempService)))
+
+	stream, err := client.BatchGetByCEP(context.Background(), &weatherv1.BatchGetByCEPRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, FieldViolationCEPs, violationField(err))
+}
+
+func TestWeatherGRPCServer_StreamGetByCEP(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+	client := newGRPCClient(t, NewCEPHandler(mockFetchCity, mockFetchTemp))
+
+	stream, err := client.StreamGetByCEP(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&weatherv1.GetByCEPRequest{Cep: "01001000"}))
+	require.NoError(t, stream.Send(&weatherv1.GetByCEPRequest{Cep: "01001000", Options: &weatherv1.ResponseOptions{Fields: []string{"bogus"}}}))
+	require.NoError(t, stream.CloseSend())
+
+	var codesSeen []int32
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if result.GetWeather() != nil {
+			assert.Equal(t, "São Paulo", result.GetWeather().City)
+			codesSeen = append(codesSeen, int32(codes.OK))
+		} else {
+			codesSeen = append(codesSeen, result.GetError().Code)
+		}
+	}
+	sort.Slice(codesSeen, func(i, j int) bool { return codesSeen[i] < codesSeen[j] })
+	assert.Equal(t, []int32{int32(codes.OK), int32(codes.InvalidArgument)}, codesSeen)
+}
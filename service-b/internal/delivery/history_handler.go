@@ -0,0 +1,241 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"service-b/internal/common"
+	"service-b/internal/historyexport"
+	"service-b/internal/usecase"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultHistoryWindow é a janela padrão das consultas de histórico
+	DefaultHistoryWindow = 24 * time.Hour
+	// DefaultHistoryInterval é o intervalo padrão das séries temporais
+	DefaultHistoryInterval = time.Hour
+)
+
+// HistoryHandler expõe as consultas analíticas, somente leitura, sobre o histórico de CEPs
+type HistoryHandler struct {
+	history usecase.HistoryService
+	now     func() time.Time
+}
+
+// NewHistoryHandler cria um novo handler
+func NewHistoryHandler(history usecase.HistoryService) *HistoryHandler {
+	return &HistoryHandler{history: history, now: time.Now}
+}
+
+// HandleTopCEPs processa GET /history/top-ceps[?window=&from=&to=&limit=]
+func (h *HistoryHandler) HandleTopCEPs(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r, "process-history-top-ceps-handler")
+	defer span.End()
+
+	query := r.URL.Query()
+	from, to, err := h.parseWindow(query)
+	limit, errLimit := parsePositiveInt(query.Get("limit"), usecase.DefaultTopLimit)
+	if err != nil || errLimit != nil {
+		log.Printf("HistoryHandler: Invalid query %q: %v %v", r.URL.RawQuery, err, errLimit)
+		span.SetStatus(codes.Error, "Invalid history query")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidHistoryQuery, "invalid history query")
+		return
+	}
+
+	items, err := h.history.TopCEPs(ctx, from, to, limit)
+	if err != nil {
+		h.writeError(ctx, span, w, err)
+		return
+	}
+	writeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"items": items,
+	})
+}
+
+// HandleCityTemperatures processa GET /history/cities/{city}/temperatures[?window=&from=&to=&interval=]
+func (h *HistoryHandler) HandleCityTemperatures(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r, "process-history-city-temperatures-handler")
+	defer span.End()
+
+	city := r.PathValue("city")
+	span.SetAttributes(attribute.String("city", city))
+
+	query := r.URL.Query()
+	from, to, err := h.parseWindow(query)
+	interval, errInterval := parseDuration(query.Get("interval"), DefaultHistoryInterval)
+	if err != nil || errInterval != nil {
+		log.Printf("HistoryHandler: Invalid query %q: %v %v", r.URL.RawQuery, err, errInterval)
+		span.SetStatus(codes.Error, "Invalid history query")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidHistoryQuery, "invalid history query")
+		return
+	}
+
+	points, err := h.history.CityTemperatures(ctx, city, from, to, interval)
+	if err != nil {
+		h.writeError(ctx, span, w, err)
+		return
+	}
+	writeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"city":     city,
+		"from":     from,
+		"to":       to,
+		"interval": interval.String(),
+		"points":   points,
+	})
+}
+
+// HandleErrorRates processa GET /history/errors[?window=&from=&to=&interval=]
+func (h *HistoryHandler) HandleErrorRates(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r, "process-history-errors-handler")
+	defer span.End()
+
+	query := r.URL.Query()
+	from, to, err := h.parseWindow(query)
+	interval, errInterval := parseDuration(query.Get("interval"), DefaultHistoryInterval)
+	if err != nil || errInterval != nil {
+		log.Printf("HistoryHandler: Invalid query %q: %v %v", r.URL.RawQuery, err, errInterval)
+		span.SetStatus(codes.Error, "Invalid history query")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidHistoryQuery, "invalid history query")
+		return
+	}
+
+	points, err := h.history.ErrorRates(ctx, from, to, interval)
+	if err != nil {
+		h.writeError(ctx, span, w, err)
+		return
+	}
+	writeResponse(ctx, w, http.StatusOK, map[string]interface{}{
+		"from":     from,
+		"to":       to,
+		"interval": interval.String(),
+		"points":   points,
+	})
+}
+
+// HandleExport processa GET /history/export[?window=&from=&to=&format=csv|parquet&columns=&gzip=true],
+// transmitindo o arquivo à medida que os registros são lidos
+func (h *HistoryHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.startSpan(r, "process-history-export-handler")
+	defer span.End()
+
+	query := r.URL.Query()
+	from, to, err := h.parseWindow(query)
+	format, errFormat := historyexport.ParseFormat(query.Get("format"))
+	columns, errColumns := historyexport.ParseColumns(query.Get("columns"))
+	gzipped, errGzip := parseOptionalBool(query.Get("gzip"))
+	if err := errors.Join(err, errFormat, errColumns, errGzip); err != nil {
+		log.Printf("HistoryHandler: Invalid export query %q: %v", r.URL.RawQuery, err)
+		span.SetStatus(codes.Error, "Invalid history query")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidHistoryQuery, "invalid history query")
+		return
+	}
+	opts := historyexport.Options{From: from, To: to, Format: format, Columns: columns, Gzip: gzipped}
+	span.SetAttributes(attribute.String("format", string(format)), attribute.Bool("gzip", gzipped))
+
+	w.Header().Set("Content-Type", historyexport.ContentType(opts))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", historyexport.FileName(opts)))
+
+	// Depois do início da transmissão, o status não pode mais mudar: falhas apenas interrompem o arquivo
+	rows, err := h.history.Export(ctx, w, opts)
+	span.SetAttributes(attribute.Int("rows", rows))
+	if errors.Is(err, usecase.ErrInvalidHistoryQuery) {
+		// Rejeitado antes de gravar qualquer byte
+		w.Header().Del("Content-Disposition")
+		h.writeError(ctx, span, w, err)
+		return
+	}
+	if err != nil {
+		log.Printf("HistoryHandler: Error exporting history after %d rows: %v", rows, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error exporting history")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func (h *HistoryHandler) startSpan(r *http.Request, name string) (context.Context, trace.Span) {
+	log.Printf("HistoryHandler: Request received: %s", r.URL.Path)
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer("service-b").Start(ctx, name)
+}
+
+func (h *HistoryHandler) writeError(ctx context.Context, span trace.Span, w http.ResponseWriter, err error) {
+	if errors.Is(err, usecase.ErrInvalidHistoryQuery) {
+		log.Printf("HistoryHandler: Invalid query: %v", err)
+		span.SetStatus(codes.Error, "Invalid history query")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidHistoryQuery, "invalid history query")
+		return
+	}
+	log.Printf("HistoryHandler: Error reading history: %v", err)
+	span.SetStatus(codes.Error, "Error reading history")
+	writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "error reading history")
+}
+
+// parseWindow define o período consultado: from/to em RFC 3339 ou, na falta deles, a janela
+// (window) terminando agora
+func (h *HistoryHandler) parseWindow(query url.Values) (time.Time, time.Time, error) {
+	to := h.now().UTC()
+	if raw := query.Get("to"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to %q", raw)
+		}
+		to = t.UTC()
+	}
+
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from %q", raw)
+		}
+		return from.UTC(), to, nil
+	}
+
+	window, err := parseDuration(query.Get("window"), DefaultHistoryWindow)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return to.Add(-window), to, nil
+}
+
+// parseOptionalBool converte um parâmetro booleano opcional (ausente significa false)
+func parseOptionalBool(raw string) (bool, error) {
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// parseDuration aceita durações do Go (ex.: "90m", "6h") e dias (ex.: "7d")
+func parseDuration(raw string, fallback time.Duration) (time.Duration, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(raw)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", raw)
+	}
+	return d, nil
+}
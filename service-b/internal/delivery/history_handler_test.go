@@ -0,0 +1,239 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-b/internal/common"
+	"service-b/internal/historyexport"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockHistoryService) Record(ctx context.Context, lookup repository.Lookup) {
+	m.Called(ctx, lookup)
+}
+
+func (m *MockHistoryService) TopCEPs(ctx context.Context, from, to time.Time, limit int) ([]usecase.CEPCount, error) {
+	args := m.Called(ctx, from, to, limit)
+	items, _ := args.Get(0).([]usecase.CEPCount)
+	return items, args.Error(1)
+}
+
+func (m *MockHistoryService) CityTemperatures(ctx context.Context, city string, from, to time.Time, interval time.Duration) ([]usecase.TemperaturePoint, error) {
+	args := m.Called(ctx, city, from, to, interval)
+	points, _ := args.Get(0).([]usecase.TemperaturePoint)
+	return points, args.Error(1)
+}
+
+func (m *MockHistoryService) ErrorRates(ctx context.Context, from, to time.Time, interval time.Duration) ([]usecase.ErrorRatePoint, error) {
+	args := m.Called(ctx, from, to, interval)
+	points, _ := args.Get(0).([]usecase.ErrorRatePoint)
+	return points, args.Error(1)
+}
+
+func (m *MockHistoryService) Export(ctx context.Context, w io.Writer, opts historyexport.Options) (int, error) {
+	args := m.Called(ctx, w, opts)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockHistoryService) Close() error {
+	return m.Called().Error(0)
+}
+
+var historyNow = time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestHistoryHandler(history usecase.HistoryService) *HistoryHandler {
+	handler := NewHistoryHandler(history)
+	handler.now = func() time.Time { return historyNow }
+	return handler
+}
+
+func TestHistoryHandler_TopCEPs(t *testing.T) {
+	mockHistory := new(MockHistoryService)
+	handler := newTestHistoryHandler(mockHistory)
+
+	avg := 21.5
+	mockHistory.On("TopCEPs", mock.Anything, historyNow.Add(-7*24*time.Hour), historyNow, 5).
+		Return([]usecase.CEPCount{{CEP: "01001000", City: "São Paulo", Count: 3, AvgTempC: &avg}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/history/top-ceps?window=7d&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleTopCEPs(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"from":"2024-01-03T12:00:00Z","to":"2024-01-10T12:00:00Z",
+		"items":[{"cep":"01001000","city":"São Paulo","count":3,"avg_temp_C":21.5}]}`, w.Body.String())
+	mockHistory.AssertExpectations(t)
+}
+
+func TestHistoryHandler_CityTemperatures(t *testing.T) {
+	mockHistory := new(MockHistoryService)
+	handler := newTestHistoryHandler(mockHistory)
+
+	from := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	mockHistory.On("CityTemperatures", mock.Anything, "São Paulo", from, to, 6*time.Hour).
+		Return([]usecase.TemperaturePoint{{Time: from, Count: 2, AvgTempC: 22, MinTempC: 20, MaxTempC: 24}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/history/cities/S%C3%A3o%20Paulo/temperatures?from=2024-01-09T00:00:00Z&to=2024-01-10T00:00:00Z&interval=6h", nil)
+	req.SetPathValue("city", "São Paulo")
+	w := httptest.NewRecorder()
+
+	handler.HandleCityTemperatures(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"city":"São Paulo","from":"2024-01-09T00:00:00Z","to":"2024-01-10T00:00:00Z","interval":"6h0m0s",
+		"points":[{"time":"2024-01-09T00:00:00Z","count":2,"avg_temp_C":22,"min_temp_C":20,"max_temp_C":24}]}`, w.Body.String())
+	mockHistory.AssertExpectations(t)
+}
+
+func TestHistoryHandler_ErrorRates(t *testing.T) {
+	mockHistory := new(MockHistoryService)
+	handler := newTestHistoryHandler(mockHistory)
+
+	from := historyNow.Add(-DefaultHistoryWindow)
+	mockHistory.On("ErrorRates", mock.Anything, from, historyNow, DefaultHistoryInterval).
+		Return([]usecase.ErrorRatePoint{{Time: from, Total: 4, ClientErrors: 1, ServerErrors: 1, ErrorRate: 0.5}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/history/errors", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleErrorRates(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"from":"2024-01-09T12:00:00Z","to":"2024-01-10T12:00:00Z","interval":"1h0m0s",
+		"points":[{"time":"2024-01-09T12:00:00Z","total":4,"client_errors":1,"server_errors":1,"error_rate":0.5}]}`, w.Body.String())
+	mockHistory.AssertExpectations(t)
+}
+
+func TestHistoryHandler_InvalidQuery(t *testing.T) {
+	mockHistory := new(MockHistoryService)
+	handler := newTestHistoryHandler(mockHistory)
+
+	for _, rawQuery := range []string{"window=abc", "window=-1h", "from=yesterday", "interval=0s", "limit=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/history/errors?"+rawQuery, nil)
+		w := httptest.NewRecorder()
+
+		if rawQuery == "limit=0" {
+			handler.HandleTopCEPs(w, req)
+		} else {
+			handler.HandleErrorRates(w, req)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+		assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidHistoryQuery, "invalid history query"), w.Body.String())
+	}
+
+	// Regras validadas pelo caso de uso
+	mockHistory.On("ErrorRates", mock.Anything, mock.Anything, mock.Anything, time.Minute).
+		Return(nil, usecase.ErrInvalidHistoryQuery)
+	req := httptest.NewRequest(http.MethodGet, "/history/errors?window=30d&interval=1m", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleErrorRates(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockHistory.AssertExpectations(t)
+}
+
+func TestHistoryHandler_Export(t *testing.T) {
+	mockHistory := new(MockHistoryService)
+	handler := newTestHistoryHandler(mockHistory)
+
+	expectedOpts := historyexport.Options{
+		From:    historyNow.Add(-6 * time.Hour),
+		To:      historyNow,
+		Format:  historyexport.FormatCSV,
+		Columns: []string{"time", "cep", "temp_C"},
+		Gzip:    true,
+	}
+	mockHistory.On("Export", mock.Anything, mock.Anything, expectedOpts).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(io.Writer).Write([]byte("compressed"))
+		}).
+		Return(1, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/history/export?window=6h&columns=time,cep,temp_C&gzip=true", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleExport(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="history-20240110T060000Z-20240110T120000Z.csv.gz"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "compressed", w.Body.String())
+	mockHistory.AssertExpectations(t)
+}
+
+func TestHistoryHandler_ExportInvalidQuery(t *testing.T) {
+	mockHistory := new(MockHistoryService)
+	handler := newTestHistoryHandler(mockHistory)
+
+	for _, rawQuery := range []string{"format=xlsx", "columns=cep,password", "gzip=maybe", "window=1x"} {
+		req := httptest.NewRequest(http.MethodGet, "/history/export?"+rawQuery, nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleExport(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+		assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidHistoryQuery, "invalid history query"), w.Body.String())
+	}
+	mockHistory.AssertNotCalled(t, "Export", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCEPHandler_RecordsHistory(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	mockHistory := new(MockHistoryService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp, WithHistory(mockHistory))
+
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+	mockHistory.On("Record", mock.Anything, mock.MatchedBy(func(l repository.Lookup) bool {
+		return l.CEP == "01001000" && l.City == "São Paulo" && l.UF == "SP" &&
+			l.TempC != nil && *l.TempC == 28.5 && l.Status == http.StatusOK && l.Error == "" && !l.Time.IsZero()
+	})).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockHistory.AssertExpectations(t)
+}
+
+func TestCEPHandler_RecordsFailedLookup(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	mockHistory := new(MockHistoryService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp, WithHistory(mockHistory))
+
+	mockFetchCity.On("Fetch", mock.Anything, "99999999").Return("", repository.ErrCEPNotFound)
+	mockHistory.On("Record", mock.Anything, mock.MatchedBy(func(l repository.Lookup) bool {
+		return l.CEP == "99999999" && l.UF == "RS" && l.TempC == nil &&
+			l.Status == http.StatusNotFound && l.Error == repository.ErrCEPNotFound.Error()
+	})).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/99999999", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockHistory.AssertExpectations(t)
+}
@@ -1,30 +1,88 @@
 package delivery
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"service-b/internal/common"
 	"service-b/internal/repository"
 	"service-b/internal/usecase"
+	"shared/cep"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// FallbackHeader sinaliza que a temperatura é da capital do estado, e não da cidade do CEP,
+// porque a consulta da cidade falhou
+const FallbackHeader = "X-Weather-Fallback"
+
+// ProviderStateCapital identifica, no cabeçalho e no histórico, respostas com a temperatura da capital
+const ProviderStateCapital = "state-capital"
+
 // CEPHandler gerencia as requisições para buscar cidade e temperatura
 type CEPHandler struct {
 	fetchCity usecase.FetchCityService
 	fetchTemp usecase.FetchTempService
+	units     *usecase.UnitRegistry
+	history   usecase.HistoryService
+	v1Sunset  time.Time
+	cacheTTL  time.Duration
+}
+
+// CEPHandlerOption configura dependências opcionais do CEPHandler
+type CEPHandlerOption func(*CEPHandler)
+
+// WithUnitRegistry define o registro de unidades usado nas conversões de temperatura
+func WithUnitRegistry(units *usecase.UnitRegistry) CEPHandlerOption {
+	return func(h *CEPHandler) {
+		h.units = units
+	}
+}
+
+// WithHistory registra cada consulta de CEP no histórico
+func WithHistory(history usecase.HistoryService) CEPHandlerOption {
+	return func(h *CEPHandler) {
+		h.history = history
+	}
+}
+
+// WithV1Sunset informa, no cabeçalho Sunset das respostas v1, a data em que a v1 deixará de existir
+func WithV1Sunset(sunset time.Time) CEPHandlerOption {
+	return func(h *CEPHandler) {
+		h.v1Sunset = sunset
+	}
+}
+
+// WithCacheTTL informa o TTL do cache de cidades e clima, de onde vem o max-age das respostas;
+// sem ele, as respostas são enviadas com Cache-Control: no-cache
+func WithCacheTTL(ttl time.Duration) CEPHandlerOption {
+	return func(h *CEPHandler) {
+		h.cacheTTL = ttl
+	}
 }
 
 // NewCEPHandler cria um novo handler
-func NewCEPHandler(fetchCity usecase.FetchCityService, fetchTemp usecase.FetchTempService) *CEPHandler {
-	return &CEPHandler{fetchCity: fetchCity, fetchTemp: fetchTemp}
+func NewCEPHandler(fetchCity usecase.FetchCityService, fetchTemp usecase.FetchTempService, opts ...CEPHandlerOption) *CEPHandler {
+	h := &CEPHandler{
+		fetchCity: fetchCity,
+		fetchTemp: fetchTemp,
+		units:     usecase.NewUnitRegistry(usecase.KelvinOffsetSI),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-// Handle processa a requisição para buscar cidade e temperatura pelo CEP
+// Handle processa a requisição para buscar cidade e temperatura pelo CEP. A versão da resposta
+// vem do caminho (/v1/cep/{cep} ou /v2/cep/{cep}) ou, em /cep/{cep}, do cabeçalho Accept. As
+// respostas trazem ETag, Cache-Control e Last-Modified e atendem GETs condicionais com 304.
 func (h *CEPHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	log.Println("CEPHandler: Request received")
 
@@ -34,65 +92,181 @@ func (h *CEPHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	defer span.End()
 	log.Printf("CEPHandler: Received TraceID=%s", span.SpanContext().TraceID().String())
 
-	cep := r.URL.Path[len("/cep/"):]
-	log.Printf("CEPHandler: CEP received: %s", cep)
+	version, path := splitVersion(r)
+	code := path[len("/cep/"):]
+	log.Printf("CEPHandler: CEP received: %s (%s)", code, version)
+	span.SetAttributes(attribute.String("api_version", version))
+	if version == APIVersion1 {
+		h.setDeprecationHeaders(w, code)
+	}
 
-	// Validação do CEP
-	if len(cep) != 8 {
-		log.Printf("CEPHandler: Invalid CEP: %s", cep)
-		span.SetStatus(codes.Error, "Invalid CEP length")
-		h.writeErrorResponse(w, http.StatusUnprocessableEntity, "invalid zipcode")
+	// Registro da consulta no histórico, preenchido ao longo do processamento
+	ctx, lookup, record := h.startLookup(ctx, code)
+	if h.history != nil {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		w = recorder
+		defer func() { record(recorder.status) }()
+	}
+
+	state, problem := h.resolveCEP(ctx, code, lookup)
+	if problem != nil {
+		common.WriteProblem(ctx, w, problem)
 		return
 	}
-	span.SetAttributes(attribute.String("cep", cep))
 
-	// Buscar cidade pelo CEP
-	city, err := h.fetchCity.Fetch(ctx, cep)
+	// Campos opcionais, unidades e precisão escolhidos pelo cliente
+	opts, err := parseResponseOptions(r.URL.Query(), h.units)
 	if err != nil {
-		if err == repository.ErrCEPNotFound {
-			log.Printf("CEPHandler: CEP not found: %s", cep)
-			span.SetStatus(codes.Error, "CEP not found")
-			h.writeErrorResponse(w, http.StatusNotFound, "can not find zipcode")
-		} else {
-			log.Printf("CEPHandler: Error fetching city for CEP %s: %v", cep, err)
-			span.SetStatus(codes.Error, "Error fetching city")
-			h.writeErrorResponse(w, http.StatusInternalServerError, "error fetching city")
-		}
+		span.SetStatus(codes.Error, "Invalid response options")
+		lookup.Error = err.Error()
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	result, problem := h.fetchWeather(ctx, code, state, lookup)
+	if problem != nil {
+		common.WriteProblem(ctx, w, problem)
+		return
+	}
+
+	// Responder com a cidade e as temperaturas nas unidades escolhidas
+	if result.fallback != "" {
+		w.Header().Set(FallbackHeader, result.fallback)
+	}
+	policy := h.cachePolicy(result)
+	if version == APIVersion2 {
+		writeCacheableResponse(ctx, w, r, MediaTypeV2, buildWeatherResponseV2(code, state, result, opts), policy)
 		return
 	}
+	writeCacheableResponse(ctx, w, r, "", buildWeatherResponse(result.city, result.obs, opts), policy)
+}
+
+// cachePolicy deriva o max-age do tempo que a observação ainda fica no cache. Respostas da
+// capital do estado (fallback) não são reaproveitadas sem revalidação.
+func (h *CEPHandler) cachePolicy(result *weatherResult) cachePolicy {
+	policy := cachePolicy{lastModified: result.obs.LastUpdated}
+	if h.cacheTTL > 0 && result.fallback == "" {
+		age := time.Duration(0)
+		if !result.obs.FetchedAt.IsZero() {
+			age = time.Since(result.obs.FetchedAt)
+		}
+		policy.maxAge = max(h.cacheTTL-age, 0)
+	}
+	return policy
+}
+
+// weatherResult é a cidade consultada (ou a capital, no fallback) e as suas condições climáticas
+type weatherResult struct {
+	city     string
+	obs      *repository.WeatherObservation
+	fallback string
+}
+
+// startLookup prepara o registro da consulta no histórico. A função retornada grava o registro
+// com o status final e não faz nada quando o histórico está desabilitado.
+func (h *CEPHandler) startLookup(ctx context.Context, code string) (context.Context, *repository.Lookup, func(status int)) {
+	span := trace.SpanFromContext(ctx)
+	lookup := &repository.Lookup{Time: time.Now().UTC(), CEP: code, TraceID: span.SpanContext().TraceID().String()}
+	if h.history == nil {
+		return ctx, lookup, func(int) {}
+	}
+
+	ctx, provider := repository.WithProviderCapture(ctx)
+	return ctx, lookup, func(status int) {
+		lookup.Latency = time.Since(lookup.Time)
+		lookup.Status = status
+		if lookup.Provider == "" {
+			lookup.Provider = provider()
+		}
+		h.history.Record(ctx, *lookup)
+	}
+}
+
+// resolveCEP valida o CEP e resolve UF e região pela tabela de faixas, antes de qualquer chamada externa
+func (h *CEPHandler) resolveCEP(ctx context.Context, code string, lookup *repository.Lookup) (cep.State, *common.Problem) {
+	span := trace.SpanFromContext(ctx)
+
+	// Validação do CEP
+	if err := cep.Validate(code); err != nil {
+		log.Printf("CEPHandler: Invalid CEP: %s", code)
+		span.SetStatus(codes.Error, "Invalid CEP format")
+		lookup.Error = err.Error()
+		return cep.State{}, common.NewProblem(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode")
+	}
+	span.SetAttributes(attribute.String("cep", code))
+
+	state, err := cep.Resolve(code)
+	if errors.Is(err, cep.ErrUnassigned) {
+		log.Printf("CEPHandler: CEP %s is in an unassigned range", code)
+		span.SetStatus(codes.Error, "CEP in unassigned range")
+		lookup.Error = err.Error()
+		return cep.State{}, common.NewProblem(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")
+	}
+	span.SetAttributes(attribute.String("state", state.UF), attribute.String("region", string(state.Region)))
+	lookup.UF = state.UF
+	return state, nil
+}
+
+// fetchWeather busca a cidade pelo CEP e as condições climáticas da cidade; se a consulta da
+// cidade falhar, usa a capital do estado
+func (h *CEPHandler) fetchWeather(ctx context.Context, code string, state cep.State, lookup *repository.Lookup) (*weatherResult, *common.Problem) {
+	span := trace.SpanFromContext(ctx)
+
+	city, err := h.fetchCity.Fetch(ctx, code)
+	if errors.Is(err, repository.ErrCEPNotFound) {
+		log.Printf("CEPHandler: CEP not found: %s", code)
+		span.SetStatus(codes.Error, "CEP not found")
+		lookup.Error = err.Error()
+		return nil, common.NewProblem(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")
+	}
+	if err != nil {
+		log.Printf("CEPHandler: Error fetching city for CEP %s, falling back to %s capital: %v", code, state.UF, err)
+		span.RecordError(err)
+		span.SetAttributes(attribute.String("fallback", ProviderStateCapital))
+		lookup.Error = err.Error()
+		return h.fallbackToCapital(ctx, state, lookup)
+	}
 	span.SetAttributes(attribute.String("city", city))
+	lookup.City = city
 
-	// Buscar temperatura pela cidade
-	tempC, err := h.fetchTemp.Fetch(ctx, city)
+	// Buscar condições climáticas pela cidade
+	obs, err := h.fetchTemp.Fetch(ctx, city)
 	if err != nil {
 		log.Printf("CEPHandler: Error fetching temperature for city %s: %v", city, err)
 		span.SetStatus(codes.Error, "Error fetching temperature")
-		h.writeErrorResponse(w, http.StatusInternalServerError, "error fetching temperature")
-		return
+		lookup.Error = err.Error()
+		return nil, common.FromError(err, common.NewProblem(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching temperature"))
 	}
-	span.SetAttributes(attribute.Float64("temperature_celsius", tempC))
+	span.SetAttributes(attribute.Float64("temperature_celsius", obs.TempC))
+	lookup.TempC = &obs.TempC
+	return &weatherResult{city: city, obs: obs}, nil
+}
 
-	// Converter temperaturas
-	tempF := usecase.CelsiusToFahrenheit(tempC)
-	tempK := usecase.CelsiusToKelvin(tempC)
+// fallbackToCapital busca a temperatura da capital do estado quando a cidade do CEP
+// não pôde ser consultada. Se a capital também falhar, o erro original da cidade é reportado.
+func (h *CEPHandler) fallbackToCapital(ctx context.Context, state cep.State, lookup *repository.Lookup) (*weatherResult, *common.Problem) {
+	span := trace.SpanFromContext(ctx)
 
-	// Responder com a cidade e as temperaturas
-	response := map[string]interface{}{
-		"city":   city,
-		"temp_C": tempC,
-		"temp_F": tempF,
-		"temp_K": tempK,
+	obs, err := h.fetchTemp.Fetch(ctx, state.Capital)
+	if err != nil {
+		log.Printf("CEPHandler: Error fetching temperature for capital %s: %v", state.Capital, err)
+		span.SetStatus(codes.Error, "Error fetching city")
+		return nil, common.FromError(err, common.NewProblem(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching city"))
 	}
-	h.writeJSONResponse(w, http.StatusOK, response)
+	span.SetAttributes(attribute.String("city", state.Capital), attribute.Float64("temperature_celsius", obs.TempC))
+	lookup.City = state.Capital
+	lookup.TempC = &obs.TempC
+	lookup.Provider = ProviderStateCapital
+	return &weatherResult{city: state.Capital, obs: obs, fallback: ProviderStateCapital}, nil
 }
 
-func (h *CEPHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.WriteHeader(statusCode)
-	w.Write([]byte(`{"error": "` + message + `"}`))
+// statusRecorder guarda o código de status enviado ao cliente para o histórico
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
 }
 
-func (h *CEPHandler) writeJSONResponse(w http.ResponseWriter, statusCode int, response map[string]interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(response)
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
 }
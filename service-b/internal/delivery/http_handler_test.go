@@ -2,16 +2,27 @@ package delivery
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"service-b/internal/common"
+	"service-b/internal/i18n"
 	"service-b/internal/repository"
 	"service-b/internal/usecase"
+	"shared/encoder"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type MockFetchCityService struct {
@@ -27,9 +38,10 @@ type MockFetchTempService struct {
 	mock.Mock
 }
 
-func (m *MockFetchTempService) Fetch(ctx context.Context, city string) (float64, error) {
+func (m *MockFetchTempService) Fetch(ctx context.Context, city string) (*repository.WeatherObservation, error) {
 	args := m.Called(ctx, city)
-	return args.Get(0).(float64), args.Error(1)
+	obs, _ := args.Get(0).(*repository.WeatherObservation)
+	return obs, args.Error(1)
 }
 
 func TestCEPHandler_Success(t *testing.T) {
@@ -45,7 +57,7 @@ func TestCEPHandler_Success(t *testing.T) {
 
 	// Configuração dos mocks
 	mockFetchCity.On("Fetch", mock.Anything, cep).Return(expectedCity, nil)
-	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(expectedTempC, nil)
+	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(&repository.WeatherObservation{TempC: expectedTempC}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep, nil)
 	w := httptest.NewRecorder()
@@ -73,7 +85,7 @@ func TestCEPHandler_InvalidCEP(t *testing.T) {
 	handler.Handle(w, req)
 
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
-	expectedResponse := `{"error":"invalid zipcode"}`
+	expectedResponse := problemBody(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode")
 	assert.JSONEq(t, expectedResponse, w.Body.String())
 }
 
@@ -93,12 +105,37 @@ func TestCEPHandler_CEPNotFound(t *testing.T) {
 	handler.Handle(w, req)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
-	expectedResponse := `{"error":"can not find zipcode"}`
+	expectedResponse := problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode")
 	assert.JSONEq(t, expectedResponse, w.Body.String())
 
 	mockFetchCity.AssertExpectations(t)
 }
 
+func TestCEPHandler_FetchCityErrorFallsBackToCapital(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	cep := "29902555"
+	expectedError := fmt.Errorf("viacep unavailable")
+
+	// A cidade falha e a temperatura da capital do ES é usada
+	mockFetchCity.On("Fetch", mock.Anything, cep).Return("", expectedError)
+	mockFetchTemp.On("Fetch", mock.Anything, "Vitória").Return(&repository.WeatherObservation{TempC: 25}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep, nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "state-capital", w.Header().Get(FallbackHeader))
+	assert.JSONEq(t, `{"city":"Vitória","temp_C":25,"temp_F":77,"temp_K":298.15}`, w.Body.String())
+
+	mockFetchCity.AssertExpectations(t)
+	mockFetchTemp.AssertExpectations(t)
+}
+
 func TestCEPHandler_FetchCityError(t *testing.T) {
 	mockFetchCity := new(MockFetchCityService)
 	mockFetchTemp := new(MockFetchTempService)
@@ -107,8 +144,9 @@ func TestCEPHandler_FetchCityError(t *testing.T) {
 	cep := "01001000"
 	expectedError := fmt.Errorf("city not found")
 
-	// Configuração do mock para erro ao buscar cidade
+	// Configuração dos mocks: a cidade e a capital do estado falham
 	mockFetchCity.On("Fetch", mock.Anything, cep).Return("", expectedError)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(nil, fmt.Errorf("weatherapi unavailable"))
 
 	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep, nil)
 	w := httptest.NewRecorder()
@@ -116,10 +154,43 @@ func TestCEPHandler_FetchCityError(t *testing.T) {
 	handler.Handle(w, req)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	expectedResponse := `{"error":"error fetching city"}`
+	assert.Empty(t, w.Header().Get(FallbackHeader))
+	expectedResponse := problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching city")
 	assert.JSONEq(t, expectedResponse, w.Body.String())
 
 	mockFetchCity.AssertExpectations(t)
+	mockFetchTemp.AssertExpectations(t)
+}
+
+func TestCEPHandler_UnassignedRange(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	// Faixa sem UF: rejeitada sem consultar o ViaCEP
+	req := httptest.NewRequest(http.MethodGet, "/cep/00000100", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode"), w.Body.String())
+	mockFetchCity.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+}
+
+func TestCEPHandler_NonNumericCEP(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/0100100A", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusUnprocessableEntity, common.CodeInvalidZipcode, "invalid zipcode"), w.Body.String())
+	mockFetchCity.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
 }
 
 func TestCEPHandler_FetchTempError(t *testing.T) {
@@ -133,7 +204,7 @@ func TestCEPHandler_FetchTempError(t *testing.T) {
 
 	// Configuração dos mocks
 	mockFetchCity.On("Fetch", mock.Anything, cep).Return(expectedCity, nil)
-	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(0.0, expectedError)
+	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(nil, expectedError)
 
 	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep, nil)
 	w := httptest.NewRecorder()
@@ -141,9 +212,554 @@ func TestCEPHandler_FetchTempError(t *testing.T) {
 	handler.Handle(w, req)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	expectedResponse := `{"error":"error fetching temperature"}`
+	expectedResponse := problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching temperature")
+	assert.JSONEq(t, expectedResponse, w.Body.String())
+
+	mockFetchCity.AssertExpectations(t)
+	mockFetchTemp.AssertExpectations(t)
+}
+
+func TestCEPHandler_FetchTempTimeout(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(nil, fmt.Errorf("weatherapi: %w", context.DeadlineExceeded))
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusGatewayTimeout, common.CodeUpstreamTimeout, "upstream request timed out"), w.Body.String())
+}
+
+func TestCEPHandler_ProblemTraceID(t *testing.T) {
+	handler := NewCEPHandler(new(MockFetchCityService), new(MockFetchTempService))
+
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled})
+	req := httptest.NewRequest(http.MethodGet, "/cep/123", nil)
+	req = req.WithContext(trace.ContextWithSpanContext(req.Context(), spanCtx))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, common.ContentTypeProblem, w.Header().Get("Content-Type"))
+	var problem common.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "/problems/invalid-zipcode", problem.Type)
+	assert.Equal(t, common.CodeInvalidZipcode, problem.Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", problem.TraceID)
+}
+
+func TestCEPHandler_LocalizedProblem(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	handler := NewCEPHandler(mockFetchCity, new(MockFetchTempService))
+	mockFetchCity.On("Fetch", mock.Anything, "99999999").Return("", repository.ErrCEPNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/99999999", nil)
+	req = req.WithContext(i18n.WithLanguage(req.Context(), i18n.BrazilianPortuguese))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	// O código continua o mesmo; só título e detalhe são traduzidos
+	assert.JSONEq(t, `{"type":"/problems/zipcode-not-found","title":"CEP não encontrado","status":404,"detail":"CEP não encontrado","code":"ZIPCODE_NOT_FOUND"}`, w.Body.String())
+}
+
+func TestCEPHandler_DetailFull(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	cep := "01001000"
+	expectedCity := "São Paulo"
+	obs := &repository.WeatherObservation{
+		TempC:         28.5,
+		FeelsLikeC:    30,
+		Humidity:      70,
+		WindKph:       12.2,
+		WindDegree:    180,
+		WindDir:       "S",
+		PressureMb:    1012,
+		UV:            7,
+		ConditionText: "Partly cloudy",
+		ConditionCode: 1003,
+		LastUpdated:   time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC),
+	}
+
+	mockFetchCity.On("Fetch", mock.Anything, cep).Return(expectedCity, nil)
+	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(obs, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep+"?detail=full", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedResponse := `{
+		"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65,
+		"feelslike_C":30,"feelslike_F":86,"feelslike_K":303.15,
+		"humidity":70,
+		"wind_kph":12.2,"wind_degree":180,"wind_dir":"S",
+		"pressure_mb":1012,
+		"uv":7,
+		"condition":"Partly cloudy","condition_code":1003,
+		"observed_at":"2025-01-20T15:30:00Z",
+		"heat_index_C":31.67,"wind_chill_C":28.5,"dew_point_C":22.5,"humidex":38.29,"heat_risk":"caution"
+	}`
 	assert.JSONEq(t, expectedResponse, w.Body.String())
 
 	mockFetchCity.AssertExpectations(t)
 	mockFetchTemp.AssertExpectations(t)
 }
+
+func TestCEPHandler_SelectedFields(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	cep := "01001000"
+	expectedCity := "São Paulo"
+	obs := &repository.WeatherObservation{TempC: 28.5, Humidity: 70, ConditionText: "Sunny", ConditionCode: 1000}
+
+	mockFetchCity.On("Fetch", mock.Anything, cep).Return(expectedCity, nil)
+	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(obs, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep+"?fields=humidity,condition", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedResponse := `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65,"humidity":70,"condition":"Sunny","condition_code":1000}`
+	assert.JSONEq(t, expectedResponse, w.Body.String())
+}
+
+func TestCEPHandler_InvalidFields(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/01001000?fields=humidity,rainbow", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid fields parameter"), w.Body.String())
+
+	mockFetchCity.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+}
+
+func TestCEPHandler_ComfortFields(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	cep := "01001000"
+	expectedCity := "São Paulo"
+	obs := &repository.WeatherObservation{TempC: -10, Humidity: 0, WindKph: 20}
+
+	mockFetchCity.On("Fetch", mock.Anything, cep).Return(expectedCity, nil)
+	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(obs, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep+"?fields=comfort", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedResponse := `{"city":"São Paulo","temp_C":-10,"temp_F":14,"temp_K":263.15,"heat_index_C":-10,"wind_chill_C":-17.86,"heat_risk":"none"}`
+	assert.JSONEq(t, expectedResponse, w.Body.String())
+}
+
+func TestCEPHandler_SelectedUnits(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	cep := "01001000"
+	expectedCity := "São Paulo"
+
+	mockFetchCity.On("Fetch", mock.Anything, cep).Return(expectedCity, nil)
+	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(&repository.WeatherObservation{TempC: 28.57}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep+"?units=C,R,Re&precision=1", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedResponse := `{"city":"São Paulo","temp_C":28.6,"temp_R":543.1,"temp_Re":22.9}`
+	assert.JSONEq(t, expectedResponse, w.Body.String())
+}
+
+func TestCEPHandler_KelvinIntegerConvention(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp, WithUnitRegistry(usecase.NewUnitRegistry(usecase.KelvinOffsetInteger)))
+
+	cep := "01001000"
+	expectedCity := "São Paulo"
+
+	mockFetchCity.On("Fetch", mock.Anything, cep).Return(expectedCity, nil)
+	mockFetchTemp.On("Fetch", mock.Anything, expectedCity).Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/"+cep, nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.5}`, w.Body.String())
+}
+
+func TestCEPHandler_InvalidUnits(t *testing.T) {
+	tests := []string{
+		"/cep/01001000?units=C,X",
+		"/cep/01001000?precision=7",
+		"/cep/01001000?precision=two",
+	}
+
+	for _, target := range tests {
+		mockFetchCity := new(MockFetchCityService)
+		mockFetchTemp := new(MockFetchTempService)
+		handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid units parameter"), w.Body.String())
+		mockFetchCity.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+	}
+}
+
+// problemBody é o corpo esperado para o problema; sem span no contexto, não há trace_id
+func problemBody(status int, code, detail string) string {
+	body, _ := json.Marshal(common.NewProblem(status, code, detail))
+	return string(body)
+}
+
+func TestCEPHandler_V1DeprecationHeaders(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp, WithV1Sunset(sunset))
+
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/cep/01001000", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</v2/cep/01001000>; rel="successor-version"`, w.Header().Get("Link"))
+	assert.Equal(t, "Thu, 31 Dec 2026 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.JSONEq(t, `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65}`, w.Body.String())
+}
+
+func TestCEPHandler_V2(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	obs := &repository.WeatherObservation{
+		Country: "Brazil", Lat: -23.53, Lon: -46.62,
+		TempC: 28.5, FeelsLikeC: 30, Humidity: 70, WindKph: 12.2, WindDegree: 180, WindDir: "S",
+		ConditionText: "Partly cloudy", ConditionCode: 1003,
+		LastUpdated: time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC),
+	}
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/cep/01001000?fields=feelslike,humidity,wind,condition&units=C,F", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MediaTypeV2, w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	expectedResponse := `{
+		"cep":"01001000",
+		"location":{"city":"São Paulo","state":"SP","region":"Sudeste","country":"Brazil","lat":-23.53,"lon":-46.62},
+		"observed_at":"2025-01-20T15:30:00Z",
+		"temperatures":[{"unit":"C","value":28.5},{"unit":"F","value":83.3}],
+		"details":{
+			"feels_like":[{"unit":"C","value":30},{"unit":"F","value":86}],
+			"humidity":70,
+			"wind":{"speed_kph":12.2,"degree":180,"direction":"S"},
+			"condition":{"text":"Partly cloudy","code":1003}
+		}
+	}`
+	assert.JSONEq(t, expectedResponse, w.Body.String())
+}
+
+func TestCEPHandler_V2Fallback(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+
+	mockFetchCity.On("Fetch", mock.Anything, "29902555").Return("", errors.New("viacep unavailable"))
+	mockFetchTemp.On("Fetch", mock.Anything, "Vitória").Return(&repository.WeatherObservation{TempC: 30}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/cep/29902555?units=C", nil)
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ProviderStateCapital, w.Header().Get(FallbackHeader))
+	assert.JSONEq(t, `{
+		"cep":"29902555",
+		"location":{"city":"Vitória","state":"ES","region":"Sudeste","lat":0,"lon":0},
+		"temperatures":[{"unit":"C","value":30}],
+		"fallback":"state-capital"
+	}`, w.Body.String())
+}
+
+func TestCEPHandler_AcceptNegotiation(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		wantDeprecation string
+	}{
+		{"no accept", "", "application/json", "true"},
+		{"json", "application/json", "application/json", "true"},
+		{"v1", MediaTypeV1, "application/json", "true"},
+		{"v2", MediaTypeV2, MediaTypeV2, ""},
+		{"v2 among others", "text/html, application/vnd.weather.v2+json;q=0.9", MediaTypeV2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetchCity := new(MockFetchCityService)
+			mockFetchTemp := new(MockFetchTempService)
+			handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+			mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+			mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantDeprecation, w.Header().Get("Deprecation"))
+		})
+	}
+}
+
+func TestCEPHandler_EncodedFormats(t *testing.T) {
+	tests := []struct {
+		name            string
+		encoder         encoder.Encoder
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "xml", encoder: encoder.XML, wantContentType: "application/xml; charset=utf-8",
+			wantBody: xml.Header + "<response><city>São Paulo</city><temp_C>28.5</temp_C><temp_F>83.3</temp_F><temp_K>301.65</temp_K></response>\n",
+		},
+		{
+			name: "csv", encoder: encoder.CSV, wantContentType: "text/csv; charset=utf-8",
+			wantBody: "city,temp_C,temp_F,temp_K\nSão Paulo,28.5,83.3,301.65\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetchCity := new(MockFetchCityService)
+			mockFetchTemp := new(MockFetchTempService)
+			handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+			mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+			mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+			req = req.WithContext(encoder.WithEncoder(req.Context(), tt.encoder))
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestCEPHandler_V2Protobuf(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/cep/01001000?units=C", nil)
+	req = req.WithContext(encoder.WithEncoder(req.Context(), encoder.Protobuf))
+	w := httptest.NewRecorder()
+
+	handler.Handle(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, encoder.MediaTypeProtobuf, w.Header().Get("Content-Type"))
+	var value structpb.Value
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &value))
+	body, err := value.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"cep":"01001000",
+		"location":{"city":"São Paulo","state":"SP","region":"Sudeste","lat":0,"lon":0},
+		"temperatures":[{"unit":"C","value":28.5}]
+	}`, string(body))
+}
+
+func TestCEPHandler_NotAcceptable(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+
+	encoder.NewRegistry().Middleware(http.HandlerFunc(handler.Handle)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, problemBody(http.StatusNotAcceptable, common.CodeNotAcceptable, "no acceptable response format"), w.Body.String())
+}
+
+func TestCEPHandler_CacheHeaders(t *testing.T) {
+	tests := []struct {
+		name             string
+		cacheTTL         time.Duration
+		fetchedAt        time.Time
+		cityErr          error
+		wantCacheControl string
+	}{
+		{"fresh observation", 5 * time.Minute, time.Now(), nil, "public, max-age=299"},
+		{"aged observation", 5 * time.Minute, time.Now().Add(-2 * time.Minute), nil, "public, max-age=179"},
+		{"expired observation", 5 * time.Minute, time.Now().Add(-10 * time.Minute), nil, "no-cache"},
+		{"cache disabled", 0, time.Now(), nil, "no-cache"},
+		{"state capital fallback", 5 * time.Minute, time.Now(), errors.New("viacep unavailable"), "no-cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetchCity := new(MockFetchCityService)
+			mockFetchTemp := new(MockFetchTempService)
+			handler := NewCEPHandler(mockFetchCity, mockFetchTemp, WithCacheTTL(tt.cacheTTL))
+			obs := &repository.WeatherObservation{TempC: 28.5, LastUpdated: time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC), FetchedAt: tt.fetchedAt}
+			mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", tt.cityErr)
+			mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantCacheControl, w.Header().Get("Cache-Control"))
+			assert.Equal(t, strongETag(w.Body.Bytes()), w.Header().Get("ETag"))
+			assert.Equal(t, "Mon, 20 Jan 2025 15:30:00 GMT", w.Header().Get("Last-Modified"))
+		})
+	}
+}
+
+func TestCEPHandler_ConditionalGet(t *testing.T) {
+	lastUpdated := time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		header     func(etag string) http.Header
+		wantStatus int
+	}{
+		{"matching etag", func(etag string) http.Header { return http.Header{"If-None-Match": {etag}} }, http.StatusNotModified},
+		{"matching etag among others", func(etag string) http.Header { return http.Header{"If-None-Match": {`"stale", W/` + etag}} }, http.StatusNotModified},
+		{"any etag", func(string) http.Header { return http.Header{"If-None-Match": {"*"}} }, http.StatusNotModified},
+		{"stale etag", func(string) http.Header { return http.Header{"If-None-Match": {`"stale"`}} }, http.StatusOK},
+		{"not modified since", func(string) http.Header {
+			return http.Header{"If-Modified-Since": {lastUpdated.Format(http.TimeFormat)}}
+		}, http.StatusNotModified},
+		{"modified since", func(string) http.Header {
+			return http.Header{"If-Modified-Since": {lastUpdated.Add(-time.Minute).Format(http.TimeFormat)}}
+		}, http.StatusOK},
+		{"etag takes precedence", func(string) http.Header {
+			return http.Header{"If-None-Match": {`"stale"`}, "If-Modified-Since": {lastUpdated.Format(http.TimeFormat)}}
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFetchCity := new(MockFetchCityService)
+			mockFetchTemp := new(MockFetchTempService)
+			handler := NewCEPHandler(mockFetchCity, mockFetchTemp, WithCacheTTL(5*time.Minute))
+			obs := &repository.WeatherObservation{TempC: 28.5, LastUpdated: lastUpdated, FetchedAt: time.Now()}
+			mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+			mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(obs, nil)
+
+			first := httptest.NewRecorder()
+			handler.Handle(first, httptest.NewRequest(http.MethodGet, "/v2/cep/01001000", nil))
+			etag := first.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+
+			req := httptest.NewRequest(http.MethodGet, "/v2/cep/01001000", nil)
+			for key, values := range tt.header(etag) {
+				req.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.wantStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+				assert.Empty(t, w.Header().Get("Content-Type"))
+				assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+			} else {
+				assert.Equal(t, first.Body.String(), w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCEPHandler_ETagVariesWithRepresentation(t *testing.T) {
+	mockFetchCity := new(MockFetchCityService)
+	mockFetchTemp := new(MockFetchTempService)
+	handler := NewCEPHandler(mockFetchCity, mockFetchTemp)
+	mockFetchCity.On("Fetch", mock.Anything, "01001000").Return("São Paulo", nil)
+	mockFetchTemp.On("Fetch", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: 28.5}, nil)
+
+	etags := map[string]bool{}
+	for _, target := range []string{"/v1/cep/01001000", "/v2/cep/01001000", "/v1/cep/01001000?units=C"} {
+		w := httptest.NewRecorder()
+		handler.Handle(w, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		etags[w.Header().Get("ETag")] = true
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/cep/01001000", nil)
+	req = req.WithContext(encoder.WithEncoder(req.Context(), encoder.XML))
+	w := httptest.NewRecorder()
+	handler.Handle(w, req)
+	etags[w.Header().Get("ETag")] = true
+
+	assert.Len(t, etags, 4)
+}
@@ -0,0 +1,126 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LocationHandler gerencia as buscas reversas: coordenadas ou cidade para CEPs e clima
+type LocationHandler struct {
+	locations usecase.LocationService
+	units     *usecase.UnitRegistry
+}
+
+// NewLocationHandler cria um novo handler
+func NewLocationHandler(locations usecase.LocationService, units *usecase.UnitRegistry) *LocationHandler {
+	return &LocationHandler{locations: locations, units: units}
+}
+
+// HandleCoordinates processa GET /location?lat=&lon=[&street=]
+func (h *LocationHandler) HandleCoordinates(w http.ResponseWriter, r *http.Request) {
+	log.Println("LocationHandler: Coordinates request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-location-handler")
+	defer span.End()
+
+	query := r.URL.Query()
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lon, errLon := strconv.ParseFloat(query.Get("lon"), 64)
+	if errLat != nil || errLon != nil {
+		log.Printf("LocationHandler: Invalid coordinates: lat=%q lon=%q", query.Get("lat"), query.Get("lon"))
+		span.SetStatus(codes.Error, "Invalid coordinates")
+		writeErrorResponse(ctx, w, http.StatusUnprocessableEntity, common.CodeInvalidLocation, "invalid location")
+		return
+	}
+	span.SetAttributes(attribute.Float64("lat", lat), attribute.Float64("lon", lon))
+
+	opts, err := parseResponseOptions(query, h.units)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid response options")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	lookup, err := h.locations.ByCoordinates(ctx, lat, lon, query.Get("street"))
+	if err != nil {
+		h.writeLookupError(ctx, w, span, err)
+		return
+	}
+	h.writeLookupResponse(ctx, w, span, lookup, opts)
+}
+
+// HandleCity processa GET /city/{uf}/{name}[?street=]
+func (h *LocationHandler) HandleCity(w http.ResponseWriter, r *http.Request) {
+	log.Println("LocationHandler: City request received")
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "process-city-handler")
+	defer span.End()
+
+	uf := r.PathValue("uf")
+	name := r.PathValue("name")
+	span.SetAttributes(attribute.String("uf", uf), attribute.String("city", name))
+
+	opts, err := parseResponseOptions(r.URL.Query(), h.units)
+	if err != nil {
+		span.SetStatus(codes.Error, "Invalid response options")
+		writeErrorResponse(ctx, w, http.StatusBadRequest, common.CodeInvalidParameter, err.Error())
+		return
+	}
+
+	lookup, err := h.locations.ByCity(ctx, uf, name, r.URL.Query().Get("street"))
+	if err != nil {
+		h.writeLookupError(ctx, w, span, err)
+		return
+	}
+	h.writeLookupResponse(ctx, w, span, lookup, opts)
+}
+
+// writeLookupResponse responde no mesmo formato de /cep/{cep}, acrescido dos CEPs candidatos
+func (h *LocationHandler) writeLookupResponse(ctx context.Context, w http.ResponseWriter, span trace.Span, lookup *usecase.LocationLookup, opts responseOptions) {
+	span.SetAttributes(
+		attribute.String("city", lookup.City),
+		attribute.Int("candidate_ceps", len(lookup.CEPs)),
+		attribute.Float64("temperature_celsius", lookup.Weather.TempC),
+	)
+
+	response := buildWeatherResponse(lookup.City, lookup.Weather, opts)
+	if len(lookup.CEPs) > 0 {
+		response["ceps"] = lookup.CEPs
+	}
+	writeResponse(ctx, w, http.StatusOK, response)
+}
+
+// writeLookupError aplica a mesma semântica de /cep/{cep}: 422 para entrada inválida e 404
+// para localidade inexistente
+func (h *LocationHandler) writeLookupError(ctx context.Context, w http.ResponseWriter, span trace.Span, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidLocation):
+		log.Printf("LocationHandler: Invalid location: %v", err)
+		span.SetStatus(codes.Error, "Invalid location")
+		writeErrorResponse(ctx, w, http.StatusUnprocessableEntity, common.CodeInvalidLocation, "invalid location")
+	case errors.Is(err, repository.ErrLocationNotFound):
+		log.Printf("LocationHandler: Location not found: %v", err)
+		span.SetStatus(codes.Error, "Location not found")
+		writeErrorResponse(ctx, w, http.StatusNotFound, common.CodeLocationNotFound, "can not find location")
+	default:
+		log.Printf("LocationHandler: Error fetching location: %v", err)
+		span.SetStatus(codes.Error, "Error fetching location")
+		writeUpstreamError(ctx, w, err, "error fetching location")
+	}
+}
@@ -0,0 +1,135 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLocationService struct {
+	mock.Mock
+}
+
+func (m *MockLocationService) ByCoordinates(ctx context.Context, lat, lon float64, street string) (*usecase.LocationLookup, error) {
+	args := m.Called(ctx, lat, lon, street)
+	lookup, _ := args.Get(0).(*usecase.LocationLookup)
+	return lookup, args.Error(1)
+}
+
+func (m *MockLocationService) ByCity(ctx context.Context, uf, city, street string) (*usecase.LocationLookup, error) {
+	args := m.Called(ctx, uf, city, street)
+	lookup, _ := args.Get(0).(*usecase.LocationLookup)
+	return lookup, args.Error(1)
+}
+
+func newTestLocationHandler(locations usecase.LocationService) *LocationHandler {
+	return NewLocationHandler(locations, usecase.NewUnitRegistry(usecase.KelvinOffsetSI))
+}
+
+func TestLocationHandler_Coordinates(t *testing.T) {
+	mockLocations := new(MockLocationService)
+	handler := newTestLocationHandler(mockLocations)
+
+	lookup := &usecase.LocationLookup{City: "São Paulo", UF: "SP", Weather: &repository.WeatherObservation{TempC: 28.5}}
+	mockLocations.On("ByCoordinates", mock.Anything, -23.55, -46.63, "").Return(lookup, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/location?lat=-23.55&lon=-46.63", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleCoordinates(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65}`, w.Body.String())
+
+	mockLocations.AssertExpectations(t)
+}
+
+func TestLocationHandler_InvalidCoordinates(t *testing.T) {
+	tests := []struct {
+		target string
+		err    error
+	}{
+		{"/location?lat=abc&lon=-46.63", nil},
+		{"/location?lon=-46.63", nil},
+		{"/location?lat=95&lon=0", usecase.ErrInvalidLocation},
+	}
+
+	for _, test := range tests {
+		mockLocations := new(MockLocationService)
+		handler := newTestLocationHandler(mockLocations)
+		if test.err != nil {
+			mockLocations.On("ByCoordinates", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, test.err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, test.target, nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleCoordinates(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, test.target)
+		assert.JSONEq(t, problemBody(http.StatusUnprocessableEntity, common.CodeInvalidLocation, "invalid location"), w.Body.String())
+	}
+}
+
+func TestLocationHandler_City(t *testing.T) {
+	mockLocations := new(MockLocationService)
+	handler := newTestLocationHandler(mockLocations)
+
+	lookup := &usecase.LocationLookup{
+		City:    "São Paulo",
+		UF:      "SP",
+		CEPs:    []string{"01310100", "01310200"},
+		Weather: &repository.WeatherObservation{TempC: 28.5, Humidity: 70},
+	}
+	mockLocations.On("ByCity", mock.Anything, "SP", "São Paulo", "Paulista").Return(lookup, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/city/SP/S%C3%A3o%20Paulo?street=Paulista&fields=humidity", nil)
+	req.SetPathValue("uf", "SP")
+	req.SetPathValue("name", "São Paulo")
+	w := httptest.NewRecorder()
+
+	handler.HandleCity(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedResponse := `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3,"temp_K":301.65,"humidity":70,"ceps":["01310100","01310200"]}`
+	assert.JSONEq(t, expectedResponse, w.Body.String())
+
+	mockLocations.AssertExpectations(t)
+}
+
+func TestLocationHandler_CityErrors(t *testing.T) {
+	tests := []struct {
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{fmt.Errorf("%w: unknown UF", usecase.ErrInvalidLocation), http.StatusUnprocessableEntity, problemBody(http.StatusUnprocessableEntity, common.CodeInvalidLocation, "invalid location")},
+		{repository.ErrLocationNotFound, http.StatusNotFound, problemBody(http.StatusNotFound, common.CodeLocationNotFound, "can not find location")},
+		{fmt.Errorf("viacep unavailable"), http.StatusInternalServerError, problemBody(http.StatusInternalServerError, common.CodeUpstreamError, "error fetching location")},
+	}
+
+	for _, test := range tests {
+		mockLocations := new(MockLocationService)
+		handler := newTestLocationHandler(mockLocations)
+		mockLocations.On("ByCity", mock.Anything, "XX", "Cidade", "").Return(nil, test.err)
+
+		req := httptest.NewRequest(http.MethodGet, "/city/XX/Cidade", nil)
+		req.SetPathValue("uf", "XX")
+		req.SetPathValue("name", "Cidade")
+		w := httptest.NewRecorder()
+
+		handler.HandleCity(w, req)
+
+		assert.Equal(t, test.expectedCode, w.Code)
+		assert.JSONEq(t, test.expectedBody, w.Body.String())
+	}
+}
@@ -0,0 +1,98 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"service-b/internal/common"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"shared/encoder"
+)
+
+// responseOptions reúne os campos opcionais e as unidades pedidas na query
+type responseOptions struct {
+	fields map[string]bool
+	units  unitSelection
+}
+
+// parseResponseOptions interpreta detail, fields, units e precision.
+// O erro retornado já contém a mensagem exibida ao cliente.
+func parseResponseOptions(query url.Values, registry *usecase.UnitRegistry) (responseOptions, error) {
+	fields, err := parseDetailFields(query)
+	if err != nil {
+		log.Printf("Invalid fields parameter: %v", err)
+		return responseOptions{}, errors.New("invalid fields parameter")
+	}
+
+	units, err := parseUnitSelection(query, registry)
+	if err != nil {
+		log.Printf("Invalid units parameter: %v", err)
+		return responseOptions{}, errors.New("invalid units parameter")
+	}
+
+	return responseOptions{fields: fields, units: units}, nil
+}
+
+// buildWeatherResponse monta a resposta padrão {city,temp_*} acrescida dos campos opcionais
+func buildWeatherResponse(city string, obs *repository.WeatherObservation, opts responseOptions) map[string]interface{} {
+	response := map[string]interface{}{
+		"city": city,
+	}
+	opts.units.apply(response, "temp_", obs.TempC)
+	appendDetailFields(response, obs, opts.fields, opts.units)
+	return response
+}
+
+// writeErrorResponse responde com um problema RFC 7807 com o código estável e o trace ID do contexto
+func writeErrorResponse(ctx context.Context, w http.ResponseWriter, statusCode int, code, detail string) {
+	common.WriteProblem(ctx, w, common.NewProblem(statusCode, code, detail))
+}
+
+// writeUpstreamError responde às falhas das dependências: timeout e circuito aberto têm problemas
+// próprios (504 e 503); as demais viram um 500 com o detalhe informado
+func writeUpstreamError(ctx context.Context, w http.ResponseWriter, err error, detail string) {
+	common.WriteProblem(ctx, w, common.FromError(err, common.NewProblem(http.StatusInternalServerError, common.CodeUpstreamError, detail)))
+}
+
+// writeResponse codifica a resposta no formato negociado pelo Accept (JSON por padrão)
+func writeResponse(ctx context.Context, w http.ResponseWriter, statusCode int, response interface{}) {
+	writeResponseAs(ctx, w, statusCode, "", response)
+}
+
+// writeResponseAs faz o mesmo que writeResponse, trocando o Content-Type do JSON por
+// jsonContentType quando informado (ex.: o media type da v2). Sem formato aceitável, responde 406.
+func writeResponseAs(ctx context.Context, w http.ResponseWriter, statusCode int, jsonContentType string, response interface{}) {
+	body, ok := encodeResponse(ctx, w, jsonContentType, response)
+	if !ok {
+		return
+	}
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// encodeResponse codifica a resposta no formato negociado e define o Content-Type. Em caso de
+// falha, já responde com o problema e retorna false.
+func encodeResponse(ctx context.Context, w http.ResponseWriter, jsonContentType string, response interface{}) ([]byte, bool) {
+	enc, ok := encoder.FromContext(ctx)
+	if !ok {
+		log.Printf("No acceptable response format for the Accept header")
+		writeErrorResponse(ctx, w, http.StatusNotAcceptable, common.CodeNotAcceptable, "no acceptable response format")
+		return nil, false
+	}
+	body, err := encoder.Encode(enc, response)
+	if err != nil {
+		log.Printf("Error encoding response as %s: %v", enc.ContentType(), err)
+		writeErrorResponse(ctx, w, http.StatusInternalServerError, common.CodeInternal, "error encoding response")
+		return nil, false
+	}
+
+	contentType := enc.ContentType()
+	if jsonContentType != "" && encoder.IsJSON(enc) {
+		contentType = jsonContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	return body, true
+}
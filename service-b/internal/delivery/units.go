@@ -0,0 +1,57 @@
+package delivery
+
+import (
+	"fmt"
+	"net/url"
+	"service-b/internal/usecase"
+	"strconv"
+	"strings"
+)
+
+// unitSelection reúne as unidades e a precisão escolhidas pelo cliente
+type unitSelection struct {
+	registry  *usecase.UnitRegistry
+	units     []usecase.Unit
+	precision int
+}
+
+// parseUnitSelection interpreta os parâmetros units (ex.: units=C,F,R) e precision (0 a 6).
+// Sem parâmetros, mantém o comportamento original: Celsius, Fahrenheit e Kelvin com 2 casas.
+func parseUnitSelection(query url.Values, registry *usecase.UnitRegistry) (unitSelection, error) {
+	selection := unitSelection{registry: registry, precision: usecase.DefaultPrecision}
+
+	if raw := query.Get("precision"); raw != "" {
+		precision, err := strconv.Atoi(raw)
+		if err != nil || precision < 0 || precision > usecase.MaxPrecision {
+			return selection, fmt.Errorf("precision must be an integer between 0 and %d", usecase.MaxPrecision)
+		}
+		selection.precision = precision
+	}
+
+	symbols := usecase.DefaultUnits
+	if raw := query.Get("units"); raw != "" {
+		symbols = strings.Split(raw, ",")
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range symbols {
+		u, ok := registry.Lookup(strings.TrimSpace(s))
+		if !ok {
+			return selection, fmt.Errorf("unknown unit %q", s)
+		}
+		if seen[u.Symbol] {
+			continue
+		}
+		seen[u.Symbol] = true
+		selection.units = append(selection.units, u)
+	}
+	return selection, nil
+}
+
+// apply adiciona à resposta a temperatura em cada unidade escolhida, usando o prefixo
+// informado (ex.: "temp_" gera temp_C, temp_F...)
+func (s unitSelection) apply(response map[string]interface{}, prefix string, celsius float64) {
+	for _, u := range s.units {
+		response[prefix+u.Symbol] = usecase.RoundTo(u.FromCelsius(celsius), s.precision)
+	}
+}
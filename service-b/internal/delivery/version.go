@@ -0,0 +1,198 @@
+package delivery
+
+import (
+	"math"
+	"mime"
+	"net/http"
+	"service-b/internal/repository"
+	"service-b/internal/usecase"
+	"shared/cep"
+	"strings"
+	"time"
+)
+
+// Versões da resposta do GET /cep/{cep}
+const (
+	// APIVersion1 é o formato original {city,temp_C,temp_F,temp_K}, mantido para os consumidores
+	// existentes e marcado como obsoleto
+	APIVersion1 = "v1"
+	// APIVersion2 é a resposta tipada com localização estruturada, horário da observação e unidades
+	APIVersion2 = "v2"
+)
+
+// Media types que pedem uma versão específica pelo cabeçalho Accept
+const (
+	MediaTypeV1 = "application/vnd.weather.v1+json"
+	MediaTypeV2 = "application/vnd.weather.v2+json"
+)
+
+// splitVersion separa o prefixo de versão do caminho (/v1/cep/..., /v2/cep/...). Sem prefixo,
+// a versão é negociada pelo Accept e, na falta dele, é a v1.
+func splitVersion(r *http.Request) (version, path string) {
+	for _, v := range []string{APIVersion1, APIVersion2} {
+		if rest, ok := strings.CutPrefix(r.URL.Path, "/"+v+"/"); ok {
+			return v, "/" + rest
+		}
+	}
+	return acceptedVersion(r.Header.Get("Accept")), r.URL.Path
+}
+
+// acceptedVersion retorna a versão pedida pelo Accept. application/json, */* e media types
+// desconhecidos mantêm a v1.
+func acceptedVersion(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case MediaTypeV2:
+			return APIVersion2
+		case MediaTypeV1:
+			return APIVersion1
+		}
+	}
+	return APIVersion1
+}
+
+// setDeprecationHeaders marca a resposta v1 como obsoleta (Deprecation, RFC 9745) e aponta a
+// rota v2 equivalente; Sunset (RFC 8594) é enviado quando a data de desligamento foi definida
+func (h *CEPHandler) setDeprecationHeaders(w http.ResponseWriter, code string) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", "</"+APIVersion2+"/cep/"+code+`>; rel="successor-version"`)
+	if !h.v1Sunset.IsZero() {
+		w.Header().Set("Sunset", h.v1Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// WeatherResponseV2 é a resposta do GET /v2/cep/{cep}
+type WeatherResponseV2 struct {
+	CEP          string           `json:"cep"`
+	Location     LocationV2       `json:"location"`
+	ObservedAt   *time.Time       `json:"observed_at,omitempty"`
+	Temperatures []TemperatureV2  `json:"temperatures"`
+	Details      *WeatherDetailV2 `json:"details,omitempty"`
+	// Fallback é state-capital quando o clima é da capital do estado, como no X-Weather-Fallback
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// LocationV2 é a cidade consultada, com a UF resolvida pelo CEP e as coordenadas do provedor
+type LocationV2 struct {
+	City    string  `json:"city"`
+	State   string  `json:"state"`
+	Region  string  `json:"region"`
+	Country string  `json:"country,omitempty"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+// TemperatureV2 é uma temperatura na unidade indicada pelo símbolo (C, F, K, ...)
+type TemperatureV2 struct {
+	Unit  string  `json:"unit"`
+	Value float64 `json:"value"`
+}
+
+// WeatherDetailV2 reúne os campos opcionais pedidos em fields ou detail=full
+type WeatherDetailV2 struct {
+	FeelsLike  []TemperatureV2 `json:"feels_like,omitempty"`
+	Humidity   *int            `json:"humidity,omitempty"`
+	Wind       *WindV2         `json:"wind,omitempty"`
+	PressureMb *float64        `json:"pressure_mb,omitempty"`
+	UV         *float64        `json:"uv,omitempty"`
+	Condition  *ConditionV2    `json:"condition,omitempty"`
+	Comfort    *ComfortV2      `json:"comfort,omitempty"`
+}
+
+// WindV2 é o vento em km/h, com a direção em graus e em pontos cardeais
+type WindV2 struct {
+	SpeedKph  float64 `json:"speed_kph"`
+	Degree    int     `json:"degree"`
+	Direction string  `json:"direction"`
+}
+
+// ConditionV2 é a condição do tempo, com o texto no idioma da requisição
+type ConditionV2 struct {
+	Text string `json:"text"`
+	Code int    `json:"code"`
+}
+
+// ComfortV2 são os índices de conforto térmico em Celsius; sem umidade, ponto de orvalho e
+// humidex ficam de fora
+type ComfortV2 struct {
+	HeatIndexC float64  `json:"heat_index_C"`
+	WindChillC float64  `json:"wind_chill_C"`
+	HeatRisk   string   `json:"heat_risk"`
+	DewPointC  *float64 `json:"dew_point_C,omitempty"`
+	Humidex    *float64 `json:"humidex,omitempty"`
+}
+
+// buildWeatherResponseV2 monta a resposta tipada com as mesmas unidades, precisão e campos
+// opcionais da v1
+func buildWeatherResponseV2(code string, state cep.State, result *weatherResult, opts responseOptions) *WeatherResponseV2 {
+	obs := result.obs
+	response := &WeatherResponseV2{
+		CEP: code,
+		Location: LocationV2{
+			City:    result.city,
+			State:   state.UF,
+			Region:  string(state.Region),
+			Country: obs.Country,
+			Lat:     obs.Lat,
+			Lon:     obs.Lon,
+		},
+		Temperatures: temperaturesV2(opts.units, obs.TempC),
+		Details:      buildDetailV2(obs, opts),
+		Fallback:     result.fallback,
+	}
+	if !obs.LastUpdated.IsZero() {
+		observedAt := obs.LastUpdated.UTC()
+		response.ObservedAt = &observedAt
+	}
+	return response
+}
+
+func temperaturesV2(units unitSelection, celsius float64) []TemperatureV2 {
+	temps := make([]TemperatureV2, 0, len(units.units))
+	for _, u := range units.units {
+		temps = append(temps, TemperatureV2{Unit: u.Symbol, Value: usecase.RoundTo(u.FromCelsius(celsius), units.precision)})
+	}
+	return temps
+}
+
+// buildDetailV2 preenche os grupos de campos opcionais selecionados, como appendDetailFields.
+// observed_at já faz parte da resposta v2 e não é repetido.
+func buildDetailV2(obs *repository.WeatherObservation, opts responseOptions) *WeatherDetailV2 {
+	selected := opts.fields
+	if len(selected) == 0 || len(selected) == 1 && selected[fieldObservedAt] {
+		return nil
+	}
+
+	d := &WeatherDetailV2{}
+	if selected[fieldFeelsLike] {
+		d.FeelsLike = temperaturesV2(opts.units, obs.FeelsLikeC)
+	}
+	if selected[fieldHumidity] {
+		d.Humidity = ptr(obs.Humidity)
+	}
+	if selected[fieldWind] {
+		d.Wind = &WindV2{SpeedKph: obs.WindKph, Degree: obs.WindDegree, Direction: obs.WindDir}
+	}
+	if selected[fieldPressure] {
+		d.PressureMb = ptr(obs.PressureMb)
+	}
+	if selected[fieldUV] {
+		d.UV = ptr(obs.UV)
+	}
+	if selected[fieldCondition] {
+		d.Condition = &ConditionV2{Text: obs.ConditionText, Code: obs.ConditionCode}
+	}
+	if selected[fieldComfort] {
+		comfort := usecase.ComputeThermalComfort(obs)
+		d.Comfort = &ComfortV2{HeatIndexC: comfort.HeatIndexC, WindChillC: comfort.WindChillC, HeatRisk: string(comfort.Risk)}
+		if !math.IsNaN(comfort.DewPointC) {
+			d.Comfort.DewPointC = ptr(comfort.DewPointC)
+			d.Comfort.Humidex = ptr(comfort.Humidex)
+		}
+	}
+	return d
+}
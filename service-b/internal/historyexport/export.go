@@ -0,0 +1,290 @@
+// Package historyexport exporta o histórico de consultas de CEP em CSV ou Parquet, lendo os
+// registros um a um do armazenamento para que intervalos grandes não fiquem em memória.
+package historyexport
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"service-b/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format identifica o formato do arquivo exportado
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// parquetRowGroupSize limita as linhas mantidas em memória antes de gravar um row group
+const parquetRowGroupSize = 10000
+
+// ErrInvalidOptions indica formato ou colunas desconhecidos
+var ErrInvalidOptions = errors.New("invalid export options")
+
+// Options define o período, o formato, as colunas (todas quando vazio) e a compressão.
+// No CSV, Gzip comprime o arquivo inteiro; no Parquet, é o codec das colunas.
+type Options struct {
+	From    time.Time
+	To      time.Time
+	Format  Format
+	Columns []string
+	Gzip    bool
+}
+
+// column descreve uma coluna exportada: o tipo no Parquet e como extrair o valor do registro.
+// value retorna nil para valores ausentes (célula vazia no CSV, nulo no Parquet).
+type column struct {
+	name  string
+	node  parquet.Node
+	value func(repository.Lookup) any
+}
+
+var columns = []column{
+	{"time", parquet.Timestamp(parquet.Millisecond), func(l repository.Lookup) any { return l.Time.UTC() }},
+	{"cep", parquet.String(), func(l repository.Lookup) any { return l.CEP }},
+	{"city", parquet.String(), func(l repository.Lookup) any { return l.City }},
+	{"uf", parquet.String(), func(l repository.Lookup) any { return l.UF }},
+	{"temp_C", parquet.Optional(parquet.Leaf(parquet.DoubleType)), func(l repository.Lookup) any {
+		if l.TempC == nil {
+			return nil
+		}
+		return *l.TempC
+	}},
+	{"latency_ms", parquet.Leaf(parquet.DoubleType), func(l repository.Lookup) any {
+		return float64(l.Latency.Microseconds()) / 1000
+	}},
+	{"provider", parquet.String(), func(l repository.Lookup) any { return l.Provider }},
+	{"trace_id", parquet.String(), func(l repository.Lookup) any { return l.TraceID }},
+	{"status", parquet.Int(32), func(l repository.Lookup) any { return int32(l.Status) }},
+	{"error", parquet.String(), func(l repository.Lookup) any { return l.Error }},
+}
+
+// ColumnNames retorna os nomes de todas as colunas, na ordem padrão
+func ColumnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// ParseFormat valida o formato informado; vazio significa CSV
+func ParseFormat(raw string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatParquet:
+		return FormatParquet, nil
+	}
+	return "", fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, raw)
+}
+
+// ParseColumns converte a lista separada por vírgulas; vazio significa todas as colunas
+func ParseColumns(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return ColumnNames(), nil
+	}
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if _, err := lookupColumn(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ContentType retorna o tipo MIME do arquivo gerado
+func ContentType(opts Options) string {
+	if opts.Format == FormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	if opts.Gzip {
+		return "application/gzip"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName sugere o nome do arquivo gerado a partir do período
+func FileName(opts Options) string {
+	const layout = "20060102T150405Z"
+	name := fmt.Sprintf("history-%s-%s.%s", opts.From.UTC().Format(layout), opts.To.UTC().Format(layout), opts.Format)
+	if opts.Format == FormatCSV && opts.Gzip {
+		name += ".gz"
+	}
+	return name
+}
+
+// Write grava no writer os registros do período, lidos em ordem cronológica do repositório,
+// e retorna quantas linhas foram exportadas
+func Write(ctx context.Context, w io.Writer, repo repository.HistoryRepository, opts Options) (int, error) {
+	selected, err := selectColumns(opts.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	switch opts.Format {
+	case FormatCSV, "":
+		return writeCSV(ctx, w, repo, opts, selected)
+	case FormatParquet:
+		return writeParquet(ctx, w, repo, opts, selected)
+	}
+	return 0, fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, opts.Format)
+}
+
+func writeCSV(ctx context.Context, w io.Writer, repo repository.HistoryRepository, opts Options, selected []column) (int, error) {
+	var gz *gzip.Writer
+	if opts.Gzip {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	out := csv.NewWriter(w)
+	header := make([]string, len(selected))
+	for i, c := range selected {
+		header[i] = c.name
+	}
+	if err := out.Write(header); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	record := make([]string, len(selected))
+	err := repo.Iterate(ctx, opts.From, opts.To, func(l repository.Lookup) error {
+		for i, c := range selected {
+			record[i] = formatCSV(c.value(l))
+		}
+		rows++
+		return out.Write(record)
+	})
+	out.Flush()
+	if err == nil {
+		err = out.Error()
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	return rows, err
+}
+
+func formatCSV(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int32:
+		return strconv.Itoa(int(v))
+	}
+	return fmt.Sprint(v)
+}
+
+func writeParquet(ctx context.Context, w io.Writer, repo repository.HistoryRepository, opts Options, selected []column) (int, error) {
+	group := parquet.Group{}
+	for _, c := range selected {
+		group[c.name] = c.node
+	}
+	schema := parquet.NewSchema("lookup", group)
+
+	writerOpts := []parquet.WriterOption{schema, parquet.MaxRowsPerRowGroup(parquetRowGroupSize)}
+	if opts.Gzip {
+		writerOpts = append(writerOpts, parquet.Compression(&parquet.Gzip))
+	}
+	writer := parquet.NewWriter(w, writerOpts...)
+
+	// O schema ordena as colunas pelo nome; cada valor precisa do índice da sua coluna
+	fields := schema.Fields()
+	ordered := make([]column, len(fields))
+	for i, f := range fields {
+		ordered[i], _ = lookupColumn(f.Name())
+	}
+
+	rows := 0
+	batch := make([]parquet.Row, 0, 256)
+	flush := func() error {
+		if _, err := writer.WriteRows(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := repo.Iterate(ctx, opts.From, opts.To, func(l repository.Lookup) error {
+		row := make(parquet.Row, len(ordered))
+		for i, c := range ordered {
+			row[i] = parquetValue(c, c.value(l), i)
+		}
+		batch = append(batch, row)
+		rows++
+		if len(batch) == cap(batch) {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return rows, err
+	}
+	return rows, writer.Close()
+}
+
+// parquetValue posiciona o valor na coluna; em colunas opcionais, o nível de definição 1
+// indica valor presente e 0, nulo
+func parquetValue(c column, v any, columnIndex int) parquet.Value {
+	if v == nil {
+		return parquet.Value{}.Level(0, 0, columnIndex)
+	}
+	if t, ok := v.(time.Time); ok {
+		v = t.UnixMilli()
+	}
+	definitionLevel := 0
+	if c.node.Optional() {
+		definitionLevel = 1
+	}
+	return parquet.ValueOf(v).Level(0, definitionLevel, columnIndex)
+}
+
+func selectColumns(names []string) ([]column, error) {
+	if len(names) == 0 {
+		return columns, nil
+	}
+	selected := make([]column, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		c, err := lookupColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+func lookupColumn(name string) (column, error) {
+	for _, c := range columns {
+		if c.name == name {
+			return c, nil
+		}
+	}
+	return column{}, fmt.Errorf("%w: unknown column %q", ErrInvalidOptions, name)
+}
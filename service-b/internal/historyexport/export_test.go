@@ -0,0 +1,137 @@
+package historyexport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"service-b/internal/repository"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exportStart = time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestRepository(t *testing.T) repository.HistoryRepository {
+	t.Helper()
+	repo, err := repository.NewBoltHistoryRepository(filepath.Join(t.TempDir(), "history.db"), false)
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	temp := 28.5
+	lookups := []repository.Lookup{
+		{Time: exportStart, CEP: "01001000", City: "São Paulo", UF: "SP", TempC: &temp, Latency: 120 * time.Millisecond, Provider: "viacep", TraceID: "abc", Status: http.StatusOK},
+		{Time: exportStart.Add(time.Minute), CEP: "99999999", UF: "RS", Latency: 80 * time.Millisecond, Status: http.StatusNotFound, Error: "CEP not found"},
+		{Time: exportStart.Add(2 * time.Hour), CEP: "20040020", Status: http.StatusOK},
+	}
+	for _, l := range lookups {
+		require.NoError(t, repo.Record(context.Background(), l))
+	}
+	return repo
+}
+
+func TestWrite_CSV(t *testing.T) {
+	repo := newTestRepository(t)
+	var buf bytes.Buffer
+
+	rows, err := Write(context.Background(), &buf, repo, Options{
+		From:   exportStart,
+		To:     exportStart.Add(time.Hour),
+		Format: FormatCSV,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, "time,cep,city,uf,temp_C,latency_ms,provider,trace_id,status,error\n"+
+		"2024-01-10T12:00:00Z,01001000,São Paulo,SP,28.5,120,viacep,abc,200,\n"+
+		"2024-01-10T12:01:00Z,99999999,,RS,,80,,,404,CEP not found\n", buf.String())
+}
+
+func TestWrite_CSVColumnsAndGzip(t *testing.T) {
+	repo := newTestRepository(t)
+	var buf bytes.Buffer
+
+	rows, err := Write(context.Background(), &buf, repo, Options{
+		Format:  FormatCSV,
+		Columns: []string{"status", "cep"},
+		Gzip:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, rows)
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "status,cep\n200,01001000\n404,99999999\n200,20040020\n", string(content))
+}
+
+func TestWrite_Parquet(t *testing.T) {
+	repo := newTestRepository(t)
+	var buf bytes.Buffer
+
+	rows, err := Write(context.Background(), &buf, repo, Options{
+		From:    exportStart,
+		To:      exportStart.Add(time.Hour),
+		Format:  FormatParquet,
+		Columns: []string{"time", "cep", "temp_C", "status"},
+		Gzip:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, rows)
+
+	type row struct {
+		Time   time.Time `parquet:"time,timestamp(millisecond)"`
+		CEP    string    `parquet:"cep"`
+		TempC  *float64  `parquet:"temp_C,optional"`
+		Status int32     `parquet:"status"`
+	}
+	reader := parquet.NewGenericReader[row](bytes.NewReader(buf.Bytes()))
+	defer reader.Close()
+
+	got := make([]row, 2)
+	n, err := reader.Read(got)
+	if err != io.EOF {
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, n)
+	assert.Equal(t, "01001000", got[0].CEP)
+	assert.True(t, got[0].Time.Equal(exportStart))
+	require.NotNil(t, got[0].TempC)
+	assert.Equal(t, 28.5, *got[0].TempC)
+	assert.Equal(t, int32(200), got[0].Status)
+	assert.Equal(t, "99999999", got[1].CEP)
+	assert.Nil(t, got[1].TempC)
+	assert.Equal(t, int32(404), got[1].Status)
+}
+
+func TestParseOptions(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = ParseFormat("Parquet")
+	require.NoError(t, err)
+	assert.Equal(t, FormatParquet, format)
+
+	_, err = ParseFormat("xlsx")
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	names, err := ParseColumns("")
+	require.NoError(t, err)
+	assert.Equal(t, ColumnNames(), names)
+
+	names, err = ParseColumns("cep, status")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cep", "status"}, names)
+
+	_, err = ParseColumns("cep,password")
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
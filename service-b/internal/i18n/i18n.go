@@ -0,0 +1,153 @@
+// Package i18n negocia o idioma das respostas pelo Accept-Language e traduz as mensagens
+// exibidas aos clientes (pt-BR, en e es). Os códigos de erro não são traduzidos.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/text/language"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Idiomas suportados
+var (
+	English             = language.English
+	BrazilianPortuguese = language.BrazilianPortuguese
+	Spanish             = language.Spanish
+)
+
+var supported = []language.Tag{English, BrazilianPortuguese, Spanish}
+
+// MetadataKey é a chave do idioma nos metadados gRPC, equivalente ao cabeçalho Accept-Language
+const MetadataKey = "accept-language"
+
+type languageKey struct{}
+
+// WithLanguage guarda o idioma negociado no contexto
+func WithLanguage(ctx context.Context, tag language.Tag) context.Context {
+	return context.WithValue(ctx, languageKey{}, tag)
+}
+
+// FromContext retorna o idioma do contexto, ou inglês quando nenhum foi negociado
+// (ex.: verificações em segundo plano)
+func FromContext(ctx context.Context) language.Tag {
+	if tag, ok := ctx.Value(languageKey{}).(language.Tag); ok {
+		return tag
+	}
+	return English
+}
+
+// Message traduz a mensagem para o idioma do contexto. key é o texto em inglês (formato do
+// fmt quando há args); mensagens fora do catálogo são retornadas em inglês.
+func Message(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(FromContext(ctx), key, args...)
+}
+
+// Translate traduz a mensagem para o idioma informado
+func Translate(tag language.Tag, key string, args ...interface{}) string {
+	format := key
+	if translated, ok := catalog[tag][key]; ok {
+		format = translated
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// WeatherAPILang retorna o parâmetro lang da WeatherAPI para o idioma, ou "" para inglês,
+// que é o idioma padrão do provedor
+func WeatherAPILang(tag language.Tag) string {
+	switch tag {
+	case BrazilianPortuguese:
+		return "pt"
+	case Spanish:
+		return "es"
+	default:
+		return ""
+	}
+}
+
+// Negotiator escolhe, entre os idiomas suportados, o que melhor atende ao Accept-Language
+type Negotiator struct {
+	tags    []language.Tag
+	matcher language.Matcher
+}
+
+// NewNegotiator cria o Negotiator com o idioma usado quando o cliente não informa nenhum
+// suportado (ex.: "en", "pt-BR" ou "es")
+func NewNegotiator(defaultLanguage string) (*Negotiator, error) {
+	tag, err := language.Parse(defaultLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("parse default language: %w", err)
+	}
+	_, index, confidence := language.NewMatcher(supported).Match(tag)
+	if confidence == language.No {
+		return nil, fmt.Errorf("unsupported default language %q", defaultLanguage)
+	}
+
+	tags := []language.Tag{supported[index]}
+	for _, t := range supported {
+		if t != supported[index] {
+			tags = append(tags, t)
+		}
+	}
+	return &Negotiator{tags: tags, matcher: language.NewMatcher(tags)}, nil
+}
+
+// Match retorna o idioma suportado para o valor do Accept-Language
+func (n *Negotiator) Match(acceptLanguage string) language.Tag {
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return n.tags[0]
+	}
+	_, index, _ := n.matcher.Match(preferred...)
+	return n.tags[index]
+}
+
+// Middleware guarda o idioma negociado no contexto da requisição e o informa em Content-Language
+func (n *Negotiator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag := n.Match(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", tag.String())
+		next.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), tag)))
+	})
+}
+
+// UnaryServerInterceptor negocia o idioma pelos metadados accept-language das chamadas gRPC
+func (n *Negotiator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(n.withIncomingLanguage(ctx), req)
+	}
+}
+
+// StreamServerInterceptor faz o mesmo que UnaryServerInterceptor para os streams
+func (n *Negotiator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &languageStream{ServerStream: ss, ctx: n.withIncomingLanguage(ss.Context())})
+	}
+}
+
+func (n *Negotiator) withIncomingLanguage(ctx context.Context) context.Context {
+	var accept string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataKey); len(values) > 0 {
+			accept = values[0]
+		}
+	}
+	return WithLanguage(ctx, n.Match(accept))
+}
+
+// languageStream troca o contexto do stream pelo contexto com o idioma
+type languageStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *languageStream) Context() context.Context {
+	return s.ctx
+}
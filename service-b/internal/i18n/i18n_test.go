@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNegotiator_Match(t *testing.T) {
+	negotiator, err := NewNegotiator("en")
+	require.NoError(t, err)
+
+	tests := []struct {
+		accept string
+		want   language.Tag
+	}{
+		{"", English},
+		{"pt-BR,pt;q=0.9,en;q=0.8", BrazilianPortuguese},
+		{"pt", BrazilianPortuguese},
+		{"pt-PT", BrazilianPortuguese},
+		{"es-AR", Spanish},
+		{"fr-FR,es;q=0.5", Spanish},
+		{"de", English},
+		{"en;q=0.1,es;q=0.9", Spanish},
+		{"not a language;;", English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			assert.Equal(t, tt.want, negotiator.Match(tt.accept))
+		})
+	}
+}
+
+func TestNewNegotiator_Default(t *testing.T) {
+	negotiator, err := NewNegotiator("pt-BR")
+	require.NoError(t, err)
+	assert.Equal(t, BrazilianPortuguese, negotiator.Match(""))
+	assert.Equal(t, BrazilianPortuguese, negotiator.Match("de"))
+	assert.Equal(t, English, negotiator.Match("en-US"))
+
+	_, err = NewNegotiator("xx-invalid-tag-!")
+	assert.Error(t, err)
+	_, err = NewNegotiator("ja")
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	negotiator, err := NewNegotiator("en")
+	require.NoError(t, err)
+
+	var got language.Tag
+	handler := negotiator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+	req.Header.Set("Accept-Language", "es")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, Spanish, got)
+	assert.Equal(t, "es", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "CEP inválido", Translate(BrazilianPortuguese, "invalid zipcode"))
+	assert.Equal(t, "código postal no encontrado", Translate(Spanish, "can not find zipcode"))
+	assert.Equal(t, "parâmetro lat inválido", Translate(BrazilianPortuguese, "invalid %s parameter", "lat"))
+	assert.Equal(t, "invalid lat parameter", Translate(English, "invalid %s parameter", "lat"))
+	// Mensagens fora do catálogo ficam em inglês
+	assert.Equal(t, "something else", Translate(Spanish, "something else"))
+}
+
+func TestMessage_DefaultsToEnglish(t *testing.T) {
+	assert.Equal(t, "invalid zipcode", Message(context.Background(), "invalid zipcode"))
+	ctx := WithLanguage(context.Background(), BrazilianPortuguese)
+	assert.Equal(t, "CEP não encontrado", Message(ctx, "can not find zipcode"))
+}
+
+func TestCatalogsCoverTheSameKeys(t *testing.T) {
+	for key := range catalog[BrazilianPortuguese] {
+		assert.Contains(t, catalog[Spanish], key)
+	}
+	for key := range catalog[Spanish] {
+		assert.Contains(t, catalog[BrazilianPortuguese], key)
+	}
+}
+
+func TestWeatherAPILang(t *testing.T) {
+	assert.Equal(t, "pt", WeatherAPILang(BrazilianPortuguese))
+	assert.Equal(t, "es", WeatherAPILang(Spanish))
+	assert.Equal(t, "", WeatherAPILang(English))
+}
+
+func TestWithIncomingLanguage(t *testing.T) {
+	negotiator, err := NewNegotiator("en")
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "pt-BR"))
+	assert.Equal(t, BrazilianPortuguese, FromContext(negotiator.withIncomingLanguage(ctx)))
+	assert.Equal(t, English, FromContext(negotiator.withIncomingLanguage(context.Background())))
+}
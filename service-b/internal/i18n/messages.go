@@ -0,0 +1,121 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// catalog traduz as mensagens exibidas aos clientes, indexadas pelo texto em inglês
+var catalog = map[language.Tag]map[string]string{
+	BrazilianPortuguese: {
+		// Títulos dos problemas
+		"Invalid zipcode":        "CEP inválido",
+		"Zipcode not found":      "CEP não encontrado",
+		"Invalid location":       "Localização inválida",
+		"Location not found":     "Localização não encontrada",
+		"Invalid address search": "Busca de endereço inválida",
+		"Invalid parameter":      "Parâmetro inválido",
+		"Invalid request body":   "Corpo da requisição inválido",
+		"Unsupported media type": "Tipo de conteúdo não suportado",
+		"Not acceptable":         "Formato de resposta não aceitável",
+		"Invalid subscription":   "Inscrição inválida",
+		"Subscription not found": "Inscrição não encontrada",
+		"Invalid history query":  "Consulta ao histórico inválida",
+		"Unauthorized":           "Não autenticado",
+		"Forbidden":              "Não autorizado",
+		"Upstream timeout":       "Tempo esgotado na dependência",
+		"Upstream circuit open":  "Circuito aberto para a dependência",
+		"Upstream error":         "Erro na dependência",
+		"Internal error":         "Erro interno",
+
+		// Detalhes
+		"invalid zipcode":                          "CEP inválido",
+		"can not find zipcode":                     "CEP não encontrado",
+		"invalid location":                         "localização inválida",
+		"can not find location":                    "localização não encontrada",
+		"invalid address search":                   "busca de endereço inválida",
+		"invalid %s parameter":                     "parâmetro %s inválido",
+		"invalid days parameter":                   "parâmetro days inválido",
+		"invalid fields parameter":                 "parâmetro fields inválido",
+		"invalid units parameter":                  "parâmetro units inválido",
+		"invalid pagination parameters":            "parâmetros de paginação inválidos",
+		"invalid request":                          "requisição inválida",
+		"invalid request body":                     "corpo da requisição inválido",
+		"unsupported content type":                 "tipo de conteúdo não suportado",
+		"no acceptable response format":            "nenhum formato de resposta aceitável",
+		"error encoding response":                  "erro ao codificar a resposta",
+		"invalid subscription":                     "inscrição inválida",
+		"can not find subscription":                "inscrição não encontrada",
+		"invalid history query":                    "consulta ao histórico inválida",
+		"invalid internal subject":                 "identificação interna inválida",
+		"missing internal subject":                 "identificação interna ausente",
+		"subject does not allow the %s operation":  "o autenticado não tem permissão para a operação %s",
+		"upstream request timed out":               "a dependência não respondeu a tempo",
+		"upstream circuit open":                    "circuito aberto para a dependência",
+		"error fetching city":                      "erro ao consultar a cidade",
+		"error fetching temperature":               "erro ao consultar a temperatura",
+		"error fetching forecast":                  "erro ao consultar a previsão",
+		"error fetching location":                  "erro ao consultar a localização",
+		"error searching addresses":                "erro ao buscar endereços",
+		"error creating subscription":              "erro ao criar a inscrição",
+		"error listing subscriptions":              "erro ao listar as inscrições",
+		"error deleting subscription":              "erro ao remover a inscrição",
+		"error listing dead letters":               "erro ao listar os eventos não entregues",
+		"error reading history":                    "erro ao ler o histórico",
+		"response does not match the api contract": "a resposta não segue o contrato da API",
+	},
+	Spanish: {
+		// Títulos dos problemas
+		"Invalid zipcode":        "Código postal inválido",
+		"Zipcode not found":      "Código postal no encontrado",
+		"Invalid location":       "Ubicación inválida",
+		"Location not found":     "Ubicación no encontrada",
+		"Invalid address search": "Búsqueda de dirección inválida",
+		"Invalid parameter":      "Parámetro inválido",
+		"Invalid request body":   "Cuerpo de la solicitud inválido",
+		"Unsupported media type": "Tipo de contenido no soportado",
+		"Not acceptable":         "Formato de respuesta no aceptable",
+		"Invalid subscription":   "Suscripción inválida",
+		"Subscription not found": "Suscripción no encontrada",
+		"Invalid history query":  "Consulta de historial inválida",
+		"Unauthorized":           "No autenticado",
+		"Forbidden":              "No autorizado",
+		"Upstream timeout":       "Tiempo agotado en la dependencia",
+		"Upstream circuit open":  "Circuito abierto para la dependencia",
+		"Upstream error":         "Error en la dependencia",
+		"Internal error":         "Error interno",
+
+		// Detalhes
+		"invalid zipcode":                          "código postal inválido",
+		"can not find zipcode":                     "código postal no encontrado",
+		"invalid location":                         "ubicación inválida",
+		"can not find location":                    "ubicación no encontrada",
+		"invalid address search":                   "búsqueda de dirección inválida",
+		"invalid %s parameter":                     "parámetro %s inválido",
+		"invalid days parameter":                   "parámetro days inválido",
+		"invalid fields parameter":                 "parámetro fields inválido",
+		"invalid units parameter":                  "parámetro units inválido",
+		"invalid pagination parameters":            "parámetros de paginación inválidos",
+		"invalid request":                          "solicitud inválida",
+		"invalid request body":                     "cuerpo de la solicitud inválido",
+		"unsupported content type":                 "tipo de contenido no soportado",
+		"no acceptable response format":            "ningún formato de respuesta aceptable",
+		"error encoding response":                  "error al codificar la respuesta",
+		"invalid subscription":                     "suscripción inválida",
+		"can not find subscription":                "suscripción no encontrada",
+		"invalid history query":                    "consulta de historial inválida",
+		"invalid internal subject":                 "identificación interna inválida",
+		"missing internal subject":                 "identificación interna ausente",
+		"subject does not allow the %s operation":  "el autenticado no tiene permiso para la operación %s",
+		"upstream request timed out":               "la dependencia no respondió a tiempo",
+		"upstream circuit open":                    "circuito abierto para la dependencia",
+		"error fetching city":                      "error al consultar la ciudad",
+		"error fetching temperature":               "error al consultar la temperatura",
+		"error fetching forecast":                  "error al consultar el pronóstico",
+		"error fetching location":                  "error al consultar la ubicación",
+		"error searching addresses":                "error al buscar direcciones",
+		"error creating subscription":              "error al crear la suscripción",
+		"error listing subscriptions":              "error al listar las suscripciones",
+		"error deleting subscription":              "error al eliminar la suscripción",
+		"error listing dead letters":               "error al listar los eventos no entregados",
+		"error reading history":                    "error al leer el historial",
+		"response does not match the api contract": "la respuesta no cumple el contrato de la API",
+	},
+}
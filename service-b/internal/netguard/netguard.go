@@ -0,0 +1,89 @@
+// Package netguard impede que URLs informadas pelos clientes (ex.: webhooks de alerta) alcancem
+// a rede interna do serviço: loopback, redes privadas, link-local (inclusive os endereços de
+// metadados das nuvens) e multicast. A checagem é feita ao validar a URL e, de novo, na conexão,
+// com o endereço efetivamente resolvido, o que cobre DNS que muda entre as duas (DNS rebinding).
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress indica um destino na rede interna, que não pode ser usado
+var ErrBlockedAddress = errors.New("address not allowed")
+
+// blockedPrefixes são as faixas bloqueadas além das classificadas pelo netip: "esta rede" e o
+// espaço compartilhado do CGNAT, que inclui o endpoint de metadados da Alibaba Cloud
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Blocked indica se o endereço está na rede interna. Cobre 169.254.169.254 (link-local) e
+// fd00:ec2::254 (privado), usados pelos serviços de metadados das nuvens.
+func Blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolver resolve os endereços de um host (net.DefaultResolver serve)
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// CheckHost resolve o host (ou interpreta o IP literal) e recusa com ErrBlockedAddress se algum
+// dos endereços estiver na rede interna
+func CheckHost(ctx context.Context, resolver Resolver, host string) error {
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		addrs, err = resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", host, err)
+		}
+	}
+	for _, addr := range addrs {
+		if Blocked(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// control recusa a conexão quando o endereço resolvido está na rede interna (net.Dialer.Control)
+func control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if Blocked(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewTransport cria um http.Transport, com os mesmos limites do http.DefaultTransport, que só
+// conecta a endereços públicos. Não usa proxy, que faria a conexão no lugar do transporte.
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: control}
+	transport.DialContext = dialer.DialContext
+	return transport
+}
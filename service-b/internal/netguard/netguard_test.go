@@ -0,0 +1,81 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver map[string][]netip.Addr
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func TestBlocked(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.0.10", true},
+		{"169.254.169.254", true},
+		{"fd00:ec2::254", true},
+		{"fe80::1", true},
+		{"100.100.100.200", true},
+		{"224.0.0.1", true},
+		{"ff02::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.blocked, Blocked(netip.MustParseAddr(tt.addr)), tt.addr)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	resolver := fakeResolver{
+		"example.com":  {netip.MustParseAddr("93.184.216.34")},
+		"internal.com": {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")},
+	}
+
+	assert.NoError(t, CheckHost(context.Background(), resolver, "example.com"))
+	assert.NoError(t, CheckHost(context.Background(), resolver, "93.184.216.34"))
+	assert.ErrorIs(t, CheckHost(context.Background(), resolver, "internal.com"), ErrBlockedAddress)
+	assert.ErrorIs(t, CheckHost(context.Background(), resolver, "169.254.169.254"), ErrBlockedAddress)
+	assert.ErrorIs(t, CheckHost(context.Background(), resolver, "::1"), ErrBlockedAddress)
+
+	err := CheckHost(context.Background(), resolver, "unknown.com")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrBlockedAddress)
+}
+
+func TestNewTransport_RefusesInternalAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport()}
+	_, err := client.Get(server.URL)
+
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+	assert.False(t, called)
+}
@@ -0,0 +1,42 @@
+// Package openapi embute o contrato OpenAPI do serviço B. O documento é servido e validado
+// pelo pacote shared/openapi, com os erros no formato de problema do serviço.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"service-b/internal/common"
+	contract "shared/openapi"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// Validator, ValidatorOption, WithResponseValidation e NewSpecHandler são os do shared/openapi
+type (
+	Validator       = contract.Validator
+	ValidatorOption = contract.ValidatorOption
+)
+
+var (
+	WithResponseValidation = contract.WithResponseValidation
+	NewSpecHandler         = contract.NewSpecHandler
+)
+
+// Load carrega o documento embutido
+func Load() (*openapi3.T, error) {
+	return contract.Load(specYAML)
+}
+
+// NewValidator cria o validador do documento
+func NewValidator(doc *openapi3.T, opts ...ValidatorOption) (*Validator, error) {
+	opts = append([]ValidatorOption{contract.WithTracerName("service-b")}, opts...)
+	return contract.NewValidator(doc, writeProblem, opts...)
+}
+
+func writeProblem(ctx context.Context, w http.ResponseWriter, status int, code, format string, args ...interface{}) {
+	common.WriteProblem(ctx, w, common.NewProblemf(status, code, format, args...))
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"service-b/internal/common"
+	"service-b/internal/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValidator(t *testing.T, opts ...ValidatorOption) *Validator {
+	t.Helper()
+	doc, err := Load()
+	require.NoError(t, err)
+	validator, err := NewValidator(doc, opts...)
+	require.NoError(t, err)
+	return validator
+}
+
+// jsonHandler responde sempre com o mesmo status e corpo JSON
+func jsonHandler(status int, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	})
+}
+
+func TestSpecHandler(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	handler, err := NewSpecHandler(doc)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "3.0.3", body["openapi"])
+	assert.Contains(t, body["paths"], "/cep/{cep}")
+}
+
+func TestMiddleware_InvalidParameter(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	handler := newTestValidator(t).Middleware(next)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cep/01001000?precision=abc", nil))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, common.ContentTypeProblem, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid precision parameter"), w.Body.String())
+}
+
+func TestMiddleware_LocalizedParameterError(t *testing.T) {
+	handler := newTestValidator(t).Middleware(jsonHandler(http.StatusOK, `{}`))
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/01001000?precision=abc", nil)
+	req = req.WithContext(i18n.WithLanguage(req.Context(), i18n.Spanish))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"type":"/problems/invalid-parameter","title":"Parámetro inválido","status":400,"detail":"parámetro precision inválido","code":"INVALID_PARAMETER"}`, w.Body.String())
+}
+
+func TestMiddleware_OutOfRangeParameter(t *testing.T) {
+	handler := newTestValidator(t).Middleware(jsonHandler(http.StatusOK, `{}`))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cep/01001000/forecast?days=15", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidParameter, "invalid days parameter"), w.Body.String())
+}
+
+func TestMiddleware_UnknownRoutePassesThrough(t *testing.T) {
+	handler := newTestValidator(t, WithResponseValidation()).Middleware(http.NotFoundHandler())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found\n", w.Body.String())
+}
+
+func TestMiddleware_RequestBodyWithoutContentType(t *testing.T) {
+	var received string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	})
+	handler := newTestValidator(t).Middleware(next)
+
+	body := `{"cep":"01001000","above_C":30,"webhook_url":"https://example.com/hook"}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/alerts/subscriptions", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, received)
+}
+
+func TestMiddleware_InvalidRequestBody(t *testing.T) {
+	handler := newTestValidator(t).Middleware(jsonHandler(http.StatusCreated, `{}`))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/alerts/subscriptions", strings.NewReader(`{"above_C":"hot"}`)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusBadRequest, common.CodeInvalidRequestBody, "invalid request body"), w.Body.String())
+}
+
+func TestMiddleware_UnsupportedContentType(t *testing.T) {
+	handler := newTestValidator(t).Middleware(jsonHandler(http.StatusCreated, `{}`))
+
+	req := httptest.NewRequest(http.MethodPost, "/alerts/subscriptions", strings.NewReader(`cep=01001000`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusUnsupportedMediaType, common.CodeUnsupportedMediaType, "unsupported content type"), w.Body.String())
+}
+
+func TestMiddleware_ValidResponse(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Weather-Fallback", "state-capital")
+		io.WriteString(w, `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3}`)
+	})
+	handler := newTestValidator(t, WithResponseValidation()).Middleware(next)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cep/01001000", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "state-capital", w.Header().Get("X-Weather-Fallback"))
+	assert.JSONEq(t, `{"city":"São Paulo","temp_C":28.5,"temp_F":83.3}`, w.Body.String())
+}
+
+func TestMiddleware_ResponseDrift(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.Handler
+	}{
+		{"undocumented body", jsonHandler(http.StatusOK, `{"temp_C":28.5}`)},
+		{"undocumented status", jsonHandler(http.StatusTeapot, `{"error":"teapot"}`)},
+		{"wrong error shape", jsonHandler(http.StatusNotFound, `{"message":"not found","code":404}`)},
+		{"missing content type", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, problemBody(http.StatusNotFound, common.CodeZipcodeNotFound, "can not find zipcode"))
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestValidator(t, WithResponseValidation()).Middleware(tt.handler)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cep/01001000", nil))
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.JSONEq(t, problemBody(http.StatusInternalServerError, common.CodeInternal, "response does not match the api contract"), w.Body.String())
+		})
+	}
+}
+
+func TestMiddleware_ResponseValidationDisabled(t *testing.T) {
+	handler := newTestValidator(t).Middleware(jsonHandler(http.StatusOK, `{"temp_C":28.5}`))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cep/01001000", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"temp_C":28.5}`, w.Body.String())
+}
+
+func TestMiddleware_StreamingResponseNotBuffered(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+		assert.True(t, ok)
+		w.Header().Set("Content-Type", "text/csv")
+		io.WriteString(w, "time,cep\n")
+	})
+	handler := newTestValidator(t, WithResponseValidation()).Middleware(next)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/history/export?format=csv", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "time,cep\n", w.Body.String())
+}
+
+// problemBody é o corpo esperado para o problema; sem span no contexto, não há trace_id
+func problemBody(status int, code, detail string) string {
+	body, _ := json.Marshal(common.NewProblem(status, code, detail))
+	return string(body)
+}
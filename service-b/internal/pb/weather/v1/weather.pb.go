@@ -0,0 +1,836 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.3
+// 	protoc        (unknown)
+// source: weather/v1/weather.proto
+
+// Contrato gRPC do Serviço B. Cada serviço gera o próprio código Go a partir deste arquivo
+// (veja proto/generate.sh).
+
+package weatherv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ResponseOptions equivale aos parâmetros detail, fields, units e precision do HTTP
+type ResponseOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Símbolos ou nomes das unidades (ex.: "C", "fahrenheit"); vazio retorna C, F e K
+	Units []string `protobuf:"bytes,1,rep,name=units,proto3" json:"units,omitempty"`
+	// Casas decimais das temperaturas, de 0 a 6; ausente usa 2
+	Precision *int32 `protobuf:"varint,2,opt,name=precision,proto3,oneof" json:"precision,omitempty"`
+	// Grupos de campos opcionais: feelslike, humidity, wind, pressure, uv, condition, observed_at, comfort
+	Fields []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Inclui todos os grupos de campos opcionais (detail=full)
+	Detail        bool `protobuf:"varint,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResponseOptions) Reset() {
+	*x = ResponseOptions{}
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResponseOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseOptions) ProtoMessage() {}
+
+func (x *ResponseOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseOptions.ProtoReflect.Descriptor instead.
+func (*ResponseOptions) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResponseOptions) GetUnits() []string {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *ResponseOptions) GetPrecision() int32 {
+	if x != nil && x.Precision != nil {
+		return *x.Precision
+	}
+	return 0
+}
+
+func (x *ResponseOptions) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *ResponseOptions) GetDetail() bool {
+	if x != nil {
+		return x.Detail
+	}
+	return false
+}
+
+type GetByCEPRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cep           string                 `protobuf:"bytes,1,opt,name=cep,proto3" json:"cep,omitempty"`
+	Options       *ResponseOptions       `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetByCEPRequest) Reset() {
+	*x = GetByCEPRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetByCEPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetByCEPRequest) ProtoMessage() {}
+
+func (x *GetByCEPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetByCEPRequest.ProtoReflect.Descriptor instead.
+func (*GetByCEPRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetByCEPRequest) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+func (x *GetByCEPRequest) GetOptions() *ResponseOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type BatchGetByCEPRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ceps          []string               `protobuf:"bytes,1,rep,name=ceps,proto3" json:"ceps,omitempty"`
+	Options       *ResponseOptions       `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetByCEPRequest) Reset() {
+	*x = BatchGetByCEPRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetByCEPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetByCEPRequest) ProtoMessage() {}
+
+func (x *BatchGetByCEPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetByCEPRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetByCEPRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchGetByCEPRequest) GetCeps() []string {
+	if x != nil {
+		return x.Ceps
+	}
+	return nil
+}
+
+func (x *BatchGetByCEPRequest) GetOptions() *ResponseOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// Temperature é uma temperatura em uma das unidades pedidas
+type Temperature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unit          string                 `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Temperature) Reset() {
+	*x = Temperature{}
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Temperature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Temperature) ProtoMessage() {}
+
+func (x *Temperature) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Temperature.ProtoReflect.Descriptor instead.
+func (*Temperature) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Temperature) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Temperature) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// Observation traz apenas os grupos de campos opcionais pedidos
+type Observation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FeelsLike     []*Temperature         `protobuf:"bytes,1,rep,name=feels_like,json=feelsLike,proto3" json:"feels_like,omitempty"`
+	Humidity      *int32                 `protobuf:"varint,2,opt,name=humidity,proto3,oneof" json:"humidity,omitempty"`
+	WindKph       *float64               `protobuf:"fixed64,3,opt,name=wind_kph,json=windKph,proto3,oneof" json:"wind_kph,omitempty"`
+	WindDegree    *int32                 `protobuf:"varint,4,opt,name=wind_degree,json=windDegree,proto3,oneof" json:"wind_degree,omitempty"`
+	WindDir       *string                `protobuf:"bytes,5,opt,name=wind_dir,json=windDir,proto3,oneof" json:"wind_dir,omitempty"`
+	PressureMb    *float64               `protobuf:"fixed64,6,opt,name=pressure_mb,json=pressureMb,proto3,oneof" json:"pressure_mb,omitempty"`
+	Uv            *float64               `protobuf:"fixed64,7,opt,name=uv,proto3,oneof" json:"uv,omitempty"`
+	Condition     *string                `protobuf:"bytes,8,opt,name=condition,proto3,oneof" json:"condition,omitempty"`
+	ConditionCode *int32                 `protobuf:"varint,9,opt,name=condition_code,json=conditionCode,proto3,oneof" json:"condition_code,omitempty"`
+	ObservedAt    *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=observed_at,json=observedAt,proto3" json:"observed_at,omitempty"`
+	HeatIndexC    *float64               `protobuf:"fixed64,11,opt,name=heat_index_c,json=heatIndexC,proto3,oneof" json:"heat_index_c,omitempty"`
+	WindChillC    *float64               `protobuf:"fixed64,12,opt,name=wind_chill_c,json=windChillC,proto3,oneof" json:"wind_chill_c,omitempty"`
+	DewPointC     *float64               `protobuf:"fixed64,13,opt,name=dew_point_c,json=dewPointC,proto3,oneof" json:"dew_point_c,omitempty"`
+	Humidex       *float64               `protobuf:"fixed64,14,opt,name=humidex,proto3,oneof" json:"humidex,omitempty"`
+	HeatRisk      *string                `protobuf:"bytes,15,opt,name=heat_risk,json=heatRisk,proto3,oneof" json:"heat_risk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Observation) Reset() {
+	*x = Observation{}
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Observation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Observation) ProtoMessage() {}
+
+func (x *Observation) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Observation.ProtoReflect.Descriptor instead.
+func (*Observation) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Observation) GetFeelsLike() []*Temperature {
+	if x != nil {
+		return x.FeelsLike
+	}
+	return nil
+}
+
+func (x *Observation) GetHumidity() int32 {
+	if x != nil && x.Humidity != nil {
+		return *x.Humidity
+	}
+	return 0
+}
+
+func (x *Observation) GetWindKph() float64 {
+	if x != nil && x.WindKph != nil {
+		return *x.WindKph
+	}
+	return 0
+}
+
+func (x *Observation) GetWindDegree() int32 {
+	if x != nil && x.WindDegree != nil {
+		return *x.WindDegree
+	}
+	return 0
+}
+
+func (x *Observation) GetWindDir() string {
+	if x != nil && x.WindDir != nil {
+		return *x.WindDir
+	}
+	return ""
+}
+
+func (x *Observation) GetPressureMb() float64 {
+	if x != nil && x.PressureMb != nil {
+		return *x.PressureMb
+	}
+	return 0
+}
+
+func (x *Observation) GetUv() float64 {
+	if x != nil && x.Uv != nil {
+		return *x.Uv
+	}
+	return 0
+}
+
+func (x *Observation) GetCondition() string {
+	if x != nil && x.Condition != nil {
+		return *x.Condition
+	}
+	return ""
+}
+
+func (x *Observation) GetConditionCode() int32 {
+	if x != nil && x.ConditionCode != nil {
+		return *x.ConditionCode
+	}
+	return 0
+}
+
+func (x *Observation) GetObservedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ObservedAt
+	}
+	return nil
+}
+
+func (x *Observation) GetHeatIndexC() float64 {
+	if x != nil && x.HeatIndexC != nil {
+		return *x.HeatIndexC
+	}
+	return 0
+}
+
+func (x *Observation) GetWindChillC() float64 {
+	if x != nil && x.WindChillC != nil {
+		return *x.WindChillC
+	}
+	return 0
+}
+
+func (x *Observation) GetDewPointC() float64 {
+	if x != nil && x.DewPointC != nil {
+		return *x.DewPointC
+	}
+	return 0
+}
+
+func (x *Observation) GetHumidex() float64 {
+	if x != nil && x.Humidex != nil {
+		return *x.Humidex
+	}
+	return 0
+}
+
+func (x *Observation) GetHeatRisk() string {
+	if x != nil && x.HeatRisk != nil {
+		return *x.HeatRisk
+	}
+	return ""
+}
+
+type GetByCEPResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Cep   string                 `protobuf:"bytes,1,opt,name=cep,proto3" json:"cep,omitempty"`
+	City  string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	// Temperaturas na ordem das unidades pedidas
+	Temperatures []*Temperature `protobuf:"bytes,3,rep,name=temperatures,proto3" json:"temperatures,omitempty"`
+	Observation  *Observation   `protobuf:"bytes,4,opt,name=observation,proto3" json:"observation,omitempty"`
+	// "state-capital" quando a cidade do CEP não pôde ser consultada e a temperatura é da capital
+	Fallback      string `protobuf:"bytes,5,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetByCEPResponse) Reset() {
+	*x = GetByCEPResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetByCEPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetByCEPResponse) ProtoMessage() {}
+
+func (x *GetByCEPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetByCEPResponse.ProtoReflect.Descriptor instead.
+func (*GetByCEPResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetByCEPResponse) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+func (x *GetByCEPResponse) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *GetByCEPResponse) GetTemperatures() []*Temperature {
+	if x != nil {
+		return x.Temperatures
+	}
+	return nil
+}
+
+func (x *GetByCEPResponse) GetObservation() *Observation {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+func (x *GetByCEPResponse) GetFallback() string {
+	if x != nil {
+		return x.Fallback
+	}
+	return ""
+}
+
+// Error é o status gRPC de um CEP que falhou dentro de um lote
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Código gRPC (google.rpc.Code)
+	Code          int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Error) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CEPResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Cep   string                 `protobuf:"bytes,1,opt,name=cep,proto3" json:"cep,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*CEPResult_Weather
+	//	*CEPResult_Error
+	Result        isCEPResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CEPResult) Reset() {
+	*x = CEPResult{}
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CEPResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CEPResult) ProtoMessage() {}
+
+func (x *CEPResult) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CEPResult.ProtoReflect.Descriptor instead.
+func (*CEPResult) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CEPResult) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+func (x *CEPResult) GetResult() isCEPResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *CEPResult) GetWeather() *GetByCEPResponse {
+	if x != nil {
+		if x, ok := x.Result.(*CEPResult_Weather); ok {
+			return x.Weather
+		}
+	}
+	return nil
+}
+
+func (x *CEPResult) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*CEPResult_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isCEPResult_Result interface {
+	isCEPResult_Result()
+}
+
+type CEPResult_Weather struct {
+	Weather *GetByCEPResponse `protobuf:"bytes,2,opt,name=weather,proto3,oneof"`
+}
+
+type CEPResult_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*CEPResult_Weather) isCEPResult_Result() {}
+
+func (*CEPResult_Error) isCEPResult_Result() {}
+
+var File_weather_v1_weather_proto protoreflect.FileDescriptor
+
+var file_weather_v1_weather_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x65, 0x61,
+	0x74, 0x68, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x77, 0x65, 0x61, 0x74,
+	0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x88, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x75,
+	0x6e, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x75, 0x6e, 0x69, 0x74,
+	0x73, 0x12, 0x21, 0x0a, 0x09, 0x70, 0x72, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x09, 0x70, 0x72, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x65,
+	0x74, 0x61, 0x69, 0x6c, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x70, 0x72, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x22, 0x5a, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x63, 0x65, 0x70, 0x12, 0x35, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x61,
+	0x0a, 0x14, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x65, 0x70, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x63, 0x65, 0x70, 0x73, 0x12, 0x35, 0x0a, 0x07, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x77, 0x65,
+	0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x22, 0x37, 0x0a, 0x0b, 0x54, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x75, 0x6e, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x75, 0x6e, 0x69, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x82, 0x06, 0x0a, 0x0b, 0x4f,
+	0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x0a, 0x66, 0x65,
+	0x65, 0x6c, 0x73, 0x5f, 0x6c, 0x69, 0x6b, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x6d, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x09, 0x66, 0x65, 0x65, 0x6c, 0x73, 0x4c, 0x69,
+	0x6b, 0x65, 0x12, 0x1f, 0x0a, 0x08, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x08, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79,
+	0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x6b, 0x70, 0x68, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x07, 0x77, 0x69, 0x6e, 0x64, 0x4b, 0x70, 0x68,
+	0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0b, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x65, 0x67, 0x72,
+	0x65, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x0a, 0x77, 0x69, 0x6e, 0x64,
+	0x44, 0x65, 0x67, 0x72, 0x65, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x77, 0x69, 0x6e,
+	0x64, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x03, 0x52, 0x07, 0x77,
+	0x69, 0x6e, 0x64, 0x44, 0x69, 0x72, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0b, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x75, 0x72, 0x65, 0x5f, 0x6d, 0x62, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x48, 0x04,
+	0x52, 0x0a, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x4d, 0x62, 0x88, 0x01, 0x01, 0x12,
+	0x13, 0x0a, 0x02, 0x75, 0x76, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x48, 0x05, 0x52, 0x02, 0x75,
+	0x76, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x48, 0x06, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x2a, 0x0a, 0x0e, 0x63, 0x6f, 0x6e, 0x64, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x07, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x64, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x3b, 0x0a, 0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f,
+	0x61, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x25, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f, 0x63,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x48, 0x08, 0x52, 0x0a, 0x68, 0x65, 0x61, 0x74, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x43, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0c, 0x77, 0x69, 0x6e, 0x64, 0x5f,
+	0x63, 0x68, 0x69, 0x6c, 0x6c, 0x5f, 0x63, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x01, 0x48, 0x09, 0x52,
+	0x0a, 0x77, 0x69, 0x6e, 0x64, 0x43, 0x68, 0x69, 0x6c, 0x6c, 0x43, 0x88, 0x01, 0x01, 0x12, 0x23,
+	0x0a, 0x0b, 0x64, 0x65, 0x77, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x5f, 0x63, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x0a, 0x52, 0x09, 0x64, 0x65, 0x77, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x43,
+	0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x65, 0x78, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x0b, 0x52, 0x07, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x65, 0x78, 0x88,
+	0x01, 0x01, 0x12, 0x20, 0x0a, 0x09, 0x68, 0x65, 0x61, 0x74, 0x5f, 0x72, 0x69, 0x73, 0x6b, 0x18,
+	0x0f, 0x20, 0x01, 0x28, 0x09, 0x48, 0x0c, 0x52, 0x08, 0x68, 0x65, 0x61, 0x74, 0x52, 0x69, 0x73,
+	0x6b, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x6b, 0x70, 0x68, 0x42, 0x0e,
+	0x0a, 0x0c, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x65, 0x67, 0x72, 0x65, 0x65, 0x42, 0x0b,
+	0x0a, 0x09, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x69, 0x72, 0x42, 0x0e, 0x0a, 0x0c, 0x5f,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x5f, 0x6d, 0x62, 0x42, 0x05, 0x0a, 0x03, 0x5f,
+	0x75, 0x76, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63,
+	0x6f, 0x64, 0x65, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x68, 0x65, 0x61, 0x74, 0x5f, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x5f, 0x63, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x63, 0x68,
+	0x69, 0x6c, 0x6c, 0x5f, 0x63, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x64, 0x65, 0x77, 0x5f, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x5f, 0x63, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x65,
+	0x78, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x68, 0x65, 0x61, 0x74, 0x5f, 0x72, 0x69, 0x73, 0x6b, 0x22,
+	0xcc, 0x01, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x63, 0x65, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x69, 0x74, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x69, 0x74, 0x79, 0x12, 0x3b, 0x0a, 0x0c, 0x74, 0x65,
+	0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65,
+	0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x0c, 0x74, 0x65, 0x6d, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72,
+	0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x77,
+	0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x22, 0x35,
+	0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x8c, 0x01, 0x0a, 0x09, 0x43, 0x45, 0x50, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x63, 0x65, 0x70, 0x12, 0x38, 0x0a, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x12,
+	0x29, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x32, 0xed, 0x01, 0x0a, 0x0e, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x45, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x42, 0x79,
+	0x43, 0x45, 0x50, 0x12, 0x1b, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1c, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4a,
+	0x0a, 0x0d, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x12,
+	0x20, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x15, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x45, 0x50, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x30, 0x01, 0x12, 0x48, 0x0a, 0x0e, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43, 0x45, 0x50, 0x12, 0x1b, 0x2e, 0x77,
+	0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x79, 0x43,
+	0x45, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x77, 0x65, 0x61, 0x74,
+	0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x45, 0x50, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x28, 0x01, 0x30, 0x01, 0x42, 0x16, 0x5a, 0x14, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2f,
+	0x76, 0x31, 0x3b, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_weather_v1_weather_proto_rawDescOnce sync.Once
+	file_weather_v1_weather_proto_rawDescData = file_weather_v1_weather_proto_rawDesc
+)
+
+func file_weather_v1_weather_proto_rawDescGZIP() []byte {
+	file_weather_v1_weather_proto_rawDescOnce.Do(func() {
+		file_weather_v1_weather_proto_rawDescData = protoimpl.X.CompressGZIP(file_weather_v1_weather_proto_rawDescData)
+	})
+	return file_weather_v1_weather_proto_rawDescData
+}
+
+var file_weather_v1_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_weather_v1_weather_proto_goTypes = []any{
+	(*ResponseOptions)(nil),       // 0: weather.v1.ResponseOptions
+	(*GetByCEPRequest)(nil),       // 1: weather.v1.GetByCEPRequest
+	(*BatchGetByCEPRequest)(nil),  // 2: weather.v1.BatchGetByCEPRequest
+	(*Temperature)(nil),           // 3: weather.v1.Temperature
+	(*Observation)(nil),           // 4: weather.v1.Observation
+	(*GetByCEPResponse)(nil),      // 5: weather.v1.GetByCEPResponse
+	(*Error)(nil),                 // 6: weather.v1.Error
+	(*CEPResult)(nil),             // 7: weather.v1.CEPResult
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_weather_v1_weather_proto_depIdxs = []int32{
+	0,  // 0: weather.v1.GetByCEPRequest.options:type_name -> weather.v1.ResponseOptions
+	0,  // 1: weather.v1.BatchGetByCEPRequest.options:type_name -> weather.v1.ResponseOptions
+	3,  // 2: weather.v1.Observation.feels_like:type_name -> weather.v1.Temperature
+	8,  // 3: weather.v1.Observation.observed_at:type_name -> google.protobuf.Timestamp
+	3,  // 4: weather.v1.GetByCEPResponse.temperatures:type_name -> weather.v1.Temperature
+	4,  // 5: weather.v1.GetByCEPResponse.observation:type_name -> weather.v1.Observation
+	5,  // 6: weather.v1.CEPResult.weather:type_name -> weather.v1.GetByCEPResponse
+	6,  // 7: weather.v1.CEPResult.error:type_name -> weather.v1.Error
+	1,  // 8: weather.v1.WeatherService.GetByCEP:input_type -> weather.v1.GetByCEPRequest
+	2,  // 9: weather.v1.WeatherService.BatchGetByCEP:input_type -> weather.v1.BatchGetByCEPRequest
+	1,  // 10: weather.v1.WeatherService.StreamGetByCEP:input_type -> weather.v1.GetByCEPRequest
+	5,  // 11: weather.v1.WeatherService.GetByCEP:output_type -> weather.v1.GetByCEPResponse
+	7,  // 12: weather.v1.WeatherService.BatchGetByCEP:output_type -> weather.v1.CEPResult
+	7,  // 13: weather.v1.WeatherService.StreamGetByCEP:output_type -> weather.v1.CEPResult
+	11, // [11:14] is the sub-list for method output_type
+	8,  // [8:11] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_weather_v1_weather_proto_init() }
+func file_weather_v1_weather_proto_init() {
+	if File_weather_v1_weather_proto != nil {
+		return
+	}
+	file_weather_v1_weather_proto_msgTypes[0].OneofWrappers = []any{}
+	file_weather_v1_weather_proto_msgTypes[4].OneofWrappers = []any{}
+	file_weather_v1_weather_proto_msgTypes[7].OneofWrappers = []any{
+		(*CEPResult_Weather)(nil),
+		(*CEPResult_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_weather_v1_weather_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weather_v1_weather_proto_goTypes,
+		DependencyIndexes: file_weather_v1_weather_proto_depIdxs,
+		MessageInfos:      file_weather_v1_weather_proto_msgTypes,
+	}.Build()
+	File_weather_v1_weather_proto = out.File
+	file_weather_v1_weather_proto_rawDesc = nil
+	file_weather_v1_weather_proto_goTypes = nil
+	file_weather_v1_weather_proto_depIdxs = nil
+}
@@ -0,0 +1,214 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: weather/v1/weather.proto
+
+// Contrato gRPC do Serviço B. Cada serviço gera o próprio código Go a partir deste arquivo
+// (veja proto/generate.sh).
+
+package weatherv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WeatherService_GetByCEP_FullMethodName       = "/weather.v1.WeatherService/GetByCEP"
+	WeatherService_BatchGetByCEP_FullMethodName  = "/weather.v1.WeatherService/BatchGetByCEP"
+	WeatherService_StreamGetByCEP_FullMethodName = "/weather.v1.WeatherService/StreamGetByCEP"
+)
+
+// WeatherServiceClient is the client API for WeatherService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WeatherService busca cidade e temperatura pelo CEP. Os erros seguem a semântica do
+// GET /cep/{cep}: INVALID_ARGUMENT com BadRequest no campo "cep" (HTTP 422) ou nas opções
+// (HTTP 400), NOT_FOUND para CEP inexistente (HTTP 404) e INTERNAL nas falhas de consulta (HTTP 500).
+type WeatherServiceClient interface {
+	// GetByCEP busca o clima de um CEP
+	GetByCEP(ctx context.Context, in *GetByCEPRequest, opts ...grpc.CallOption) (*GetByCEPResponse, error)
+	// BatchGetByCEP busca vários CEPs com as mesmas opções e envia cada resultado assim que
+	// fica pronto, fora da ordem do pedido. Erros de um CEP não interrompem os demais.
+	BatchGetByCEP(ctx context.Context, in *BatchGetByCEPRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CEPResult], error)
+	// StreamGetByCEP responde a cada pedido recebido no stream, na ordem em que ficam prontos
+	StreamGetByCEP(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetByCEPRequest, CEPResult], error)
+}
+
+type weatherServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWeatherServiceClient(cc grpc.ClientConnInterface) WeatherServiceClient {
+	return &weatherServiceClient{cc}
+}
+
+func (c *weatherServiceClient) GetByCEP(ctx context.Context, in *GetByCEPRequest, opts ...grpc.CallOption) (*GetByCEPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetByCEPResponse)
+	err := c.cc.Invoke(ctx, WeatherService_GetByCEP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) BatchGetByCEP(ctx context.Context, in *BatchGetByCEPRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CEPResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WeatherService_ServiceDesc.Streams[0], WeatherService_BatchGetByCEP_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchGetByCEPRequest, CEPResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherService_BatchGetByCEPClient = grpc.ServerStreamingClient[CEPResult]
+
+func (c *weatherServiceClient) StreamGetByCEP(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetByCEPRequest, CEPResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WeatherService_ServiceDesc.Streams[1], WeatherService_StreamGetByCEP_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetByCEPRequest, CEPResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherService_StreamGetByCEPClient = grpc.BidiStreamingClient[GetByCEPRequest, CEPResult]
+
+// WeatherServiceServer is the server API for WeatherService service.
+// All implementations must embed UnimplementedWeatherServiceServer
+// for forward compatibility.
+//
+// WeatherService busca cidade e temperatura pelo CEP. Os erros seguem a semântica do
+// GET /cep/{cep}: INVALID_ARGUMENT com BadRequest no campo "cep" (HTTP 422) ou nas opções
+// (HTTP 400), NOT_FOUND para CEP inexistente (HTTP 404) e INTERNAL nas falhas de consulta (HTTP 500).
+type WeatherServiceServer interface {
+	// GetByCEP busca o clima de um CEP
+	GetByCEP(context.Context, *GetByCEPRequest) (*GetByCEPResponse, error)
+	// BatchGetByCEP busca vários CEPs com as mesmas opções e envia cada resultado assim que
+	// fica pronto, fora da ordem do pedido. Erros de um CEP não interrompem os demais.
+	BatchGetByCEP(*BatchGetByCEPRequest, grpc.ServerStreamingServer[CEPResult]) error
+	// StreamGetByCEP responde a cada pedido recebido no stream, na ordem em que ficam prontos
+	StreamGetByCEP(grpc.BidiStreamingServer[GetByCEPRequest, CEPResult]) error
+	mustEmbedUnimplementedWeatherServiceServer()
+}
+
+// UnimplementedWeatherServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWeatherServiceServer struct{}
+
+func (UnimplementedWeatherServiceServer) GetByCEP(context.Context, *GetByCEPRequest) (*GetByCEPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByCEP not implemented")
+}
+func (UnimplementedWeatherServiceServer) BatchGetByCEP(*BatchGetByCEPRequest, grpc.ServerStreamingServer[CEPResult]) error {
+	return status.Errorf(codes.Unimplemented, "method BatchGetByCEP not implemented")
+}
+func (UnimplementedWeatherServiceServer) StreamGetByCEP(grpc.BidiStreamingServer[GetByCEPRequest, CEPResult]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamGetByCEP not implemented")
+}
+func (UnimplementedWeatherServiceServer) mustEmbedUnimplementedWeatherServiceServer() {}
+func (UnimplementedWeatherServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeWeatherServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WeatherServiceServer will
+// result in compilation errors.
+type UnsafeWeatherServiceServer interface {
+	mustEmbedUnimplementedWeatherServiceServer()
+}
+
+func RegisterWeatherServiceServer(s grpc.ServiceRegistrar, srv WeatherServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWeatherServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WeatherService_ServiceDesc, srv)
+}
+
+func _WeatherService_GetByCEP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByCEPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).GetByCEP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_GetByCEP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).GetByCEP(ctx, req.(*GetByCEPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_BatchGetByCEP_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchGetByCEPRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WeatherServiceServer).BatchGetByCEP(m, &grpc.GenericServerStream[BatchGetByCEPRequest, CEPResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherService_BatchGetByCEPServer = grpc.ServerStreamingServer[CEPResult]
+
+func _WeatherService_StreamGetByCEP_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WeatherServiceServer).StreamGetByCEP(&grpc.GenericServerStream[GetByCEPRequest, CEPResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherService_StreamGetByCEPServer = grpc.BidiStreamingServer[GetByCEPRequest, CEPResult]
+
+// WeatherService_ServiceDesc is the grpc.ServiceDesc for WeatherService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WeatherService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "weather.v1.WeatherService",
+	HandlerType: (*WeatherServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetByCEP",
+			Handler:    _WeatherService_GetByCEP_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchGetByCEP",
+			Handler:       _WeatherService_BatchGetByCEP_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamGetByCEP",
+			Handler:       _WeatherService_StreamGetByCEP_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "weather/v1/weather.proto",
+}
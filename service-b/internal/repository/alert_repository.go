@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrSubscriptionNotFound indica que a inscrição de alerta não existe
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// AlertState é a situação da temperatura em relação aos limites da inscrição
+type AlertState string
+
+const (
+	AlertStateNormal AlertState = "normal"
+	AlertStateAbove  AlertState = "above"
+	AlertStateBelow  AlertState = "below"
+)
+
+// Subscription é uma inscrição de alerta de temperatura para um CEP. Above e Below são
+// opcionais, mas ao menos um deve ser informado.
+type Subscription struct {
+	ID            string     `json:"id"`
+	CEP           string     `json:"cep"`
+	City          string     `json:"city"`
+	AboveC        *float64   `json:"above_C,omitempty"`
+	BelowC        *float64   `json:"below_C,omitempty"`
+	WebhookURL    string     `json:"webhook_url"`
+	Secret        string     `json:"secret,omitempty"`
+	State         AlertState `json:"state"`
+	LastTempC     *float64   `json:"last_temp_C,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// AlertEvent é a notificação enviada ao webhook quando o estado do alerta muda
+type AlertEvent struct {
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	SubscriptionID string     `json:"subscription_id"`
+	CEP            string     `json:"cep"`
+	City           string     `json:"city"`
+	TempC          float64    `json:"temp_C"`
+	ThresholdC     *float64   `json:"threshold_C,omitempty"`
+	State          AlertState `json:"state"`
+	PreviousState  AlertState `json:"previous_state"`
+	OccurredAt     time.Time  `json:"occurred_at"`
+}
+
+// DeadLetter é uma notificação que não pôde ser entregue após todas as tentativas
+type DeadLetter struct {
+	Event      AlertEvent `json:"event"`
+	WebhookURL string     `json:"webhook_url"`
+	Attempts   int        `json:"attempts"`
+	LastError  string     `json:"last_error"`
+	FailedAt   time.Time  `json:"failed_at"`
+}
+
+// AlertRepository armazena as inscrições de alerta, o estado de cada uma e as notificações não entregues
+type AlertRepository interface {
+	CreateSubscription(ctx context.Context, sub Subscription) error
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	UpdateSubscriptionState(ctx context.Context, id string, state AlertState, tempC float64, checkedAt time.Time) error
+	AddDeadLetter(ctx context.Context, letter DeadLetter) error
+	ListDeadLetters(ctx context.Context) ([]DeadLetter, error)
+	Close() error
+}
+
+var (
+	bucketSubscriptions = []byte("subscriptions")
+	bucketDeadLetters   = []byte("dead_letters")
+)
+
+type boltAlertRepository struct {
+	db *bolt.DB
+}
+
+// NewBoltAlertRepository abre (ou cria) o armazenamento de alertas embutido em disco
+func NewBoltAlertRepository(path string) (AlertRepository, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening alert store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketSubscriptions, bucketDeadLetters} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing alert store: %w", err)
+	}
+	return &boltAlertRepository{db: db}, nil
+}
+
+// CreateSubscription grava uma nova inscrição
+func (r *boltAlertRepository) CreateSubscription(ctx context.Context, sub Subscription) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSubscriptions).Put([]byte(sub.ID), data)
+	})
+}
+
+// ListSubscriptions retorna todas as inscrições, incluindo os segredos usados na assinatura
+func (r *boltAlertRepository) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	subs := []Subscription{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSubscriptions).ForEach(func(_, v []byte) error {
+			var sub Subscription
+			if err := json.Unmarshal(v, &sub); err != nil {
+				return err
+			}
+			subs = append(subs, sub)
+			return nil
+		})
+	})
+	return subs, err
+}
+
+// DeleteSubscription remove uma inscrição
+func (r *boltAlertRepository) DeleteSubscription(ctx context.Context, id string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSubscriptions)
+		if b.Get([]byte(id)) == nil {
+			return ErrSubscriptionNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+// UpdateSubscriptionState registra o resultado da última verificação da inscrição
+func (r *boltAlertRepository) UpdateSubscriptionState(ctx context.Context, id string, state AlertState, tempC float64, checkedAt time.Time) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSubscriptions)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return ErrSubscriptionNotFound
+		}
+
+		var sub Subscription
+		if err := json.Unmarshal(data, &sub); err != nil {
+			return err
+		}
+		sub.State = state
+		sub.LastTempC = &tempC
+		sub.LastCheckedAt = &checkedAt
+
+		updated, err := json.Marshal(sub)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(id), updated)
+	})
+}
+
+// AddDeadLetter guarda uma notificação não entregue, em ordem de chegada
+func (r *boltAlertRepository) AddDeadLetter(ctx context.Context, letter DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketDeadLetters)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(lookupKey(letter.FailedAt, seq), data)
+	})
+}
+
+// ListDeadLetters retorna as notificações não entregues, da mais antiga para a mais recente
+func (r *boltAlertRepository) ListDeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	letters := []DeadLetter{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketDeadLetters).ForEach(func(_, v []byte) error {
+			var letter DeadLetter
+			if err := json.Unmarshal(v, &letter); err != nil {
+				return err
+			}
+			letters = append(letters, letter)
+			return nil
+		})
+	})
+	return letters, err
+}
+
+// Close fecha o arquivo de alertas
+func (r *boltAlertRepository) Close() error {
+	return r.db.Close()
+}
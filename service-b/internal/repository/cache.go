@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"service-b/internal/i18n"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// minCacheSweep é o tamanho a partir do qual o cache passa a remover as entradas expiradas
+const minCacheSweep = 1024
+
+// ttlCache guarda valores por até ttl. As entradas expiradas são descartadas na leitura e, quando
+// o cache dobra de tamanho, numa varredura completa.
+type ttlCache[V any] struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]cacheEntry[V]
+	nextSweep int
+}
+
+type cacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+	return &ttlCache[V]{ttl: ttl, now: time.Now, entries: make(map[string]cacheEntry[V]), nextSweep: minCacheSweep}
+}
+
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[V]) set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.entries[key] = cacheEntry[V]{value: value, expires: now.Add(c.ttl)}
+	if len(c.entries) >= c.nextSweep {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = max(2*len(c.entries), minCacheSweep)
+	}
+}
+
+type cachedTemperatureRepository struct {
+	next  TemperatureRepository
+	cache *ttlCache[WeatherObservation]
+}
+
+// NewCachedTemperatureRepository cria um TemperatureRepository que reaproveita, por ttl, as
+// observações de next. A chave inclui o idioma, que muda o texto da condição; erros não são guardados.
+func NewCachedTemperatureRepository(next TemperatureRepository, ttl time.Duration) TemperatureRepository {
+	return &cachedTemperatureRepository{next: next, cache: newTTLCache[WeatherObservation](ttl)}
+}
+
+// FetchWeather retorna a observação em cache ou a busca em next
+func (r *cachedTemperatureRepository) FetchWeather(ctx context.Context, query string) (*WeatherObservation, error) {
+	span := trace.SpanFromContext(ctx)
+	key := i18n.FromContext(ctx).String() + "|" + strings.ToLower(strings.TrimSpace(query))
+
+	if obs, ok := r.cache.get(key); ok {
+		span.SetAttributes(attribute.Bool("weather_cache_hit", true))
+		return &obs, nil
+	}
+	span.SetAttributes(attribute.Bool("weather_cache_hit", false))
+
+	obs, err := r.next.FetchWeather(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.set(key, *obs)
+	log.Printf("CachedTemperatureRepository: Cached weather for %s", query)
+	return obs, nil
+}
+
+// cachedCity é a cidade do CEP e o provedor que a informou, repetido no histórico a cada acerto
+type cachedCity struct {
+	city     string
+	provider string
+}
+
+type cachedCityRepository struct {
+	next  CityRepository
+	cache *ttlCache[cachedCity]
+}
+
+// NewCachedCityRepository cria um CityRepository que reaproveita, por ttl, a cidade de cada CEP.
+// A busca de endereços não passa pelo cache.
+func NewCachedCityRepository(next CityRepository, ttl time.Duration) CityRepository {
+	return &cachedCityRepository{next: next, cache: newTTLCache[cachedCity](ttl)}
+}
+
+// FetchCityFromCEP retorna a cidade em cache ou a busca em next
+func (r *cachedCityRepository) FetchCityFromCEP(ctx context.Context, cep string) (string, error) {
+	span := trace.SpanFromContext(ctx)
+
+	if cached, ok := r.cache.get(cep); ok {
+		span.SetAttributes(attribute.Bool("city_cache_hit", true))
+		setProvider(ctx, cached.provider)
+		return cached.city, nil
+	}
+	span.SetAttributes(attribute.Bool("city_cache_hit", false))
+
+	nextCtx, provider := WithProviderCapture(ctx)
+	city, err := r.next.FetchCityFromCEP(nextCtx, cep)
+	if err != nil {
+		return "", err
+	}
+	setProvider(ctx, provider())
+	r.cache.set(cep, cachedCity{city: city, provider: provider()})
+	return city, nil
+}
+
+// SearchAddresses repassa a busca a next
+func (r *cachedCityRepository) SearchAddresses(ctx context.Context, uf, city, street string) ([]Address, error) {
+	return r.next.SearchAddresses(ctx, uf, city, street)
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"service-b/internal/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTemperatureRepository struct {
+	mock.Mock
+}
+
+func (m *MockTemperatureRepository) FetchWeather(ctx context.Context, query string) (*WeatherObservation, error) {
+	args := m.Called(ctx, query)
+	obs, _ := args.Get(0).(*WeatherObservation)
+	return obs, args.Error(1)
+}
+
+type MockCityRepository struct {
+	mock.Mock
+}
+
+func (m *MockCityRepository) FetchCityFromCEP(ctx context.Context, cep string) (string, error) {
+	args := m.Called(ctx, cep)
+	setProvider(ctx, ProviderViaCEP)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCityRepository) SearchAddresses(ctx context.Context, uf, city, street string) ([]Address, error) {
+	args := m.Called(ctx, uf, city, street)
+	addresses, _ := args.Get(0).([]Address)
+	return addresses, args.Error(1)
+}
+
+// fakeClock é um relógio controlado pelo teste
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestCachedTemperatureRepository_ReusesUntilExpired(t *testing.T) {
+	next := new(MockTemperatureRepository)
+	next.On("FetchWeather", mock.Anything, "São Paulo").Return(&WeatherObservation{TempC: 28.5}, nil).Twice()
+	repo := NewCachedTemperatureRepository(next, time.Minute).(*cachedTemperatureRepository)
+	clock := &fakeClock{now: time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)}
+	repo.cache.now = clock.Now
+
+	for _, query := range []string{"São Paulo", "são paulo ", "SÃO PAULO"} {
+		obs, err := repo.FetchWeather(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, 28.5, obs.TempC)
+	}
+	next.AssertNumberOfCalls(t, "FetchWeather", 1)
+
+	clock.now = clock.now.Add(time.Minute)
+	_, err := repo.FetchWeather(context.Background(), "São Paulo")
+	require.NoError(t, err)
+	next.AssertNumberOfCalls(t, "FetchWeather", 2)
+}
+
+func TestCachedTemperatureRepository_KeyedByLanguage(t *testing.T) {
+	next := new(MockTemperatureRepository)
+	next.On("FetchWeather", mock.Anything, "São Paulo").Return(&WeatherObservation{ConditionText: "Sunny"}, nil).Once()
+	next.On("FetchWeather", mock.Anything, "São Paulo").Return(&WeatherObservation{ConditionText: "Ensolarado"}, nil).Once()
+	repo := NewCachedTemperatureRepository(next, time.Minute)
+
+	english, err := repo.FetchWeather(i18n.WithLanguage(context.Background(), i18n.English), "São Paulo")
+	require.NoError(t, err)
+	portuguese, err := repo.FetchWeather(i18n.WithLanguage(context.Background(), i18n.BrazilianPortuguese), "São Paulo")
+	require.NoError(t, err)
+	cached, err := repo.FetchWeather(i18n.WithLanguage(context.Background(), i18n.English), "São Paulo")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Sunny", english.ConditionText)
+	assert.Equal(t, "Ensolarado", portuguese.ConditionText)
+	assert.Equal(t, "Sunny", cached.ConditionText)
+	next.AssertExpectations(t)
+}
+
+func TestCachedTemperatureRepository_DoesNotCacheErrors(t *testing.T) {
+	next := new(MockTemperatureRepository)
+	next.On("FetchWeather", mock.Anything, "Atlantis").Return(nil, ErrLocationNotFound).Twice()
+	repo := NewCachedTemperatureRepository(next, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.FetchWeather(context.Background(), "Atlantis")
+		assert.ErrorIs(t, err, ErrLocationNotFound)
+	}
+	next.AssertExpectations(t)
+}
+
+func TestCachedCityRepository_KeepsProvider(t *testing.T) {
+	next := new(MockCityRepository)
+	next.On("FetchCityFromCEP", mock.Anything, "01001000").Return("São Paulo", nil).Once()
+	repo := NewCachedCityRepository(next, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		ctx, provider := WithProviderCapture(context.Background())
+		city, err := repo.FetchCityFromCEP(ctx, "01001000")
+		require.NoError(t, err)
+		assert.Equal(t, "São Paulo", city)
+		assert.Equal(t, ProviderViaCEP, provider())
+	}
+	next.AssertExpectations(t)
+}
+
+func TestCachedCityRepository_DoesNotCacheErrors(t *testing.T) {
+	next := new(MockCityRepository)
+	next.On("FetchCityFromCEP", mock.Anything, "01001000").Return("", errors.New("viacep unavailable")).Once()
+	next.On("FetchCityFromCEP", mock.Anything, "01001000").Return("São Paulo", nil).Once()
+	repo := NewCachedCityRepository(next, time.Minute)
+
+	_, err := repo.FetchCityFromCEP(context.Background(), "01001000")
+	assert.Error(t, err)
+	city, err := repo.FetchCityFromCEP(context.Background(), "01001000")
+	require.NoError(t, err)
+	assert.Equal(t, "São Paulo", city)
+	next.AssertExpectations(t)
+}
+
+func TestTTLCache_SweepsExpiredEntries(t *testing.T) {
+	cache := newTTLCache[int](time.Minute)
+	clock := &fakeClock{now: time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)}
+	cache.now = clock.Now
+
+	for i := 0; i < minCacheSweep-1; i++ {
+		cache.set(strconv.Itoa(i), i)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	cache.set("fresh", 1)
+
+	assert.Len(t, cache.entries, 1)
+	value, ok := cache.get("fresh")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"service-b/internal/config"
+	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -16,8 +18,20 @@ import (
 
 var ErrCEPNotFound = errors.New("CEP not found")
 
+// Address representa um endereço retornado pela busca do ViaCEP
+type Address struct {
+	CEP        string `json:"cep"`
+	Street     string `json:"street"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district"`
+	City       string `json:"city"`
+	UF         string `json:"uf"`
+	IBGE       string `json:"ibge,omitempty"`
+}
+
 type CityRepository interface {
 	FetchCityFromCEP(ctx context.Context, cep string) (string, error)
+	SearchAddresses(ctx context.Context, uf, city, street string) ([]Address, error)
 }
 
 type cityRepository struct{}
@@ -71,5 +85,78 @@ func (r *cityRepository) FetchCityFromCEP(ctx context.Context, cep string) (stri
 
 	span.SetAttributes(attribute.String("cep", cep), attribute.String("city", result.Localidade))
 	span.SetStatus(codes.Ok, "Successfully fetched city")
+	setProvider(ctx, ProviderViaCEP)
 	return result.Localidade, nil
 }
+
+// viaCEPAddress espelha um item da resposta de busca por logradouro do ViaCEP
+type viaCEPAddress struct {
+	CEP         string `json:"cep"`
+	Logradouro  string `json:"logradouro"`
+	Complemento string `json:"complemento"`
+	Bairro      string `json:"bairro"`
+	Localidade  string `json:"localidade"`
+	UF          string `json:"uf"`
+	IBGE        string `json:"ibge"`
+}
+
+// SearchAddresses busca endereços por UF, cidade e logradouro (/ws/{UF}/{cidade}/{logradouro}/json/)
+func (r *cityRepository) SearchAddresses(ctx context.Context, uf, city, street string) ([]Address, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "search-addresses")
+	defer span.End()
+	span.SetAttributes(attribute.String("uf", uf), attribute.String("city", city), attribute.String("street", street))
+
+	url := fmt.Sprintf("%s%s/%s/%s/json/", config.AppConfig.ViaCEPAPIURL,
+		url.PathEscape(uf), url.PathEscape(city), url.PathEscape(street))
+	log.Printf("SearchAddresses: Searching addresses from URL: %s", url)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create request")
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("SearchAddresses: Error making request to ViaCEP: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error making request to ViaCEP")
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+		log.Printf("SearchAddresses: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Non-OK HTTP status")
+		return nil, err
+	}
+
+	var result []viaCEPAddress
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		log.Printf("SearchAddresses: Error decoding response: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error decoding response")
+		return nil, err
+	}
+
+	addresses := make([]Address, 0, len(result))
+	for _, a := range result {
+		addresses = append(addresses, Address{
+			CEP:        strings.ReplaceAll(a.CEP, "-", ""),
+			Street:     a.Logradouro,
+			Complement: a.Complemento,
+			District:   a.Bairro,
+			City:       a.Localidade,
+			UF:         a.UF,
+			IBGE:       a.IBGE,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("results", len(addresses)))
+	span.SetStatus(codes.Ok, "Successfully searched addresses")
+	return addresses, nil
+}
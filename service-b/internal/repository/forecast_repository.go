@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"service-b/internal/config"
+	"service-b/internal/i18n"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ForecastDay é a previsão de um dia retornada pela WeatherAPI
+type ForecastDay struct {
+	Date          time.Time
+	MaxTempC      float64
+	MinTempC      float64
+	AvgTempC      float64
+	ChanceOfRain  int
+	TotalPrecipMm float64
+	AvgHumidity   int
+	ConditionText string
+	ConditionCode int
+}
+
+type ForecastRepository interface {
+	// FetchForecast busca a previsão diária de uma cidade ou coordenada, a partir de hoje
+	FetchForecast(ctx context.Context, query string, days int) ([]ForecastDay, error)
+}
+
+type forecastRepository struct{}
+
+// NewForecastRepository cria um novo repositório ForecastRepository
+func NewForecastRepository() ForecastRepository {
+	return &forecastRepository{}
+}
+
+// weatherAPIForecastResponse espelha o trecho da resposta de forecast.json que utilizamos
+type weatherAPIForecastResponse struct {
+	Forecast struct {
+		ForecastDay []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxTempC          float64 `json:"maxtemp_c"`
+				MinTempC          float64 `json:"mintemp_c"`
+				AvgTempC          float64 `json:"avgtemp_c"`
+				TotalPrecipMm     float64 `json:"totalprecip_mm"`
+				AvgHumidity       float64 `json:"avghumidity"`
+				DailyChanceOfRain int     `json:"daily_chance_of_rain"`
+				Condition         struct {
+					Text string `json:"text"`
+					Code int    `json:"code"`
+				} `json:"condition"`
+			} `json:"day"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+// FetchForecast busca a previsão diária de uma cidade ou coordenada
+func (r *forecastRepository) FetchForecast(ctx context.Context, city string, days int) ([]ForecastDay, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "fetch-forecast")
+	defer span.End()
+	span.SetAttributes(attribute.String("city", city), attribute.Int("days", days))
+
+	query := url.Values{}
+	query.Set("key", config.AppConfig.WeatherAPIKey)
+	query.Set("q", city)
+	query.Set("days", fmt.Sprint(days))
+	if lang := i18n.WeatherAPILang(i18n.FromContext(ctx)); lang != "" {
+		query.Set("lang", lang)
+	}
+	url := config.AppConfig.WeatherAPIForecastURL + "?" + query.Encode()
+
+	log.Printf("FetchForecast: Fetching %d-day forecast for city: %s", days, city)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "Failed to create request")
+		log.Printf("Error creating request: %v", err)
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Error, "Failed to fetch forecast")
+		log.Printf("Error fetching forecast: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		var apiErr weatherAPIError
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error.Code == weatherAPINoMatchingLocation {
+			span.SetStatus(codes.Error, "Location not found")
+			log.Printf("FetchForecast: Location not found: %s", city)
+			return nil, ErrLocationNotFound
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		span.SetStatus(codes.Error, fmt.Sprintf("Non-OK HTTP status: %s", resp.Status))
+		log.Printf("Non-OK HTTP status: %s", resp.Status)
+		return nil, fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+
+	var result weatherAPIForecastResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		span.SetStatus(codes.Error, "Failed to decode response")
+		log.Printf("Error decoding response: %v", err)
+		return nil, err
+	}
+
+	forecast := make([]ForecastDay, 0, len(result.Forecast.ForecastDay))
+	for _, fd := range result.Forecast.ForecastDay {
+		date, err := time.Parse(time.DateOnly, fd.Date)
+		if err != nil {
+			span.SetStatus(codes.Error, "Failed to decode response")
+			log.Printf("Error decoding forecast date %q: %v", fd.Date, err)
+			return nil, err
+		}
+		forecast = append(forecast, ForecastDay{
+			Date:          date,
+			MaxTempC:      fd.Day.MaxTempC,
+			MinTempC:      fd.Day.MinTempC,
+			AvgTempC:      fd.Day.AvgTempC,
+			ChanceOfRain:  fd.Day.DailyChanceOfRain,
+			TotalPrecipMm: fd.Day.TotalPrecipMm,
+			AvgHumidity:   int(fd.Day.AvgHumidity),
+			ConditionText: fd.Day.Condition.Text,
+			ConditionCode: fd.Day.Condition.Code,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("forecast_days", len(forecast)))
+	span.SetStatus(codes.Ok, "Successfully fetched forecast")
+	return forecast, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Lookup é o registro de uma consulta de CEP no histórico
+type Lookup struct {
+	Time     time.Time     `json:"time"`
+	CEP      string        `json:"cep"`
+	City     string        `json:"city,omitempty"`
+	UF       string        `json:"uf,omitempty"`
+	TempC    *float64      `json:"temp_C,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	Provider string        `json:"provider,omitempty"`
+	TraceID  string        `json:"trace_id,omitempty"`
+	Status   int           `json:"status"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// HistoryRepository armazena o histórico de consultas. Iterate percorre em ordem cronológica
+// as consultas com from <= Time < to (zero em qualquer extremo significa sem limite).
+type HistoryRepository interface {
+	Record(ctx context.Context, lookup Lookup) error
+	Iterate(ctx context.Context, from, to time.Time, fn func(Lookup) error) error
+	Close() error
+}
+
+var bucketLookups = []byte("lookups")
+
+type boltHistoryRepository struct {
+	db *bolt.DB
+}
+
+// NewBoltHistoryRepository abre (ou cria) o histórico embutido em disco no caminho informado.
+// O serviço abre em modo escrita, o que exige acesso exclusivo ao arquivo; ferramentas de
+// leitura, como o history-export, abrem em modo somente leitura e só com o serviço parado
+// (a abertura falha com bolt.ErrTimeout enquanto ele estiver em execução).
+func NewBoltHistoryRepository(path string, readOnly bool) (HistoryRepository, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("opening history store %s: %w", path, err)
+	}
+	if readOnly {
+		return &boltHistoryRepository{db: db}, nil
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketLookups)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing history store: %w", err)
+	}
+	return &boltHistoryRepository{db: db}, nil
+}
+
+// Record grava uma consulta. As escritas concorrentes são agrupadas em uma única transação
+// (bolt.Batch) para não pagar um fsync por requisição.
+func (r *boltHistoryRepository) Record(ctx context.Context, lookup Lookup) error {
+	tracer := otel.Tracer("service-b")
+	_, span := tracer.Start(ctx, "record-lookup-history")
+	defer span.End()
+	span.SetAttributes(attribute.String("cep", lookup.CEP), attribute.Int("status", lookup.Status))
+
+	data, err := json.Marshal(lookup)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error encoding lookup")
+		return err
+	}
+
+	err = r.db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketLookups)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(lookupKey(lookup.Time, seq), data)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error writing lookup")
+		return err
+	}
+	return nil
+}
+
+// Iterate percorre as consultas do intervalo em ordem cronológica, lendo uma por vez
+func (r *boltHistoryRepository) Iterate(ctx context.Context, from, to time.Time, fn func(Lookup) error) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketLookups)
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+
+		var k, v []byte
+		if from.IsZero() {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(lookupKey(from, 0))
+		}
+
+		var end []byte
+		if !to.IsZero() {
+			end = lookupKey(to, 0)
+		}
+
+		for ; k != nil; k, v = c.Next() {
+			if end != nil && bytes.Compare(k, end) >= 0 {
+				break
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			var lookup Lookup
+			if err := json.Unmarshal(v, &lookup); err != nil {
+				return err
+			}
+			if err := fn(lookup); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close fecha o arquivo do histórico
+func (r *boltHistoryRepository) Close() error {
+	return r.db.Close()
+}
+
+// lookupKey ordena as consultas pelo horário; a sequência evita colisões no mesmo nanossegundo
+func lookupKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"service-b/internal/cepindex"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// maxOfflineSearchResults acompanha o limite de resultados do ViaCEP
+const maxOfflineSearchResults = 50
+
+type offlineCityRepository struct {
+	index *cepindex.Index
+}
+
+// NewOfflineCityRepository cria um CityRepository que responde a partir do índice local de CEPs
+func NewOfflineCityRepository(index *cepindex.Index) CityRepository {
+	return &offlineCityRepository{index: index}
+}
+
+// FetchCityFromCEP busca a cidade correspondente a um CEP no índice local
+func (r *offlineCityRepository) FetchCityFromCEP(ctx context.Context, cep string) (string, error) {
+	tracer := otel.Tracer("service-b")
+	_, span := tracer.Start(ctx, "fetch-city-from-index")
+	defer span.End()
+	span.SetAttributes(attribute.String("cep", cep))
+
+	record, err := r.index.Get(cep)
+	if errors.Is(err, cepindex.ErrNotFound) {
+		log.Printf("FetchCityFromIndex: CEP %s not found", cep)
+		span.SetStatus(codes.Error, "CEP not found")
+		return "", ErrCEPNotFound
+	}
+	if err != nil {
+		log.Printf("FetchCityFromIndex: Error reading index: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error reading index")
+		return "", err
+	}
+
+	span.SetAttributes(attribute.String("city", record.City))
+	span.SetStatus(codes.Ok, "Successfully fetched city")
+	setProvider(ctx, ProviderCEPIndex)
+	return record.City, nil
+}
+
+// SearchAddresses busca endereços por UF, cidade e logradouro no índice local
+func (r *offlineCityRepository) SearchAddresses(ctx context.Context, uf, city, street string) ([]Address, error) {
+	tracer := otel.Tracer("service-b")
+	_, span := tracer.Start(ctx, "search-addresses-in-index")
+	defer span.End()
+	span.SetAttributes(attribute.String("uf", uf), attribute.String("city", city), attribute.String("street", street))
+
+	records, err := r.index.Search(uf, city, street, maxOfflineSearchResults)
+	if err != nil {
+		log.Printf("SearchAddressesInIndex: Error reading index: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error reading index")
+		return nil, err
+	}
+
+	addresses := make([]Address, 0, len(records))
+	for _, rec := range records {
+		addresses = append(addresses, Address{
+			CEP:        rec.CEP,
+			Street:     rec.Street,
+			Complement: rec.Complement,
+			District:   rec.District,
+			City:       rec.City,
+			UF:         rec.UF,
+			IBGE:       rec.IBGE,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("results", len(addresses)))
+	span.SetStatus(codes.Ok, "Successfully searched addresses")
+	return addresses, nil
+}
+
+type fallbackCityRepository struct {
+	primary  CityRepository
+	fallback CityRepository
+}
+
+// NewFallbackCityRepository cria um CityRepository que consulta primary e, em caso de erro ou
+// ausência de resultado, recorre a fallback (ex.: índice local primeiro, ViaCEP depois)
+func NewFallbackCityRepository(primary, fallback CityRepository) CityRepository {
+	return &fallbackCityRepository{primary: primary, fallback: fallback}
+}
+
+// FetchCityFromCEP busca a cidade no repositório principal e, se falhar, no alternativo
+func (r *fallbackCityRepository) FetchCityFromCEP(ctx context.Context, cep string) (string, error) {
+	city, err := r.primary.FetchCityFromCEP(ctx, cep)
+	if err == nil {
+		return city, nil
+	}
+	log.Printf("FallbackCityRepository: Primary lookup failed for CEP %s, falling back: %v", cep, err)
+	return r.fallback.FetchCityFromCEP(ctx, cep)
+}
+
+// SearchAddresses busca no repositório principal e, sem resultados, no alternativo
+func (r *fallbackCityRepository) SearchAddresses(ctx context.Context, uf, city, street string) ([]Address, error) {
+	addresses, err := r.primary.SearchAddresses(ctx, uf, city, street)
+	if err == nil && len(addresses) > 0 {
+		return addresses, nil
+	}
+	log.Printf("FallbackCityRepository: Primary search returned no results for %s/%s/%s, falling back: %v", uf, city, street, err)
+	return r.fallback.SearchAddresses(ctx, uf, city, street)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import "context"
+
+// Provedores de CEP registrados no histórico de consultas
+const (
+	ProviderViaCEP   = "viacep"
+	ProviderCEPIndex = "cep-index"
+)
+
+type providerKey struct{}
+
+// WithProviderCapture prepara o contexto para registrar qual provedor respondeu a consulta de CEP.
+// A função retornada informa o provedor (vazio se nenhum respondeu), útil quando o repositório
+// combina mais de uma origem, como no modo offline-first.
+func WithProviderCapture(ctx context.Context) (context.Context, func() string) {
+	provider := new(string)
+	return context.WithValue(ctx, providerKey{}, provider), func() string { return *provider }
+}
+
+func setProvider(ctx context.Context, name string) {
+	if provider, ok := ctx.Value(providerKey{}).(*string); ok {
+		*provider = name
+	}
+}
@@ -3,19 +3,52 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"service-b/internal/config"
+	"service-b/internal/i18n"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
+// ErrLocationNotFound indica que a WeatherAPI não encontrou a localidade consultada
+var ErrLocationNotFound = errors.New("location not found")
+
+// weatherAPINoMatchingLocation é o código de erro da WeatherAPI para localidade inexistente
+const weatherAPINoMatchingLocation = 1006
+
+// WeatherObservation representa as condições atuais retornadas pela WeatherAPI
+type WeatherObservation struct {
+	LocationName string
+	Region       string
+	Country      string
+	Lat          float64
+	Lon          float64
+
+	TempC         float64
+	FeelsLikeC    float64
+	Humidity      int
+	WindKph       float64
+	WindDegree    int
+	WindDir       string
+	PressureMb    float64
+	UV            float64
+	ConditionText string
+	ConditionCode int
+	LastUpdated   time.Time
+	// FetchedAt é quando a observação foi obtida da WeatherAPI; dá a idade das observações em cache
+	FetchedAt time.Time
+}
+
 type TemperatureRepository interface {
-	FetchTemperature(ctx context.Context, city string) (float64, error)
+	// FetchWeather aceita qualquer consulta suportada pela WeatherAPI: nome da cidade ou "lat,lon"
+	FetchWeather(ctx context.Context, query string) (*WeatherObservation, error)
 }
 
 type temperatureRepository struct{}
@@ -25,8 +58,42 @@ func NewTemperatureRepository() TemperatureRepository {
 	return &temperatureRepository{}
 }
 
-// FetchTemperature busca a temperatura de uma cidade
-func (r *temperatureRepository) FetchTemperature(ctx context.Context, city string) (float64, error) {
+// weatherAPIResponse espelha o trecho da resposta de current.json que utilizamos
+type weatherAPIResponse struct {
+	Location struct {
+		Name    string  `json:"name"`
+		Region  string  `json:"region"`
+		Country string  `json:"country"`
+		Lat     float64 `json:"lat"`
+		Lon     float64 `json:"lon"`
+	} `json:"location"`
+	Current struct {
+		LastUpdatedEpoch int64   `json:"last_updated_epoch"`
+		TempC            float64 `json:"temp_c"`
+		FeelsLikeC       float64 `json:"feelslike_c"`
+		Humidity         int     `json:"humidity"`
+		WindKph          float64 `json:"wind_kph"`
+		WindDegree       int     `json:"wind_degree"`
+		WindDir          string  `json:"wind_dir"`
+		PressureMb       float64 `json:"pressure_mb"`
+		UV               float64 `json:"uv"`
+		Condition        struct {
+			Text string `json:"text"`
+			Code int    `json:"code"`
+		} `json:"condition"`
+	} `json:"current"`
+}
+
+// weatherAPIError espelha o corpo de erro da WeatherAPI
+type weatherAPIError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// FetchWeather busca as condições climáticas atuais de uma cidade ou coordenada
+func (r *temperatureRepository) FetchWeather(ctx context.Context, city string) (*WeatherObservation, error) {
 	tracer := otel.Tracer("service-b")
 	ctx, span := tracer.Start(ctx, "fetch-temperature")
 	defer span.End()
@@ -34,42 +101,78 @@ func (r *temperatureRepository) FetchTemperature(ctx context.Context, city strin
 	apiKey := config.AppConfig.WeatherAPIKey
 	encodedCity := url.QueryEscape(city)
 	url := fmt.Sprintf("%s?key=%s&q=%s", config.AppConfig.WeatherAPIURL, apiKey, encodedCity)
+	// A WeatherAPI traduz o texto da condição; as demais leituras não dependem do idioma
+	if lang := i18n.WeatherAPILang(i18n.FromContext(ctx)); lang != "" {
+		url += "&lang=" + lang
+	}
 
-	log.Printf("FetchTemperature: Fetching temperature for city: %s", city)
+	log.Printf("FetchWeather: Fetching weather for city: %s", city)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		span.SetStatus(codes.Error, "Failed to create request")
 		log.Printf("Error creating request: %v", err)
-		return 0, err
+		return nil, err
 	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		span.SetStatus(codes.Error, "Failed to fetch temperature")
 		log.Printf("Error fetching temperature: %v", err)
-		return 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusBadRequest {
+		var apiErr weatherAPIError
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error.Code == weatherAPINoMatchingLocation {
+			span.SetStatus(codes.Error, "Location not found")
+			log.Printf("FetchWeather: Location not found: %s", city)
+			return nil, ErrLocationNotFound
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		span.SetStatus(codes.Error, fmt.Sprintf("Non-OK HTTP status: %s", resp.Status))
 		log.Printf("Non-OK HTTP status: %s", resp.Status)
-		return 0, fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+		return nil, fmt.Errorf("non-OK HTTP status: %s", resp.Status)
 	}
 
-	var result struct {
-		Current struct {
-			TempC float64 `json:"temp_c"`
-		} `json:"current"`
-	}
+	var result weatherAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		span.SetStatus(codes.Error, "Failed to decode response")
 		log.Printf("Error decoding response: %v", err)
-		return 0, err
+		return nil, err
+	}
+
+	obs := &WeatherObservation{
+		LocationName:  result.Location.Name,
+		Region:        result.Location.Region,
+		Country:       result.Location.Country,
+		Lat:           result.Location.Lat,
+		Lon:           result.Location.Lon,
+		TempC:         result.Current.TempC,
+		FeelsLikeC:    result.Current.FeelsLikeC,
+		Humidity:      result.Current.Humidity,
+		WindKph:       result.Current.WindKph,
+		WindDegree:    result.Current.WindDegree,
+		WindDir:       result.Current.WindDir,
+		PressureMb:    result.Current.PressureMb,
+		UV:            result.Current.UV,
+		ConditionText: result.Current.Condition.Text,
+		ConditionCode: result.Current.Condition.Code,
+		FetchedAt:     time.Now().UTC(),
+	}
+	if result.Current.LastUpdatedEpoch > 0 {
+		obs.LastUpdated = time.Unix(result.Current.LastUpdatedEpoch, 0).UTC()
 	}
 
-	span.SetAttributes(attribute.String("city", city), attribute.Float64("temperature", result.Current.TempC))
+	span.SetAttributes(
+		attribute.String("city", city),
+		attribute.Float64("temperature", obs.TempC),
+		attribute.Int("humidity", obs.Humidity),
+		attribute.String("condition", obs.ConditionText),
+	)
 	span.SetStatus(codes.Ok, "Successfully fetched temperature")
-	return result.Current.TempC, nil
+	return obs, nil
 }
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Cabeçalhos enviados em cada notificação. A assinatura tem o formato "t=<unix>,v1=<hex>", em que
+// v1 é o HMAC-SHA256, com o segredo da inscrição, de "<unix>.<corpo>".
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-ID"
+)
+
+// WebhookNotifier entrega eventos de alerta ao webhook da inscrição. Retorna quantas tentativas
+// foram feitas e o erro da última quando nenhuma teve sucesso.
+type WebhookNotifier interface {
+	Notify(ctx context.Context, url, secret string, event AlertEvent) (int, error)
+}
+
+type webhookNotifier struct {
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewWebhookNotifier cria um WebhookNotifier que tenta até maxAttempts vezes, dobrando a espera
+// (a partir de backoff) entre as tentativas. Erros de rede, 429 e 5xx são repetidos; os demais
+// códigos 4xx são definitivos.
+func NewWebhookNotifier(client *http.Client, maxAttempts int, backoff time.Duration) WebhookNotifier {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &webhookNotifier{client: client, maxAttempts: maxAttempts, backoff: backoff}
+}
+
+// Notify envia o evento assinado com HMAC ao webhook
+func (n *webhookNotifier) Notify(ctx context.Context, url, secret string, event AlertEvent) (int, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "deliver-webhook")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("subscription_id", event.SubscriptionID),
+		attribute.String("event_type", event.Type),
+	)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error encoding event")
+		return 0, err
+	}
+
+	var lastErr error
+	wait := n.backoff
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		retry, err := n.send(ctx, url, secret, event, body)
+		if err == nil {
+			span.SetAttributes(attribute.Int("attempts", attempt))
+			span.SetStatus(codes.Ok, "Webhook delivered")
+			return attempt, nil
+		}
+		lastErr = err
+		log.Printf("WebhookNotifier: Attempt %d/%d for event %s failed: %v", attempt, n.maxAttempts, event.ID, err)
+
+		if !retry || attempt == n.maxAttempts {
+			span.SetAttributes(attribute.Int("attempts", attempt))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Webhook delivery failed")
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			span.SetAttributes(attribute.Int("attempts", attempt))
+			span.SetStatus(codes.Error, "Webhook delivery cancelled")
+			return attempt, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	return n.maxAttempts, lastErr
+}
+
+// send faz uma tentativa de entrega e informa se uma falha pode ser repetida
+func (n *webhookNotifier) send(ctx context.Context, url, secret string, event AlertEvent, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookIDHeader, event.ID)
+	req.Header.Set(WebhookSignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, SignWebhookPayload(secret, timestamp, body)))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+}
+
+// SignWebhookPayload calcula a assinatura v1 de uma notificação, para verificação pelo receptor
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
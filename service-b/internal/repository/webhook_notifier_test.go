@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier_SignsAndRetries(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		// Verificação como faria o receptor: t=<unix>,v1=<hmac>
+		parts := strings.Split(r.Header.Get(WebhookSignatureHeader), ",")
+		require.Len(t, parts, 2)
+		timestamp, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+		require.NoError(t, err)
+		expected := SignWebhookPayload(secret, timestamp, body)
+		assert.True(t, hmac.Equal([]byte(expected), []byte(strings.TrimPrefix(parts[1], "v1="))))
+		assert.Equal(t, "temperature.above", r.Header.Get(WebhookEventHeader))
+		assert.Equal(t, "evt-1", r.Header.Get(WebhookIDHeader))
+
+		var event AlertEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, "01001000", event.CEP)
+
+		// Falha temporária nas duas primeiras tentativas
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.Client(), 5, time.Millisecond)
+	attempts, err := notifier.Notify(context.Background(), server.URL, secret, AlertEvent{ID: "evt-1", Type: "temperature.above", CEP: "01001000"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestWebhookNotifier_PermanentFailure(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.Client(), 5, time.Millisecond)
+	attempts, err := notifier.Notify(context.Background(), server.URL, "secret", AlertEvent{ID: "evt-1"})
+
+	assert.ErrorContains(t, err, "410")
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWebhookNotifier_ExhaustsAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.Client(), 3, time.Millisecond)
+	attempts, err := notifier.Notify(context.Background(), server.URL, "secret", AlertEvent{ID: "evt-1"})
+
+	assert.ErrorContains(t, err, "429")
+	assert.Equal(t, 3, attempts)
+}
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"service-b/internal/netguard"
+	"service-b/internal/repository"
+	"shared/cep"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSubscription indica CEP, limites ou webhook fora das regras de inscrição
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+const (
+	// DefaultAlertHysteresis é quanto (em °C) a temperatura precisa voltar além do limite para
+	// o alerta ser encerrado, evitando notificações alternadas perto do limite
+	DefaultAlertHysteresis = 1.0
+	// MinWebhookSecretLength é o tamanho mínimo de um segredo informado pelo cliente
+	MinWebhookSecretLength = 16
+)
+
+// Tipos de evento enviados ao webhook
+const (
+	AlertEventAbove     = "temperature.above"
+	AlertEventBelow     = "temperature.below"
+	AlertEventRecovered = "temperature.normal"
+)
+
+// SubscriptionRequest são os dados de uma nova inscrição de alerta
+type SubscriptionRequest struct {
+	CEP        string   `json:"cep"`
+	AboveC     *float64 `json:"above_C"`
+	BelowC     *float64 `json:"below_C"`
+	WebhookURL string   `json:"webhook_url"`
+	Secret     string   `json:"secret"`
+}
+
+// AlertService gerencia as inscrições de alerta e verifica as temperaturas inscritas
+type AlertService interface {
+	Subscribe(ctx context.Context, req SubscriptionRequest) (*repository.Subscription, error)
+	List(ctx context.Context) ([]repository.Subscription, error)
+	Unsubscribe(ctx context.Context, id string) error
+	DeadLetters(ctx context.Context) ([]repository.DeadLetter, error)
+	CheckAll(ctx context.Context) error
+}
+
+type alertService struct {
+	repo       repository.AlertRepository
+	fetchCity  FetchCityService
+	fetchTemp  FetchTempService
+	notifier   repository.WebhookNotifier
+	hysteresis float64
+	resolver   netguard.Resolver
+	now        func() time.Time
+}
+
+// NewAlertService cria um novo serviço AlertService
+func NewAlertService(repo repository.AlertRepository, fetchCity FetchCityService, fetchTemp FetchTempService, notifier repository.WebhookNotifier, hysteresis float64) AlertService {
+	return &alertService{
+		repo:       repo,
+		fetchCity:  fetchCity,
+		fetchTemp:  fetchTemp,
+		notifier:   notifier,
+		hysteresis: hysteresis,
+		resolver:   net.DefaultResolver,
+		now:        time.Now,
+	}
+}
+
+// Subscribe valida e grava uma inscrição. A cidade do CEP é resolvida uma única vez; quando o
+// segredo não é informado, um é gerado e devolvido apenas nesta resposta.
+func (s *alertService) Subscribe(ctx context.Context, req SubscriptionRequest) (*repository.Subscription, error) {
+	code := cep.Normalize(req.CEP)
+	if _, err := cep.Resolve(code); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	if req.AboveC == nil && req.BelowC == nil {
+		return nil, fmt.Errorf("%w: above_C or below_C is required", ErrInvalidSubscription)
+	}
+	if req.AboveC != nil && req.BelowC != nil && *req.AboveC <= *req.BelowC {
+		return nil, fmt.Errorf("%w: above_C must be greater than below_C", ErrInvalidSubscription)
+	}
+	if err := s.validateWebhookURL(ctx, req.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = randomID(32)
+	} else if len(secret) < MinWebhookSecretLength {
+		return nil, fmt.Errorf("%w: secret must have at least %d characters", ErrInvalidSubscription, MinWebhookSecretLength)
+	}
+
+	city, err := s.fetchCity.Fetch(ctx, code)
+	if err != nil {
+		log.Printf("Error resolving city for alert subscription on CEP %s: %v", code, err)
+		return nil, err
+	}
+
+	sub := repository.Subscription{
+		ID:         randomID(16),
+		CEP:        code,
+		City:       city,
+		AboveC:     req.AboveC,
+		BelowC:     req.BelowC,
+		WebhookURL: req.WebhookURL,
+		Secret:     secret,
+		State:      repository.AlertStateNormal,
+		CreatedAt:  s.now().UTC(),
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		log.Printf("Error saving alert subscription: %v", err)
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// List retorna as inscrições sem os segredos
+func (s *alertService) List(ctx context.Context) ([]repository.Subscription, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		log.Printf("Error listing alert subscriptions: %v", err)
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// Unsubscribe remove uma inscrição
+func (s *alertService) Unsubscribe(ctx context.Context, id string) error {
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+// DeadLetters retorna as notificações que não puderam ser entregues
+func (s *alertService) DeadLetters(ctx context.Context) ([]repository.DeadLetter, error) {
+	return s.repo.ListDeadLetters(ctx)
+}
+
+// CheckAll busca a temperatura de cada cidade inscrita (uma vez por cidade) e notifica as
+// inscrições cujo estado mudou. Falhas de uma cidade não interrompem as demais.
+func (s *alertService) CheckAll(ctx context.Context) error {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		log.Printf("Error listing alert subscriptions: %v", err)
+		return err
+	}
+
+	byCity := map[string][]repository.Subscription{}
+	for _, sub := range subs {
+		byCity[sub.City] = append(byCity[sub.City], sub)
+	}
+
+	var deliveries sync.WaitGroup
+	for city, citySubs := range byCity {
+		obs, err := s.fetchTemp.Fetch(ctx, city)
+		if err != nil {
+			log.Printf("AlertService: Error fetching temperature for %s, skipping %d subscriptions: %v", city, len(citySubs), err)
+			continue
+		}
+		checkedAt := s.now().UTC()
+
+		for _, sub := range citySubs {
+			state := EvaluateAlert(sub.State, obs.TempC, sub.AboveC, sub.BelowC, s.hysteresis)
+			if err := s.repo.UpdateSubscriptionState(ctx, sub.ID, state, obs.TempC, checkedAt); err != nil {
+				log.Printf("AlertService: Error updating subscription %s: %v", sub.ID, err)
+				continue
+			}
+			if state == sub.State {
+				continue
+			}
+
+			event := newAlertEvent(sub, state, obs.TempC, checkedAt)
+			deliveries.Add(1)
+			go func(sub repository.Subscription) {
+				defer deliveries.Done()
+				s.deliver(ctx, sub, event)
+			}(sub)
+		}
+	}
+	deliveries.Wait()
+	return nil
+}
+
+// deliver envia o evento e, se todas as tentativas falharem, guarda-o na lista de não entregues
+func (s *alertService) deliver(ctx context.Context, sub repository.Subscription, event repository.AlertEvent) {
+	attempts, err := s.notifier.Notify(ctx, sub.WebhookURL, sub.Secret, event)
+	if err == nil {
+		return
+	}
+
+	log.Printf("AlertService: Moving event %s to dead letters after %d attempts: %v", event.ID, attempts, err)
+	letter := repository.DeadLetter{
+		Event:      event,
+		WebhookURL: sub.WebhookURL,
+		Attempts:   attempts,
+		LastError:  err.Error(),
+		FailedAt:   s.now().UTC(),
+	}
+	if err := s.repo.AddDeadLetter(context.WithoutCancel(ctx), letter); err != nil {
+		log.Printf("AlertService: Error saving dead letter for event %s: %v", event.ID, err)
+	}
+}
+
+// EvaluateAlert calcula o novo estado do alerta. Acima de above (ou abaixo de below) o alerta é
+// disparado; para voltar ao normal, a temperatura precisa recuar além do limite pela histerese.
+func EvaluateAlert(current repository.AlertState, tempC float64, above, below *float64, hysteresis float64) repository.AlertState {
+	switch {
+	case above != nil && tempC > *above:
+		return repository.AlertStateAbove
+	case below != nil && tempC < *below:
+		return repository.AlertStateBelow
+	case current == repository.AlertStateAbove && above != nil && tempC > *above-hysteresis:
+		return repository.AlertStateAbove
+	case current == repository.AlertStateBelow && below != nil && tempC < *below+hysteresis:
+		return repository.AlertStateBelow
+	}
+	return repository.AlertStateNormal
+}
+
+// RunAlertScheduler verifica as inscrições a cada interval até o contexto ser cancelado
+func RunAlertScheduler(ctx context.Context, alerts AlertService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := alerts.CheckAll(ctx); err != nil {
+				log.Printf("AlertScheduler: Check failed: %v", err)
+			}
+		}
+	}
+}
+
+func newAlertEvent(sub repository.Subscription, state repository.AlertState, tempC float64, at time.Time) repository.AlertEvent {
+	event := repository.AlertEvent{
+		ID:             randomID(16),
+		SubscriptionID: sub.ID,
+		CEP:            sub.CEP,
+		City:           sub.City,
+		TempC:          tempC,
+		State:          state,
+		PreviousState:  sub.State,
+		OccurredAt:     at,
+	}
+	switch state {
+	case repository.AlertStateAbove:
+		event.Type = AlertEventAbove
+		event.ThresholdC = sub.AboveC
+	case repository.AlertStateBelow:
+		event.Type = AlertEventBelow
+		event.ThresholdC = sub.BelowC
+	default:
+		event.Type = AlertEventRecovered
+	}
+	return event
+}
+
+// validateWebhookURL exige uma URL http(s) absoluta cujo host resolva apenas para endereços públicos
+func (s *alertService) validateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: webhook_url must be an absolute http(s) URL", ErrInvalidSubscription)
+	}
+	// A entrega confere de novo o endereço na conexão (netguard.NewTransport); aqui o cliente
+	// recebe o erro na inscrição em vez de uma notificação que nunca chega
+	if err := netguard.CheckHost(ctx, s.resolver, u.Hostname()); err != nil {
+		if errors.Is(err, netguard.ErrBlockedAddress) {
+			return fmt.Errorf("%w: webhook_url must not point to a private or internal address", ErrInvalidSubscription)
+		}
+		return fmt.Errorf("%w: webhook_url host can not be resolved", ErrInvalidSubscription)
+	}
+	return nil
+}
+
+// randomID gera um identificador hexadecimal aleatório com n bytes
+func randomID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("reading random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
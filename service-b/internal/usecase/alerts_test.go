@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"path/filepath"
+	"testing"
+
+	"service-b/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhookNotifier struct {
+	mock.Mock
+}
+
+func (m *MockWebhookNotifier) Notify(ctx context.Context, url, secret string, event repository.AlertEvent) (int, error) {
+	args := m.Called(ctx, url, secret, event)
+	return args.Int(0), args.Error(1)
+}
+
+// fakeResolver resolve os hosts dos testes sem acessar o DNS
+type fakeResolver map[string][]netip.Addr
+
+func (r fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func newTestAlertService(t *testing.T, cityRepo *MockRepository, tempRepo *MockTemperatureRepository, notifier *MockWebhookNotifier) (AlertService, repository.AlertRepository) {
+	t.Helper()
+	repo, err := repository.NewBoltAlertRepository(filepath.Join(t.TempDir(), "alerts.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	service := NewAlertService(repo, NewFetchCityService(cityRepo), NewFetchTempService(tempRepo), notifier, DefaultAlertHysteresis)
+	service.(*alertService).resolver = fakeResolver{
+		"example.com":          {netip.MustParseAddr("93.184.216.34")},
+		"internal.example.com": {netip.MustParseAddr("10.0.0.5")},
+	}
+	return service, repo
+}
+
+func TestEvaluateAlert(t *testing.T) {
+	above, below := 35.0, 2.0
+	normal, hot, cold := repository.AlertStateNormal, repository.AlertStateAbove, repository.AlertStateBelow
+
+	tests := []struct {
+		current  repository.AlertState
+		tempC    float64
+		expected repository.AlertState
+	}{
+		{normal, 20, normal},
+		{normal, 35, normal},
+		{normal, 35.1, hot},
+		{hot, 34.5, hot},  // dentro da histerese
+		{hot, 34, normal}, // recuou além da histerese
+		{normal, 1.9, cold},
+		{cold, 2.8, cold}, // dentro da histerese
+		{cold, 3.1, normal},
+		{hot, 1, cold}, // mudança direta entre extremos
+	}
+
+	for _, test := range tests {
+		got := EvaluateAlert(test.current, test.tempC, &above, &below, DefaultAlertHysteresis)
+		assert.Equal(t, test.expected, got, "%s at %.1f", test.current, test.tempC)
+	}
+
+	// Somente limite inferior
+	assert.Equal(t, normal, EvaluateAlert(normal, 50, nil, &below, DefaultAlertHysteresis))
+}
+
+func TestAlertService_Subscribe(t *testing.T) {
+	cityRepo := new(MockRepository)
+	service, _ := newTestAlertService(t, cityRepo, new(MockTemperatureRepository), new(MockWebhookNotifier))
+	cityRepo.On("FetchCityFromCEP", mock.Anything, "01001000").Return("São Paulo", nil)
+
+	above := 35.0
+	sub, err := service.Subscribe(context.Background(), SubscriptionRequest{
+		CEP:        "01001-000",
+		AboveC:     &above,
+		WebhookURL: "https://example.com/hooks/temperature",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "01001000", sub.CEP)
+	assert.Equal(t, "São Paulo", sub.City)
+	assert.Equal(t, repository.AlertStateNormal, sub.State)
+	assert.Len(t, sub.Secret, 64)
+
+	subs, err := service.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, sub.ID, subs[0].ID)
+	assert.Empty(t, subs[0].Secret)
+
+	require.NoError(t, service.Unsubscribe(context.Background(), sub.ID))
+	assert.ErrorIs(t, service.Unsubscribe(context.Background(), sub.ID), repository.ErrSubscriptionNotFound)
+}
+
+func TestAlertService_SubscribeInvalid(t *testing.T) {
+	service, _ := newTestAlertService(t, new(MockRepository), new(MockTemperatureRepository), new(MockWebhookNotifier))
+	above, below := 35.0, 2.0
+
+	requests := []SubscriptionRequest{
+		{CEP: "123", AboveC: &above, WebhookURL: "https://example.com"},
+		{CEP: "00000100", AboveC: &above, WebhookURL: "https://example.com"},
+		{CEP: "01001000", WebhookURL: "https://example.com"},
+		{CEP: "01001000", AboveC: &below, BelowC: &above, WebhookURL: "https://example.com"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "ftp://example.com"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "/relative"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "https://example.com", Secret: "short"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "http://127.0.0.1:8080/hook"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "http://[::1]/hook"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "http://169.254.169.254/latest/meta-data"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "https://internal.example.com/hook"},
+		{CEP: "01001000", AboveC: &above, WebhookURL: "https://unknown.example.com/hook"},
+	}
+
+	for _, req := range requests {
+		_, err := service.Subscribe(context.Background(), req)
+		assert.ErrorIs(t, err, ErrInvalidSubscription, "%+v", req)
+	}
+}
+
+func TestAlertService_CheckAllWithHysteresis(t *testing.T) {
+	cityRepo := new(MockRepository)
+	tempRepo := new(MockTemperatureRepository)
+	notifier := new(MockWebhookNotifier)
+	service, repo := newTestAlertService(t, cityRepo, tempRepo, notifier)
+	ctx := context.Background()
+
+	cityRepo.On("FetchCityFromCEP", mock.Anything, "01001000").Return("São Paulo", nil)
+	above := 35.0
+	sub, err := service.Subscribe(ctx, SubscriptionRequest{CEP: "01001000", AboveC: &above, WebhookURL: "https://example.com/hook"})
+	require.NoError(t, err)
+
+	check := func(tempC float64) {
+		tempRepo.On("FetchWeather", mock.Anything, "São Paulo").Return(&repository.WeatherObservation{TempC: tempC}, nil).Once()
+		require.NoError(t, service.CheckAll(ctx))
+	}
+	isEvent := func(eventType string, tempC float64) interface{} {
+		return mock.MatchedBy(func(e repository.AlertEvent) bool {
+			return e.Type == eventType && e.TempC == tempC && e.SubscriptionID == sub.ID && e.CEP == "01001000"
+		})
+	}
+
+	// Ultrapassa o limite: notifica
+	notifier.On("Notify", mock.Anything, "https://example.com/hook", sub.Secret, isEvent(AlertEventAbove, 36)).Return(1, nil).Once()
+	check(36)
+
+	// Oscila perto do limite: sem novas notificações
+	check(35.5)
+	check(34.5)
+
+	// Recua além da histerese: notifica a normalização
+	notifier.On("Notify", mock.Anything, "https://example.com/hook", sub.Secret, isEvent(AlertEventRecovered, 33.5)).Return(1, nil).Once()
+	check(33.5)
+
+	notifier.AssertExpectations(t)
+	subs, err := repo.ListSubscriptions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, repository.AlertStateNormal, subs[0].State)
+	assert.Equal(t, 33.5, *subs[0].LastTempC)
+}
+
+func TestAlertService_DeadLetter(t *testing.T) {
+	cityRepo := new(MockRepository)
+	tempRepo := new(MockTemperatureRepository)
+	notifier := new(MockWebhookNotifier)
+	service, _ := newTestAlertService(t, cityRepo, tempRepo, notifier)
+	ctx := context.Background()
+
+	cityRepo.On("FetchCityFromCEP", mock.Anything, "90010000").Return("Porto Alegre", nil)
+	below := 2.0
+	sub, err := service.Subscribe(ctx, SubscriptionRequest{CEP: "90010000", BelowC: &below, WebhookURL: "https://example.com/hook"})
+	require.NoError(t, err)
+
+	tempRepo.On("FetchWeather", mock.Anything, "Porto Alegre").Return(&repository.WeatherObservation{TempC: 0.5}, nil)
+	notifier.On("Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(5, errors.New("webhook responded 503 Service Unavailable"))
+
+	require.NoError(t, service.CheckAll(ctx))
+
+	letters, err := service.DeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, sub.ID, letters[0].Event.SubscriptionID)
+	assert.Equal(t, AlertEventBelow, letters[0].Event.Type)
+	assert.Equal(t, 2.0, *letters[0].Event.ThresholdC)
+	assert.Equal(t, 5, letters[0].Attempts)
+	assert.Equal(t, "webhook responded 503 Service Unavailable", letters[0].LastError)
+}
+
+func TestAlertService_CheckAllSkipsFailedCity(t *testing.T) {
+	cityRepo := new(MockRepository)
+	tempRepo := new(MockTemperatureRepository)
+	notifier := new(MockWebhookNotifier)
+	service, _ := newTestAlertService(t, cityRepo, tempRepo, notifier)
+	ctx := context.Background()
+
+	cityRepo.On("FetchCityFromCEP", mock.Anything, "01001000").Return("São Paulo", nil)
+	above := 35.0
+	_, err := service.Subscribe(ctx, SubscriptionRequest{CEP: "01001000", AboveC: &above, WebhookURL: "https://example.com/hook"})
+	require.NoError(t, err)
+
+	tempRepo.On("FetchWeather", mock.Anything, "São Paulo").Return(nil, errors.New("weatherapi unavailable"))
+
+	require.NoError(t, service.CheckAll(ctx))
+	notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -11,3 +11,8 @@ func CelsiusToFahrenheit(c float64) float64 {
 func CelsiusToKelvin(c float64) float64 {
 	return math.Round((c+273.15)*100) / 100
 }
+
+// FahrenheitToCelsius converte Fahrenheit para Celsius e arredonda para 2 casas decimais
+func FahrenheitToCelsius(f float64) float64 {
+	return math.Round((f-32)/1.8*100) / 100
+}
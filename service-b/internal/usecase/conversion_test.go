@@ -39,3 +39,20 @@ func TestCelsiusToKelvin(t *testing.T) {
 		assert.Equal(t, test.expected, result)
 	}
 }
+
+func TestFahrenheitToCelsius(t *testing.T) {
+	tests := []struct {
+		fahrenheit float64
+		expected   float64
+	}{
+		{32, 0},
+		{212, 100},
+		{-40, -40},
+		{98.6, 37},
+	}
+
+	for _, test := range tests {
+		result := FahrenheitToCelsius(test.fahrenheit)
+		assert.Equal(t, test.expected, result)
+	}
+}
@@ -20,6 +20,12 @@ func (m *MockRepository) FetchCityFromCEP(ctx context.Context, cep string) (stri
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockRepository) SearchAddresses(ctx context.Context, uf, city, street string) ([]repository.Address, error) {
+	args := m.Called(ctx, uf, city, street)
+	addresses, _ := args.Get(0).([]repository.Address)
+	return addresses, args.Error(1)
+}
+
 func TestFetchCityService_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
 	service := NewFetchCityService(mockRepo)
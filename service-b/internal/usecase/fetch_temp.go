@@ -6,9 +6,9 @@ import (
 	"service-b/internal/repository"
 )
 
-// FetchTempService define a interface para buscar a temperatura de uma cidade
+// FetchTempService define a interface para buscar as condições climáticas de uma cidade
 type FetchTempService interface {
-	Fetch(ctx context.Context, city string) (float64, error)
+	Fetch(ctx context.Context, city string) (*repository.WeatherObservation, error)
 }
 
 type fetchTempService struct {
@@ -20,12 +20,12 @@ func NewFetchTempService(repo repository.TemperatureRepository) FetchTempService
 	return &fetchTempService{repo: repo}
 }
 
-// Fetch busca a temperatura de uma cidade
-func (s *fetchTempService) Fetch(ctx context.Context, city string) (float64, error) {
-	temp, err := s.repo.FetchTemperature(ctx, city)
+// Fetch busca as condições climáticas atuais de uma cidade
+func (s *fetchTempService) Fetch(ctx context.Context, city string) (*repository.WeatherObservation, error) {
+	obs, err := s.repo.FetchWeather(ctx, city)
 	if err != nil {
 		log.Printf("Error fetching temperature for city %s: %v", city, err)
-		return 0, err
+		return nil, err
 	}
-	return temp, nil
+	return obs, nil
 }
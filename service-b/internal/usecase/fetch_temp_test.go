@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 
+	"service-b/internal/repository"
+
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -13,9 +15,10 @@ type MockTemperatureRepository struct {
 	mock.Mock
 }
 
-func (m *MockTemperatureRepository) FetchTemperature(ctx context.Context, city string) (float64, error) {
+func (m *MockTemperatureRepository) FetchWeather(ctx context.Context, city string) (*repository.WeatherObservation, error) {
 	args := m.Called(ctx, city)
-	return args.Get(0).(float64), args.Error(1)
+	obs, _ := args.Get(0).(*repository.WeatherObservation)
+	return obs, args.Error(1)
 }
 
 func TestFetchTempService_Success(t *testing.T) {
@@ -23,17 +26,17 @@ func TestFetchTempService_Success(t *testing.T) {
 	service := NewFetchTempService(mockRepo)
 
 	city := "São Paulo"
-	expectedTemp := 25.5
+	expectedObs := &repository.WeatherObservation{TempC: 25.5, Humidity: 60, ConditionText: "Sunny"}
 
 	// Configuração do mock
-	mockRepo.On("FetchTemperature", mock.Anything, city).Return(expectedTemp, nil)
+	mockRepo.On("FetchWeather", mock.Anything, city).Return(expectedObs, nil)
 
 	// Execução do teste
-	temp, err := service.Fetch(context.Background(), city)
+	obs, err := service.Fetch(context.Background(), city)
 
 	// Validação
 	require.NoError(t, err)
-	require.Equal(t, expectedTemp, temp)
+	require.Equal(t, expectedObs, obs)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -46,15 +49,15 @@ func TestFetchTempService_APIError(t *testing.T) {
 	expectedError := fmt.Errorf("API error")
 
 	// Configuração do mock para erro de comunicação com a API
-	mockRepo.On("FetchTemperature", mock.Anything, city).Return(0.0, expectedError)
+	mockRepo.On("FetchWeather", mock.Anything, city).Return(nil, expectedError)
 
 	// Execução do teste
-	temp, err := service.Fetch(context.Background(), city)
+	obs, err := service.Fetch(context.Background(), city)
 
 	// Validação
 	require.Error(t, err)
 	require.Equal(t, expectedError, err)
-	require.Equal(t, 0.0, temp)
+	require.Nil(t, obs)
 
 	mockRepo.AssertExpectations(t)
 }
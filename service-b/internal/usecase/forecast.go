@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"service-b/internal/repository"
+)
+
+// ErrInvalidForecastDays indica um número de dias fora do intervalo aceito pela WeatherAPI
+var ErrInvalidForecastDays = errors.New("invalid forecast days")
+
+const (
+	// DefaultForecastDays é o número de dias da previsão quando o cliente não informa
+	DefaultForecastDays = 3
+	// MaxForecastDays é o limite de dias da previsão da WeatherAPI
+	MaxForecastDays = 14
+)
+
+// ForecastService define a interface para buscar a previsão diária de uma cidade
+type ForecastService interface {
+	Forecast(ctx context.Context, city string, days int) ([]repository.ForecastDay, error)
+}
+
+type forecastService struct {
+	repo repository.ForecastRepository
+}
+
+// NewForecastService cria um novo serviço ForecastService
+func NewForecastService(repo repository.ForecastRepository) ForecastService {
+	return &forecastService{repo: repo}
+}
+
+// Forecast busca a previsão dos próximos dias (1 a MaxForecastDays, contando hoje) de uma cidade
+func (s *forecastService) Forecast(ctx context.Context, city string, days int) ([]repository.ForecastDay, error) {
+	if days < 1 || days > MaxForecastDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidForecastDays, MaxForecastDays)
+	}
+
+	forecast, err := s.repo.FetchForecast(ctx, city, days)
+	if err != nil {
+		log.Printf("Error fetching forecast for city %s: %v", city, err)
+		return nil, err
+	}
+	// A WeatherAPI limita os dias conforme o plano; nunca devolvemos mais do que o pedido
+	if len(forecast) > days {
+		forecast = forecast[:days]
+	}
+	return forecast, nil
+}
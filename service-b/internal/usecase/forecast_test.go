@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service-b/internal/repository"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockForecastRepository struct {
+	mock.Mock
+}
+
+func (m *MockForecastRepository) FetchForecast(ctx context.Context, city string, days int) ([]repository.ForecastDay, error) {
+	args := m.Called(ctx, city, days)
+	forecast, _ := args.Get(0).([]repository.ForecastDay)
+	return forecast, args.Error(1)
+}
+
+func TestForecastService_Success(t *testing.T) {
+	mockRepo := new(MockForecastRepository)
+	service := NewForecastService(mockRepo)
+
+	days := []repository.ForecastDay{
+		{Date: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), MaxTempC: 30, MinTempC: 19},
+		{Date: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), MaxTempC: 27, MinTempC: 18},
+		{Date: time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), MaxTempC: 25, MinTempC: 17},
+	}
+	mockRepo.On("FetchForecast", mock.Anything, "São Paulo", 2).Return(days, nil)
+
+	forecast, err := service.Forecast(context.Background(), "São Paulo", 2)
+
+	require.NoError(t, err)
+	require.Equal(t, days[:2], forecast)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestForecastService_InvalidDays(t *testing.T) {
+	mockRepo := new(MockForecastRepository)
+	service := NewForecastService(mockRepo)
+
+	for _, days := range []int{0, -1, MaxForecastDays + 1} {
+		_, err := service.Forecast(context.Background(), "São Paulo", days)
+		require.ErrorIs(t, err, ErrInvalidForecastDays)
+	}
+	mockRepo.AssertNotCalled(t, "FetchForecast", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForecastService_RepositoryError(t *testing.T) {
+	mockRepo := new(MockForecastRepository)
+	service := NewForecastService(mockRepo)
+
+	mockRepo.On("FetchForecast", mock.Anything, "Atlantis", 3).Return(nil, repository.ErrLocationNotFound)
+
+	forecast, err := service.Forecast(context.Background(), "Atlantis", 3)
+
+	require.True(t, errors.Is(err, repository.ErrLocationNotFound))
+	require.Nil(t, forecast)
+}
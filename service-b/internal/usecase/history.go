@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"service-b/internal/historyexport"
+	"service-b/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidHistoryQuery indica janela, intervalo ou limite fora das regras das consultas de histórico
+var ErrInvalidHistoryQuery = errors.New("invalid history query")
+
+const (
+	// DefaultTopLimit é a quantidade padrão de CEPs no ranking
+	DefaultTopLimit = 10
+	// MaxTopLimit é a quantidade máxima de CEPs no ranking
+	MaxTopLimit = 100
+	// MaxSeriesPoints limita a quantidade de intervalos de uma série temporal
+	MaxSeriesPoints = 1000
+
+	// historyBufferSize é a quantidade de consultas aguardando gravação antes de descartar novas
+	historyBufferSize = 1024
+)
+
+// CEPCount é um item do ranking de CEPs mais consultados
+type CEPCount struct {
+	CEP      string   `json:"cep"`
+	City     string   `json:"city,omitempty"`
+	Count    int      `json:"count"`
+	AvgTempC *float64 `json:"avg_temp_C,omitempty"`
+}
+
+// TemperaturePoint resume as temperaturas retornadas para uma cidade em um intervalo
+type TemperaturePoint struct {
+	Time     time.Time `json:"time"`
+	Count    int       `json:"count"`
+	AvgTempC float64   `json:"avg_temp_C"`
+	MinTempC float64   `json:"min_temp_C"`
+	MaxTempC float64   `json:"max_temp_C"`
+}
+
+// ErrorRatePoint resume as falhas de um intervalo: erros do cliente (4xx), do servidor (5xx)
+// e a fração das consultas que falharam
+type ErrorRatePoint struct {
+	Time         time.Time `json:"time"`
+	Total        int       `json:"total"`
+	ClientErrors int       `json:"client_errors"`
+	ServerErrors int       `json:"server_errors"`
+	ErrorRate    float64   `json:"error_rate"`
+}
+
+// HistoryService registra as consultas de CEP e responde às consultas analíticas sobre elas
+type HistoryService interface {
+	Record(ctx context.Context, lookup repository.Lookup)
+	TopCEPs(ctx context.Context, from, to time.Time, limit int) ([]CEPCount, error)
+	CityTemperatures(ctx context.Context, city string, from, to time.Time, interval time.Duration) ([]TemperaturePoint, error)
+	ErrorRates(ctx context.Context, from, to time.Time, interval time.Duration) ([]ErrorRatePoint, error)
+	Export(ctx context.Context, w io.Writer, opts historyexport.Options) (int, error)
+	Close() error
+}
+
+type historyService struct {
+	repo    repository.HistoryRepository
+	pending chan repository.Lookup
+	done    sync.WaitGroup
+	// mu protege closed: Record envia com o RLock, e Close fecha pending com o Lock
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewHistoryService cria um novo serviço HistoryService. As consultas são gravadas em segundo
+// plano para não somar a escrita em disco à latência da requisição.
+func NewHistoryService(repo repository.HistoryRepository) HistoryService {
+	s := &historyService{repo: repo, pending: make(chan repository.Lookup, historyBufferSize)}
+	s.done.Add(1)
+	go s.write()
+	return s
+}
+
+// Record enfileira a consulta para gravação; se a fila estiver cheia ou o serviço já tiver sido
+// encerrado (requisições ainda em andamento no desligamento), a consulta é descartada
+func (s *historyService) Record(ctx context.Context, lookup repository.Lookup) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		log.Printf("HistoryService: Closed, dropping lookup for CEP %s", lookup.CEP)
+		return
+	}
+	select {
+	case s.pending <- lookup:
+	default:
+		log.Printf("HistoryService: Buffer full, dropping lookup for CEP %s", lookup.CEP)
+	}
+}
+
+func (s *historyService) write() {
+	defer s.done.Done()
+	for lookup := range s.pending {
+		if err := s.repo.Record(context.Background(), lookup); err != nil {
+			log.Printf("HistoryService: Error recording lookup for CEP %s: %v", lookup.CEP, err)
+		}
+	}
+}
+
+// Close grava as consultas pendentes e encerra o serviço
+func (s *historyService) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.pending)
+	}
+	s.mu.Unlock()
+	s.done.Wait()
+	return nil
+}
+
+// TopCEPs retorna os CEPs mais consultados com sucesso na janela, com a temperatura média retornada
+func (s *historyService) TopCEPs(ctx context.Context, from, to time.Time, limit int) ([]CEPCount, error) {
+	if limit < 1 || limit > MaxTopLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidHistoryQuery, MaxTopLimit)
+	}
+
+	type aggregate struct {
+		CEPCount
+		tempSum   float64
+		tempCount int
+	}
+	byCEP := map[string]*aggregate{}
+
+	err := s.repo.Iterate(ctx, from, to, func(l repository.Lookup) error {
+		if l.Status != http.StatusOK {
+			return nil
+		}
+		agg, ok := byCEP[l.CEP]
+		if !ok {
+			agg = &aggregate{CEPCount: CEPCount{CEP: l.CEP}}
+			byCEP[l.CEP] = agg
+		}
+		agg.Count++
+		agg.City = l.City
+		if l.TempC != nil {
+			agg.tempSum += *l.TempC
+			agg.tempCount++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error reading lookup history: %v", err)
+		return nil, err
+	}
+
+	top := make([]CEPCount, 0, len(byCEP))
+	for _, agg := range byCEP {
+		if agg.tempCount > 0 {
+			avg := round2(agg.tempSum / float64(agg.tempCount))
+			agg.AvgTempC = &avg
+		}
+		top = append(top, agg.CEPCount)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].CEP < top[j].CEP
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top, nil
+}
+
+// CityTemperatures retorna a série temporal das temperaturas retornadas para a cidade
+// (sem diferenciar caixa), agrupada por intervalo; intervalos sem consultas são omitidos
+func (s *historyService) CityTemperatures(ctx context.Context, city string, from, to time.Time, interval time.Duration) ([]TemperaturePoint, error) {
+	if err := validateSeries(from, to, interval); err != nil {
+		return nil, err
+	}
+
+	points := map[int]*TemperaturePoint{}
+	err := s.repo.Iterate(ctx, from, to, func(l repository.Lookup) error {
+		if l.TempC == nil || !strings.EqualFold(l.City, city) {
+			return nil
+		}
+		slot := int(l.Time.Sub(from) / interval)
+		p, ok := points[slot]
+		if !ok {
+			p = &TemperaturePoint{Time: from.Add(time.Duration(slot) * interval), MinTempC: math.Inf(1), MaxTempC: math.Inf(-1)}
+			points[slot] = p
+		}
+		p.Count++
+		p.AvgTempC += *l.TempC
+		p.MinTempC = math.Min(p.MinTempC, *l.TempC)
+		p.MaxTempC = math.Max(p.MaxTempC, *l.TempC)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error reading lookup history: %v", err)
+		return nil, err
+	}
+
+	series := make([]TemperaturePoint, 0, len(points))
+	for _, p := range points {
+		p.AvgTempC = round2(p.AvgTempC / float64(p.Count))
+		series = append(series, *p)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Time.Before(series[j].Time) })
+	return series, nil
+}
+
+// ErrorRates retorna a taxa de falhas das consultas por intervalo; intervalos sem consultas são omitidos
+func (s *historyService) ErrorRates(ctx context.Context, from, to time.Time, interval time.Duration) ([]ErrorRatePoint, error) {
+	if err := validateSeries(from, to, interval); err != nil {
+		return nil, err
+	}
+
+	points := map[int]*ErrorRatePoint{}
+	err := s.repo.Iterate(ctx, from, to, func(l repository.Lookup) error {
+		slot := int(l.Time.Sub(from) / interval)
+		p, ok := points[slot]
+		if !ok {
+			p = &ErrorRatePoint{Time: from.Add(time.Duration(slot) * interval)}
+			points[slot] = p
+		}
+		p.Total++
+		switch {
+		case l.Status >= 500:
+			p.ServerErrors++
+		case l.Status >= 400:
+			p.ClientErrors++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error reading lookup history: %v", err)
+		return nil, err
+	}
+
+	series := make([]ErrorRatePoint, 0, len(points))
+	for _, p := range points {
+		p.ErrorRate = RoundTo(float64(p.ClientErrors+p.ServerErrors)/float64(p.Total), 4)
+		series = append(series, *p)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Time.Before(series[j].Time) })
+	return series, nil
+}
+
+// Export grava as consultas do período no formato pedido, sem carregá-las todas em memória
+func (s *historyService) Export(ctx context.Context, w io.Writer, opts historyexport.Options) (int, error) {
+	if !opts.From.IsZero() && !opts.To.IsZero() && !opts.From.Before(opts.To) {
+		return 0, fmt.Errorf("%w: window must end after it starts", ErrInvalidHistoryQuery)
+	}
+	rows, err := historyexport.Write(ctx, w, s.repo, opts)
+	if errors.Is(err, historyexport.ErrInvalidOptions) {
+		return rows, fmt.Errorf("%w: %v", ErrInvalidHistoryQuery, err)
+	}
+	if err != nil {
+		log.Printf("Error exporting lookup history: %v", err)
+	}
+	return rows, err
+}
+
+func validateSeries(from, to time.Time, interval time.Duration) error {
+	if !from.Before(to) {
+		return fmt.Errorf("%w: window must end after it starts", ErrInvalidHistoryQuery)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("%w: interval must be positive", ErrInvalidHistoryQuery)
+	}
+	if to.Sub(from)/interval > MaxSeriesPoints {
+		return fmt.Errorf("%w: window has more than %d intervals", ErrInvalidHistoryQuery, MaxSeriesPoints)
+	}
+	return nil
+}
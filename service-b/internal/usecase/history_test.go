@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"service-b/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHistory(t *testing.T, lookups ...repository.Lookup) HistoryService {
+	t.Helper()
+	repo, err := repository.NewBoltHistoryRepository(filepath.Join(t.TempDir(), "history.db"), false)
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	for _, l := range lookups {
+		require.NoError(t, repo.Record(context.Background(), l))
+	}
+	service := NewHistoryService(repo)
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func temp(v float64) *float64 { return &v }
+
+var historyStart = time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func TestHistoryService_TopCEPs(t *testing.T) {
+	service := newTestHistory(t,
+		repository.Lookup{Time: historyStart, CEP: "01001000", City: "São Paulo", TempC: temp(20), Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(time.Minute), CEP: "01001000", City: "São Paulo", TempC: temp(22), Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(2 * time.Minute), CEP: "20040020", City: "Rio de Janeiro", TempC: temp(30), Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(3 * time.Minute), CEP: "20040020", Status: http.StatusInternalServerError},
+		repository.Lookup{Time: historyStart.Add(2 * time.Hour), CEP: "20040020", City: "Rio de Janeiro", TempC: temp(31), Status: http.StatusOK},
+	)
+
+	top, err := service.TopCEPs(context.Background(), historyStart, historyStart.Add(time.Hour), 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, []CEPCount{
+		{CEP: "01001000", City: "São Paulo", Count: 2, AvgTempC: temp(21)},
+		{CEP: "20040020", City: "Rio de Janeiro", Count: 1, AvgTempC: temp(30)},
+	}, top)
+
+	// Empate em 2 consultas: desempate pelo CEP
+	top, err = service.TopCEPs(context.Background(), historyStart, historyStart.Add(3*time.Hour), 1)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, "01001000", top[0].CEP)
+
+	_, err = service.TopCEPs(context.Background(), historyStart, historyStart.Add(time.Hour), MaxTopLimit+1)
+	assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+}
+
+func TestHistoryService_CityTemperatures(t *testing.T) {
+	service := newTestHistory(t,
+		repository.Lookup{Time: historyStart, CEP: "01001000", City: "São Paulo", TempC: temp(20), Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(30 * time.Minute), CEP: "01310100", City: "São Paulo", TempC: temp(24), Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(40 * time.Minute), CEP: "20040020", City: "Rio de Janeiro", TempC: temp(35), Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(2*time.Hour + time.Minute), CEP: "01001000", City: "São Paulo", TempC: temp(18), Status: http.StatusOK},
+	)
+
+	series, err := service.CityTemperatures(context.Background(), "são paulo", historyStart, historyStart.Add(3*time.Hour), time.Hour)
+
+	require.NoError(t, err)
+	assert.Equal(t, []TemperaturePoint{
+		{Time: historyStart, Count: 2, AvgTempC: 22, MinTempC: 20, MaxTempC: 24},
+		{Time: historyStart.Add(2 * time.Hour), Count: 1, AvgTempC: 18, MinTempC: 18, MaxTempC: 18},
+	}, series)
+}
+
+func TestHistoryService_ErrorRates(t *testing.T) {
+	service := newTestHistory(t,
+		repository.Lookup{Time: historyStart, CEP: "01001000", Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(time.Minute), CEP: "123", Status: http.StatusUnprocessableEntity},
+		repository.Lookup{Time: historyStart.Add(2 * time.Minute), CEP: "01001000", Status: http.StatusInternalServerError},
+		repository.Lookup{Time: historyStart.Add(3 * time.Minute), CEP: "01001000", Status: http.StatusOK},
+		repository.Lookup{Time: historyStart.Add(90 * time.Minute), CEP: "01001000", Status: http.StatusOK},
+	)
+
+	series, err := service.ErrorRates(context.Background(), historyStart, historyStart.Add(2*time.Hour), time.Hour)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ErrorRatePoint{
+		{Time: historyStart, Total: 4, ClientErrors: 1, ServerErrors: 1, ErrorRate: 0.5},
+		{Time: historyStart.Add(time.Hour), Total: 1},
+	}, series)
+}
+
+func TestHistoryService_InvalidSeries(t *testing.T) {
+	service := newTestHistory(t)
+	ctx := context.Background()
+
+	_, err := service.ErrorRates(ctx, historyStart, historyStart, time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+
+	_, err = service.ErrorRates(ctx, historyStart, historyStart.Add(time.Hour), 0)
+	assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+
+	_, err = service.CityTemperatures(ctx, "São Paulo", historyStart, historyStart.Add(30*24*time.Hour), time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+}
+
+func TestHistoryService_RecordFlushesOnClose(t *testing.T) {
+	repo, err := repository.NewBoltHistoryRepository(filepath.Join(t.TempDir(), "history.db"), false)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	service := NewHistoryService(repo)
+	service.Record(context.Background(), repository.Lookup{Time: historyStart, CEP: "01001000", Status: http.StatusOK})
+	service.Record(context.Background(), repository.Lookup{Time: historyStart.Add(time.Second), CEP: "20040020", Status: http.StatusOK})
+	require.NoError(t, service.Close())
+
+	var ceps []string
+	err = repo.Iterate(context.Background(), time.Time{}, time.Time{}, func(l repository.Lookup) error {
+		ceps = append(ceps, l.CEP)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01001000", "20040020"}, ceps)
+}
+
+func TestHistoryService_RecordAfterCloseIsDropped(t *testing.T) {
+	repo, err := repository.NewBoltHistoryRepository(filepath.Join(t.TempDir(), "history.db"), false)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	service := NewHistoryService(repo)
+	require.NoError(t, service.Close())
+	require.NoError(t, service.Close())
+
+	assert.NotPanics(t, func() {
+		service.Record(context.Background(), repository.Lookup{Time: historyStart, CEP: "01001000", Status: http.StatusOK})
+	})
+	count := 0
+	err = repo.Iterate(context.Background(), time.Time{}, time.Time{}, func(l repository.Lookup) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestHistoryService_RecordConcurrentWithClose(t *testing.T) {
+	repo, err := repository.NewBoltHistoryRepository(filepath.Join(t.TempDir(), "history.db"), false)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	service := NewHistoryService(repo)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				service.Record(context.Background(), repository.Lookup{Time: historyStart, CEP: "01001000", Status: http.StatusOK})
+			}
+		}()
+	}
+	require.NoError(t, service.Close())
+	wg.Wait()
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"service-b/internal/repository"
+	"shared/cep"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidLocation indica coordenadas, UF ou nomes fora das regras de busca
+var ErrInvalidLocation = errors.New("invalid location")
+
+// MinSearchTermLength é o tamanho mínimo de cidade e logradouro aceito pelo ViaCEP
+const MinSearchTermLength = 3
+
+// LocationLookup é o resultado de uma busca reversa: a cidade encontrada, os CEPs candidatos
+// e as condições climáticas atuais
+type LocationLookup struct {
+	City    string
+	UF      string
+	CEPs    []string
+	Weather *repository.WeatherObservation
+}
+
+// LocationService define a interface para resolver coordenadas ou cidade em CEPs e clima
+type LocationService interface {
+	ByCoordinates(ctx context.Context, lat, lon float64, street string) (*LocationLookup, error)
+	ByCity(ctx context.Context, uf, city, street string) (*LocationLookup, error)
+}
+
+type locationService struct {
+	cityRepo repository.CityRepository
+	tempRepo repository.TemperatureRepository
+}
+
+// NewLocationService cria um novo serviço LocationService
+func NewLocationService(cityRepo repository.CityRepository, tempRepo repository.TemperatureRepository) LocationService {
+	return &locationService{cityRepo: cityRepo, tempRepo: tempRepo}
+}
+
+// ByCoordinates busca o clima em uma coordenada. A cidade vem da WeatherAPI e, quando o
+// logradouro é informado, os CEPs candidatos são buscados no ViaCEP.
+func (s *locationService) ByCoordinates(ctx context.Context, lat, lon float64, street string) (*LocationLookup, error) {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("%w: coordinates out of range", ErrInvalidLocation)
+	}
+
+	query := strconv.FormatFloat(lat, 'f', -1, 64) + "," + strconv.FormatFloat(lon, 'f', -1, 64)
+	obs, err := s.tempRepo.FetchWeather(ctx, query)
+	if err != nil {
+		log.Printf("Error fetching weather for coordinates %s: %v", query, err)
+		return nil, err
+	}
+
+	lookup := &LocationLookup{City: obs.LocationName, Weather: obs}
+	if state, ok := cep.StateFromName(obs.Region); ok {
+		lookup.UF = state.UF
+	}
+
+	if street != "" && lookup.UF != "" {
+		addresses, err := s.search(ctx, lookup.UF, lookup.City, street)
+		if err != nil {
+			return nil, err
+		}
+		lookup.CEPs = cepsOf(addresses)
+	}
+	return lookup, nil
+}
+
+// ByCity busca o clima de uma cidade. Quando o logradouro é informado, os CEPs candidatos são
+// buscados no ViaCEP e o nome oficial da cidade é usado na consulta de clima.
+func (s *locationService) ByCity(ctx context.Context, uf, city, street string) (*LocationLookup, error) {
+	uf = strings.ToUpper(strings.TrimSpace(uf))
+	city = strings.TrimSpace(city)
+	if !cep.IsValidUF(uf) {
+		return nil, fmt.Errorf("%w: unknown UF %q", ErrInvalidLocation, uf)
+	}
+	if utf8.RuneCountInString(city) < MinSearchTermLength {
+		return nil, fmt.Errorf("%w: city must have at least %d characters", ErrInvalidLocation, MinSearchTermLength)
+	}
+
+	lookup := &LocationLookup{City: city, UF: uf}
+	if street != "" {
+		addresses, err := s.search(ctx, uf, city, street)
+		if err != nil {
+			return nil, err
+		}
+		if len(addresses) == 0 {
+			return nil, repository.ErrLocationNotFound
+		}
+		lookup.City = addresses[0].City
+		lookup.CEPs = cepsOf(addresses)
+	}
+
+	obs, err := s.tempRepo.FetchWeather(ctx, lookup.City)
+	if err != nil {
+		log.Printf("Error fetching weather for city %s: %v", lookup.City, err)
+		return nil, err
+	}
+	lookup.Weather = obs
+	return lookup, nil
+}
+
+func (s *locationService) search(ctx context.Context, uf, city, street string) ([]repository.Address, error) {
+	uf, err := ValidateAddressSearch(uf, city, street)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := s.cityRepo.SearchAddresses(ctx, uf, city, strings.TrimSpace(street))
+	if err != nil {
+		log.Printf("Error searching addresses for %s/%s/%s: %v", uf, city, street, err)
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func cepsOf(addresses []repository.Address) []string {
+	ceps := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		ceps = append(ceps, a.CEP)
+	}
+	return ceps
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"service-b/internal/repository"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationService_ByCoordinates(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewLocationService(mockCityRepo, mockTempRepo)
+
+	obs := &repository.WeatherObservation{LocationName: "São Paulo", Region: "Sao Paulo", Country: "Brazil", TempC: 22}
+
+	// Configuração dos mocks
+	mockTempRepo.On("FetchWeather", mock.Anything, "-23.55,-46.63").Return(obs, nil)
+
+	// Execução do teste
+	lookup, err := service.ByCoordinates(context.Background(), -23.55, -46.63, "")
+
+	// Validação
+	require.NoError(t, err)
+	require.Equal(t, "São Paulo", lookup.City)
+	require.Equal(t, "SP", lookup.UF)
+	require.Empty(t, lookup.CEPs)
+	require.Equal(t, obs, lookup.Weather)
+
+	mockTempRepo.AssertExpectations(t)
+	mockCityRepo.AssertNotCalled(t, "SearchAddresses", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLocationService_ByCoordinatesWithStreet(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewLocationService(mockCityRepo, mockTempRepo)
+
+	obs := &repository.WeatherObservation{LocationName: "São Paulo", Region: "Sao Paulo", TempC: 22}
+	addresses := []repository.Address{{CEP: "01310100", City: "São Paulo", UF: "SP"}, {CEP: "01310200", City: "São Paulo", UF: "SP"}}
+
+	// Configuração dos mocks
+	mockTempRepo.On("FetchWeather", mock.Anything, "-23.56,-46.65").Return(obs, nil)
+	mockCityRepo.On("SearchAddresses", mock.Anything, "SP", "São Paulo", "Paulista").Return(addresses, nil)
+
+	// Execução do teste
+	lookup, err := service.ByCoordinates(context.Background(), -23.56, -46.65, "Paulista")
+
+	// Validação
+	require.NoError(t, err)
+	require.Equal(t, []string{"01310100", "01310200"}, lookup.CEPs)
+
+	mockTempRepo.AssertExpectations(t)
+	mockCityRepo.AssertExpectations(t)
+}
+
+func TestLocationService_ByCoordinatesOutOfRange(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewLocationService(mockCityRepo, mockTempRepo)
+
+	// Execução do teste
+	lookup, err := service.ByCoordinates(context.Background(), 91, 0, "")
+
+	// Validação
+	require.ErrorIs(t, err, ErrInvalidLocation)
+	require.Nil(t, lookup)
+}
+
+func TestLocationService_ByCity(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewLocationService(mockCityRepo, mockTempRepo)
+
+	obs := &repository.WeatherObservation{TempC: 30}
+	addresses := []repository.Address{{CEP: "29902555", City: "Linhares", UF: "ES"}}
+
+	// Configuração dos mocks
+	mockCityRepo.On("SearchAddresses", mock.Anything, "ES", "linhares", "Rua").Return(addresses, nil)
+	mockTempRepo.On("FetchWeather", mock.Anything, "Linhares").Return(obs, nil)
+
+	// Execução do teste
+	lookup, err := service.ByCity(context.Background(), "es", "linhares", "Rua")
+
+	// Validação
+	require.NoError(t, err)
+	require.Equal(t, "Linhares", lookup.City)
+	require.Equal(t, "ES", lookup.UF)
+	require.Equal(t, []string{"29902555"}, lookup.CEPs)
+	require.Equal(t, obs, lookup.Weather)
+
+	mockCityRepo.AssertExpectations(t)
+	mockTempRepo.AssertExpectations(t)
+}
+
+func TestLocationService_ByCityNoAddresses(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewLocationService(mockCityRepo, mockTempRepo)
+
+	// Configuração do mock para busca sem resultados
+	mockCityRepo.On("SearchAddresses", mock.Anything, "SP", "São Paulo", "Inexistente").Return([]repository.Address{}, nil)
+
+	// Execução do teste
+	lookup, err := service.ByCity(context.Background(), "SP", "São Paulo", "Inexistente")
+
+	// Validação
+	require.ErrorIs(t, err, repository.ErrLocationNotFound)
+	require.Nil(t, lookup)
+
+	mockTempRepo.AssertNotCalled(t, "FetchWeather", mock.Anything, mock.Anything)
+}
+
+func TestLocationService_ByCityInvalid(t *testing.T) {
+	tests := []struct {
+		uf     string
+		city   string
+		street string
+	}{
+		{"XX", "São Paulo", ""},
+		{"SP", "SP", ""},
+		{"SP", "São Paulo", "Av"},
+	}
+
+	for _, test := range tests {
+		service := NewLocationService(new(MockRepository), new(MockTemperatureRepository))
+
+		_, err := service.ByCity(context.Background(), test.uf, test.city, test.street)
+
+		require.ErrorIs(t, err, ErrInvalidLocation, "%s/%s/%s", test.uf, test.city, test.street)
+	}
+}
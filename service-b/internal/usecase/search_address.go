@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"service-b/internal/repository"
+	"shared/cep"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limites de paginação da busca de endereços (o ViaCEP retorna no máximo 50 resultados)
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 50
+	MaxPage         = 10000
+)
+
+// AddressSearch descreve uma busca de endereços por UF, cidade e logradouro
+type AddressSearch struct {
+	UF             string
+	City           string
+	Street         string
+	Page           int
+	PageSize       int
+	IncludeWeather bool
+}
+
+// AddressPage é uma página de resultados da busca de endereços. Weather só é preenchido quando
+// solicitado e todos os resultados pertencem à mesma cidade.
+type AddressPage struct {
+	Addresses []repository.Address
+	Page      int
+	PageSize  int
+	Total     int
+	City      string
+	Weather   *repository.WeatherObservation
+}
+
+// TotalPages retorna o número de páginas disponíveis
+func (p *AddressPage) TotalPages() int {
+	if p.PageSize == 0 {
+		return 0
+	}
+	return (p.Total + p.PageSize - 1) / p.PageSize
+}
+
+// SearchAddressService define a interface para buscar endereços pelo logradouro
+type SearchAddressService interface {
+	Search(ctx context.Context, search AddressSearch) (*AddressPage, error)
+}
+
+type searchAddressService struct {
+	cityRepo repository.CityRepository
+	tempRepo repository.TemperatureRepository
+}
+
+// NewSearchAddressService cria um novo serviço SearchAddressService
+func NewSearchAddressService(cityRepo repository.CityRepository, tempRepo repository.TemperatureRepository) SearchAddressService {
+	return &searchAddressService{cityRepo: cityRepo, tempRepo: tempRepo}
+}
+
+// ValidateAddressSearch aplica as regras do ViaCEP: UF válida e cidade e logradouro com pelo
+// menos 3 caracteres. Retorna a UF normalizada em maiúsculas.
+func ValidateAddressSearch(uf, city, street string) (string, error) {
+	uf = strings.ToUpper(strings.TrimSpace(uf))
+	if !cep.IsValidUF(uf) {
+		return "", fmt.Errorf("%w: unknown UF %q", ErrInvalidLocation, uf)
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(city)) < MinSearchTermLength {
+		return "", fmt.Errorf("%w: city must have at least %d characters", ErrInvalidLocation, MinSearchTermLength)
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(street)) < MinSearchTermLength {
+		return "", fmt.Errorf("%w: street must have at least %d characters", ErrInvalidLocation, MinSearchTermLength)
+	}
+	return uf, nil
+}
+
+// Search busca os endereços e retorna a página solicitada
+func (s *searchAddressService) Search(ctx context.Context, search AddressSearch) (*AddressPage, error) {
+	uf, err := ValidateAddressSearch(search.UF, search.City, search.Street)
+	if err != nil {
+		return nil, err
+	}
+	if search.Page < 1 {
+		search.Page = 1
+	}
+	if search.PageSize < 1 || search.PageSize > MaxPageSize {
+		search.PageSize = DefaultPageSize
+	}
+
+	addresses, err := s.cityRepo.SearchAddresses(ctx, uf, strings.TrimSpace(search.City), strings.TrimSpace(search.Street))
+	if err != nil {
+		log.Printf("Error searching addresses for %s/%s/%s: %v", uf, search.City, search.Street, err)
+		return nil, err
+	}
+
+	page := &AddressPage{
+		Page:     search.Page,
+		PageSize: search.PageSize,
+		Total:    len(addresses),
+		City:     sharedCity(addresses),
+	}
+
+	// A página é comparada antes da multiplicação, que estouraria com páginas muito grandes
+	if search.Page-1 <= len(addresses)/search.PageSize && (search.Page-1)*search.PageSize < len(addresses) {
+		start := (search.Page - 1) * search.PageSize
+		end := start + search.PageSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		page.Addresses = addresses[start:end]
+	} else {
+		page.Addresses = []repository.Address{}
+	}
+
+	if search.IncludeWeather && page.City != "" {
+		obs, err := s.tempRepo.FetchWeather(ctx, page.City)
+		if err != nil {
+			log.Printf("Error fetching weather for city %s: %v", page.City, err)
+			return nil, err
+		}
+		page.Weather = obs
+	}
+	return page, nil
+}
+
+// sharedCity retorna a cidade comum a todos os endereços, ou vazio se houver mais de uma
+func sharedCity(addresses []repository.Address) string {
+	if len(addresses) == 0 {
+		return ""
+	}
+	city := addresses[0].City
+	for _, a := range addresses[1:] {
+		if a.City != city {
+			return ""
+		}
+	}
+	return city
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"service-b/internal/repository"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func makeAddresses(n int, city string) []repository.Address {
+	addresses := make([]repository.Address, 0, n)
+	for i := 0; i < n; i++ {
+		addresses = append(addresses, repository.Address{CEP: fmt.Sprintf("0131%04d", i), City: city, UF: "SP"})
+	}
+	return addresses
+}
+
+func TestSearchAddressService_Pagination(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewSearchAddressService(mockCityRepo, mockTempRepo)
+
+	// Configuração do mock
+	mockCityRepo.On("SearchAddresses", mock.Anything, "SP", "São Paulo", "Paulista").Return(makeAddresses(23, "São Paulo"), nil)
+
+	// Execução do teste
+	page, err := service.Search(context.Background(), AddressSearch{UF: "sp", City: "São Paulo", Street: "Paulista", Page: 3, PageSize: 10})
+
+	// Validação
+	require.NoError(t, err)
+	require.Equal(t, 23, page.Total)
+	require.Equal(t, 3, page.TotalPages())
+	require.Len(t, page.Addresses, 3)
+	require.Equal(t, "01310020", page.Addresses[0].CEP)
+	require.Nil(t, page.Weather)
+
+	mockCityRepo.AssertExpectations(t)
+	mockTempRepo.AssertNotCalled(t, "FetchWeather", mock.Anything, mock.Anything)
+}
+
+func TestSearchAddressService_PageOutOfRange(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	service := NewSearchAddressService(mockCityRepo, new(MockTemperatureRepository))
+
+	// Configuração do mock
+	mockCityRepo.On("SearchAddresses", mock.Anything, "SP", "São Paulo", "Paulista").Return(makeAddresses(5, "São Paulo"), nil)
+
+	// Execução do teste
+	page, err := service.Search(context.Background(), AddressSearch{UF: "SP", City: "São Paulo", Street: "Paulista", Page: 2, PageSize: 10})
+
+	// Validação
+	require.NoError(t, err)
+	require.Equal(t, 5, page.Total)
+	require.Empty(t, page.Addresses)
+}
+
+func TestSearchAddressService_HugePage(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	service := NewSearchAddressService(mockCityRepo, new(MockTemperatureRepository))
+	mockCityRepo.On("SearchAddresses", mock.Anything, "SP", "São Paulo", "Paulista").Return(makeAddresses(5, "São Paulo"), nil)
+
+	// (page-1)*page_size estoura para um número negativo
+	page, err := service.Search(context.Background(), AddressSearch{UF: "SP", City: "São Paulo", Street: "Paulista", Page: math.MaxInt, PageSize: 50})
+
+	require.NoError(t, err)
+	require.Equal(t, 5, page.Total)
+	require.Empty(t, page.Addresses)
+}
+
+func TestSearchAddressService_WithWeather(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewSearchAddressService(mockCityRepo, mockTempRepo)
+
+	obs := &repository.WeatherObservation{TempC: 25}
+
+	// Configuração dos mocks
+	mockCityRepo.On("SearchAddresses", mock.Anything, "SP", "São Paulo", "Paulista").Return(makeAddresses(2, "São Paulo"), nil)
+	mockTempRepo.On("FetchWeather", mock.Anything, "São Paulo").Return(obs, nil)
+
+	// Execução do teste
+	page, err := service.Search(context.Background(), AddressSearch{UF: "SP", City: "São Paulo", Street: "Paulista", IncludeWeather: true})
+
+	// Validação
+	require.NoError(t, err)
+	require.Equal(t, "São Paulo", page.City)
+	require.Equal(t, obs, page.Weather)
+
+	mockCityRepo.AssertExpectations(t)
+	mockTempRepo.AssertExpectations(t)
+}
+
+func TestSearchAddressService_WeatherSkippedForMixedCities(t *testing.T) {
+	mockCityRepo := new(MockRepository)
+	mockTempRepo := new(MockTemperatureRepository)
+	service := NewSearchAddressService(mockCityRepo, mockTempRepo)
+
+	addresses := append(makeAddresses(1, "Santo André"), makeAddresses(1, "São Bernardo do Campo")...)
+
+	// Configuração do mock
+	mockCityRepo.On("SearchAddresses", mock.Anything, "SP", "Santo", "Rua Principal").Return(addresses, nil)
+
+	// Execução do teste
+	page, err := service.Search(context.Background(), AddressSearch{UF: "SP", City: "Santo", Street: "Rua Principal", IncludeWeather: true})
+
+	// Validação
+	require.NoError(t, err)
+	require.Empty(t, page.City)
+	require.Nil(t, page.Weather)
+
+	mockTempRepo.AssertNotCalled(t, "FetchWeather", mock.Anything, mock.Anything)
+}
+
+func TestValidateAddressSearch(t *testing.T) {
+	tests := []struct {
+		uf      string
+		city    string
+		street  string
+		wantErr bool
+	}{
+		{"SP", "São Paulo", "Paulista", false},
+		{"rs", "Porto Alegre", "Dom", false},
+		{"S", "São Paulo", "Paulista", true},
+		{"XX", "São Paulo", "Paulista", true},
+		{"SP", "SP", "Paulista", true},
+		{"SP", "São Paulo", "Av", true},
+		{"SP", "São Paulo", "   ", true},
+	}
+
+	for _, test := range tests {
+		_, err := ValidateAddressSearch(test.uf, test.city, test.street)
+		if test.wantErr {
+			require.ErrorIs(t, err, ErrInvalidLocation, "%s/%s/%s", test.uf, test.city, test.street)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
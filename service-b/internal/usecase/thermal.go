@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"math"
+	"service-b/internal/repository"
+)
+
+// HeatRisk classifica o risco térmico segundo as faixas de índice de calor do NWS
+type HeatRisk string
+
+const (
+	HeatRiskNone           HeatRisk = "none"
+	HeatRiskCaution        HeatRisk = "caution"
+	HeatRiskExtremeCaution HeatRisk = "extreme_caution"
+	HeatRiskDanger         HeatRisk = "danger"
+	HeatRiskExtremeDanger  HeatRisk = "extreme_danger"
+)
+
+// ThermalComfort agrupa os índices de conforto térmico derivados de uma observação
+type ThermalComfort struct {
+	HeatIndexC float64
+	WindChillC float64
+	DewPointC  float64
+	Humidex    float64
+	Risk       HeatRisk
+}
+
+// ComputeThermalComfort calcula todos os índices de conforto térmico de uma observação
+func ComputeThermalComfort(obs *repository.WeatherObservation) ThermalComfort {
+	rh := float64(obs.Humidity)
+	heatIndex := HeatIndex(obs.TempC, rh)
+	dewPoint := DewPoint(obs.TempC, rh)
+
+	return ThermalComfort{
+		HeatIndexC: heatIndex,
+		WindChillC: WindChill(obs.TempC, obs.WindKph),
+		DewPointC:  dewPoint,
+		Humidex:    Humidex(obs.TempC, dewPoint),
+		Risk:       ClassifyHeatRisk(heatIndex),
+	}
+}
+
+// HeatIndex calcula o índice de calor (°C) pela regressão de Rothfusz usada pelo NWS,
+// incluindo os ajustes para umidade muito baixa ou muito alta.
+// Abaixo de 80°F o índice não é definido e retorna a própria temperatura.
+func HeatIndex(tempC, relativeHumidity float64) float64 {
+	t := CelsiusToFahrenheit(tempC)
+	rh := relativeHumidity
+	if t < 80 {
+		return round2(tempC)
+	}
+
+	// Fórmula simplificada de Steadman, válida enquanto o resultado ficar abaixo de 80°F
+	hi := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+	if (hi+t)/2 < 80 {
+		return FahrenheitToCelsius(hi)
+	}
+
+	hi = -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t -
+		0.05481717*rh*rh + 0.00122874*t*t*rh +
+		0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	switch {
+	case rh < 13 && t >= 80 && t <= 112:
+		hi -= ((13 - rh) / 4) * math.Sqrt((17-math.Abs(t-95))/17)
+	case rh > 85 && t >= 80 && t <= 87:
+		hi += ((rh - 85) / 10) * ((87 - t) / 5)
+	}
+	return FahrenheitToCelsius(hi)
+}
+
+// WindChill calcula a sensação térmica pelo vento (°C) pela fórmula do NWS/Environment Canada.
+// Fora do domínio de validade (acima de 10°C ou vento até 4,8 km/h) retorna a própria temperatura.
+func WindChill(tempC, windKph float64) float64 {
+	if tempC > 10 || windKph <= 4.8 {
+		return round2(tempC)
+	}
+	v := math.Pow(windKph, 0.16)
+	return round2(13.12 + 0.6215*tempC - 11.37*v + 0.3965*tempC*v)
+}
+
+// DewPoint calcula o ponto de orvalho (°C) pela aproximação de Magnus (Alduchov-Eskridge)
+func DewPoint(tempC, relativeHumidity float64) float64 {
+	const a, b = 17.625, 243.04
+	if relativeHumidity <= 0 {
+		return math.NaN()
+	}
+	gamma := math.Log(relativeHumidity/100) + a*tempC/(b+tempC)
+	return round2(b * gamma / (a - gamma))
+}
+
+// Humidex calcula o índice humidex canadense a partir da temperatura e do ponto de orvalho
+func Humidex(tempC, dewPointC float64) float64 {
+	if math.IsNaN(dewPointC) {
+		return round2(tempC)
+	}
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/(273.15+dewPointC)))
+	return round2(tempC + 0.5555*(e-10))
+}
+
+// Limites das faixas do NWS (80, 90, 103 e 125°F) em °C, com a mesma precisão dos índices
+const (
+	heatCautionC        = 26.67
+	heatExtremeCautionC = 32.22
+	heatDangerC         = 39.44
+	heatExtremeDangerC  = 51.67
+)
+
+// ClassifyHeatRisk classifica o índice de calor (°C) nas faixas de alerta do NWS
+func ClassifyHeatRisk(heatIndexC float64) HeatRisk {
+	switch {
+	case heatIndexC >= heatExtremeDangerC:
+		return HeatRiskExtremeDanger
+	case heatIndexC >= heatDangerC:
+		return HeatRiskDanger
+	case heatIndexC >= heatExtremeCautionC:
+		return HeatRiskExtremeCaution
+	case heatIndexC >= heatCautionC:
+		return HeatRiskCaution
+	default:
+		return HeatRiskNone
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
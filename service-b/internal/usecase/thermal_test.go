@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+
+	"service-b/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Valores de referência da tabela de índice de calor do NWS (°F, arredondados)
+func TestHeatIndex(t *testing.T) {
+	tests := []struct {
+		tempF    float64
+		humidity float64
+		expected float64
+	}{
+		{80, 40, 80},
+		{86, 90, 105},
+		{90, 50, 95},
+		{96, 65, 121},
+		{100, 40, 109},
+		{104, 55, 137},
+		{110, 10, 104},
+		{70, 90, 70}, // abaixo de 80°F não há ajuste
+	}
+
+	for _, test := range tests {
+		result := CelsiusToFahrenheit(HeatIndex(FahrenheitToCelsius(test.tempF), test.humidity))
+		assert.InDelta(t, test.expected, result, 0.5, "T=%.0f°F RH=%.0f%%", test.tempF, test.humidity)
+	}
+}
+
+// Valores de referência da tabela de sensação térmica da Environment Canada (°C)
+func TestWindChill(t *testing.T) {
+	tests := []struct {
+		tempC    float64
+		windKph  float64
+		expected float64
+	}{
+		{0, 10, -3},
+		{5, 40, -1},
+		{-10, 20, -18},
+		{-20, 30, -33},
+		{-30, 50, -49},
+		{15, 30, 15}, // acima de 10°C não há ajuste
+		{0, 3, 0},    // vento fraco não há ajuste
+	}
+
+	for _, test := range tests {
+		result := WindChill(test.tempC, test.windKph)
+		assert.InDelta(t, test.expected, result, 0.5, "T=%.0f°C V=%.0fkm/h", test.tempC, test.windKph)
+	}
+}
+
+func TestDewPoint(t *testing.T) {
+	tests := []struct {
+		tempC    float64
+		humidity float64
+		expected float64
+	}{
+		{20, 50, 9.3},
+		{30, 70, 23.9},
+		{25, 100, 25},
+		{10, 80, 6.7},
+		{-5, 60, -11.6},
+	}
+
+	for _, test := range tests {
+		result := DewPoint(test.tempC, test.humidity)
+		assert.InDelta(t, test.expected, result, 0.1, "T=%.0f°C RH=%.0f%%", test.tempC, test.humidity)
+	}
+
+	assert.True(t, math.IsNaN(DewPoint(20, 0)))
+}
+
+// Valores de referência da tabela de humidex da Environment Canada
+func TestHumidex(t *testing.T) {
+	tests := []struct {
+		tempC     float64
+		dewPointC float64
+		expected  float64
+	}{
+		{25, 20, 33},
+		{30, 15, 34},
+		{35, 25, 47},
+		{40, 26, 53},
+	}
+
+	for _, test := range tests {
+		result := Humidex(test.tempC, test.dewPointC)
+		assert.InDelta(t, test.expected, result, 0.5, "T=%.0f°C Td=%.0f°C", test.tempC, test.dewPointC)
+	}
+}
+
+func TestClassifyHeatRisk(t *testing.T) {
+	tests := []struct {
+		heatIndexF float64
+		expected   HeatRisk
+	}{
+		{75, HeatRiskNone},
+		{80, HeatRiskCaution},
+		{89, HeatRiskCaution},
+		{90, HeatRiskExtremeCaution},
+		{103, HeatRiskDanger},
+		{124, HeatRiskDanger},
+		{125, HeatRiskExtremeDanger},
+	}
+
+	for _, test := range tests {
+		result := ClassifyHeatRisk(FahrenheitToCelsius(test.heatIndexF))
+		assert.Equal(t, test.expected, result, "HI=%.0f°F", test.heatIndexF)
+	}
+}
+
+func TestComputeThermalComfort(t *testing.T) {
+	obs := &repository.WeatherObservation{TempC: 32.22, Humidity: 50, WindKph: 10}
+
+	comfort := ComputeThermalComfort(obs)
+
+	assert.InDelta(t, 95, CelsiusToFahrenheit(comfort.HeatIndexC), 0.5)
+	assert.Equal(t, 32.22, comfort.WindChillC)
+	assert.InDelta(t, 20.4, comfort.DewPointC, 0.1)
+	assert.InDelta(t, 40.2, comfort.Humidex, 0.1)
+	assert.Equal(t, HeatRiskExtremeCaution, comfort.Risk)
+}
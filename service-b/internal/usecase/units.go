@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Convenções para o deslocamento Kelvin: o SI usa 273,15, enquanto a especificação
+// original do projeto (README) documenta K = C + 273
+const (
+	KelvinConventionSI      = "si"
+	KelvinConventionInteger = "integer"
+
+	KelvinOffsetSI      = 273.15
+	KelvinOffsetInteger = 273.0
+)
+
+const (
+	DefaultPrecision = 2
+	MaxPrecision     = 6
+)
+
+// DefaultUnits são as unidades retornadas quando o cliente não escolhe nenhuma
+var DefaultUnits = []string{"C", "F", "K"}
+
+// Unit descreve uma escala de temperatura e como obtê-la a partir de Celsius
+type Unit struct {
+	Symbol      string
+	Name        string
+	FromCelsius func(c float64) float64
+}
+
+// UnitRegistry mantém as escalas de temperatura suportadas, indexadas pelo símbolo
+type UnitRegistry struct {
+	units   map[string]Unit
+	symbols []string
+}
+
+// KelvinOffsetForConvention retorna o deslocamento Kelvin da convenção informada, já normalizada
+// em minúsculas pela configuração
+func KelvinOffsetForConvention(convention string) (float64, error) {
+	switch convention {
+	case "", KelvinConventionSI:
+		return KelvinOffsetSI, nil
+	case KelvinConventionInteger:
+		return KelvinOffsetInteger, nil
+	default:
+		return 0, fmt.Errorf("unknown kelvin convention %q", convention)
+	}
+}
+
+// NewUnitRegistry cria um registro com Celsius, Fahrenheit, Kelvin, Rankine e Réaumur.
+// Só o Kelvin usa o deslocamento informado; o Rankine segue a definição exata (0 °C = 491,67 °R),
+// já que a convenção inteira do README trata apenas do Kelvin.
+func NewUnitRegistry(kelvinOffset float64) *UnitRegistry {
+	r := &UnitRegistry{units: make(map[string]Unit)}
+	r.Register(Unit{Symbol: "C", Name: "celsius", FromCelsius: func(c float64) float64 { return c }})
+	r.Register(Unit{Symbol: "F", Name: "fahrenheit", FromCelsius: func(c float64) float64 { return c*1.8 + 32 }})
+	r.Register(Unit{Symbol: "K", Name: "kelvin", FromCelsius: func(c float64) float64 { return c + kelvinOffset }})
+	r.Register(Unit{Symbol: "R", Name: "rankine", FromCelsius: func(c float64) float64 { return c*1.8 + 491.67 }})
+	r.Register(Unit{Symbol: "Re", Name: "reaumur", FromCelsius: func(c float64) float64 { return c * 0.8 }})
+	return r
+}
+
+// Register adiciona ou substitui uma unidade no registro
+func (r *UnitRegistry) Register(u Unit) {
+	if _, exists := r.units[u.Symbol]; !exists {
+		r.symbols = append(r.symbols, u.Symbol)
+	}
+	r.units[u.Symbol] = u
+}
+
+// Lookup busca uma unidade pelo símbolo (ex.: "F") ou pelo nome (ex.: "fahrenheit")
+func (r *UnitRegistry) Lookup(name string) (Unit, bool) {
+	if u, ok := r.units[name]; ok {
+		return u, true
+	}
+	for _, u := range r.units {
+		if strings.EqualFold(u.Symbol, name) || strings.EqualFold(u.Name, name) {
+			return u, true
+		}
+	}
+	return Unit{}, false
+}
+
+// Symbols retorna os símbolos registrados na ordem de registro
+func (r *UnitRegistry) Symbols() []string {
+	return append([]string(nil), r.symbols...)
+}
+
+// Convert converte uma temperatura em Celsius para a unidade informada,
+// arredondando para o número de casas decimais pedido
+func (r *UnitRegistry) Convert(c float64, symbol string, precision int) (float64, error) {
+	u, ok := r.Lookup(symbol)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", symbol)
+	}
+	return RoundTo(u.FromCelsius(c), precision), nil
+}
+
+// RoundTo arredonda um valor para o número de casas decimais informado
+func RoundTo(v float64, precision int) float64 {
+	p := math.Pow(10, float64(precision))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitRegistry_Convert(t *testing.T) {
+	registry := NewUnitRegistry(KelvinOffsetSI)
+
+	tests := []struct {
+		celsius   float64
+		unit      string
+		precision int
+		expected  float64
+	}{
+		{0, "C", 2, 0},
+		{28.456, "C", 1, 28.5},
+		{100, "F", 2, 212},
+		{37, "fahrenheit", 2, 98.6},
+		{0, "K", 2, 273.15},
+		{-273.15, "K", 2, 0},
+		{0, "R", 2, 491.67},
+		{100, "rankine", 2, 671.67},
+		{100, "Re", 2, 80},
+		{25, "reaumur", 0, 20},
+		{28.5, "F", 0, 83},
+	}
+
+	for _, test := range tests {
+		result, err := registry.Convert(test.celsius, test.unit, test.precision)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, result, "%v°C -> %s", test.celsius, test.unit)
+	}
+}
+
+func TestUnitRegistry_KelvinIntegerConvention(t *testing.T) {
+	offset, err := KelvinOffsetForConvention(KelvinConventionInteger)
+	require.NoError(t, err)
+	registry := NewUnitRegistry(offset)
+
+	kelvin, err := registry.Convert(28.5, "K", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 301.5, kelvin)
+
+	// O Rankine não usa o deslocamento inteiro do Kelvin
+	rankine, err := registry.Convert(0, "R", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 491.67, rankine)
+	rankine, err = registry.Convert(28.5, "R", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 542.97, rankine)
+}
+
+func TestUnitRegistry_UnknownUnit(t *testing.T) {
+	registry := NewUnitRegistry(KelvinOffsetSI)
+
+	_, err := registry.Convert(10, "X", 2)
+	assert.Error(t, err)
+
+	_, ok := registry.Lookup("delisle")
+	assert.False(t, ok)
+}
+
+func TestKelvinOffsetForConvention(t *testing.T) {
+	tests := []struct {
+		convention string
+		expected   float64
+		wantErr    bool
+	}{
+		{"", KelvinOffsetSI, false},
+		{"si", KelvinOffsetSI, false},
+		// A configuração normaliza o valor antes (ver config.LoadConfig)
+		{"SI", 0, true},
+		{"integer", KelvinOffsetInteger, false},
+		{"rounded", 0, true},
+	}
+
+	for _, test := range tests {
+		offset, err := KelvinOffsetForConvention(test.convention)
+		if test.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, offset)
+	}
+}
@@ -0,0 +1,178 @@
+// Package cep reúne as regras de CEP usadas pelos dois serviços: validação de formato,
+// normalização e a tabela de faixas dos Correios que associa cada CEP à sua UF e região.
+package cep
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidFormat indica um CEP que não tem exatamente 8 dígitos
+	ErrInvalidFormat = errors.New("invalid CEP format")
+	// ErrUnassigned indica um CEP bem formado em uma faixa não atribuída a nenhuma UF
+	ErrUnassigned = errors.New("CEP in unassigned range")
+)
+
+// Region é uma macrorregião brasileira
+type Region string
+
+const (
+	RegionNorte       Region = "Norte"
+	RegionNordeste    Region = "Nordeste"
+	RegionCentroOeste Region = "Centro-Oeste"
+	RegionSudeste     Region = "Sudeste"
+	RegionSul         Region = "Sul"
+)
+
+// State descreve uma unidade federativa
+type State struct {
+	UF      string
+	Name    string
+	Capital string
+	Region  Region
+}
+
+var states = map[string]State{
+	"AC": {"AC", "Acre", "Rio Branco", RegionNorte},
+	"AL": {"AL", "Alagoas", "Maceió", RegionNordeste},
+	"AP": {"AP", "Amapá", "Macapá", RegionNorte},
+	"AM": {"AM", "Amazonas", "Manaus", RegionNorte},
+	"BA": {"BA", "Bahia", "Salvador", RegionNordeste},
+	"CE": {"CE", "Ceará", "Fortaleza", RegionNordeste},
+	"DF": {"DF", "Distrito Federal", "Brasília", RegionCentroOeste},
+	"ES": {"ES", "Espírito Santo", "Vitória", RegionSudeste},
+	"GO": {"GO", "Goiás", "Goiânia", RegionCentroOeste},
+	"MA": {"MA", "Maranhão", "São Luís", RegionNordeste},
+	"MT": {"MT", "Mato Grosso", "Cuiabá", RegionCentroOeste},
+	"MS": {"MS", "Mato Grosso do Sul", "Campo Grande", RegionCentroOeste},
+	"MG": {"MG", "Minas Gerais", "Belo Horizonte", RegionSudeste},
+	"PA": {"PA", "Pará", "Belém", RegionNorte},
+	"PB": {"PB", "Paraíba", "João Pessoa", RegionNordeste},
+	"PR": {"PR", "Paraná", "Curitiba", RegionSul},
+	"PE": {"PE", "Pernambuco", "Recife", RegionNordeste},
+	"PI": {"PI", "Piauí", "Teresina", RegionNordeste},
+	"RJ": {"RJ", "Rio de Janeiro", "Rio de Janeiro", RegionSudeste},
+	"RN": {"RN", "Rio Grande do Norte", "Natal", RegionNordeste},
+	"RS": {"RS", "Rio Grande do Sul", "Porto Alegre", RegionSul},
+	"RO": {"RO", "Rondônia", "Porto Velho", RegionNorte},
+	"RR": {"RR", "Roraima", "Boa Vista", RegionNorte},
+	"SC": {"SC", "Santa Catarina", "Florianópolis", RegionSul},
+	"SP": {"SP", "São Paulo", "São Paulo", RegionSudeste},
+	"SE": {"SE", "Sergipe", "Aracaju", RegionNordeste},
+	"TO": {"TO", "Tocantins", "Palmas", RegionNorte},
+}
+
+// cepRange é uma faixa de CEPs pelos 5 primeiros dígitos (inclusive)
+type cepRange struct {
+	start, end int
+	uf         string
+}
+
+// ranges é a tabela de faixas de CEP por UF dos Correios, ordenada pelo início da faixa.
+// As lacunas (00000-00999 e 78900-78999) não estão atribuídas.
+var ranges = []cepRange{
+	{1000, 19999, "SP"},
+	{20000, 28999, "RJ"},
+	{29000, 29999, "ES"},
+	{30000, 39999, "MG"},
+	{40000, 48999, "BA"},
+	{49000, 49999, "SE"},
+	{50000, 56999, "PE"},
+	{57000, 57999, "AL"},
+	{58000, 58999, "PB"},
+	{59000, 59999, "RN"},
+	{60000, 63999, "CE"},
+	{64000, 64999, "PI"},
+	{65000, 65999, "MA"},
+	{66000, 68899, "PA"},
+	{68900, 68999, "AP"},
+	{69000, 69299, "AM"},
+	{69300, 69399, "RR"},
+	{69400, 69899, "AM"},
+	{69900, 69999, "AC"},
+	{70000, 72799, "DF"},
+	{72800, 72999, "GO"},
+	{73000, 73699, "DF"},
+	{73700, 76799, "GO"},
+	{76800, 76999, "RO"},
+	{77000, 77999, "TO"},
+	{78000, 78899, "MT"},
+	{79000, 79999, "MS"},
+	{80000, 87999, "PR"},
+	{88000, 89999, "SC"},
+	{90000, 99999, "RS"},
+}
+
+var formatting = strings.NewReplacer("-", "", ".", "", " ", "")
+
+// Normalize remove a formatação do CEP (ex.: "01001-000" vira "01001000")
+func Normalize(code string) string {
+	return formatting.Replace(code)
+}
+
+// Validate verifica se o CEP tem exatamente 8 dígitos
+func Validate(code string) error {
+	if len(code) != 8 {
+		return ErrInvalidFormat
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return ErrInvalidFormat
+		}
+	}
+	return nil
+}
+
+// Resolve identifica a UF do CEP pela tabela de faixas, sem chamadas de rede
+func Resolve(code string) (State, error) {
+	if err := Validate(code); err != nil {
+		return State{}, err
+	}
+	prefix, _ := strconv.Atoi(code[:5])
+
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].end >= prefix })
+	if i == len(ranges) || prefix < ranges[i].start {
+		return State{}, ErrUnassigned
+	}
+	return states[ranges[i].uf], nil
+}
+
+// LookupState busca uma UF pela sigla, sem diferenciar maiúsculas e minúsculas
+func LookupState(uf string) (State, bool) {
+	s, ok := states[strings.ToUpper(strings.TrimSpace(uf))]
+	return s, ok
+}
+
+// IsValidUF informa se a sigla corresponde a uma unidade federativa brasileira
+func IsValidUF(uf string) bool {
+	_, ok := LookupState(uf)
+	return ok
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "â", "a", "ã", "a", "à", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
+// StateFromName encontra a UF pelo nome do estado, ignorando acentos e caixa
+// (a WeatherAPI, por exemplo, retorna "Sao Paulo")
+func StateFromName(name string) (State, bool) {
+	wanted := normalizeName(name)
+	for _, s := range states {
+		if normalizeName(s.Name) == wanted {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+func normalizeName(name string) string {
+	return accentReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
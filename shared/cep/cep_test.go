@@ -0,0 +1,105 @@
+package cep
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		cep    string
+		uf     string
+		region Region
+	}{
+		{"01001000", "SP", RegionSudeste},
+		{"19999999", "SP", RegionSudeste},
+		{"20040020", "RJ", RegionSudeste},
+		{"29902555", "ES", RegionSudeste},
+		{"30130010", "MG", RegionSudeste},
+		{"40020000", "BA", RegionNordeste},
+		{"68900000", "AP", RegionNorte},
+		{"69301000", "RR", RegionNorte},
+		{"69400000", "AM", RegionNorte},
+		{"69900000", "AC", RegionNorte},
+		{"70040010", "DF", RegionCentroOeste},
+		{"72800000", "GO", RegionCentroOeste},
+		{"73000000", "DF", RegionCentroOeste},
+		{"78000000", "MT", RegionCentroOeste},
+		{"79000000", "MS", RegionCentroOeste},
+		{"80010000", "PR", RegionSul},
+		{"88010000", "SC", RegionSul},
+		{"99999999", "RS", RegionSul},
+	}
+
+	for _, test := range tests {
+		state, err := Resolve(test.cep)
+		require.NoError(t, err, test.cep)
+		assert.Equal(t, test.uf, state.UF, test.cep)
+		assert.Equal(t, test.region, state.Region, test.cep)
+	}
+}
+
+func TestResolve_Unassigned(t *testing.T) {
+	for _, code := range []string{"00000000", "00999999", "78900000", "78999999"} {
+		_, err := Resolve(code)
+		assert.ErrorIs(t, err, ErrUnassigned, code)
+	}
+}
+
+func TestResolve_InvalidFormat(t *testing.T) {
+	for _, code := range []string{"", "123", "0100100", "010010000", "01001-00", "abcdefgh"} {
+		_, err := Resolve(code)
+		assert.ErrorIs(t, err, ErrInvalidFormat, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("01001000"))
+	// O serviço A confere o formato antes de montar o caminho da chamada ao serviço B
+	for _, code := range []string{"0100100", "01001-00", "0100100a", "../x/../", "０１００１０００"} {
+		assert.ErrorIs(t, Validate(code), ErrInvalidFormat, code)
+	}
+}
+
+func TestRangesCoverEveryState(t *testing.T) {
+	covered := map[string]bool{}
+	for i, r := range ranges {
+		require.LessOrEqual(t, r.start, r.end)
+		if i > 0 {
+			require.Greater(t, r.start, ranges[i-1].end, "ranges must be sorted and disjoint")
+		}
+		_, ok := states[r.uf]
+		require.True(t, ok, r.uf)
+		covered[r.uf] = true
+	}
+	assert.Len(t, covered, len(states))
+}
+
+func TestStateFromName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Sao Paulo", "SP"},
+		{"São Paulo", "SP"},
+		{"espirito santo", "ES"},
+		{"Distrito Federal", "DF"},
+		{"Parana", "PR"},
+	}
+
+	for _, test := range tests {
+		state, ok := StateFromName(test.name)
+		require.True(t, ok, test.name)
+		require.Equal(t, test.expected, state.UF)
+	}
+
+	_, ok := StateFromName("California")
+	require.False(t, ok)
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "01001000", Normalize("01001-000"))
+	assert.Equal(t, "01001000", Normalize("01.001-000"))
+}
@@ -0,0 +1,192 @@
+// Package encoder escolhe, pelo cabeçalho Accept, o formato das respostas (JSON, XML, CSV ou
+// protobuf) e codifica nele os mesmos valores que os handlers montam para o JSON. É usado pelos
+// dois serviços.
+package encoder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media types dos formatos registrados por NewRegistry
+const (
+	MediaTypeJSON     = "application/json"
+	MediaTypeXML      = "application/xml"
+	MediaTypeCSV      = "text/csv"
+	MediaTypeProtobuf = "application/x-protobuf"
+)
+
+// Encoder grava a resposta em um formato
+type Encoder interface {
+	// ContentType é o Content-Type das respostas codificadas
+	ContentType() string
+	Encode(w io.Writer, v interface{}) error
+}
+
+// entry associa um encoder aos media types do Accept que ele atende
+type entry struct {
+	mediaTypes []string
+	encoder    Encoder
+}
+
+// Registry guarda os encoders disponíveis; o primeiro registrado é o padrão, usado quando o
+// cliente aceita qualquer formato
+type Registry struct {
+	entries []entry
+}
+
+// NewRegistry cria o registro com JSON (padrão), XML, CSV e protobuf
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.Register(JSON, MediaTypeJSON)
+	r.Register(XML, MediaTypeXML, "text/xml")
+	r.Register(CSV, MediaTypeCSV)
+	r.Register(Protobuf, MediaTypeProtobuf, "application/protobuf")
+	return r
+}
+
+// Register adiciona o encoder para os media types informados
+func (r *Registry) Register(enc Encoder, mediaTypes ...string) {
+	r.entries = append(r.entries, entry{mediaTypes: mediaTypes, encoder: enc})
+}
+
+// Negotiate retorna o encoder do media type de maior preferência no Accept. Accept vazio aceita o
+// padrão; media types com sufixo +json (ex.: application/vnd.weather.v2+json) usam o JSON.
+// Retorna false quando nenhum dos media types aceitos está registrado.
+func (r *Registry) Negotiate(accept string) (Encoder, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return r.entries[0].encoder, true
+	}
+	for _, mediaType := range parseAccept(accept) {
+		if enc, ok := r.lookup(mediaType); ok {
+			return enc, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Registry) lookup(mediaType string) (Encoder, bool) {
+	if mediaType == "*/*" {
+		return r.entries[0].encoder, true
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		mediaType = MediaTypeJSON
+	}
+	for _, e := range r.entries {
+		for _, m := range e.mediaTypes {
+			if m == mediaType || strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(m, strings.TrimSuffix(mediaType, "*")) {
+				return e.encoder, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// parseAccept retorna os media types do Accept em ordem de preferência, sem os de q=0
+func parseAccept(accept string) []string {
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+	var types []accepted
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			types = append(types, accepted{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool { return types[i].q > types[j].q })
+
+	mediaTypes := make([]string, len(types))
+	for i, t := range types {
+		mediaTypes[i] = t.mediaType
+	}
+	return mediaTypes
+}
+
+type encoderKey struct{}
+
+// negotiation é o resultado guardado no contexto; encoder nil indica que nenhum formato
+// aceito pelo cliente é suportado
+type negotiation struct {
+	encoder Encoder
+}
+
+// Middleware guarda no contexto o encoder negociado pelo Accept. A recusa (406) fica a cargo de
+// quem codifica a resposta, para não afetar as rotas com formato próprio (ex.: exportação).
+func (r *Registry) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		enc, _ := r.Negotiate(req.Header.Get("Accept"))
+		w.Header().Add("Vary", "Accept")
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), encoderKey{}, negotiation{encoder: enc})))
+	})
+}
+
+// FromContext retorna o encoder negociado, ou JSON quando não houve negociação (ex.: chamadas
+// internas). Retorna false quando o cliente não aceita nenhum formato suportado.
+func FromContext(ctx context.Context) (Encoder, bool) {
+	n, ok := ctx.Value(encoderKey{}).(negotiation)
+	if !ok {
+		return JSON, true
+	}
+	return n.encoder, n.encoder != nil
+}
+
+// WithEncoder guarda o encoder no contexto, como o Middleware
+func WithEncoder(ctx context.Context, enc Encoder) context.Context {
+	return context.WithValue(ctx, encoderKey{}, negotiation{encoder: enc})
+}
+
+// Encode codifica a resposta em memória, para que uma falha de codificação ainda possa virar
+// uma resposta de erro
+func Encode(enc Encoder, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generic converte o valor na árvore genérica do JSON (mapas, listas, json.Number, strings,
+// bool e nil), com os mesmos nomes de campo e valores da resposta em JSON
+func generic(v interface{}, useNumber bool) (interface{}, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if useNumber {
+		decoder.UseNumber()
+	}
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// sortedKeys retorna as chaves do objeto em ordem alfabética, para uma saída estável
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for k := range object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+package encoder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRegistry_Negotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   Encoder
+		wantOK bool
+	}{
+		{"empty", "", JSON, true},
+		{"any", "*/*", JSON, true},
+		{"json", "application/json", JSON, true},
+		{"vendor json", "application/vnd.weather.v2+json", JSON, true},
+		{"xml", "application/xml", XML, true},
+		{"text xml", "text/xml", XML, true},
+		{"csv", "text/csv; charset=utf-8", CSV, true},
+		{"protobuf", "application/x-protobuf", Protobuf, true},
+		{"quality order", "application/json;q=0.5, text/csv", CSV, true},
+		{"unsupported first", "text/html, application/xml;q=0.8", XML, true},
+		{"type wildcard", "application/*", JSON, true},
+		{"refused format", "application/xml;q=0, text/html", nil, false},
+		{"unsupported", "text/html", nil, false},
+	}
+	registry := NewRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, ok := registry.Negotiate(tt.accept)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, enc)
+		})
+	}
+}
+
+func TestRegistry_Middleware(t *testing.T) {
+	var got Encoder
+	var gotOK bool
+	handler := NewRegistry().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, gotOK = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/cep/01001000", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.True(t, gotOK)
+	assert.Equal(t, XML, got)
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+
+	req.Header.Set("Accept", "text/html")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.False(t, gotOK)
+}
+
+func TestFromContext_WithoutNegotiation(t *testing.T) {
+	enc, ok := FromContext(context.Background())
+
+	assert.True(t, ok)
+	assert.Equal(t, JSON, enc)
+}
+
+type sample struct {
+	City  string   `json:"city"`
+	TempC float64  `json:"temp_C"`
+	Tags  []string `json:"tags,omitempty"`
+	Items []point  `json:"items,omitempty"`
+}
+
+type point struct {
+	Date  string  `json:"date"`
+	TempC float64 `json:"temp_C"`
+}
+
+func TestXML_Encode(t *testing.T) {
+	body, err := Encode(XML, sample{City: "São Paulo", TempC: 28.5, Items: []point{{Date: "2024-01-10", TempC: 30}}})
+
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><city>São Paulo</city><items><item><date>2024-01-10</date><temp_C>30</temp_C></item></items><temp_C>28.5</temp_C></response>`+"\n", string(body))
+}
+
+func TestCSV_Encode(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "object",
+			value: sample{City: "São Paulo", TempC: 28.5, Tags: []string{"a", "b"}},
+			want:  "city,tags,temp_C\nSão Paulo,a;b,28.5\n",
+		},
+		{
+			name:  "object with list",
+			value: sample{City: "São Paulo", TempC: 28.5, Items: []point{{Date: "2024-01-10", TempC: 30}, {Date: "2024-01-11", TempC: 31.5}}},
+			want:  "city,temp_C,date\nSão Paulo,30,2024-01-10\nSão Paulo,31.5,2024-01-11\n",
+		},
+		{
+			name:  "list",
+			value: []point{{Date: "2024-01-10", TempC: 30}},
+			want:  "date,temp_C\n2024-01-10,30\n",
+		},
+		{
+			name:  "nested object",
+			value: map[string]interface{}{"location": map[string]interface{}{"city": "São Paulo", "state": "SP"}},
+			want:  "location.city,location.state\nSão Paulo,SP\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := Encode(CSV, tt.value)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+}
+
+func TestProtobuf_Encode(t *testing.T) {
+	body, err := Encode(Protobuf, sample{City: "São Paulo", TempC: 28.5})
+	require.NoError(t, err)
+
+	var value structpb.Value
+	require.NoError(t, proto.Unmarshal(body, &value))
+	assert.Equal(t, "São Paulo", value.GetStructValue().GetFields()["city"].GetStringValue())
+	assert.Equal(t, 28.5, value.GetStructValue().GetFields()["temp_C"].GetNumberValue())
+}
+
+func TestProtobuf_EncodeMessage(t *testing.T) {
+	message := structpb.NewStringValue("São Paulo")
+
+	body, err := Encode(Protobuf, message)
+	require.NoError(t, err)
+
+	expected, err := proto.Marshal(message)
+	require.NoError(t, err)
+	assert.Equal(t, expected, body)
+}
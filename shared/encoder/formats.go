@@ -0,0 +1,271 @@
+package encoder
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Encoders dos formatos suportados
+var (
+	JSON     Encoder = jsonEncoder{}
+	XML      Encoder = xmlEncoder{}
+	CSV      Encoder = csvEncoder{}
+	Protobuf Encoder = protobufEncoder{}
+)
+
+// IsJSON indica se o encoder é o JSON, cujo Content-Type pode ser um media type próprio
+// (ex.: application/vnd.weather.v2+json)
+func IsJSON(enc Encoder) bool {
+	return enc == JSON
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return MediaTypeJSON
+}
+
+func (jsonEncoder) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// xmlEncoder grava o JSON como XML: a raiz é <response>, cada campo vira um elemento com o
+// mesmo nome e cada item de uma lista, um elemento <item>
+type xmlEncoder struct{}
+
+func (xmlEncoder) ContentType() string {
+	return MediaTypeXML + "; charset=utf-8"
+}
+
+func (xmlEncoder) Encode(w io.Writer, v interface{}) error {
+	tree, err := generic(v, true)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXMLElement(enc, "response", tree); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func writeXMLElement(enc *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			if err := writeXMLElement(enc, key, v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := writeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(scalarText(v))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// csvEncoder grava o JSON como tabela. As linhas são os itens da resposta: a própria lista, ou
+// a única lista de objetos do objeto (ex.: items, points, forecast), com os demais campos
+// repetidos em cada linha; sem lista, a resposta é uma linha só. Objetos aninhados viram colunas
+// com o caminho separado por ponto (ex.: location.city).
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string {
+	return MediaTypeCSV + "; charset=utf-8"
+}
+
+func (csvEncoder) Encode(w io.Writer, v interface{}) error {
+	tree, err := generic(v, true)
+	if err != nil {
+		return err
+	}
+	header, rows := csvRows(tree)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, column := range header {
+			record[i] = row[column]
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// csvRows achata a resposta em linhas; as colunas do objeto vêm antes das colunas dos itens
+func csvRows(tree interface{}) ([]string, []map[string]string) {
+	var items []interface{}
+	common := map[string]string{}
+	switch v := tree.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		listKey := ""
+		for key, value := range v {
+			if isObjectList(value) {
+				if listKey != "" {
+					listKey = "" // Mais de uma lista: a resposta vira uma linha só
+					break
+				}
+				listKey = key
+			}
+		}
+		if listKey == "" {
+			flatten(common, "", v)
+			return sortedKeysOf(common), []map[string]string{common}
+		}
+		items = v[listKey].([]interface{})
+		rest := make(map[string]interface{}, len(v)-1)
+		for key, value := range v {
+			if key != listKey {
+				rest[key] = value
+			}
+		}
+		flatten(common, "", rest)
+	default:
+		flatten(common, "value", tree)
+		return []string{"value"}, []map[string]string{common}
+	}
+
+	header := sortedKeysOf(common)
+	itemColumns := map[string]string{}
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		row := map[string]string{}
+		flatten(row, "", item)
+		for column := range row {
+			itemColumns[column] = ""
+		}
+		for column, value := range common {
+			if _, ok := row[column]; !ok {
+				row[column] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	for _, column := range sortedKeysOf(itemColumns) {
+		if _, ok := common[column]; !ok {
+			header = append(header, column)
+		}
+	}
+	return header, rows
+}
+
+func isObjectList(value interface{}) bool {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return ok
+	}
+	_, isObject := list[0].(map[string]interface{})
+	return isObject
+}
+
+// flatten grava cada valor escalar na coluna do seu caminho; listas usam o índice no caminho
+// (ex.: temperatures.0.unit), exceto as de escalares, unidas por ponto e vírgula
+func flatten(row map[string]string, prefix string, value interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flatten(row, join(key), child)
+		}
+	case []interface{}:
+		if !isObjectList(v) {
+			values := make([]string, len(v))
+			for i, item := range v {
+				values[i] = scalarText(item)
+			}
+			row[prefix] = strings.Join(values, ";")
+			return
+		}
+		for i, child := range v {
+			flatten(row, join(fmt.Sprint(i)), child)
+		}
+	default:
+		row[prefix] = scalarText(v)
+	}
+}
+
+func sortedKeysOf(row map[string]string) []string {
+	object := make(map[string]interface{}, len(row))
+	for k := range row {
+		object[k] = nil
+	}
+	return sortedKeys(object)
+}
+
+// scalarText é o texto de um valor escalar: números como no JSON e null vazio
+func scalarText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// protobufEncoder grava mensagens protobuf como estão; os demais valores são gravados como
+// google.protobuf.Value, com a mesma estrutura do JSON
+type protobufEncoder struct{}
+
+func (protobufEncoder) ContentType() string {
+	return MediaTypeProtobuf
+}
+
+func (protobufEncoder) Encode(w io.Writer, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		tree, err := generic(v, false)
+		if err != nil {
+			return err
+		}
+		if message, err = structpb.NewValue(tree); err != nil {
+			return err
+		}
+	}
+	body, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
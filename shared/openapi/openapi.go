@@ -0,0 +1,260 @@
+// Package openapi carrega um contrato OpenAPI, serve o documento em /openapi.json e valida as
+// requisições (e, nos testes, as respostas) contra ele. Cada serviço embute o próprio documento
+// e informa como gravar os erros no seu formato de problema.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Códigos dos problemas gravados pelo validador, iguais aos dos serviços
+const (
+	CodeInvalidParameter     = "INVALID_PARAMETER"
+	CodeInvalidRequestBody   = "INVALID_REQUEST_BODY"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeInternal             = "INTERNAL_ERROR"
+)
+
+// streamingExtension marca as operações cuja resposta é transmitida aos poucos e, por isso,
+// não passa pela validação de resposta
+const streamingExtension = "x-streaming"
+
+// vendorMediaTypes são os media types JSON próprios dos contratos (versões da resposta de CEP),
+// decodificados como application/json na validação
+var vendorMediaTypes = []string{"application/vnd.weather.v1+json", "application/vnd.weather.v2+json"}
+
+func init() {
+	for _, mediaType := range vendorMediaTypes {
+		openapi3filter.RegisterBodyDecoder(mediaType, openapi3filter.JSONBodyDecoder)
+	}
+}
+
+// ProblemWriter grava a resposta de erro do validador no formato do serviço. format é a chave
+// do catálogo de mensagens (ex.: "invalid %s parameter").
+type ProblemWriter func(ctx context.Context, w http.ResponseWriter, status int, code, format string, args ...interface{})
+
+// Load carrega o documento e verifica se ele é um OpenAPI 3 válido
+func Load(spec []byte) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("parse openapi spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+	return doc, nil
+}
+
+// NewSpecHandler serve o documento em JSON (GET /openapi.json)
+func NewSpecHandler(doc *openapi3.T) (http.Handler, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode openapi spec: %w", err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}), nil
+}
+
+// Validator confere as requisições, e opcionalmente as respostas, contra o documento
+type Validator struct {
+	router            routers.Router
+	writeProblem      ProblemWriter
+	tracerName        string
+	validateResponses bool
+}
+
+// ValidatorOption configura o Validator
+type ValidatorOption func(*Validator)
+
+// WithResponseValidation também valida as respostas: uma resposta fora do contrato é trocada por
+// um 500. Feito para os testes e ambientes de homologação, porque a resposta fica em memória.
+func WithResponseValidation() ValidatorOption {
+	return func(v *Validator) {
+		v.validateResponses = true
+	}
+}
+
+// WithTracerName define o nome do tracer dos spans de validação (padrão "openapi")
+func WithTracerName(name string) ValidatorOption {
+	return func(v *Validator) {
+		v.tracerName = name
+	}
+}
+
+// NewValidator cria o validador das rotas do documento, com os erros gravados por writeProblem
+func NewValidator(doc *openapi3.T, writeProblem ProblemWriter, opts ...ValidatorOption) (*Validator, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("build openapi router: %w", err)
+	}
+	v := &Validator{router: router, writeProblem: writeProblem, tracerName: "openapi"}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v, nil
+}
+
+// Middleware rejeita com 400 as requisições fora do contrato antes de chegarem ao handler.
+// Rotas que não estão no documento seguem direto para o próximo handler.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		tracer := otel.Tracer(v.tracerName)
+		ctx, span := tracer.Start(ctx, "openapi-validate-request")
+		span.SetAttributes(attribute.String("operation", route.Operation.OperationID))
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			// Credenciais são conferidas pelos middlewares de autenticação dos serviços
+			Options: &openapi3filter.Options{SkipSettingDefaults: true, AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		}
+		if !v.validateRequest(ctx, w, input) {
+			span.SetStatus(codes.Error, "Request does not match the API contract")
+			span.End()
+			return
+		}
+		span.End()
+
+		if !v.validateResponses || route.Operation.Extensions[streamingExtension] == true {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if isEncoded(recorder.header.Get("Content-Type")) {
+			// XML, CSV e protobuf são gerados a partir do mesmo valor do JSON; só o JSON é validado
+			recorder.copyTo(w)
+			return
+		}
+		if err := v.validateResponse(ctx, input, recorder); err != nil {
+			log.Printf("OpenAPI: Response to %s %s does not match the API contract: %v", r.Method, r.URL.Path, err)
+			v.writeProblem(ctx, w, http.StatusInternalServerError, CodeInternal, "response does not match the api contract")
+			return
+		}
+		recorder.copyTo(w)
+	})
+}
+
+// validateRequest grava o problema e retorna false quando a requisição viola o contrato.
+// Corpo sem Content-Type é tratado como JSON, como os handlers sempre fizeram.
+func (v *Validator) validateRequest(ctx context.Context, w http.ResponseWriter, input *openapi3filter.RequestValidationInput) bool {
+	r := input.Request
+	if body := input.Route.Operation.RequestBody; body != nil && body.Value != nil {
+		contentType := r.Header.Get("Content-Type")
+		if contentType == "" {
+			r.Header.Set("Content-Type", "application/json")
+		} else if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || body.Value.Content.Get(mediaType) == nil {
+			log.Printf("OpenAPI: Unsupported content type %q for %s %s", contentType, r.Method, r.URL.Path)
+			v.writeProblem(ctx, w, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "unsupported content type")
+			return false
+		}
+	}
+
+	err := openapi3filter.ValidateRequest(ctx, input)
+	if err == nil {
+		return true
+	}
+	log.Printf("OpenAPI: Invalid request %s %s: %v", r.Method, r.URL.Path, err)
+
+	var reqErr *openapi3filter.RequestError
+	switch {
+	case errors.As(err, &reqErr) && reqErr.Parameter != nil:
+		v.writeProblem(ctx, w, http.StatusBadRequest, CodeInvalidParameter, "invalid %s parameter", reqErr.Parameter.Name)
+	case errors.As(err, &reqErr) && reqErr.RequestBody != nil:
+		v.writeProblem(ctx, w, http.StatusBadRequest, CodeInvalidRequestBody, "invalid request body")
+	default:
+		v.writeProblem(ctx, w, http.StatusBadRequest, CodeInvalidParameter, "invalid request")
+	}
+	return false
+}
+
+// validateResponse confere status, Content-Type e corpo da resposta gravada
+func (v *Validator) validateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, recorder *responseRecorder) error {
+	tracer := otel.Tracer(v.tracerName)
+	ctx, span := tracer.Start(ctx, "openapi-validate-response")
+	defer span.End()
+	span.SetAttributes(attribute.Int("status", recorder.status))
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 recorder.status,
+		Header:                 recorder.header,
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	responseInput.SetBodyBytes(recorder.body.Bytes())
+	if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Response does not match the API contract")
+		return err
+	}
+	return nil
+}
+
+// isEncoded indica se a resposta está em um formato diferente do JSON (XML, CSV ou protobuf);
+// respostas sem Content-Type continuam sendo validadas
+func isEncoded(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType != "application/json" && mediaType != "application/problem+json" && !strings.HasSuffix(mediaType, "+json")
+}
+
+// responseRecorder guarda a resposta do handler para validá-la antes de enviá-la ao cliente
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) copyTo(w http.ResponseWriter) {
+	for key, values := range r.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(r.status)
+	w.Write(r.body.Bytes())
+}
@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info:
+  title: test
+  version: "1"
+paths:
+  /items:
+    post:
+      operationId: createItem
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 10
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: string
+`
+
+// problem é o que o ProblemWriter de teste recebeu
+type problem struct {
+	status int
+	code   string
+	detail string
+}
+
+func newTestValidator(t *testing.T, got *[]problem, opts ...ValidatorOption) *Validator {
+	t.Helper()
+	doc, err := Load([]byte(testSpec))
+	require.NoError(t, err)
+	writeProblem := func(ctx context.Context, w http.ResponseWriter, status int, code, format string, args ...interface{}) {
+		*got = append(*got, problem{status: status, code: code, detail: fmt.Sprintf(format, args...)})
+		w.WriteHeader(status)
+	}
+	validator, err := NewValidator(doc, writeProblem, opts...)
+	require.NoError(t, err)
+	return validator
+}
+
+func serve(handler http.Handler, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoad_InvalidSpec(t *testing.T) {
+	_, err := Load([]byte("openapi: 3.0.3\npaths: {}\n"))
+
+	assert.Error(t, err)
+}
+
+func TestMiddleware_Requests(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		want        problem
+	}{
+		{name: "invalid parameter", target: "/items?limit=50", body: `{"name":"a"}`, want: problem{http.StatusBadRequest, CodeInvalidParameter, "invalid limit parameter"}},
+		{name: "invalid body", target: "/items", body: `{}`, want: problem{http.StatusBadRequest, CodeInvalidRequestBody, "invalid request body"}},
+		{name: "unsupported content type", target: "/items", contentType: "text/plain", body: `name`, want: problem{http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "unsupported content type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []problem
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+			w := serve(newTestValidator(t, &got).Middleware(next), tt.target, tt.contentType, tt.body)
+
+			assert.Equal(t, tt.want.status, w.Code)
+			assert.Equal(t, []problem{tt.want}, got)
+			assert.False(t, called)
+		})
+	}
+}
+
+func TestMiddleware_ValidRequestWithoutContentType(t *testing.T) {
+	var got []problem
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	serve(newTestValidator(t, &got).Middleware(next), "/items", "", `{"name":"a"}`)
+
+	assert.True(t, called)
+	assert.Empty(t, got)
+}
+
+func TestMiddleware_ResponseDrift(t *testing.T) {
+	var got []problem
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"name":"a"}`)
+	})
+
+	w := serve(newTestValidator(t, &got, WithResponseValidation()).Middleware(next), "/items", "", `{"name":"a"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, []problem{{http.StatusInternalServerError, CodeInternal, "response does not match the api contract"}}, got)
+}